@@ -0,0 +1,134 @@
+package account
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Fallback cooldowns when a banner doesn't say when the limit resets.
+const (
+	// DefaultUsageCooldown matches Claude's five-hour usage window.
+	DefaultUsageCooldown = 5 * time.Hour
+
+	// DefaultRateCooldown covers short-lived API rate limiting.
+	DefaultRateCooldown = 5 * time.Minute
+
+	// passedResetWindow is how far back a clock-only reset time ("resets
+	// 3pm") is read as having just passed rather than as tomorrow's. A
+	// banner never names a reset further out than the usage window.
+	passedResetWindow = DefaultUsageCooldown
+)
+
+// Limit kinds.
+const (
+	LimitUsage = "usage"
+	LimitRate  = "rate"
+)
+
+// Limit is a usage or rate limit banner found in a session's pane.
+type Limit struct {
+	Kind   string    // LimitUsage or LimitRate
+	Reset  time.Time // when the account can be used again
+	Banner string    // the line the limit was found on
+}
+
+var (
+	// "Claude usage limit reached. Your limit will reset at 3pm (America/Los_Angeles)."
+	// "5-hour limit reached ∙ resets 3pm", "You've hit your limit · resets 5am"
+	usageLimitRe = regexp.MustCompile(`(?i)(limit reached|hit your (usage )?limit|out of extra usage)`)
+
+	// "API Error: 429 {"type":"error","error":{"type":"rate_limit_error",...}}"
+	rateLimitRe = regexp.MustCompile(`(?i)(rate_limit_error|rate limit(ed)? (exceeded|reached)|429 too many requests)`)
+
+	// "Claude AI usage limit reached|1767225600" (reset as a Unix time)
+	epochResetRe = regexp.MustCompile(`limit reached\|(\d{9,})`)
+
+	// "resets 3pm", "reset at 3:30pm (Europe/Berlin)", "resets Oct 20, 9am"
+	clockResetRe = regexp.MustCompile(`(?i)resets?(?:\s+at)?\s+(?:([a-z]{3})[a-z]*\.?\s+(\d{1,2}),?\s+(?:at\s+)?)?(\d{1,2})(?::(\d{2}))?\s*(am|pm)(?:\s*\(([^)]+)\))?`)
+)
+
+// DetectLimit looks for a usage or rate limit banner in captured pane
+// output and returns the last one, or nil. "Approaching usage limit"
+// warnings are not limits, and neither is a banner whose reset time has
+// passed: it is left over on screen from a limit that is already over.
+func DetectLimit(pane string, now time.Time) *Limit {
+	lines := strings.Split(pane, "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		var limit *Limit
+		switch {
+		case rateLimitRe.MatchString(line):
+			limit = &Limit{Kind: LimitRate, Reset: parseReset(line, now, DefaultRateCooldown), Banner: line}
+		case usageLimitRe.MatchString(line):
+			limit = &Limit{Kind: LimitUsage, Reset: parseReset(line, now, DefaultUsageCooldown), Banner: line}
+		default:
+			continue
+		}
+		if !limit.Reset.After(now) {
+			return nil
+		}
+		return limit
+	}
+	return nil
+}
+
+// parseReset extracts the reset time from a banner line, falling back to
+// now+fallback. Clock times are in the banner's time zone if it names
+// one, else local time. A clock time that passed within passedResetWindow
+// is returned as is; an earlier one refers to the next such time after now.
+// The result is not after now when the limit has already reset.
+func parseReset(line string, now time.Time, fallback time.Duration) time.Time {
+	if m := epochResetRe.FindStringSubmatch(line); m != nil {
+		if secs, err := strconv.ParseInt(m[1], 10, 64); err == nil {
+			return time.Unix(secs, 0)
+		}
+	}
+
+	m := clockResetRe.FindStringSubmatch(line)
+	if m == nil {
+		return now.Add(fallback)
+	}
+	loc := now.Location()
+	if m[6] != "" {
+		if l, err := time.LoadLocation(strings.TrimSpace(m[6])); err == nil {
+			loc = l
+		}
+	}
+	hour, _ := strconv.Atoi(m[3])
+	minute, _ := strconv.Atoi(m[4])
+	if hour < 1 || hour > 12 || minute > 59 {
+		return now.Add(fallback)
+	}
+	hour %= 12
+	if strings.EqualFold(m[5], "pm") {
+		hour += 12
+	}
+
+	local := now.In(loc)
+	if m[1] != "" {
+		month, err := time.Parse("Jan", strings.ToUpper(m[1][:1])+strings.ToLower(m[1][1:]))
+		day, _ := strconv.Atoi(m[2])
+		if err != nil || day < 1 || day > 31 {
+			return now.Add(fallback)
+		}
+		reset := time.Date(local.Year(), month.Month(), day, hour, minute, 0, 0, loc)
+		if !reset.After(now) {
+			reset = reset.AddDate(1, 0, 0)
+		}
+		return reset
+	}
+	reset := time.Date(local.Year(), local.Month(), local.Day(), hour, minute, 0, 0, loc)
+	if reset.After(now) {
+		// Still ahead today, unless it is yesterday's reset that just passed
+		if yesterday := reset.AddDate(0, 0, -1); now.Sub(yesterday) <= passedResetWindow {
+			return yesterday
+		}
+		return reset
+	}
+	if now.Sub(reset) <= passedResetWindow {
+		return reset
+	}
+	return reset.AddDate(0, 0, 1)
+}
@@ -0,0 +1,92 @@
+package account
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDetectLimit(t *testing.T) {
+	la, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Skipf("no tzdata: %v", err)
+	}
+	now := time.Date(2026, 3, 10, 13, 20, 0, 0, la) // 1:20pm
+
+	tests := []struct {
+		name  string
+		pane  string
+		kind  string
+		reset time.Time
+	}{
+		{
+			name:  "usage limit with zone",
+			pane:  "⏺ Running tests...\nClaude usage limit reached. Your limit will reset at 3pm (America/Los_Angeles).\n> ",
+			kind:  LimitUsage,
+			reset: time.Date(2026, 3, 10, 15, 0, 0, 0, la),
+		},
+		{
+			name:  "reset time long passed today",
+			pane:  "5-hour limit reached ∙ resets 7am",
+			kind:  LimitUsage,
+			reset: time.Date(2026, 3, 11, 7, 0, 0, 0, la),
+		},
+		{
+			name:  "weekly limit with date",
+			pane:  "You've hit your limit · resets Mar 14, 9am (America/Los_Angeles)",
+			kind:  LimitUsage,
+			reset: time.Date(2026, 3, 14, 9, 0, 0, 0, la),
+		},
+		{
+			name:  "epoch reset",
+			pane:  "Claude AI usage limit reached|1773190800",
+			kind:  LimitUsage,
+			reset: time.Unix(1773190800, 0),
+		},
+		{
+			name:  "usage limit without reset",
+			pane:  "Claude usage limit reached.",
+			kind:  LimitUsage,
+			reset: now.Add(DefaultUsageCooldown),
+		},
+		{
+			name:  "rate limit",
+			pane:  `API Error: 429 {"type":"error","error":{"type":"rate_limit_error","message":"slow down"}}`,
+			kind:  LimitRate,
+			reset: now.Add(DefaultRateCooldown),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetectLimit(tt.pane, now)
+			if got == nil {
+				t.Fatal("DetectLimit = nil")
+			}
+			if got.Kind != tt.kind || !got.Reset.Equal(tt.reset) {
+				t.Errorf("DetectLimit = %s until %v, want %s until %v", got.Kind, got.Reset, tt.kind, tt.reset)
+			}
+		})
+	}
+
+	for _, pane := range []string{
+		"",
+		"⏺ All tests pass.\n> ",
+		"Approaching usage limit · resets at 3pm",
+		"5-hour limit reached ∙ resets 11:30am",    // just passed
+		"Claude AI usage limit reached|1773160800", // 9:40am, passed
+	} {
+		if got := DetectLimit(pane, now); got != nil {
+			t.Errorf("DetectLimit(%q) = %+v, want nil", pane, got)
+		}
+	}
+}
+
+func TestDetectLimitResetJustPassedYesterday(t *testing.T) {
+	now := time.Date(2026, 3, 11, 1, 0, 0, 0, time.UTC)
+	if got := DetectLimit("You've hit your limit · resets 11pm", now); got != nil {
+		t.Errorf("DetectLimit = %+v, want nil for last night's reset", got)
+	}
+	want := time.Date(2026, 3, 11, 3, 0, 0, 0, time.UTC)
+	if got := DetectLimit("You've hit your limit · resets 3am", now); got == nil || !got.Reset.Equal(want) {
+		t.Errorf("DetectLimit = %+v, want reset at %v", got, want)
+	}
+}
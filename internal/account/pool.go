@@ -0,0 +1,98 @@
+package account
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+)
+
+// ConfigDirEnv is the session environment variable naming the account a
+// session runs on.
+const ConfigDirEnv = "CLAUDE_CONFIG_DIR"
+
+// sessionLister is the part of session.SessionBackend the pool needs.
+type sessionLister interface {
+	ListSessions() ([]string, error)
+	GetEnvironment(session, key string) (string, error)
+}
+
+// SessionCounts returns the number of live Gas Town sessions on each
+// account, found by matching each session's CLAUDE_CONFIG_DIR against the
+// accounts' config dirs.
+func SessionCounts(b sessionLister, cfg *config.AccountsConfig) map[string]int {
+	counts := make(map[string]int, len(cfg.Accounts))
+	sessions, err := b.ListSessions()
+	if err != nil {
+		return counts
+	}
+	for _, name := range sessions {
+		if !strings.HasPrefix(name, constants.SessionPrefix) && !strings.HasPrefix(name, constants.HQSessionPrefix) {
+			continue
+		}
+		dir, err := b.GetEnvironment(name, ConfigDirEnv)
+		if err != nil {
+			continue
+		}
+		if handle := cfg.HandleForConfigDir(dir); handle != "" {
+			counts[handle]++
+		}
+	}
+	return counts
+}
+
+// Pick returns the account a new session should use: the one with the
+// fewest live sessions among those not cooling down, preferring the
+// default and then handle order on ties. If every account is cooling
+// down, it returns the one that resets soonest. It returns "" if no
+// accounts are configured.
+func Pick(cfg *config.AccountsConfig, state *State, counts map[string]int, now time.Time) string {
+	handles := make([]string, 0, len(cfg.Accounts))
+	for handle := range cfg.Accounts {
+		handles = append(handles, handle)
+	}
+	sort.Slice(handles, func(i, j int) bool {
+		a, b := handles[i], handles[j]
+		if counts[a] != counts[b] {
+			return counts[a] < counts[b]
+		}
+		if (a == cfg.Default) != (b == cfg.Default) {
+			return a == cfg.Default
+		}
+		return a < b
+	})
+
+	soonest := ""
+	var soonestUntil time.Time
+	for _, handle := range handles {
+		c, cooling := state.CoolingDown(handle, now)
+		if !cooling {
+			return handle
+		}
+		if soonest == "" || c.Until.Before(soonestUntil) {
+			soonest, soonestUntil = handle, c.Until
+		}
+	}
+	return soonest
+}
+
+// ResolveSpawnAccount resolves the account for a new polecat session. In
+// pool mode, unless accountFlag or GT_ACCOUNT names an account, it picks
+// one with Pick; otherwise it defers to config.ResolveAccountConfigDir.
+func ResolveSpawnAccount(townRoot string, b sessionLister, accountFlag string) (configDir, handle string, err error) {
+	accountsPath := constants.MayorAccountsPath(townRoot)
+	cfg, loadErr := config.LoadAccountsConfig(accountsPath)
+	if loadErr != nil || !cfg.Pool || len(cfg.Accounts) == 0 || accountFlag != "" || os.Getenv("GT_ACCOUNT") != "" {
+		return config.ResolveAccountConfigDir(accountsPath, accountFlag)
+	}
+
+	state, err := LoadState(StatePath(townRoot))
+	if err != nil {
+		return "", "", err
+	}
+	handle = Pick(cfg, state, SessionCounts(b, cfg), time.Now())
+	return cfg.Accounts[handle].ExpandedConfigDir(), handle, nil
+}
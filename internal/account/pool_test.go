@@ -0,0 +1,120 @@
+package account
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+type fakeSessions map[string]string // session -> CLAUDE_CONFIG_DIR
+
+func (f fakeSessions) ListSessions() ([]string, error) {
+	var names []string
+	for name := range f {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (f fakeSessions) GetEnvironment(session, key string) (string, error) {
+	return f[session], nil
+}
+
+func testAccounts() *config.AccountsConfig {
+	cfg := config.NewAccountsConfig()
+	cfg.Accounts["alpha"] = config.Account{ConfigDir: "/accounts/alpha"}
+	cfg.Accounts["bravo"] = config.Account{ConfigDir: "/accounts/bravo/"}
+	cfg.Accounts["charlie"] = config.Account{ConfigDir: "/accounts/charlie"}
+	cfg.Default = "charlie"
+	cfg.Pool = true
+	return cfg
+}
+
+func TestSessionCounts(t *testing.T) {
+	sessions := fakeSessions{
+		"gt-gastown-nux":     "/accounts/alpha",
+		"gt-gastown-furiosa": "/accounts/alpha",
+		"gt-gastown-slit":    "/accounts/bravo",
+		"hq-mayor":           "/accounts/bravo",
+		"gt-gastown-witness": "",
+		"personal":           "/accounts/charlie",
+	}
+	got := SessionCounts(sessions, testAccounts())
+	if got["alpha"] != 2 || got["bravo"] != 2 || got["charlie"] != 0 {
+		t.Errorf("SessionCounts = %v", got)
+	}
+}
+
+func TestPick(t *testing.T) {
+	cfg := testAccounts()
+	now := time.Now()
+	state := &State{Cooldowns: make(map[string]Cooldown)}
+
+	// Least loaded wins; the default breaks ties.
+	if got := Pick(cfg, state, map[string]int{"alpha": 1, "bravo": 3, "charlie": 2}, now); got != "alpha" {
+		t.Errorf("Pick = %s, want alpha", got)
+	}
+	if got := Pick(cfg, state, map[string]int{}, now); got != "charlie" {
+		t.Errorf("Pick on idle pool = %s, want default charlie", got)
+	}
+
+	// Cooling accounts are skipped however idle they are.
+	state.MarkCoolingDown("alpha", now.Add(time.Hour), now, "limit reached")
+	if got := Pick(cfg, state, map[string]int{"alpha": 0, "bravo": 3, "charlie": 2}, now); got != "charlie" {
+		t.Errorf("Pick with alpha cooling = %s, want charlie", got)
+	}
+
+	// With everything cooling, the soonest reset wins.
+	state.MarkCoolingDown("bravo", now.Add(10*time.Minute), now, "limit reached")
+	state.MarkCoolingDown("charlie", now.Add(2*time.Hour), now, "limit reached")
+	if got := Pick(cfg, state, nil, now); got != "bravo" {
+		t.Errorf("Pick with all cooling = %s, want bravo", got)
+	}
+}
+
+func TestStateRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "accounts.json")
+	now := time.Now().Truncate(time.Second)
+
+	state, err := LoadState(path)
+	if err != nil || len(state.Cooldowns) != 0 {
+		t.Fatalf("LoadState(missing) = %+v, %v", state, err)
+	}
+	state.MarkCoolingDown("alpha", now.Add(time.Hour), now, "limit reached")
+	state.MarkCoolingDown("alpha", now.Add(time.Minute), now, "rate limit") // earlier reset ignored
+	state.MarkCoolingDown("bravo", now.Add(-cooldownRetention-time.Minute), now, "stale")
+	state.MarkCoolingDown("charlie", now.Add(-time.Minute), now, "limit reached")
+	if err := state.Save(path, now); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	loaded, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("LoadState: %v", err)
+	}
+	c, ok := loaded.CoolingDown("alpha", now)
+	if !ok || !c.Until.Equal(now.Add(time.Hour)) || c.Reason != "limit reached" {
+		t.Errorf("alpha cooldown = %+v, %v", c, ok)
+	}
+	if _, ok := loaded.Cooldowns["bravo"]; ok {
+		t.Error("long-expired cooldown was saved")
+	}
+	if _, ok := loaded.CoolingDown("charlie", now); ok {
+		t.Error("ended cooldown still in effect")
+	}
+
+	// The same banner, still on screen, doesn't re-arm or extend a cooldown.
+	loaded.MarkCoolingDown("charlie", now.Add(5*time.Hour), now, "limit reached")
+	if _, ok := loaded.CoolingDown("charlie", now); ok {
+		t.Error("unchanged banner re-armed an ended cooldown")
+	}
+	loaded.MarkCoolingDown("alpha", now.Add(5*time.Hour), now, "limit reached")
+	if c, _ := loaded.CoolingDown("alpha", now); !c.Until.Equal(now.Add(time.Hour)) {
+		t.Errorf("unchanged banner extended alpha to %v", c.Until)
+	}
+	if _, ok := loaded.CoolingDown("alpha", now.Add(2*time.Hour)); ok {
+		t.Error("cooldown still in effect after it ended")
+	}
+}
@@ -0,0 +1,94 @@
+// Package account implements the Claude account pool: spreading spawns
+// across the accounts in mayor/accounts.json and resting accounts that
+// have hit a usage limit until it resets.
+package account
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// Cooldown records that an account hit a usage or rate limit.
+type Cooldown struct {
+	Until  time.Time `json:"until"`            // when the limit resets
+	Since  time.Time `json:"since"`            // when the limit was detected
+	Reason string    `json:"reason,omitempty"` // the banner that was seen
+}
+
+// State is the pool's runtime state: which accounts are cooling down.
+// It lives in <town>/.runtime so it is never committed.
+type State struct {
+	Cooldowns map[string]Cooldown `json:"cooldowns"` // handle -> cooldown
+}
+
+// StatePath returns the pool state file: <town>/.runtime/accounts.json.
+func StatePath(townRoot string) string {
+	return filepath.Join(constants.TownRuntimePath(townRoot), constants.FileAccountsJSON)
+}
+
+// LoadState loads the pool state. A missing file returns an empty state.
+func LoadState(path string) (*State, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &State{Cooldowns: make(map[string]Cooldown)}, nil
+		}
+		return nil, fmt.Errorf("reading account state: %w", err)
+	}
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parsing account state: %w", err)
+	}
+	if s.Cooldowns == nil {
+		s.Cooldowns = make(map[string]Cooldown)
+	}
+	return &s, nil
+}
+
+// cooldownRetention is how long an ended cooldown is kept, so the banner
+// that caused it, still on screen, doesn't re-arm it.
+const cooldownRetention = DefaultUsageCooldown
+
+// Save writes the state to path, dropping cooldowns that ended more than
+// cooldownRetention before now. Witnesses save independently; a lost
+// update only means a limit is detected again on the next patrol.
+func (s *State) Save(path string, now time.Time) error {
+	for handle, c := range s.Cooldowns {
+		if !now.Before(c.Until.Add(cooldownRetention)) {
+			delete(s.Cooldowns, handle)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating account state dir: %w", err)
+	}
+	return util.AtomicWriteJSON(path, s)
+}
+
+// CoolingDown returns the account's cooldown if it is still in effect.
+func (s *State) CoolingDown(handle string, now time.Time) (Cooldown, bool) {
+	c, ok := s.Cooldowns[handle]
+	if !ok || !now.Before(c.Until) {
+		return Cooldown{}, false
+	}
+	return c, true
+}
+
+// MarkCoolingDown rests an account until the given time. An existing
+// cooldown that ends later is kept, and so is one (even if ended) from the
+// same banner: a banner without a reset time would otherwise push the
+// cooldown back on every patrol for as long as it stays on screen.
+func (s *State) MarkCoolingDown(handle string, until, now time.Time, reason string) {
+	if c, ok := s.Cooldowns[handle]; ok && reason != "" && c.Reason == reason {
+		return
+	}
+	if c, ok := s.CoolingDown(handle, now); ok && !until.After(c.Until) {
+		return
+	}
+	s.Cooldowns[handle] = Cooldown{Until: until, Since: now, Reason: reason}
+}
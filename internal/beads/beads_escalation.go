@@ -27,6 +27,45 @@ type EscalationFields struct {
 	ReescalationCount  int    // Number of times this has been re-escalated
 	LastReescalatedAt  string // When last re-escalated (empty if never)
 	LastReescalatedBy  string // Who last re-escalated (empty if never)
+	Deliveries         []EscalationDelivery // External notification attempts, oldest first
+}
+
+// EscalationDelivery records one external notification (email, SMS, Slack,
+// webhook) sent for an escalation. Stored as a "delivery:" line:
+//
+//	delivery: email:human | sent | 2025-01-02T15:04:05Z | attempts=1 | oncall@example.com
+type EscalationDelivery struct {
+	Action   string // Route action, e.g. "email:human"
+	Status   string // sent, failed, skipped
+	At       string // ISO 8601 timestamp of the last attempt
+	Attempts int    // Number of attempts made (0 if skipped)
+	Detail   string // Recipient, error, or skip reason
+}
+
+// String formats the delivery as stored after "delivery: ".
+func (d EscalationDelivery) String() string {
+	detail := strings.NewReplacer("\n", " ", "|", "/").Replace(d.Detail)
+	return fmt.Sprintf("%s | %s | %s | attempts=%d | %s", d.Action, d.Status, d.At, d.Attempts, detail)
+}
+
+// parseEscalationDelivery parses a "delivery:" value written by String.
+func parseEscalationDelivery(value string) (EscalationDelivery, bool) {
+	parts := strings.SplitN(value, " | ", 5)
+	if len(parts) < 4 {
+		return EscalationDelivery{}, false
+	}
+	d := EscalationDelivery{
+		Action: strings.TrimSpace(parts[0]),
+		Status: strings.TrimSpace(parts[1]),
+		At:     strings.TrimSpace(parts[2]),
+	}
+	if n, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(parts[3]), "attempts=")); err == nil {
+		d.Attempts = n
+	}
+	if len(parts) == 5 {
+		d.Detail = strings.TrimSpace(parts[4])
+	}
+	return d, true
 }
 
 // EscalationState constants for bead status tracking.
@@ -103,6 +142,10 @@ func FormatEscalationDescription(title string, fields *EscalationFields) string
 		lines = append(lines, "last_reescalated_by: null")
 	}
 
+	for _, d := range fields.Deliveries {
+		lines = append(lines, "delivery: "+d.String())
+	}
+
 	return strings.Join(lines, "\n")
 }
 
@@ -158,6 +201,10 @@ func ParseEscalationFields(description string) *EscalationFields {
 			fields.LastReescalatedAt = value
 		case "last_reescalated_by":
 			fields.LastReescalatedBy = value
+		case "delivery":
+			if d, ok := parseEscalationDelivery(value); ok {
+				fields.Deliveries = append(fields.Deliveries, d)
+			}
 		}
 	}
 
@@ -228,6 +275,30 @@ func (b *Beads) AckEscalation(id, ackedBy string) error {
 	})
 }
 
+// RecordEscalationDeliveries appends external notification records to an
+// escalation bead.
+func (b *Beads) RecordEscalationDeliveries(id string, deliveries []EscalationDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	issue, err := b.Show(id)
+	if err != nil {
+		return err
+	}
+
+	// Verify it's an escalation
+	if !HasLabel(issue, "gt:escalation") {
+		return fmt.Errorf("issue %s is not an escalation bead (missing gt:escalation label)", id)
+	}
+
+	fields := ParseEscalationFields(issue.Description)
+	fields.Deliveries = append(fields.Deliveries, deliveries...)
+	description := FormatEscalationDescription(issue.Title, fields)
+
+	return b.Update(id, UpdateOptions{Description: &description})
+}
+
 // CloseEscalation closes an escalation bead with a resolution reason.
 // Sets closed_by and closed_reason fields, closes the issue.
 func (b *Beads) CloseEscalation(id, closedBy, reason string) error {
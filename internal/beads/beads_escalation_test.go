@@ -0,0 +1,40 @@
+package beads
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEscalationDeliveries_RoundTrip(t *testing.T) {
+	fields := &EscalationFields{
+		Severity:    "critical",
+		Reason:      "Build failing",
+		EscalatedBy: "gastown/witness",
+		EscalatedAt: "2025-01-02T15:04:05Z",
+		Deliveries: []EscalationDelivery{
+			{Action: "email:human", Status: "sent", At: "2025-01-02T15:04:06Z", Attempts: 1, Detail: "oncall@example.com"},
+			{Action: "sms:human", Status: "skipped", At: "2025-01-02T15:04:06Z", Detail: "contacts.human_sms not configured"},
+			{Action: "webhook:pager", Status: "failed", At: "2025-01-02T15:04:09Z", Attempts: 3, Detail: "HTTP 502 Bad Gateway"},
+		},
+	}
+
+	desc := FormatEscalationDescription("Build failing", fields)
+	got := ParseEscalationFields(desc)
+	if !reflect.DeepEqual(got.Deliveries, fields.Deliveries) {
+		t.Errorf("Deliveries = %+v\nwant %+v\ndescription:\n%s", got.Deliveries, fields.Deliveries, desc)
+	}
+	if got.Severity != "critical" || got.EscalatedBy != "gastown/witness" {
+		t.Errorf("other fields lost: %+v", got)
+	}
+}
+
+func TestEscalationDelivery_SanitizesDetail(t *testing.T) {
+	d := EscalationDelivery{Action: "slack", Status: "failed", At: "t", Attempts: 2, Detail: "exit 1: a | b\nc"}
+	got, ok := parseEscalationDelivery(d.String())
+	if !ok {
+		t.Fatalf("parse failed for %q", d.String())
+	}
+	if got.Detail != "exit 1: a / b c" || got.Attempts != 2 {
+		t.Errorf("parsed %+v", got)
+	}
+}
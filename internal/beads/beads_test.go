@@ -568,6 +568,8 @@ func TestMRFieldsRoundTrip(t *testing.T) {
 		Rig:         "gastown",
 		MergeCommit: "abc123def789",
 		CloseReason: "merged",
+
+		ConflictStrategy: "auto_rebase",
 	}
 
 	// Format to string
@@ -184,6 +184,11 @@ type MRFields struct {
 	LastConflictSHA string // SHA of main when conflict occurred
 	ConflictTaskID  string // Link to conflict-resolution task (if any)
 
+	// ConflictStrategy records how the last conflict was handled:
+	// "auto_rebase" (refinery rebased the branch itself) or "assign_back"
+	// (a conflict-resolution task was created).
+	ConflictStrategy string
+
 	// Convoy tracking (for priority scoring - convoy starvation prevention)
 	ConvoyID        string // Parent convoy ID if part of a convoy
 	ConvoyCreatedAt string // Convoy creation time (ISO 8601) for starvation prevention
@@ -255,6 +260,9 @@ func ParseMRFields(issue *Issue) *MRFields {
 		case "conflict_task_id", "conflict-task-id", "conflicttaskid":
 			fields.ConflictTaskID = value
 			hasFields = true
+		case "conflict_strategy", "conflict-strategy", "conflictstrategy":
+			fields.ConflictStrategy = value
+			hasFields = true
 		case "convoy_id", "convoy-id", "convoyid", "convoy":
 			fields.ConvoyID = value
 			hasFields = true
@@ -319,6 +327,9 @@ func FormatMRFields(fields *MRFields) string {
 	if fields.ConflictTaskID != "" {
 		lines = append(lines, "conflict_task_id: "+fields.ConflictTaskID)
 	}
+	if fields.ConflictStrategy != "" {
+		lines = append(lines, "conflict_strategy: "+fields.ConflictStrategy)
+	}
 	if fields.ConvoyID != "" {
 		lines = append(lines, "convoy_id: "+fields.ConvoyID)
 	}
@@ -364,6 +375,9 @@ func SetMRFields(issue *Issue, fields *MRFields) string {
 		"conflict_task_id":   true,
 		"conflict-task-id":   true,
 		"conflicttaskid":     true,
+		"conflict_strategy":  true,
+		"conflict-strategy":  true,
+		"conflictstrategy":   true,
 		"convoy_id":          true,
 		"convoy-id":          true,
 		"convoyid":           true,
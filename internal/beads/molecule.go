@@ -174,6 +174,17 @@ func ParseMoleculeSteps(description string) ([]MoleculeStep, error) {
 	return steps, nil
 }
 
+// StepTier returns the tier hint ("haiku", "sonnet", "opus") recorded in an
+// instantiated step's description, or "" if the step has none.
+func StepTier(description string) string {
+	for _, line := range strings.Split(description, "\n") {
+		if matches := tierLineRegex.FindStringSubmatch(strings.TrimSpace(line)); matches != nil {
+			return strings.ToLower(matches[1])
+		}
+	}
+	return ""
+}
+
 // parseBackoffConfig parses a backoff configuration string.
 // Expected format: "base=30s, multiplier=2, max=10m"
 // Returns nil if parsing fails.
@@ -143,6 +143,22 @@ Tier: opus`
 	}
 }
 
+func TestStepTier(t *testing.T) {
+	tests := []struct {
+		desc, want string
+	}{
+		{"Do it.\n\ninstantiated_from: mol-x\nstep: quick\ntier: haiku", "haiku"},
+		{"Tier: Opus", "opus"},
+		{"instantiated_from: mol-x\nstep: quick", ""},
+		{"tier: gpt", ""},
+	}
+	for _, tt := range tests {
+		if got := StepTier(tt.desc); got != tt.want {
+			t.Errorf("StepTier(%q) = %q, want %q", tt.desc, got, tt.want)
+		}
+	}
+}
+
 func TestParseMoleculeSteps_WithWaitsFor(t *testing.T) {
 	desc := `## Step: survey
 Discover work items.
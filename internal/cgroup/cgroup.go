@@ -0,0 +1,214 @@
+// Package cgroup runs agent sessions in systemd user scopes, so cgroup v2
+// can cap their CPU, memory and process count, and reads back their usage.
+package cgroup
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// controllersPath exists only on a cgroup v2 (unified) hierarchy.
+const controllersPath = "/sys/fs/cgroup/cgroup.controllers"
+
+// ErrUnavailable is returned by Available when sessions can't be limited.
+var ErrUnavailable = errors.New("cgroup v2 resource limits unavailable")
+
+// ErrNoScope is returned by GetUsage when the session has no scope, e.g. it
+// was started without limits.
+var ErrNoScope = errors.New("no scope for session")
+
+// ResultOOMKill is the systemd unit result after the kernel OOM killer
+// stopped a process in it.
+const ResultOOMKill = "oom-kill"
+
+// Available checks that sessions can be wrapped in a scope: cgroup v2 is
+// mounted, systemd-run is installed and the user's systemd manager answers.
+func Available() error {
+	if _, err := os.Stat(controllersPath); err != nil {
+		return fmt.Errorf("%w: cgroup v2 is not mounted", ErrUnavailable)
+	}
+	if _, err := exec.LookPath("systemd-run"); err != nil {
+		return fmt.Errorf("%w: systemd-run not found", ErrUnavailable)
+	}
+	if err := exec.Command("systemctl", "--user", "show-environment").Run(); err != nil {
+		return fmt.Errorf("%w: no systemd user manager: %v", ErrUnavailable, err)
+	}
+	return nil
+}
+
+var unitUnsafeRe = regexp.MustCompile(`[^A-Za-z0-9:_.-]`)
+
+// ScopeUnit returns the name of the scope a session runs in.
+func ScopeUnit(session string) string {
+	return unitUnsafeRe.ReplaceAllString(session, "_") + ".scope"
+}
+
+// WrapCommand returns command wrapped to run in a new scope unit with the
+// given limits. Memory limits also disable swap, so a runaway session is
+// OOM-killed instead of dragging the machine into swap. Each property is
+// quoted, so a limit read from settings can't inject into the shell line.
+func WrapCommand(unit string, limits *config.ResourceLimits, command string) string {
+	args := []string{"systemd-run", "--user", "--scope", "--quiet", "--unit=" + unit}
+	if limits.CPU != "" {
+		args = append(args, "-p", shellQuote("CPUQuota="+limits.CPU))
+	}
+	if limits.Memory != "" {
+		args = append(args, "-p", shellQuote("MemoryMax="+limits.Memory), "-p", "MemorySwapMax=0")
+	}
+	if limits.Pids > 0 {
+		args = append(args, "-p", "TasksMax="+strconv.Itoa(limits.Pids))
+	}
+	args = append(args, "--", "sh", "-c", shellQuote(command))
+	return strings.Join(args, " ")
+}
+
+// Prepare clears a scope left over from a previous session with the same
+// name, so a new one can be started: leftover processes are stopped and a
+// failed (e.g. OOM-killed) unit is reset. Best-effort.
+func Prepare(unit string) {
+	_ = exec.Command("systemctl", "--user", "stop", unit).Run()
+	_ = exec.Command("systemctl", "--user", "reset-failed", unit).Run()
+}
+
+// Usage is a session scope's resource usage and limits.
+type Usage struct {
+	Unit      string        `json:"unit"`
+	Active    bool          `json:"active"`
+	Result    string        `json:"result"`               // "success", "oom-kill", ...
+	Memory    uint64        `json:"memory_bytes"`         // current memory use
+	MemoryMax uint64        `json:"memory_max,omitempty"` // 0 when unlimited
+	Tasks     uint64        `json:"tasks"`                // current processes and threads
+	TasksMax  uint64        `json:"tasks_max,omitempty"`  // 0 when unlimited
+	CPU       time.Duration `json:"cpu"`                  // CPU time used so far
+	CPUQuota  time.Duration `json:"cpu_quota,omitempty"`  // CPU time allowed per second; 0 when unlimited
+}
+
+// showProperties are the unit properties GetUsage reads.
+var showProperties = []string{
+	"LoadState", "ActiveState", "Result",
+	"MemoryCurrent", "MemoryMax", "TasksCurrent", "TasksMax",
+	"CPUUsageNSec", "CPUQuotaPerSecUSec",
+}
+
+// GetUsage reads a scope's usage from systemd. A scope that was OOM-killed
+// stays loaded in the failed state, so its Result can be read after the
+// session has died.
+func GetUsage(unit string) (*Usage, error) {
+	args := []string{"--user", "show", unit}
+	for _, p := range showProperties {
+		args = append(args, "-p", p)
+	}
+	out, err := exec.Command("systemctl", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("systemctl show %s: %w", unit, err)
+	}
+	return parseUsage(unit, string(out))
+}
+
+// OOMKilled reports whether a session's scope was stopped by the OOM killer.
+func OOMKilled(session string) bool {
+	u, err := GetUsage(ScopeUnit(session))
+	return err == nil && u.Result == ResultOOMKill
+}
+
+// parseUsage parses `systemctl show` key=value output.
+func parseUsage(unit, out string) (*Usage, error) {
+	props := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		if k, v, ok := strings.Cut(strings.TrimSpace(line), "="); ok {
+			props[k] = v
+		}
+	}
+	if props["LoadState"] == "not-found" || props["LoadState"] == "" {
+		return nil, fmt.Errorf("%w: %s", ErrNoScope, unit)
+	}
+
+	u := &Usage{
+		Unit:      unit,
+		Active:    props["ActiveState"] == "active",
+		Result:    props["Result"],
+		Memory:    parseCount(props["MemoryCurrent"]),
+		MemoryMax: parseCount(props["MemoryMax"]),
+		Tasks:     parseCount(props["TasksCurrent"]),
+		TasksMax:  parseCount(props["TasksMax"]),
+		CPU:       time.Duration(parseCount(props["CPUUsageNSec"])),
+	}
+	if d, err := time.ParseDuration(props["CPUQuotaPerSecUSec"]); err == nil {
+		u.CPUQuota = d
+	}
+	return u, nil
+}
+
+// parseCount parses a systemd counter, mapping "[not set]", "infinity" and
+// UINT64_MAX (systemd's "no value") to zero.
+func parseCount(s string) uint64 {
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil || n == ^uint64(0) {
+		return 0
+	}
+	return n
+}
+
+// String summarizes the usage, e.g. "cpu 3m12s (200%) · mem 1.2G/4.0G · pids 37/512".
+func (u *Usage) String() string {
+	cpu := "cpu " + u.CPU.Round(time.Second).String()
+	if u.CPUQuota > 0 {
+		cpu += fmt.Sprintf(" (%d%%)", u.CPUQuota*100/time.Second)
+	}
+	mem := "mem " + FormatBytes(u.Memory)
+	if u.MemoryMax > 0 {
+		mem += "/" + FormatBytes(u.MemoryMax)
+	}
+	pids := fmt.Sprintf("pids %d", u.Tasks)
+	if u.TasksMax > 0 {
+		pids += fmt.Sprintf("/%d", u.TasksMax)
+	}
+	s := strings.Join([]string{cpu, mem, pids}, " · ")
+	if u.Result == ResultOOMKill {
+		s += " · OOM-killed"
+	}
+	return s
+}
+
+// FormatBytes formats a byte count with a binary unit, e.g. "1.2G".
+func FormatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%c", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// shellQuote quotes s for sh.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// WrapSession wraps a session's startup command in the session's scope
+// when limits are configured. Without limits the command is returned as is.
+// When limits are configured but can't be applied, the command is returned
+// unwrapped along with the reason, so the session can still start.
+func WrapSession(session string, limits *config.ResourceLimits, command string) (string, error) {
+	if limits == nil || limits.IsZero() {
+		return command, nil
+	}
+	if err := Available(); err != nil {
+		return command, err
+	}
+	unit := ScopeUnit(session)
+	Prepare(unit)
+	return WrapCommand(unit, limits, command), nil
+}
@@ -0,0 +1,80 @@
+package cgroup
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func TestWrapCommand(t *testing.T) {
+	limits := &config.ResourceLimits{CPU: "200%", Memory: "4G", Pids: 512}
+	got := WrapCommand(ScopeUnit("gt-gastown-nux"), limits, `export GT_ROLE=polecat && claude 'it''s'`)
+	want := "systemd-run --user --scope --quiet --unit=gt-gastown-nux.scope -p 'CPUQuota=200%' " +
+		"-p 'MemoryMax=4G' -p MemorySwapMax=0 -p TasksMax=512 -- sh -c " +
+		`'export GT_ROLE=polecat && claude '\''it'\'''\''s'\'''`
+	if got != want {
+		t.Errorf("WrapCommand =\n  %s\nwant\n  %s", got, want)
+	}
+
+	// The wrapped command still runs the original through sh.
+	only := WrapCommand("x.scope", &config.ResourceLimits{}, "echo 'a b'")
+	quoted := only[strings.Index(only, "-- sh -c ")+len("-- sh -c "):]
+	out, err := exec.Command("sh", "-c", "sh -c "+quoted).Output()
+	if err != nil || string(out) != "a b\n" {
+		t.Errorf("unwrapped command output = %q, %v", out, err)
+	}
+
+	// Limits are quoted, so a bad value can't run anything.
+	evil := WrapCommand("x.scope", &config.ResourceLimits{Memory: "4G; touch pwned"}, "true")
+	if !strings.Contains(evil, `-p 'MemoryMax=4G; touch pwned'`) {
+		t.Errorf("memory limit not quoted: %s", evil)
+	}
+
+	if unit := ScopeUnit("gt-my rig-nux/1"); unit != "gt-my_rig-nux_1.scope" {
+		t.Errorf("ScopeUnit = %q", unit)
+	}
+}
+
+func TestParseUsage(t *testing.T) {
+	out := `LoadState=loaded
+ActiveState=active
+Result=success
+MemoryCurrent=1288490188
+MemoryMax=4294967296
+TasksCurrent=37
+TasksMax=512
+CPUUsageNSec=192400000000
+CPUQuotaPerSecUSec=2s
+`
+	u, err := parseUsage("gt-gastown-nux.scope", out)
+	if err != nil {
+		t.Fatalf("parseUsage: %v", err)
+	}
+	if !u.Active || u.Memory != 1288490188 || u.TasksMax != 512 || u.CPU != 192400*time.Millisecond || u.CPUQuota != 2*time.Second {
+		t.Errorf("usage = %+v", u)
+	}
+	if got, want := u.String(), "cpu 3m12s (200%) · mem 1.2G/4.0G · pids 37/512"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	// A scope the OOM killer stopped, with no limits besides memory.
+	u, err = parseUsage("x.scope", "LoadState=loaded\nActiveState=failed\nResult=oom-kill\n"+
+		"MemoryCurrent=[not set]\nMemoryMax=infinity\nTasksMax=18446744073709551615\nCPUQuotaPerSecUSec=infinity\n")
+	if err != nil {
+		t.Fatalf("parseUsage: %v", err)
+	}
+	if u.Active || u.Result != ResultOOMKill || u.Memory != 0 || u.MemoryMax != 0 || u.TasksMax != 0 || u.CPUQuota != 0 {
+		t.Errorf("usage = %+v", u)
+	}
+	if !strings.HasSuffix(u.String(), "OOM-killed") {
+		t.Errorf("String() = %q", u.String())
+	}
+
+	if _, err := parseUsage("x.scope", "LoadState=not-found\n"); !errors.Is(err, ErrNoScope) {
+		t.Errorf("not-found err = %v", err)
+	}
+}
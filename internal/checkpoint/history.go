@@ -0,0 +1,317 @@
+package checkpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HistoryFilename is the checkpoint history file within the polecat directory.
+const HistoryFilename = ".polecat-checkpoints.json"
+
+// HistoryLimit is how many checkpoints the history keeps; older ones (and
+// their snapshot refs) are dropped as new ones are recorded.
+const HistoryLimit = 20
+
+// RefPrefix is where worktree snapshots are kept, as
+// refs/gt/checkpoints/<polecat>/<n>.
+const RefPrefix = "refs/gt/checkpoints/"
+
+// Entry is a numbered checkpoint in the history.
+type Entry struct {
+	// N numbers checkpoints in the order they were recorded.
+	N int `json:"n"`
+
+	// Snapshot is a commit of the whole worktree, uncommitted and untracked
+	// changes included, on top of LastCommit. Empty if git wasn't available.
+	Snapshot string `json:"snapshot,omitempty"`
+
+	// Ref is the git ref that keeps Snapshot alive.
+	Ref string `json:"ref,omitempty"`
+
+	Checkpoint
+}
+
+// History is a polecat's bounded checkpoint history, oldest first.
+type History struct {
+	Next    int     `json:"next"`
+	Entries []Entry `json:"entries"`
+}
+
+// HistoryPath returns the history file path for a given polecat directory.
+func HistoryPath(polecatDir string) string {
+	return filepath.Join(polecatDir, HistoryFilename)
+}
+
+// ReadHistory loads the checkpoint history from the polecat directory.
+// Returns an empty history if none exists.
+func ReadHistory(polecatDir string) (*History, error) {
+	data, err := os.ReadFile(HistoryPath(polecatDir)) //nolint:gosec // G304: path is constructed from trusted polecatDir
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &History{Next: 1}, nil
+		}
+		return nil, fmt.Errorf("reading checkpoint history: %w", err)
+	}
+	var h History
+	if err := json.Unmarshal(data, &h); err != nil {
+		return nil, fmt.Errorf("parsing checkpoint history: %w", err)
+	}
+	if h.Next < 1 {
+		h.Next = 1
+	}
+	return &h, nil
+}
+
+func (h *History) write(polecatDir string) error {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling checkpoint history: %w", err)
+	}
+	if err := os.WriteFile(HistoryPath(polecatDir), data, 0600); err != nil {
+		return fmt.Errorf("writing checkpoint history: %w", err)
+	}
+	return nil
+}
+
+// Get returns checkpoint n, or nil if the history no longer has it.
+func (h *History) Get(n int) *Entry {
+	for i := range h.Entries {
+		if h.Entries[i].N == n {
+			return &h.Entries[i]
+		}
+	}
+	return nil
+}
+
+// Latest returns the most recent checkpoint, or nil.
+func (h *History) Latest() *Entry {
+	if len(h.Entries) == 0 {
+		return nil
+	}
+	return &h.Entries[len(h.Entries)-1]
+}
+
+// Record writes cp as the current checkpoint and appends it to the
+// history, snapshotting the worktree under refs/gt/checkpoints/<name>/<n>.
+// name identifies the polecat; refs are shared by all worktrees of a rig.
+func Record(polecatDir, name string, cp *Checkpoint) (*Entry, error) {
+	if err := Write(polecatDir, cp); err != nil {
+		return nil, err
+	}
+	h, err := ReadHistory(polecatDir)
+	if err != nil {
+		return nil, err
+	}
+	name = refName(polecatDir, name)
+
+	// Numbers keep climbing even if the history file was lost, so a
+	// reused polecat name never overwrites a snapshot it can't see.
+	refs := listRefs(polecatDir, name)
+	for n := range refs {
+		if n >= h.Next {
+			h.Next = n + 1
+		}
+	}
+
+	e := Entry{N: h.Next, Checkpoint: *cp}
+	h.Next++
+	if sha, err := snapshot(polecatDir, fmt.Sprintf("gt checkpoint %s/%d", name, e.N)); err == nil {
+		ref := fmt.Sprintf("%s%s/%d", RefPrefix, name, e.N)
+		if _, err := runGit(polecatDir, nil, "update-ref", ref, sha); err == nil {
+			e.Snapshot, e.Ref = sha, ref
+			refs[e.N] = ref
+		}
+	}
+
+	h.Entries = append(h.Entries, e)
+	if len(h.Entries) > HistoryLimit {
+		h.Entries = h.Entries[len(h.Entries)-HistoryLimit:]
+	}
+
+	// Drop snapshot refs that fell out of the ring.
+	for n, ref := range refs {
+		if h.Get(n) == nil {
+			_, _ = runGit(polecatDir, nil, "update-ref", "-d", ref)
+		}
+	}
+
+	if err := h.write(polecatDir); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// Restore rolls the worktree back to checkpoint n: its branch at its last
+// commit, with its uncommitted changes back in the worktree (unstaged),
+// and makes it the current checkpoint. A dirty worktree is refused unless
+// force is set. Whenever the restore would discard something (uncommitted
+// changes, or commits since the checkpoint's last commit) the current state
+// is recorded as a new checkpoint first (returned as safety), so the
+// restore can itself be undone.
+func Restore(polecatDir, name string, n int, force bool) (restored, safety *Entry, err error) {
+	h, err := ReadHistory(polecatDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	e := h.Get(n)
+	if e == nil {
+		return nil, nil, fmt.Errorf("no checkpoint %d (see gt checkpoint list)", n)
+	}
+	if e.Snapshot == "" && e.LastCommit == "" {
+		return nil, nil, fmt.Errorf("checkpoint %d has no git state to restore", n)
+	}
+	target := *e
+
+	dirty, err := isDirty(polecatDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if dirty && !force {
+		return nil, nil, fmt.Errorf("worktree has uncommitted changes (use --force to checkpoint them and restore anyway)")
+	}
+	head, _ := runGit(polecatDir, nil, "rev-parse", "--verify", "-q", "HEAD")
+	moved := target.LastCommit != "" && head != target.LastCommit
+	if dirty || moved {
+		cp, _ := Capture(polecatDir)
+		cp.WithNotes(fmt.Sprintf("automatic checkpoint before restoring checkpoint %d", n))
+		if safety, err = Record(polecatDir, name, cp); err != nil {
+			return nil, nil, fmt.Errorf("checkpointing current state: %w", err)
+		}
+		if h, err = ReadHistory(polecatDir); err != nil {
+			return nil, safety, err
+		}
+	}
+
+	steps := [][]string{
+		{"reset", "-q", "--hard"},
+		{"clean", "-fdq", "-e", Filename, "-e", HistoryFilename},
+	}
+	if b := target.Branch; b != "" && b != "HEAD" {
+		if cur, _ := runGit(polecatDir, nil, "rev-parse", "--abbrev-ref", "HEAD"); cur != b {
+			steps = append(steps, []string{"checkout", "-q", b})
+		}
+	}
+	if target.LastCommit != "" {
+		steps = append(steps, []string{"reset", "-q", "--hard", target.LastCommit})
+	}
+	if target.Snapshot != "" {
+		steps = append(steps,
+			[]string{"read-tree", "-u", "--reset", target.Snapshot},
+			[]string{"reset", "-q"})
+	}
+	for _, args := range steps {
+		if _, err := runGit(polecatDir, nil, args...); err != nil {
+			return nil, safety, fmt.Errorf("restoring checkpoint %d: %w", n, err)
+		}
+	}
+
+	// The checkpoint files may have been committed, in which case the
+	// reset removed or rewound them; put the history back.
+	if err := h.write(polecatDir); err != nil {
+		return nil, safety, err
+	}
+	current := target.Checkpoint
+	current.Timestamp = time.Now()
+	current.WithNotes(strings.TrimSpace(fmt.Sprintf("restored from checkpoint %d. %s", n, target.Notes)))
+	if err := Write(polecatDir, &current); err != nil {
+		return nil, safety, err
+	}
+	return &target, safety, nil
+}
+
+// snapshot commits the whole worktree (uncommitted and untracked files
+// included, checkpoint files excluded) without touching the index or the
+// worktree, and returns the commit SHA.
+func snapshot(dir, message string) (string, error) {
+	tmp, err := os.MkdirTemp("", "gt-checkpoint-")
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+	env := []string{"GIT_INDEX_FILE=" + filepath.Join(tmp, "index")}
+
+	head, headErr := runGit(dir, nil, "rev-parse", "--verify", "-q", "HEAD")
+	if headErr == nil {
+		if _, err := runGit(dir, env, "read-tree", "HEAD"); err != nil {
+			return "", err
+		}
+	}
+	if _, err := runGit(dir, env, "add", "-A", "--", ".", ":!"+Filename, ":!"+HistoryFilename); err != nil {
+		return "", err
+	}
+	tree, err := runGit(dir, env, "write-tree")
+	if err != nil {
+		return "", err
+	}
+	args := []string{"commit-tree", tree, "-m", message}
+	if headErr == nil {
+		args = append(args, "-p", head)
+	}
+	return runGit(dir, []string{
+		"GIT_AUTHOR_NAME=Gas Town", "GIT_AUTHOR_EMAIL=checkpoint@gastown.local",
+		"GIT_COMMITTER_NAME=Gas Town", "GIT_COMMITTER_EMAIL=checkpoint@gastown.local",
+	}, args...)
+}
+
+// listRefs returns the polecat's snapshot refs by checkpoint number.
+func listRefs(dir, name string) map[int]string {
+	refs := make(map[int]string)
+	out, err := runGit(dir, nil, "for-each-ref", "--format=%(refname)", RefPrefix+name)
+	if err != nil {
+		return refs
+	}
+	for _, ref := range strings.Fields(out) {
+		if n, err := strconv.Atoi(ref[strings.LastIndex(ref, "/")+1:]); err == nil {
+			refs[n] = ref
+		}
+	}
+	return refs
+}
+
+// isDirty reports whether the worktree has changes outside the checkpoint
+// files.
+func isDirty(dir string) (bool, error) {
+	out, err := runGit(dir, nil, "status", "--porcelain")
+	if err != nil {
+		return false, fmt.Errorf("checking worktree: %w", err)
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if len(line) > 3 {
+			file := strings.TrimSpace(line[3:])
+			if file != Filename && file != HistoryFilename {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// refName returns the ref path component for a polecat, defaulting to
+// the directory name.
+func refName(polecatDir, name string) string {
+	if name == "" {
+		name = filepath.Base(polecatDir)
+	}
+	return strings.ReplaceAll(name, " ", "-")
+}
+
+func runGit(dir string, env []string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
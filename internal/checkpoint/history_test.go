@@ -0,0 +1,190 @@
+package checkpoint
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// initRepo creates a git repo with one committed file.
+func initRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"config", "user.name", "Test"},
+		{"config", "user.email", "test@example.com"},
+	} {
+		gitT(t, dir, args...)
+	}
+	writeFile(t, dir, "main.go", "package main\n")
+	gitT(t, dir, "add", ".")
+	gitT(t, dir, "commit", "-q", "-m", "initial")
+	return dir
+}
+
+func gitT(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := runGit(dir, nil, args...)
+	if err != nil {
+		t.Fatalf("git %v: %v", args, err)
+	}
+	return out
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, dir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return "<missing>"
+	}
+	return string(data)
+}
+
+func TestRecordAndRestore(t *testing.T) {
+	dir := initRepo(t)
+	first := gitT(t, dir, "rev-parse", "HEAD")
+
+	// Checkpoint 1: an uncommitted edit and a new untracked file.
+	writeFile(t, dir, "main.go", "package main\n\n// wip\n")
+	writeFile(t, dir, "notes.txt", "todo\n")
+	cp, _ := Capture(dir)
+	e1, err := Record(dir, "nux", cp.WithMolecule("mol-1", "gt-step1", "Step one"))
+	if err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if e1.N != 1 || e1.Ref != RefPrefix+"nux/1" || e1.Snapshot == "" {
+		t.Fatalf("entry = %+v", e1)
+	}
+	if got := readFile(t, dir, "main.go"); got != "package main\n\n// wip\n" {
+		t.Error("Record touched the worktree")
+	}
+
+	// A bad session: commit, then make a mess.
+	gitT(t, dir, "add", "-A")
+	gitT(t, dir, "commit", "-q", "-m", "bad")
+	cp, _ = Capture(dir)
+	if _, err := Record(dir, "nux", cp); err != nil {
+		t.Fatalf("Record 2: %v", err)
+	}
+	writeFile(t, dir, "main.go", "broken\n")
+	writeFile(t, dir, "junk.txt", "junk\n")
+
+	if _, _, err := Restore(dir, "nux", 1, false); err == nil || !strings.Contains(err.Error(), "uncommitted") {
+		t.Fatalf("Restore of a dirty worktree without force: err = %v", err)
+	}
+	restored, safety, err := Restore(dir, "nux", 1, true)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if restored.N != 1 || safety == nil || safety.N != 3 {
+		t.Fatalf("restored %+v, safety %+v", restored, safety)
+	}
+
+	if head := gitT(t, dir, "rev-parse", "HEAD"); head != first {
+		t.Errorf("HEAD = %s, want %s", head, first)
+	}
+	if got := readFile(t, dir, "main.go"); got != "package main\n\n// wip\n" {
+		t.Errorf("main.go = %q", got)
+	}
+	if got := readFile(t, dir, "notes.txt"); got != "todo\n" {
+		t.Errorf("notes.txt = %q", got)
+	}
+	if got := readFile(t, dir, "junk.txt"); got != "<missing>" {
+		t.Error("file created after the checkpoint survived the restore")
+	}
+	if staged := gitT(t, dir, "diff", "--cached", "--name-only"); staged != "" {
+		t.Errorf("restored changes should be unstaged, got %s", staged)
+	}
+
+	current, err := Read(dir)
+	if err != nil || current.CurrentStep != "gt-step1" || !strings.Contains(current.Notes, "restored from checkpoint 1") {
+		t.Errorf("current checkpoint = %+v, %v", current, err)
+	}
+
+	// The safety checkpoint undoes the restore.
+	if _, _, err := Restore(dir, "nux", safety.N, true); err != nil {
+		t.Fatalf("Restore safety: %v", err)
+	}
+	if got := readFile(t, dir, "junk.txt"); got != "junk\n" {
+		t.Errorf("junk.txt = %q after restoring the safety checkpoint", got)
+	}
+
+	if _, _, err := Restore(dir, "nux", 99, true); err == nil {
+		t.Error("expected error for unknown checkpoint")
+	}
+}
+
+func TestRestoreKeepsLaterCommits(t *testing.T) {
+	dir := initRepo(t)
+	cp, _ := Capture(dir)
+	e1, err := Record(dir, "nux", cp)
+	if err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	// Committed work after the checkpoint, with a clean worktree.
+	writeFile(t, dir, "later.go", "package main\n")
+	gitT(t, dir, "add", "later.go")
+	gitT(t, dir, "commit", "-q", "-m", "later")
+	later := gitT(t, dir, "rev-parse", "HEAD")
+
+	_, safety, err := Restore(dir, "nux", e1.N, false)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if safety == nil {
+		t.Fatal("restoring over later commits should record a safety checkpoint")
+	}
+	if _, _, err := Restore(dir, "nux", safety.N, false); err != nil {
+		t.Fatalf("Restore safety: %v", err)
+	}
+	if head := gitT(t, dir, "rev-parse", "HEAD"); head != later {
+		t.Errorf("HEAD = %s after undoing the restore, want %s", head, later)
+	}
+
+	// Nothing to lose: restoring the current commit records nothing.
+	if _, safety, err = Restore(dir, "nux", safety.N, false); err != nil || safety != nil {
+		t.Errorf("Restore in place: safety %+v, err %v", safety, err)
+	}
+}
+
+func TestHistoryRing(t *testing.T) {
+	dir := initRepo(t)
+	for i := 0; i < HistoryLimit+2; i++ {
+		if _, err := Record(dir, "slit", &Checkpoint{}); err != nil {
+			t.Fatalf("Record %d: %v", i, err)
+		}
+	}
+	h, err := ReadHistory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h.Entries) != HistoryLimit || h.Entries[0].N != 3 || h.Latest().N != HistoryLimit+2 {
+		t.Errorf("history has %d entries, %d..%d", len(h.Entries), h.Entries[0].N, h.Latest().N)
+	}
+	if refs := listRefs(dir, "slit"); len(refs) != HistoryLimit || refs[1] != "" {
+		t.Errorf("refs = %v", refs)
+	}
+
+	// Losing the history file doesn't reuse numbers still held by refs.
+	if err := os.Remove(HistoryPath(dir)); err != nil {
+		t.Fatal(err)
+	}
+	e, err := Record(dir, "slit", &Checkpoint{})
+	if err != nil || e.N != HistoryLimit+3 {
+		t.Errorf("after losing history: %+v, %v", e, err)
+	}
+}
@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"os"
 	"sort"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/account"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
 	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
@@ -28,13 +31,17 @@ var accountCmd = &cobra.Command{
 	Long: `Manage multiple Claude Code accounts for Gas Town.
 
 This enables switching between accounts (e.g., personal vs work) with
-easy account selection per spawn or globally.
+easy account selection per spawn or globally. In pool mode, polecat
+spawns are spread across all accounts and polecats are moved off
+accounts that hit a usage limit.
 
 Commands:
   gt account list              List registered accounts
   gt account add <handle>      Add a new account
   gt account default <handle>  Set the default account
-  gt account status            Show current account info`,
+  gt account status            Show current account info
+  gt account pool [on|off]     Show or set pool mode
+  gt account rotate [rig]      Move polecats off limited accounts`,
 }
 
 var accountListCmd = &cobra.Command{
@@ -42,7 +49,9 @@ var accountListCmd = &cobra.Command{
 	Short: "List registered accounts",
 	Long: `List all registered Claude Code accounts.
 
-Shows account handles, emails, and which is the default.
+Shows account handles, emails, and which is the default. In pool mode,
+also shows live session counts and accounts cooling down after a usage
+limit.
 
 Examples:
   gt account list           # Text output
@@ -89,6 +98,10 @@ type AccountListItem struct {
 	Description string `json:"description,omitempty"`
 	ConfigDir   string `json:"config_dir"`
 	IsDefault   bool   `json:"is_default"`
+
+	// Pool mode only
+	Sessions     int        `json:"sessions,omitempty"`
+	CoolingUntil *time.Time `json:"cooling_until,omitempty"`
 }
 
 func runAccountList(cmd *cobra.Command, args []string) error {
@@ -114,16 +127,35 @@ func runAccountList(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	// Pool mode: live sessions and cooldowns per account
+	var counts map[string]int
+	var state *account.State
+	if cfg.Pool {
+		counts = account.SessionCounts(tmux.NewTmux(), cfg)
+		if state, err = account.LoadState(account.StatePath(townRoot)); err != nil {
+			return err
+		}
+	}
+	now := time.Now()
+
 	// Build list items
 	var items []AccountListItem
 	for handle, acct := range cfg.Accounts {
-		items = append(items, AccountListItem{
+		item := AccountListItem{
 			Handle:      handle,
 			Email:       acct.Email,
 			Description: acct.Description,
 			ConfigDir:   acct.ConfigDir,
 			IsDefault:   handle == cfg.Default,
-		})
+			Sessions:    counts[handle],
+		}
+		if state != nil {
+			if c, ok := state.CoolingDown(handle, now); ok {
+				until := c.Until
+				item.CoolingUntil = &until
+			}
+		}
+		items = append(items, item)
 	}
 
 	// Sort by handle for consistent output
@@ -138,7 +170,11 @@ func runAccountList(cmd *cobra.Command, args []string) error {
 	}
 
 	// Text output
-	fmt.Printf("%s\n\n", style.Bold.Render("Claude Code Accounts"))
+	title := "Claude Code Accounts"
+	if cfg.Pool {
+		title += " (pool)"
+	}
+	fmt.Printf("%s\n\n", style.Bold.Render(title))
 	for _, item := range items {
 		marker := "  "
 		if item.IsDefault {
@@ -152,6 +188,12 @@ func runAccountList(cmd *cobra.Command, args []string) error {
 		if item.IsDefault {
 			fmt.Printf("  %s", style.Dim.Render("(default)"))
 		}
+		if cfg.Pool {
+			fmt.Printf("  %s", style.Dim.Render(fmt.Sprintf("%d session(s)", item.Sessions)))
+		}
+		if item.CoolingUntil != nil {
+			fmt.Printf("  %s", style.Warning.Render("cooling down until "+item.CoolingUntil.Local().Format("Jan 2 15:04")))
+		}
 		fmt.Println()
 
 		if item.Description != "" {
@@ -283,6 +325,65 @@ Examples:
 	RunE: runAccountSwitch,
 }
 
+var accountPoolCmd = &cobra.Command{
+	Use:   "pool [on|off]",
+	Short: "Show or set account pool mode",
+	Long: `Show or set account pool mode.
+
+In pool mode, each polecat spawn without --account or GT_ACCOUNT goes to
+the account with the fewest live sessions, skipping accounts cooling down
+after a usage limit. The Witness moves polecats off an account when it
+hits a limit (see gt account rotate).
+
+Examples:
+  gt account pool        # Show whether pool mode is on
+  gt account pool on
+  gt account pool off`,
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: []string{"on", "off"},
+	RunE:      runAccountPool,
+}
+
+func runAccountPool(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwd()
+	if err != nil {
+		return fmt.Errorf("finding town root: %w", err)
+	}
+
+	accountsPath := constants.MayorAccountsPath(townRoot)
+	cfg, err := config.LoadAccountsConfig(accountsPath)
+	if err != nil {
+		return fmt.Errorf("loading accounts config: %w", err)
+	}
+
+	if len(args) == 0 {
+		if cfg.Pool {
+			fmt.Printf("Account pool is on (%d accounts)\n", len(cfg.Accounts))
+		} else {
+			fmt.Println("Account pool is off")
+		}
+		return nil
+	}
+
+	switch args[0] {
+	case "on":
+		cfg.Pool = true
+	case "off":
+		cfg.Pool = false
+	default:
+		return fmt.Errorf("expected 'on' or 'off', got %q", args[0])
+	}
+	if err := config.SaveAccountsConfig(accountsPath, cfg); err != nil {
+		return fmt.Errorf("saving accounts config: %w", err)
+	}
+
+	fmt.Printf("Account pool %s\n", args[0])
+	if cfg.Pool && len(cfg.Accounts) < 2 {
+		fmt.Println(style.Dim.Render("Add more accounts with 'gt account add' to spread load."))
+	}
+	return nil
+}
+
 func runAccountStatus(cmd *cobra.Command, args []string) error {
 	townRoot, err := workspace.FindFromCwd()
 	if err != nil {
@@ -466,6 +567,7 @@ func init() {
 	accountCmd.AddCommand(accountDefaultCmd)
 	accountCmd.AddCommand(accountStatusCmd)
 	accountCmd.AddCommand(accountSwitchCmd)
+	accountCmd.AddCommand(accountPoolCmd)
 
 	rootCmd.AddCommand(accountCmd)
 }
@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/account"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/session"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/tmux"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var accountRotateDryRun bool
+
+// rotateCaptureLines is how much of each pane is checked for a limit
+// banner. Limit banners are printed at the bottom, where the agent stalls.
+const rotateCaptureLines = 15
+
+var accountRotateCmd = &cobra.Command{
+	Use:   "rotate [rig]",
+	Short: "Move polecats off accounts that hit a usage limit",
+	Long: `Check polecat sessions for usage-limit and rate-limit banners and move
+them to another account.
+
+For each polecat whose pane shows a limit banner, the session's account is
+marked as cooling down until the reset time in the banner (or a default
+cooldown if the banner has none), and the polecat is respawned on the
+pool account with the fewest live sessions. The Claude session is resumed
+so the polecat keeps its conversation; if there is no session to resume
+it starts fresh and picks its work up from its hook.
+
+Requires pool mode (gt account pool on). Without a rig, checks every rig.
+The Witness runs this on each patrol.
+
+Examples:
+  gt account rotate gastown
+  gt account rotate --dry-run`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runAccountRotate,
+}
+
+func runAccountRotate(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	rigFilter := ""
+	if len(args) > 0 {
+		rigFilter = args[0]
+	}
+
+	cfg, err := config.LoadAccountsConfig(constants.MayorAccountsPath(townRoot))
+	if err != nil {
+		return fmt.Errorf("loading accounts config: %w", err)
+	}
+	if !cfg.Pool {
+		fmt.Println("Account pool is off; nothing to rotate (enable with: gt account pool on)")
+		return nil
+	}
+
+	statePath := account.StatePath(townRoot)
+	state, err := account.LoadState(statePath)
+	if err != nil {
+		return err
+	}
+
+	t := tmux.NewTmux()
+	sessions, err := t.ListSessions()
+	if err != nil {
+		return fmt.Errorf("listing sessions: %w", err)
+	}
+
+	// First pass: find limited sessions and rest their accounts, so the
+	// second pass doesn't move one limited polecat onto another's account.
+	type limitedSession struct {
+		name     string
+		identity *session.AgentIdentity
+		handle   string
+		limit    *account.Limit
+	}
+	var limited []limitedSession
+	now := time.Now()
+	for _, name := range sessions {
+		identity, err := session.ParseSessionName(name)
+		if err != nil || identity.Role != session.RolePolecat {
+			continue
+		}
+		if rigFilter != "" && identity.Rig != rigFilter {
+			continue
+		}
+		pane, err := t.CapturePane(name, rotateCaptureLines)
+		if err != nil {
+			continue
+		}
+		limit := account.DetectLimit(pane, now)
+		if limit == nil {
+			continue
+		}
+		configDir, _ := t.GetEnvironment(name, account.ConfigDirEnv)
+		handle := cfg.HandleForConfigDir(configDir)
+		if handle != "" {
+			state.MarkCoolingDown(handle, limit.Reset, now, limit.Banner)
+		}
+		limited = append(limited, limitedSession{name: name, identity: identity, handle: handle, limit: limit})
+	}
+
+	if len(limited) == 0 {
+		fmt.Println("No polecats at a usage limit.")
+		return state.Save(statePath, now)
+	}
+
+	counts := account.SessionCounts(t, cfg)
+	rotated := 0
+	for _, ls := range limited {
+		from := ls.handle
+		if from == "" {
+			from = "(unpooled)"
+		}
+		fmt.Printf("%s %s: %s limit on %s until %s\n", style.Warning.Render("⚠"), ls.identity.Address(),
+			ls.limit.Kind, from, ls.limit.Reset.Local().Format("Jan 2 15:04"))
+
+		to := account.Pick(cfg, state, counts, now)
+		if c, cooling := state.CoolingDown(to, now); cooling {
+			fmt.Printf("  %s every account is cooling down (soonest: %s at %s); leaving it\n",
+				style.Dim.Render("○"), to, c.Until.Local().Format("Jan 2 15:04"))
+			continue
+		}
+		if accountRotateDryRun {
+			fmt.Printf("  Would move to account %s\n", to)
+			counts[to]++
+			continue
+		}
+
+		resumed, err := respawnOnAccount(t, townRoot, ls.name, ls.identity, cfg.Accounts[to].ExpandedConfigDir())
+		if err != nil {
+			style.PrintWarning("could not respawn %s: %v", ls.name, err)
+			continue
+		}
+		counts[to]++
+		if ls.handle != "" {
+			counts[ls.handle]--
+		}
+		rotated++
+		how := "started fresh"
+		if resumed {
+			how = "resumed"
+		}
+		fmt.Printf("  %s Moved to account %s (%s)\n", style.Bold.Render("✓"), to, how)
+		_ = events.LogFeed(events.TypeAccountRotated, ls.identity.Address(),
+			events.AccountRotatedPayload(ls.name, ls.identity.Address(), ls.handle, to, ls.limit.Reset, ls.limit.Banner))
+	}
+
+	if err := state.Save(statePath, now); err != nil {
+		return err
+	}
+	if !accountRotateDryRun {
+		fmt.Printf("\nRotated %d of %d limited polecat(s).\n", rotated, len(limited))
+	}
+	return nil
+}
+
+// respawnOnAccount restarts a polecat's pane under configDir, resuming its
+// last Claude session if there is one. It reports whether it resumed.
+func respawnOnAccount(t *tmux.Tmux, townRoot, sessionName string, identity *session.AgentIdentity, configDir string) (bool, error) {
+	pane, err := t.GetPaneID(sessionName)
+	if err != nil {
+		return false, fmt.Errorf("getting pane: %w", err)
+	}
+	workDir, err := t.GetPaneWorkDir(sessionName)
+	if err != nil {
+		return false, fmt.Errorf("getting working directory: %w", err)
+	}
+	rigPath := filepath.Join(townRoot, identity.Rig)
+	address := identity.Address()
+
+	// Resume the conversation from the polecat's last session_start event.
+	agentName, _ := config.ResolveRoleAgentName("polecat", townRoot, rigPath)
+	resumeCmd := config.BuildResumeCommand(agentName, lastSessionID(townRoot, address))
+
+	var command string
+	if resumeCmd != "" {
+		envVars := config.AgentEnv(config.AgentEnvConfig{
+			Role:             "polecat",
+			Rig:              identity.Rig,
+			AgentName:        identity.Name,
+			TownRoot:         townRoot,
+			RuntimeConfigDir: configDir,
+			BeadsNoDaemon:    true,
+		})
+		command = config.PrependEnv(resumeCmd, envVars)
+	} else {
+		command = config.PrependEnv(
+			config.BuildPolecatStartupCommand(identity.Rig, identity.Name, rigPath, ""),
+			map[string]string{account.ConfigDirEnv: configDir})
+	}
+	command = fmt.Sprintf("cd %s && %s", workDir, command)
+
+	// Point the session at the new account so pool counts and costs follow it.
+	if err := t.SetEnvironment(sessionName, account.ConfigDirEnv, configDir); err != nil {
+		return false, fmt.Errorf("setting %s: %w", account.ConfigDirEnv, err)
+	}
+	// Clear the banner so the next patrol doesn't see it again.
+	if err := t.ClearHistory(pane); err != nil {
+		style.PrintWarning("could not clear history: %v", err)
+	}
+	if err := t.RespawnPane(pane, command); err != nil {
+		return false, fmt.Errorf("respawning pane: %w", err)
+	}
+
+	// A resumed agent waits at its prompt; nudge it back to work.
+	if resumeCmd != "" {
+		_ = t.WaitForCommand(sessionName, constants.SupportedShells, constants.ClaudeStartTimeout)
+		time.Sleep(2 * time.Second)
+		_ = t.NudgeSession(sessionName, "Your account hit a usage limit; this session was moved to another account. "+session.PropulsionNudge())
+	}
+	return resumeCmd != "", nil
+}
+
+// lastSessionID returns the agent session ID from the actor's most recent
+// session_start event, or "".
+func lastSessionID(townRoot, actor string) string {
+	evts, err := events.Query(townRoot, events.Filter{
+		Types:  []string{events.TypeSessionStart},
+		Actors: []string{actor},
+		Limit:  1,
+	})
+	if err != nil || len(evts) == 0 {
+		return ""
+	}
+	id, _ := evts[0].Payload["session_id"].(string)
+	// Fallback IDs from gt prime ("<actor>-<pid>") aren't resumable.
+	if strings.HasPrefix(id, actor+"-") {
+		return ""
+	}
+	return id
+}
+
+func init() {
+	accountRotateCmd.Flags().BoolVar(&accountRotateDryRun, "dry-run", false, "Show what would be rotated without respawning")
+	accountCmd.AddCommand(accountRotateCmd)
+}
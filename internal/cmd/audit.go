@@ -403,36 +403,17 @@ func formatTownlogSummary(e townlog.Event) string {
 func collectFeedEvents(townRoot, actor string, since time.Time) ([]AuditEntry, error) {
 	var entries []AuditEntry
 
-	eventsPath := filepath.Join(townRoot, events.EventsFile)
-	file, err := os.Open(eventsPath)
+	filter := events.Filter{Since: since}
+	if actor != "" {
+		filter.Match = func(e events.Event) bool { return matchesActor(e.Actor, actor) }
+	}
+	found, err := events.Query(townRoot, filter)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil // No events file yet
-		}
 		return nil, err
 	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		var e events.Event
-		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
-			continue // Skip malformed lines
-		}
 
-		// Apply actor filter
-		if actor != "" && !matchesActor(e.Actor, actor) {
-			continue
-		}
-
-		// Parse timestamp
+	for _, e := range found {
 		ts, _ := time.Parse(time.RFC3339, e.Timestamp)
-
-		// Apply since filter
-		if !since.IsZero() && ts.Before(since) {
-			continue
-		}
-
 		entries = append(entries, AuditEntry{
 			Timestamp: ts,
 			Source:    "events",
@@ -1,8 +1,11 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -28,7 +31,12 @@ Checkpoint data includes:
 - Git branch and last commit
 - Timestamp
 
-Checkpoints are stored in .polecat-checkpoint.json in the polecat directory.`,
+The current checkpoint is stored in .polecat-checkpoint.json in the polecat
+directory. Each write is also appended to .polecat-checkpoints.json, a history
+of the last 20 checkpoints, together with a snapshot of the worktree
+(uncommitted and untracked changes included) kept under
+refs/gt/checkpoints/<polecat>/<n>. Use 'gt checkpoint list' to see the
+history and 'gt checkpoint restore <n>' to roll the worktree back.`,
 }
 
 var checkpointWriteCmd = &cobra.Command{
@@ -52,6 +60,38 @@ var checkpointReadCmd = &cobra.Command{
 	RunE:  runCheckpointRead,
 }
 
+var checkpointListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the checkpoint history",
+	Long: `List the checkpoints recorded for this worktree, newest first.
+
+Each entry shows its number, age, molecule step, branch and commit, and the
+ref holding its worktree snapshot.`,
+	RunE: runCheckpointList,
+}
+
+var checkpointRestoreCmd = &cobra.Command{
+	Use:   "restore <n>",
+	Short: "Roll the worktree back to an earlier checkpoint",
+	Long: `Restore the worktree to checkpoint n from 'gt checkpoint list'.
+
+The branch is reset to the checkpoint's last commit and its uncommitted
+changes (including untracked files) are put back in the worktree, unstaged.
+The restored checkpoint becomes the current one, so a respawned session
+resumes from its molecule step.
+
+A worktree with uncommitted changes is refused unless --force is given.
+Whenever the restore would discard uncommitted changes or newer commits,
+the current state is recorded as a new checkpoint first, so the restore
+can itself be undone.
+
+Examples:
+  gt checkpoint restore 4
+  gt checkpoint restore 4 --force`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCheckpointRestore,
+}
+
 var checkpointClearCmd = &cobra.Command{
 	Use:   "clear",
 	Short: "Clear the checkpoint file",
@@ -63,11 +103,15 @@ var (
 	checkpointNotes    string
 	checkpointMolecule string
 	checkpointStep     string
+	checkpointListJSON bool
+	checkpointForce    bool
 )
 
 func init() {
 	checkpointCmd.AddCommand(checkpointWriteCmd)
 	checkpointCmd.AddCommand(checkpointReadCmd)
+	checkpointCmd.AddCommand(checkpointListCmd)
+	checkpointCmd.AddCommand(checkpointRestoreCmd)
 	checkpointCmd.AddCommand(checkpointClearCmd)
 
 	checkpointWriteCmd.Flags().StringVar(&checkpointNotes, "notes", "",
@@ -76,6 +120,9 @@ func init() {
 		"Override molecule ID (auto-detected if not specified)")
 	checkpointWriteCmd.Flags().StringVar(&checkpointStep, "step", "",
 		"Override step ID (auto-detected if not specified)")
+	checkpointListCmd.Flags().BoolVar(&checkpointListJSON, "json", false, "Output as JSON")
+	checkpointRestoreCmd.Flags().BoolVarP(&checkpointForce, "force", "f", false,
+		"Checkpoint and discard uncommitted changes instead of refusing")
 
 	rootCmd.AddCommand(checkpointCmd)
 }
@@ -140,12 +187,13 @@ func runCheckpointWrite(cmd *cobra.Command, args []string) error {
 		cp.WithHookedBead(hookedBead)
 	}
 
-	// Write checkpoint
-	if err := checkpoint.Write(cwd, cp); err != nil {
+	// Write checkpoint and append it to the history
+	entry, err := checkpoint.Record(cwd, checkpointOwner(cwd, roleInfo), cp)
+	if err != nil {
 		return fmt.Errorf("writing checkpoint: %w", err)
 	}
 
-	fmt.Printf("%s Checkpoint written\n", style.Bold.Render("✓"))
+	fmt.Printf("%s Checkpoint %d written\n", style.Bold.Render("✓"), entry.N)
 	fmt.Printf("  %s\n", cp.Summary())
 
 	return nil
@@ -204,6 +252,90 @@ func runCheckpointRead(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func runCheckpointList(cmd *cobra.Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getting current directory: %w", err)
+	}
+
+	h, err := checkpoint.ReadHistory(cwd)
+	if err != nil {
+		return err
+	}
+
+	// Newest first
+	entries := make([]checkpoint.Entry, 0, len(h.Entries))
+	for i := len(h.Entries) - 1; i >= 0; i-- {
+		entries = append(entries, h.Entries[i])
+	}
+
+	if checkpointListJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	}
+
+	if len(entries) == 0 {
+		fmt.Printf("%s No checkpoint history\n", style.Dim.Render("○"))
+		return nil
+	}
+
+	fmt.Printf("%s\n\n", style.Bold.Render("Checkpoints"))
+	for _, e := range entries {
+		commit := e.LastCommit[:min(8, len(e.LastCommit))]
+		fmt.Printf("  %s  %s  %s\n", style.Bold.Render(fmt.Sprintf("%3d", e.N)),
+			style.Dim.Render(formatAge(e.Timestamp)), e.Summary())
+		var details []string
+		if e.Branch != "" {
+			details = append(details, e.Branch+"@"+commit)
+		}
+		if e.Ref != "" {
+			details = append(details, e.Ref)
+		} else {
+			details = append(details, "no snapshot")
+		}
+		fmt.Printf("       %s\n", style.Dim.Render(strings.Join(details, "  ")))
+		if e.Notes != "" {
+			fmt.Printf("       %s\n", e.Notes)
+		}
+	}
+	return nil
+}
+
+func runCheckpointRestore(cmd *cobra.Command, args []string) error {
+	n, err := strconv.Atoi(strings.TrimPrefix(args[0], "#"))
+	if err != nil {
+		return fmt.Errorf("invalid checkpoint number %q", args[0])
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("getting current directory: %w", err)
+	}
+
+	var roleInfo RoleInfo
+	if townRoot, err := workspace.FindFromCwd(); err == nil && townRoot != "" {
+		if info, err := GetRoleWithContext(cwd, townRoot); err == nil {
+			roleInfo = info
+		}
+	}
+
+	restored, safety, err := checkpoint.Restore(cwd, checkpointOwner(cwd, roleInfo), n, checkpointForce)
+	if safety != nil {
+		fmt.Printf("%s Current state saved as checkpoint %d\n", style.Dim.Render("○"), safety.N)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s Restored checkpoint %d\n", style.Bold.Render("✓"), restored.N)
+	fmt.Printf("  %s\n", restored.Summary())
+	if safety != nil {
+		fmt.Printf("  Undo with: gt checkpoint restore %d\n", safety.N)
+	}
+	return nil
+}
+
 func runCheckpointClear(cmd *cobra.Command, args []string) error {
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -218,6 +350,15 @@ func runCheckpointClear(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// checkpointOwner names the worker whose snapshot refs a checkpoint goes
+// under, falling back to the worktree directory name.
+func checkpointOwner(cwd string, roleInfo RoleInfo) string {
+	if roleInfo.Polecat != "" {
+		return roleInfo.Polecat
+	}
+	return filepath.Base(cwd)
+}
+
 // detectMoleculeContext tries to detect the current molecule and step from beads.
 func detectMoleculeContext(workDir string, ctx RoleInfo) (moleculeID, stepID, stepTitle string) {
 	b := beads.New(workDir)
@@ -17,6 +17,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/dag"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/tui/convoy"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -65,6 +66,7 @@ var (
 	convoyNotify       string
 	convoyOwner        string
 	convoyStatusJSON   bool
+	convoyStatusFormat string
 	convoyListJSON     bool
 	convoyListStatus   string
 	convoyListAll      bool
@@ -143,7 +145,16 @@ var convoyStatusCmd = &cobra.Command{
 	Long: `Show detailed status for a convoy.
 
 Displays convoy metadata, tracked issues, and completion progress.
-Without an ID, shows status of all active convoys.`,
+Without an ID, shows status of all active convoys.
+
+With --format, renders the tracked issues' dependency graph instead:
+nodes colored by status and labeled with assignee and what blocks them,
+with the longest remaining chain (the critical path) highlighted.
+
+Examples:
+  gt convoy status hq-cv-abc
+  gt convoy status hq-cv-abc --format dot | dot -Tsvg > convoy.svg
+  gt convoy status hq-cv-abc --format mermaid`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runConvoyStatus,
 }
@@ -238,6 +249,7 @@ func init() {
 
 	// Status flags
 	convoyStatusCmd.Flags().BoolVar(&convoyStatusJSON, "json", false, "Output as JSON")
+	convoyStatusCmd.Flags().StringVar(&convoyStatusFormat, "format", "", graphFormatUsage)
 
 	// List flags
 	convoyListCmd.Flags().BoolVar(&convoyListJSON, "json", false, "Output as JSON")
@@ -490,8 +502,13 @@ func runConvoyCheck(cmd *cobra.Command, args []string) error {
 }
 
 func runConvoyClose(cmd *cobra.Command, args []string) error {
-	convoyID := args[0]
+	return closeConvoy(args[0], convoyCloseReason, convoyCloseNotify)
+}
 
+// closeConvoy closes a convoy with an optional reason and notifies notify
+// (or the convoy's own notify address when empty). Closing an already
+// closed convoy is a no-op.
+func closeConvoy(convoyID, closeReason, notify string) error {
 	townBeads, err := getTownBeadsDir()
 	if err != nil {
 		return err
@@ -537,7 +554,7 @@ func runConvoyClose(cmd *cobra.Command, args []string) error {
 	}
 
 	// Build close reason
-	reason := convoyCloseReason
+	reason := closeReason
 	if reason == "" {
 		reason = "Manually closed"
 	}
@@ -552,13 +569,13 @@ func runConvoyClose(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Printf("%s Closed convoy 🚚 %s: %s\n", style.Bold.Render("✓"), convoyID, convoy.Title)
-	if convoyCloseReason != "" {
-		fmt.Printf("  Reason: %s\n", convoyCloseReason)
+	if closeReason != "" {
+		fmt.Printf("  Reason: %s\n", closeReason)
 	}
 
 	// Send notification if --notify flag provided
-	if convoyCloseNotify != "" {
-		sendCloseNotification(convoyCloseNotify, convoyID, convoy.Title, reason)
+	if notify != "" {
+		sendCloseNotification(notify, convoyID, convoy.Title, reason)
 	} else {
 		// Check if convoy has a notify address in description
 		notifyConvoyCompletion(townBeads, convoyID, convoy.Title)
@@ -864,9 +881,15 @@ func runConvoyStatus(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	if err := checkGraphFormat(convoyStatusFormat); err != nil {
+		return err
+	}
 
 	// If no ID provided, show all active convoys
 	if len(args) == 0 {
+		if convoyStatusFormat != "" {
+			return fmt.Errorf("--format needs a convoy ID")
+		}
 		return showAllConvoyStatus(townBeads)
 	}
 
@@ -932,6 +955,10 @@ func runConvoyStatus(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if convoyStatusFormat != "" {
+		return printGraph(convoyGraph(townBeads, convoy.ID+": "+convoy.Title, tracked), convoyStatusFormat)
+	}
+
 	if convoyStatusJSON {
 		type jsonStatus struct {
 			ID        string             `json:"id"`
@@ -1182,6 +1209,35 @@ type trackedIssueInfo struct {
 	WorkerAge string `json:"worker_age,omitempty"` // How long worker has been on this issue
 }
 
+// convoyGraph returns the dependency graph of a convoy's tracked issues,
+// plus whatever outside the convoy they are blocked on.
+func convoyGraph(townBeads, title string, tracked []trackedIssueInfo) *dag.Graph {
+	ids := make([]string, 0, len(tracked))
+	for _, t := range tracked {
+		ids = append(ids, t.ID)
+	}
+	issues, _ := beads.New(filepath.Dir(townBeads)).ShowMultiple(ids)
+
+	g := dag.New(title)
+	for _, t := range tracked {
+		var needs []string
+		if issue := issues[t.ID]; issue != nil {
+			needs = beadNeeds(issue, "")
+		}
+		assignee := t.Assignee
+		if assignee == "" {
+			assignee = t.Worker
+		}
+		g.Add(t.ID, t.Title, t.Status, assignee, needs...)
+	}
+	for _, t := range tracked {
+		if issue := issues[t.ID]; issue != nil {
+			addBeadBlockers(g, issue, "")
+		}
+	}
+	return g
+}
+
 // getTrackedIssues queries SQLite directly to get issues tracked by a convoy.
 // This is needed because bd dep list doesn't properly show cross-rig external dependencies.
 // Uses batched lookup to avoid N+1 subprocess calls.
@@ -15,6 +15,8 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/costs"
+	"github.com/steveyegge/gastown/internal/runtime"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -44,22 +46,22 @@ var (
 var costsCmd = &cobra.Command{
 	Use:     "costs",
 	GroupID: GroupDiag,
-	Short:   "Show costs for running Claude sessions [DISABLED]",
-	Long: `Display costs for Claude Code sessions in Gas Town.
+	Short:   "Show costs for running agent sessions",
+	Long: `Display costs for agent sessions in Gas Town.
 
-⚠️  COST TRACKING IS CURRENTLY DISABLED
+Costs are computed from the per-session JSONL transcripts that agent
+runtimes write (Claude Code: ~/.claude/projects/, Codex: ~/.codex/sessions/).
+Token usage (input, output, cache write, cache read) is summed per model and
+priced with a built-in table, which can be overridden per model prefix in
+settings/config.json:
 
-Claude Code displays costs in the TUI status bar, which cannot be captured
-via tmux. All sessions will show $0.00 until Claude Code exposes cost data
-through an API or environment variable.
+  "model_prices": {
+    "claude-sonnet-4": {"input": 3, "output": 15, "cache_write": 3.75, "cache_read": 0.3}
+  }
 
-What we need from Claude Code:
-  - Stop hook env var (e.g., $CLAUDE_SESSION_COST)
-  - Or queryable file/API endpoint
-
-See: GH#24, gt-7awfj
-
-The infrastructure remains in place and will work once cost data is available.
+Prices are USD per million tokens. Sessions are matched to transcripts by
+session ID (CLAUDE_SESSION_ID, or the variable named by GT_SESSION_ID_ENV),
+falling back to the newest transcript for the session's working directory.
 
 Examples:
   gt costs              # Live costs from running sessions
@@ -70,7 +72,7 @@ Examples:
   gt costs --json       # Output as JSON
 
 Subcommands:
-  gt costs record       # Record session cost as ephemeral wisp (Stop hook)
+  gt costs record       # Record session usage as ephemeral wisp (Stop hook)
   gt costs digest       # Aggregate wisps into daily digest bead (Deacon patrol)`,
 	RunE: runCosts,
 }
@@ -81,8 +83,10 @@ var costsRecordCmd = &cobra.Command{
 	Long: `Record the final cost of a session as an ephemeral wisp.
 
 This command is intended to be called from a Claude Code Stop hook.
-It captures the final cost from the tmux session and creates an ephemeral
-event that is NOT exported to JSONL (avoiding log-in-database pollution).
+It reads the session's transcript, records the token usage and cost added
+since the previous call, and creates an ephemeral event that is NOT exported
+to JSONL (avoiding log-in-database pollution). Turns that added no usage
+are not recorded.
 
 Session cost wisps are aggregated daily by 'gt costs digest' into a single
 permanent "Cost Report YYYY-MM-DD" bead for audit purposes.
@@ -160,12 +164,14 @@ func init() {
 
 // SessionCost represents cost info for a single session.
 type SessionCost struct {
-	Session string  `json:"session"`
-	Role    string  `json:"role"`
-	Rig     string  `json:"rig,omitempty"`
-	Worker  string  `json:"worker,omitempty"`
-	Cost    float64 `json:"cost_usd"`
-	Running bool    `json:"running"`
+	Session    string       `json:"session"`
+	Role       string       `json:"role"`
+	Rig        string       `json:"rig,omitempty"`
+	Worker     string       `json:"worker,omitempty"`
+	Cost       float64      `json:"cost_usd"`
+	Running    bool         `json:"running"`
+	Usage      *costs.Usage `json:"usage,omitempty"`
+	Transcript string       `json:"transcript,omitempty"`
 }
 
 // CostEntry is a ledger entry for historical cost tracking.
@@ -178,6 +184,11 @@ type CostEntry struct {
 	StartedAt time.Time `json:"started_at"`
 	EndedAt   time.Time `json:"ended_at"`
 	WorkItem  string    `json:"work_item,omitempty"`
+
+	InputTokens         int64 `json:"input_tokens,omitempty"`
+	OutputTokens        int64 `json:"output_tokens,omitempty"`
+	CacheCreationTokens int64 `json:"cache_creation_tokens,omitempty"`
+	CacheReadTokens     int64 `json:"cache_read_tokens,omitempty"`
 }
 
 // CostsOutput is the JSON output structure.
@@ -203,12 +214,8 @@ func runCosts(cmd *cobra.Command, args []string) error {
 }
 
 func runLiveCosts() error {
-	// Warn that cost tracking is disabled
-	fmt.Fprintf(os.Stderr, "%s Cost tracking is disabled - Claude Code does not expose session costs.\n",
-		style.Warning.Render("⚠"))
-	fmt.Fprintf(os.Stderr, "   All sessions will show $0.00. See: GH#24, gt-7awfj\n\n")
-
 	t := tmux.NewTmux()
+	prices := loadPriceTable()
 
 	// Get all tmux sessions
 	sessions, err := t.ListSessions()
@@ -216,7 +223,7 @@ func runLiveCosts() error {
 		return fmt.Errorf("listing sessions: %w", err)
 	}
 
-	var costs []SessionCost
+	var sessionCosts []SessionCost
 	var total float64
 
 	for _, session := range sessions {
@@ -228,50 +235,55 @@ func runLiveCosts() error {
 		// Parse session name to get role/rig/worker
 		role, rig, worker := parseSessionName(session)
 
-		// Capture pane content
-		content, err := t.CapturePaneAll(session)
-		if err != nil {
-			continue // Skip sessions we can't capture
-		}
-
-		// Extract cost from content
-		cost := extractCost(content)
-
-		// Check if an agent appears to be running
-		running := t.IsAgentRunning(session)
-
-		costs = append(costs, SessionCost{
+		sc := SessionCost{
 			Session: session,
 			Role:    role,
 			Rig:     rig,
 			Worker:  worker,
-			Cost:    cost,
-			Running: running,
-		})
-		total += cost
+			Running: t.IsAgentRunning(session),
+		}
+
+		if transcript, usage, err := liveSessionUsage(t, session); err == nil {
+			cost, unpriced := prices.Cost(usage)
+			usageTotal := usage.Total()
+			sc.Cost = cost
+			sc.Usage = &usageTotal
+			sc.Transcript = transcript
+			if costsVerbose && len(unpriced) > 0 {
+				fmt.Fprintf(os.Stderr, "[costs] %s: no price for models %v\n", session, unpriced)
+			}
+		} else {
+			if costsVerbose {
+				fmt.Fprintf(os.Stderr, "[costs] %s: %v\n", session, err)
+			}
+			// No transcript: fall back to scraping the pane for a "$X.XX" status.
+			content, err := t.CapturePaneAll(session)
+			if err != nil {
+				continue // Skip sessions we can't capture
+			}
+			sc.Cost = extractCost(content)
+		}
+
+		sessionCosts = append(sessionCosts, sc)
+		total += sc.Cost
 	}
 
 	// Sort by session name
-	sort.Slice(costs, func(i, j int) bool {
-		return costs[i].Session < costs[j].Session
+	sort.Slice(sessionCosts, func(i, j int) bool {
+		return sessionCosts[i].Session < sessionCosts[j].Session
 	})
 
 	if costsJSON {
 		return outputCostsJSON(CostsOutput{
-			Sessions: costs,
+			Sessions: sessionCosts,
 			Total:    total,
 		})
 	}
 
-	return outputCostsHuman(costs, total)
+	return outputCostsHuman(sessionCosts, total)
 }
 
 func runCostsFromLedger() error {
-	// Warn that cost tracking is disabled
-	fmt.Fprintf(os.Stderr, "%s Cost tracking is disabled - Claude Code does not expose session costs.\n",
-		style.Warning.Render("⚠"))
-	fmt.Fprintf(os.Stderr, "   Historical data may show $0.00 for all sessions. See: GH#24, gt-7awfj\n\n")
-
 	now := time.Now()
 	var entries []CostEntry
 	var err error
@@ -364,6 +376,28 @@ type SessionPayload struct {
 	Rig       string  `json:"rig"`
 	Worker    string  `json:"worker"`
 	EndedAt   string  `json:"ended_at"`
+
+	InputTokens         int64 `json:"input_tokens"`
+	OutputTokens        int64 `json:"output_tokens"`
+	CacheCreationTokens int64 `json:"cache_creation_tokens"`
+	CacheReadTokens     int64 `json:"cache_read_tokens"`
+}
+
+// costEntryFromPayload builds a ledger entry from a session.ended payload.
+func costEntryFromPayload(payload SessionPayload, endedAt time.Time, workItem string) CostEntry {
+	return CostEntry{
+		SessionID:           payload.SessionID,
+		Role:                payload.Role,
+		Rig:                 payload.Rig,
+		Worker:              payload.Worker,
+		CostUSD:             payload.CostUSD,
+		EndedAt:             endedAt,
+		WorkItem:            workItem,
+		InputTokens:         payload.InputTokens,
+		OutputTokens:        payload.OutputTokens,
+		CacheCreationTokens: payload.CacheCreationTokens,
+		CacheReadTokens:     payload.CacheReadTokens,
+	}
 }
 
 // EventListItem represents an event from bd list (minimal fields).
@@ -496,15 +530,7 @@ func querySessionEventsFromLocation(location string) ([]CostEntry, error) {
 			}
 		}
 
-		entries = append(entries, CostEntry{
-			SessionID: payload.SessionID,
-			Role:      payload.Role,
-			Rig:       payload.Rig,
-			Worker:    payload.Worker,
-			CostUSD:   payload.CostUSD,
-			EndedAt:   endedAt,
-			WorkItem:  event.Target,
-		})
+		entries = append(entries, costEntryFromPayload(payload, endedAt, event.Target))
 	}
 
 	return entries, nil
@@ -636,7 +662,8 @@ func parseSessionName(session string) (role, rig, worker string) {
 }
 
 // extractCost finds the most recent cost value in pane content.
-// Claude Code displays cost in the format "$X.XX" in the status area.
+// This is the fallback for sessions without a transcript: some runtimes
+// display cost in the format "$X.XX" in the status area.
 func extractCost(content string) float64 {
 	matches := costRegex.FindAllStringSubmatch(content, -1)
 	if len(matches) == 0 {
@@ -669,9 +696,9 @@ func outputCostsHuman(costs []SessionCost, total float64) error {
 	fmt.Printf("\n%s Live Session Costs\n\n", style.Bold.Render("💰"))
 
 	// Print table header
-	fmt.Printf("%-25s %-10s %-15s %10s %8s\n",
-		"Session", "Role", "Rig/Worker", "Cost", "Status")
-	fmt.Println(strings.Repeat("─", 75))
+	fmt.Printf("%-25s %-10s %-15s %10s %10s %8s\n",
+		"Session", "Role", "Rig/Worker", "Tokens", "Cost", "Status")
+	fmt.Println(strings.Repeat("─", 86))
 
 	// Print each session
 	for _, c := range costs {
@@ -689,16 +716,23 @@ func outputCostsHuman(costs []SessionCost, total float64) error {
 			}
 		}
 
-		fmt.Printf("%-25s %-10s %-15s %10s %8s\n",
+		tokens := "-"
+		if c.Usage != nil {
+			u := c.Usage
+			tokens = formatTokenCount(u.InputTokens + u.OutputTokens + u.CacheCreationTokens + u.CacheReadTokens)
+		}
+
+		fmt.Printf("%-25s %-10s %-15s %10s %10s %8s\n",
 			c.Session,
 			c.Role,
 			rigWorker,
+			tokens,
 			fmt.Sprintf("$%.2f", c.Cost),
 			statusIcon)
 	}
 
 	// Print total
-	fmt.Println(strings.Repeat("─", 75))
+	fmt.Println(strings.Repeat("─", 86))
 	fmt.Printf("%s %s\n", style.Bold.Render("Total:"), fmt.Sprintf("$%.2f", total))
 
 	return nil
@@ -758,17 +792,44 @@ func runCostsRecord(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("--session flag required (or set GT_SESSION env var, or GT_RIG/GT_ROLE)")
 	}
 
-	t := tmux.NewTmux()
-
-	// Capture pane content
-	content, err := t.CapturePaneAll(session)
+	// Find town root so bd can find the .beads database.
+	// The stop hook may run from a role subdirectory (e.g., mayor/) that
+	// doesn't have its own .beads, so we need to run bd from town root.
+	townRoot, err := workspace.FindFromCwd()
 	if err != nil {
-		// Session may already be gone - that's OK, we'll record with zero cost
-		content = ""
+		return fmt.Errorf("finding town root: %w", err)
+	}
+	if townRoot == "" {
+		return fmt.Errorf("not in a Gas Town workspace")
 	}
 
-	// Extract cost
-	cost := extractCost(content)
+	// Usage since the last record for this session, from its transcript.
+	var cost float64
+	var delta costs.ModelUsage
+	var cursor *costs.Cursor
+	cursorPath := costs.CursorPath(townRoot, session)
+	workDir, _ := os.Getwd()
+	transcript, err := costs.NewLocator().Find(runtime.SessionIDFromEnv(), workDir)
+	if err == nil {
+		usage, parseErr := costs.ParseTranscriptFile(transcript)
+		if parseErr != nil {
+			return fmt.Errorf("parsing transcript %s: %w", transcript, parseErr)
+		}
+		cursor, err = costs.LoadCursor(cursorPath)
+		if err != nil {
+			return err
+		}
+		delta = cursor.Advance(transcript, usage)
+		cost, _ = loadPriceTable().Cost(delta)
+		if delta.Total().IsZero() && recordWorkItem == "" {
+			return nil // Nothing new since the last turn
+		}
+	} else {
+		// No transcript: fall back to scraping the pane for a "$X.XX" status.
+		// Session may already be gone - that's OK, we'll record with zero cost.
+		content, _ := tmux.NewTmux().CapturePaneAll(session)
+		cost = extractCost(content)
+	}
 
 	// Parse session name
 	role, rig, worker := parseSessionName(session)
@@ -795,6 +856,13 @@ func runCostsRecord(cmd *cobra.Command, args []string) error {
 	if worker != "" {
 		payload["worker"] = worker
 	}
+	if usage := delta.Total(); !usage.IsZero() {
+		payload["input_tokens"] = usage.InputTokens
+		payload["output_tokens"] = usage.OutputTokens
+		payload["cache_creation_tokens"] = usage.CacheCreationTokens
+		payload["cache_read_tokens"] = usage.CacheReadTokens
+		payload["models"] = delta.Models()
+	}
 	payloadJSON, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("marshaling payload: %w", err)
@@ -825,17 +893,6 @@ func runCostsRecord(cmd *cobra.Command, args []string) error {
 	// event fields (event_kind, actor, payload) to not be stored properly.
 	// The bd command will auto-detect the correct rig from cwd.
 
-	// Find town root so bd can find the .beads database.
-	// The stop hook may run from a role subdirectory (e.g., mayor/) that
-	// doesn't have its own .beads, so we need to run bd from town root.
-	townRoot, err := workspace.FindFromCwd()
-	if err != nil {
-		return fmt.Errorf("finding town root: %w", err)
-	}
-	if townRoot == "" {
-		return fmt.Errorf("not in a Gas Town workspace")
-	}
-
 	// Execute bd create from town root
 	bdCmd := exec.Command("bd", bdArgs...)
 	bdCmd.Dir = townRoot
@@ -846,6 +903,13 @@ func runCostsRecord(cmd *cobra.Command, args []string) error {
 
 	wispID := strings.TrimSpace(string(output))
 
+	// Only advance the cursor once the usage is safely recorded.
+	if cursor != nil {
+		if err := cursor.Save(cursorPath); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: could not save cost cursor for %s: %v\n", session, err)
+		}
+	}
+
 	// Auto-close session cost wisps immediately after creation.
 	// These are informational records that don't need to stay open.
 	// The wisp data is preserved and queryable until digested.
@@ -966,6 +1030,58 @@ func buildAgentPath(role, rig, worker string) string {
 	}
 }
 
+// loadPriceTable returns the token price table with town overrides applied.
+// Outside a town (or with unreadable settings) the built-in prices are used.
+func loadPriceTable() costs.PriceTable {
+	townRoot, err := workspace.FindFromCwd()
+	if err != nil || townRoot == "" {
+		return costs.NewPriceTable(nil)
+	}
+	settings, err := config.LoadOrCreateTownSettings(config.TownSettingsPath(townRoot))
+	if err != nil {
+		return costs.NewPriceTable(nil)
+	}
+	return costs.NewPriceTable(settings.ModelPrices)
+}
+
+// liveSessionUsage finds and parses the transcript for a running tmux session.
+// The session's own environment tells us its session ID and config dir.
+func liveSessionUsage(t *tmux.Tmux, session string) (string, costs.ModelUsage, error) {
+	loc := costs.NewLocator()
+	if dir, err := t.GetEnvironment(session, "CLAUDE_CONFIG_DIR"); err == nil && dir != "" {
+		loc.ClaudeConfigDir = dir
+	}
+
+	idEnv := "CLAUDE_SESSION_ID"
+	if name, err := t.GetEnvironment(session, "GT_SESSION_ID_ENV"); err == nil && name != "" {
+		idEnv = name
+	}
+	sessionID, _ := t.GetEnvironment(session, idEnv)
+	workDir, _ := t.GetPaneWorkDir(session)
+
+	transcript, err := loc.Find(sessionID, workDir)
+	if err != nil {
+		return "", nil, err
+	}
+	usage, err := costs.ParseTranscriptFile(transcript)
+	if err != nil {
+		return "", nil, err
+	}
+	return transcript, usage, nil
+}
+
+// formatTokenCount renders a token count compactly (e.g., 950, 12.3K, 4.1M).
+func formatTokenCount(n int64) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fK", float64(n)/1_000)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
+
 // CostDigest represents the aggregated daily cost report.
 type CostDigest struct {
 	Date         string             `json:"date"`
@@ -1147,15 +1263,7 @@ func querySessionCostWisps(targetDate time.Time) ([]CostEntry, error) {
 			continue
 		}
 
-		sessionCostWisps = append(sessionCostWisps, CostEntry{
-			SessionID: payload.SessionID,
-			Role:      payload.Role,
-			Rig:       payload.Rig,
-			Worker:    payload.Worker,
-			CostUSD:   payload.CostUSD,
-			EndedAt:   endedAt,
-			WorkItem:  event.Target,
-		})
+		sessionCostWisps = append(sessionCostWisps, costEntryFromPayload(payload, endedAt, event.Target))
 	}
 
 	return sessionCostWisps, nil
@@ -4,17 +4,20 @@ import (
 	"fmt"
 	"net/http"
 	"os/exec"
+	"path/filepath"
 	"runtime"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/web"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
 var (
-	dashboardPort int
-	dashboardOpen bool
+	dashboardPort     int
+	dashboardOpen     bool
+	dashboardReadOnly bool
 )
 
 var dashboardCmd = &cobra.Command{
@@ -27,24 +30,49 @@ The dashboard shows real-time convoy status with:
 - Convoy list with status indicators
 - Progress tracking for each convoy
 - Last activity indicator (green/yellow/red)
-- Auto-refresh every 30 seconds via htmx
+- Live activity feed streamed from the town events log
+- Auto-refresh every 10 seconds
+
+All assets are embedded in the gt binary, so the dashboard works offline.
+The same data is available as JSON for scripts and other front-ends:
+
+  /api/convoys    Convoys and progress
+  /api/mq         Refinery merge queue
+  /api/polecats   Polecat workers
+  /api/events     Recent events (?limit=N&type=T)
+  /api/stream     Server-Sent Events tailing .events.jsonl
+
+Control actions (nudge a polecat, peek at its pane, retry or reject a
+merge request, close a convoy) are POST endpoints under /api/actions/
+that require the token stored in settings/dashboard.token:
+
+  curl -X POST -H "Authorization: Bearer $(cat settings/dashboard.token)" \
+       -d '{"target":"gastown/nux","message":"status?"}' \
+       http://localhost:8080/api/actions/nudge
+
+The URL printed at startup carries the token so the browser can use the
+action buttons. Every action is recorded in the events log. Use
+--read-only to disable actions.
 
 Example:
   gt dashboard              # Start on default port 8080
   gt dashboard --port 3000  # Start on port 3000
-  gt dashboard --open       # Start and open browser`,
+  gt dashboard --open       # Start and open browser
+  gt dashboard --read-only  # Disable control actions`,
 	RunE: runDashboard,
 }
 
 func init() {
 	dashboardCmd.Flags().IntVar(&dashboardPort, "port", 8080, "HTTP port to listen on")
 	dashboardCmd.Flags().BoolVar(&dashboardOpen, "open", false, "Open browser automatically")
+	dashboardCmd.Flags().BoolVar(&dashboardReadOnly, "read-only", false, "Disable control actions")
 	rootCmd.AddCommand(dashboardCmd)
 }
 
 func runDashboard(cmd *cobra.Command, args []string) error {
 	// Verify we're in a workspace
-	if _, err := workspace.FindFromCwdOrError(); err != nil {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
 		return fmt.Errorf("not in a Gas Town workspace: %w", err)
 	}
 
@@ -55,14 +83,25 @@ func runDashboard(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create the handler
-	handler, err := web.NewConvoyHandler(fetcher)
+	handler, err := web.NewServer(fetcher, filepath.Join(townRoot, events.EventsFile))
 	if err != nil {
-		return fmt.Errorf("creating convoy handler: %w", err)
+		return fmt.Errorf("creating dashboard server: %w", err)
 	}
 
 	// Build the URL
 	url := fmt.Sprintf("http://localhost:%d", dashboardPort)
 
+	// Enable control actions behind the town's dashboard token. The token
+	// travels in the URL fragment, which browsers never send to the server.
+	if !dashboardReadOnly {
+		token, err := web.LoadOrCreateToken(townRoot)
+		if err != nil {
+			return err
+		}
+		handler.EnableActions(&dashboardActions{townRoot: townRoot}, token)
+		url += "/#token=" + token
+	}
+
 	// Open browser if requested
 	if dashboardOpen {
 		go openBrowser(url)
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/tmux"
+	"github.com/steveyegge/gastown/internal/web"
+)
+
+// dashboardActions carries out dashboard control actions through the same
+// helpers as gt nudge, gt mq retry/reject, gt convoy close and gt peek.
+type dashboardActions struct {
+	townRoot string
+}
+
+var _ web.Actions = (*dashboardActions)(nil)
+
+// Nudge nudges a rig/polecat or rig/crew/name address, honoring DND like
+// gt nudge without --force.
+func (a *dashboardActions) Nudge(target, message string) error {
+	if !strings.Contains(target, "/") {
+		return fmt.Errorf("target must be rig/polecat or rig/crew/name, got %q", target)
+	}
+	if shouldSend, level, _ := shouldNudgeTarget(a.townRoot, target, false); !shouldSend {
+		return fmt.Errorf("%s has DND enabled (%s)", target, level)
+	}
+	return nudgeAddress(tmux.NewTmux(), target, "dashboard", "[from dashboard] "+message)
+}
+
+// RetryMR requeues a failed merge request for the next refinery cycle.
+func (a *dashboardActions) RetryMR(rigName, id string) error {
+	mgr, _, _, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+	if err := mgr.Retry(id, false); err != nil {
+		switch err {
+		case refinery.ErrMRNotFound:
+			return fmt.Errorf("merge request '%s' not found in rig '%s'", id, rigName)
+		case refinery.ErrMRNotFailed:
+			return fmt.Errorf("merge request '%s' has not failed", id)
+		}
+		return fmt.Errorf("retrying merge request: %w", err)
+	}
+	return nil
+}
+
+// RejectMR rejects a merge request and mails its worker.
+func (a *dashboardActions) RejectMR(rigName, id, reason string) error {
+	mgr, _, _, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+	if _, err := mgr.RejectMR(id, reason, true); err != nil {
+		return fmt.Errorf("rejecting MR: %w", err)
+	}
+	return nil
+}
+
+// CloseConvoy closes a convoy, notifying its subscribers.
+func (a *dashboardActions) CloseConvoy(id, reason string) error {
+	if reason == "" {
+		reason = "Closed from dashboard"
+	}
+	return closeConvoy(id, reason, "")
+}
+
+// Peek captures recent output from an agent session.
+func (a *dashboardActions) Peek(target string, lines int) (string, error) {
+	return peekAddress(target, lines)
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/dag"
+	"github.com/steveyegge/gastown/internal/formula"
+)
+
+// graphFormatUsage is the help text for the --format flag of commands
+// that can render their dependency graph.
+var graphFormatUsage = "Render the dependency graph as " + strings.Join(dag.Formats, ", ") + " (critical path highlighted)"
+
+// checkGraphFormat rejects an unknown --format before any work is done.
+func checkGraphFormat(format string) error {
+	if format == "" {
+		return nil
+	}
+	for _, f := range dag.Formats {
+		if format == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown --format %q (must be %s)", format, strings.Join(dag.Formats, ", "))
+}
+
+// printGraph analyzes g and writes it to stdout.
+func printGraph(g *dag.Graph, format string) error {
+	g.Analyze()
+	return g.Render(os.Stdout, format)
+}
+
+// isBlockingDep reports whether a dependency type holds up its dependent.
+// Parent-child, tracks and informational links don't.
+func isBlockingDep(depType string) bool {
+	return depType == "" || depType == "blocks"
+}
+
+// beadNeeds returns the IDs an issue is blocked on, leaving out skip
+// (e.g. the epic a swarm's tasks hang off).
+func beadNeeds(issue *beads.Issue, skip string) []string {
+	var needs []string
+	add := func(id string) {
+		if id == skip || id == issue.ID {
+			return
+		}
+		for _, n := range needs {
+			if n == id {
+				return
+			}
+		}
+		needs = append(needs, id)
+	}
+	for _, id := range issue.DependsOn {
+		add(id)
+	}
+	for _, id := range issue.BlockedBy {
+		add(id)
+	}
+	for _, dep := range issue.Dependencies {
+		if isBlockingDep(dep.DependencyType) {
+			add(dep.ID)
+		}
+	}
+	return needs
+}
+
+// addBeadBlockers adds the issue's blocking dependencies that aren't in g
+// yet, so the graph shows what it waits on even outside the set being
+// graphed. Add the graphed issues themselves first.
+func addBeadBlockers(g *dag.Graph, issue *beads.Issue, skip string) {
+	for _, dep := range issue.Dependencies {
+		if isBlockingDep(dep.DependencyType) && dep.ID != skip && g.Node(dep.ID) == nil {
+			g.Add(dep.ID, dep.Title, dep.Status, "")
+		}
+	}
+}
+
+// formulaRunGraph returns the dependency graph of a formula run's units.
+func formulaRunGraph(run *formula.Run) *dag.Graph {
+	g := dag.New(run.ID + ": " + run.Formula)
+	for _, u := range run.Units {
+		n := g.Add(u.ID, u.Title, string(u.Status), "", u.Needs...)
+		if u.Error != "" {
+			n.Reason = u.Error
+		}
+	}
+	return g
+}
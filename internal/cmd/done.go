@@ -8,6 +8,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/mail"
@@ -274,6 +275,13 @@ func runDone(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("branch '%s' has 0 commits ahead of %s; nothing to merge\nMake and commit changes first, or use --status DEFERRED to exit without completing", branch, originDefault)
 		}
 
+		// Run pre-done lifecycle hooks (e.g., lint) in the worktree; a failing
+		// "fail" hook blocks submission until the polecat fixes it.
+		hookEnv := rig.HookEnv{Rig: rigName, Polecat: polecatName, Bead: issueID, Branch: branch, Worktree: cwd}
+		if err := rig.RunLifecycleHooks(filepath.Join(townRoot, rigName), config.HookPreDone, hookEnv, os.Stdout); err != nil {
+			return fmt.Errorf("cannot complete: %w\nFix the problem, commit, and run gt done again", err)
+		}
+
 		// CRITICAL: Push branch BEFORE creating MR bead (hq-6dk53, hq-a4ksk)
 		// The MR bead triggers Refinery to process this branch. If the branch
 		// isn't pushed yet, Refinery finds nothing to merge. The worktree gets
@@ -6,16 +6,17 @@ import (
 
 // Escalate command flags
 var (
-	escalateSeverity    string
-	escalateReason      string
-	escalateSource      string
-	escalateRelatedBead string
-	escalateJSON        bool
-	escalateListJSON    bool
-	escalateListAll     bool
-	escalateStaleJSON   bool
-	escalateDryRun      bool
-	escalateCloseReason string
+	escalateSeverity      string
+	escalateReason        string
+	escalateSource        string
+	escalateRelatedBead   string
+	escalateJSON          bool
+	escalateListJSON      bool
+	escalateListAll       bool
+	escalateStaleJSON     bool
+	escalateDryRun        bool
+	escalateCloseReason   string
+	escalateTestRouteSend bool
 )
 
 var escalateCmd = &cobra.Command{
@@ -43,8 +44,10 @@ WORKFLOW:
 
 CONFIGURATION:
   Routing is configured in ~/gt/settings/escalation.json:
-  - routes: Map severity to action lists (bead, mail:mayor, email:human, sms:human)
-  - contacts: Human email/SMS for external notifications
+  - routes: Map severity to action lists (bead, mail:mayor, email:human,
+    sms:human, slack, webhook:<name>)
+  - contacts: Human email/SMS and Slack webhook for external notifications
+  - channels: SMTP server, SMS gateway hook, named webhooks, and retries
   - stale_threshold: When unacked escalations are re-escalated (default: 4h)
   - max_reescalations: How many times to bump severity (default: 2)
 
@@ -55,7 +58,8 @@ Examples:
   gt escalate list                          # Show open escalations
   gt escalate ack hq-abc123                 # Acknowledge
   gt escalate close hq-abc123 --reason "Fixed in commit abc"
-  gt escalate stale                         # Re-escalate stale escalations`,
+  gt escalate stale                         # Re-escalate stale escalations
+  gt escalate test-route critical           # Check where critical escalations go`,
 }
 
 var escalateListCmd = &cobra.Command{
@@ -135,6 +139,37 @@ Examples:
 	RunE: runEscalateShow,
 }
 
+var escalateTestRouteCmd = &cobra.Command{
+	Use:   "test-route <severity>",
+	Short: "Show (and optionally test) the route for a severity",
+	Long: `Show how an escalation of the given severity would be routed.
+
+Each action in the route is resolved against settings/escalation.json and
+reported with its backend and recipient, or the reason it would be skipped
+(e.g., contacts.human_email or channels.smtp not configured).
+
+Nothing is sent unless --send is given, in which case a clearly marked test
+notification is delivered through every external action (email, SMS, Slack,
+webhooks). No escalation bead or mail is created, and nothing is written to
+the escalation log.
+
+Example channels config:
+  "channels": {
+    "smtp": {"host": "smtp.example.com", "port": 587, "username": "gt",
+             "password_env": "GT_SMTP_PASSWORD", "from": "gt@example.com"},
+    "sms": {"command": "twilio api:core:messages:create --to \"$GT_TO\" --body \"$GT_SUBJECT\""},
+    "webhooks": {"pager": {"url": "https://events.example.com/v2/enqueue"}},
+    "retries": 2,
+    "retry_backoff": "2s"
+  }
+
+Examples:
+  gt escalate test-route critical          # Dry run
+  gt escalate test-route high --send       # Deliver a test notification`,
+	Args: cobra.ExactArgs(1),
+	RunE: runEscalateTestRoute,
+}
+
 func init() {
 	// Main escalate command flags
 	escalateCmd.Flags().StringVarP(&escalateSeverity, "severity", "s", "medium", "Severity level: critical, high, medium, low")
@@ -159,12 +194,16 @@ func init() {
 	// Show subcommand flags
 	escalateShowCmd.Flags().BoolVar(&escalateJSON, "json", false, "Output as JSON")
 
+	// Test-route subcommand flags
+	escalateTestRouteCmd.Flags().BoolVar(&escalateTestRouteSend, "send", false, "Deliver a test notification through external actions")
+
 	// Add subcommands
 	escalateCmd.AddCommand(escalateListCmd)
 	escalateCmd.AddCommand(escalateAckCmd)
 	escalateCmd.AddCommand(escalateCloseCmd)
 	escalateCmd.AddCommand(escalateStaleCmd)
 	escalateCmd.AddCommand(escalateShowCmd)
+	escalateCmd.AddCommand(escalateTestRouteCmd)
 
 	rootCmd.AddCommand(escalateCmd)
 }
@@ -1,9 +1,11 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -12,6 +14,7 @@ import (
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/notify"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
@@ -114,8 +117,10 @@ func runEscalate(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Process external notification actions (email:, sms:, slack)
-	executeExternalActions(actions, escalationConfig, issue.ID, severity, description)
+	// Process external notification actions (email:, sms:, slack, webhook:)
+	deliveries := executeExternalActions(townRoot, actions, escalationConfig, issue.ID, severity, description,
+		formatEscalationMailBody(issue.ID, severity, escalateReason, agentID, escalateRelatedBead))
+	recordDeliveries(bd, issue.ID, deliveries)
 
 	// Log to activity feed
 	payload := events.EscalationPayload(issue.ID, agentID, strings.Join(targets, ","), description)
@@ -379,6 +384,11 @@ func runEscalateStale(cmd *cobra.Command, args []string) error {
 				}
 			}
 
+			// Notify external channels at the new severity
+			deliveries := executeExternalActions(townRoot, actions, escalationConfig, result.ID, result.NewSeverity,
+				"Re-escalated: "+result.Title, formatReescalationMailBody(result, reescalatedBy))
+			recordDeliveries(bd, result.ID, deliveries)
+
 			// Log to activity feed
 			_ = events.LogFeed(events.TypeEscalationSent, reescalatedBy, map[string]interface{}{
 				"escalation_id":    result.ID,
@@ -490,6 +500,7 @@ func runEscalateShow(cmd *cobra.Command, args []string) error {
 			"closedBy":    fields.ClosedBy,
 			"closedReason": fields.ClosedReason,
 			"relatedBead": fields.RelatedBead,
+			"deliveries":  fields.Deliveries,
 		}
 		out, _ := json.MarshalIndent(data, "", "  ")
 		fmt.Println(string(out))
@@ -516,6 +527,12 @@ func runEscalateShow(cmd *cobra.Command, args []string) error {
 	if fields.RelatedBead != "" {
 		fmt.Printf("  Related: %s\n", fields.RelatedBead)
 	}
+	if len(fields.Deliveries) > 0 {
+		fmt.Printf("  Deliveries:\n")
+		for _, d := range fields.Deliveries {
+			fmt.Printf("    %s %s (%d attempt(s), %s) %s\n", d.Action, d.Status, d.Attempts, d.At, d.Detail)
+		}
+	}
 
 	return nil
 }
@@ -538,41 +555,155 @@ func extractMailTargetsFromActions(actions []string) []string {
 	return targets
 }
 
-// executeExternalActions processes external notification actions (email:, sms:, slack).
-// For now, this logs warnings if contacts aren't configured - actual sending is future work.
-func executeExternalActions(actions []string, cfg *config.EscalationConfig, _, _, _ string) {
+// executeExternalActions delivers external notification actions (email:, sms:,
+// slack, webhook:) with retries and returns a delivery record for each. The
+// "log" action appends the escalation to the town's escalation log.
+func executeExternalActions(townRoot string, actions []string, cfg *config.EscalationConfig, beadID, severity, description, body string) []beads.EscalationDelivery {
+	msg := notify.Message{
+		ID:       beadID,
+		Severity: severity,
+		Title:    description,
+		Body:     body,
+		Source:   escalateSource,
+	}
+
+	var records []beads.EscalationDelivery
+	for _, d := range notify.Dispatch(context.Background(), actions, cfg, msg) {
+		record := beads.EscalationDelivery{
+			Action:   d.Action,
+			Status:   d.Status,
+			At:       d.At.UTC().Format(time.RFC3339),
+			Attempts: d.Attempts,
+			Detail:   d.Detail,
+		}
+		switch d.Status {
+		case notify.DeliverySent:
+			record.Detail = d.To
+			fmt.Printf("  %s %s: sent via %s%s\n", style.Success.Render("✓"), d.Action, d.Notifier, formatRecipient(d.To))
+		case notify.DeliverySkipped:
+			style.PrintWarning("%s skipped: %s in settings/escalation.json", d.Action, d.Detail)
+		default:
+			style.PrintWarning("%s failed after %d attempt(s): %s", d.Action, d.Attempts, d.Detail)
+		}
+		records = append(records, record)
+	}
+
 	for _, action := range actions {
-		switch {
-		case strings.HasPrefix(action, "email:"):
-			if cfg.Contacts.HumanEmail == "" {
-				style.PrintWarning("email action '%s' skipped: contacts.human_email not configured in settings/escalation.json", action)
+		if action == "log" {
+			if err := appendEscalationLog(townRoot, beadID, severity, description); err != nil {
+				style.PrintWarning("writing escalation log: %v", err)
 			} else {
-				// TODO: Implement actual email sending
-				fmt.Printf("  📧 Would send email to %s (not yet implemented)\n", cfg.Contacts.HumanEmail)
+				fmt.Printf("  📝 Logged to %s\n", escalationLogPath(townRoot))
 			}
+		}
+	}
+	return records
+}
 
-		case strings.HasPrefix(action, "sms:"):
-			if cfg.Contacts.HumanSMS == "" {
-				style.PrintWarning("sms action '%s' skipped: contacts.human_sms not configured in settings/escalation.json", action)
-			} else {
-				// TODO: Implement actual SMS sending
-				fmt.Printf("  📱 Would send SMS to %s (not yet implemented)\n", cfg.Contacts.HumanSMS)
-			}
+// escalationLogPath returns the path of the town's escalation log.
+func escalationLogPath(townRoot string) string {
+	return filepath.Join(townRoot, "logs", "escalations.log")
+}
 
-		case action == "slack":
-			if cfg.Contacts.SlackWebhook == "" {
-				style.PrintWarning("slack action skipped: contacts.slack_webhook not configured in settings/escalation.json")
+// appendEscalationLog appends one line per escalation to the escalation log:
+// timestamp, severity, bead ID and description.
+func appendEscalationLog(townRoot, beadID, severity, description string) error {
+	path := escalationLogPath(townRoot)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	line := fmt.Sprintf("%s [%s] %s: %s\n", time.Now().UTC().Format(time.RFC3339), severity, beadID, description)
+	if _, err := f.WriteString(line); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// recordDeliveries stores external delivery records on the escalation bead.
+func recordDeliveries(bd *beads.Beads, beadID string, records []beads.EscalationDelivery) {
+	if err := bd.RecordEscalationDeliveries(beadID, records); err != nil {
+		style.PrintWarning("failed to record deliveries on %s: %v", beadID, err)
+	}
+}
+
+// formatRecipient renders " to <recipient>" when there is one.
+func formatRecipient(to string) string {
+	if to == "" {
+		return ""
+	}
+	return " to " + to
+}
+
+func runEscalateTestRoute(cmd *cobra.Command, args []string) error {
+	severity := strings.ToLower(args[0])
+	if !config.IsValidSeverity(severity) {
+		return fmt.Errorf("invalid severity '%s': must be critical, high, medium, or low", args[0])
+	}
+
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	escalationConfig, err := config.LoadOrCreateEscalationConfig(config.EscalationConfigPath(townRoot))
+	if err != nil {
+		return fmt.Errorf("loading escalation config: %w", err)
+	}
+
+	actions := escalationConfig.GetRouteForSeverity(severity)
+	fmt.Printf("%s Route for %s: %s\n\n", severityEmoji(severity), severity, strings.Join(actions, ", "))
+
+	for _, action := range actions {
+		switch {
+		case action == "bead":
+			fmt.Printf("  %s %-14s create escalation bead\n", style.Success.Render("✓"), action)
+		case strings.HasPrefix(action, "mail:"):
+			fmt.Printf("  %s %-14s gt mail to %s\n", style.Success.Render("✓"), action, strings.TrimPrefix(action, "mail:"))
+		case action == "log":
+			fmt.Printf("  %s %-14s append to %s (not written by a test)\n", style.Success.Render("✓"), action, escalationLogPath(townRoot))
+		case notify.IsExternal(action):
+			route := notify.Resolve(action, escalationConfig)
+			if route.Notifier == nil {
+				fmt.Printf("  %s %-14s %s\n", style.Warning.Render("⚠"), action, route.Skip)
 			} else {
-				// TODO: Implement actual Slack webhook posting
-				fmt.Printf("  💬 Would post to Slack (not yet implemented)\n")
+				fmt.Printf("  %s %-14s %s%s (timeout %s)\n", style.Success.Render("✓"), action, route.Notifier.Name(), formatRecipient(route.To), route.Timeout)
 			}
+		default:
+			fmt.Printf("  %s %-14s unknown action\n", style.Error.Render("✗"), action)
+		}
+	}
 
-		case action == "log":
-			// Log action always succeeds - writes to escalation log file
-			// TODO: Implement actual log file writing
-			fmt.Printf("  📝 Logged to escalation log\n")
+	if !escalateTestRouteSend {
+		fmt.Printf("\n%s\n", style.Dim.Render("Dry run: nothing was sent. Use --send to deliver a test notification to external channels."))
+		return nil
+	}
+
+	fmt.Printf("\nSending test notification...\n")
+	title := fmt.Sprintf("Test escalation route (%s)", severity)
+	body := fmt.Sprintf("This is a test of the %s escalation route from gt escalate test-route.\nNo action is needed.", severity)
+	// A test must not leave a fake entry in the real escalation log.
+	var external []string
+	for _, action := range actions {
+		if action != "log" {
+			external = append(external, action)
 		}
 	}
+	records := executeExternalActions(townRoot, external, escalationConfig, "test", severity, title, body)
+	failed := 0
+	for _, r := range records {
+		if r.Status != notify.DeliverySent {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d external action(s) not delivered", failed, len(records))
+	}
+	return nil
 }
 
 func formatEscalationMailBody(beadID, severity, reason, from, related string) string {
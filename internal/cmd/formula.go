@@ -8,11 +8,12 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
-	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/formula"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/workspace"
 	"golang.org/x/text/cases"
@@ -26,6 +27,9 @@ var (
 	formulaRunPR      int
 	formulaRunRig     string
 	formulaRunDryRun  bool
+	formulaRunVars    []string
+	formulaRunVarList []string
+	formulaRunDetach  bool
 	formulaCreateType string
 )
 
@@ -42,10 +46,12 @@ and composition rules. They can be "poured" to create molecules or "wisped"
 for ephemeral patrol cycles.
 
 Commands:
-  list    List available formulas from all search paths
-  show    Display formula details (steps, variables, composition)
-  run     Execute a formula (pour and dispatch)
-  create  Create a new formula template
+  list      List available formulas from all search paths
+  show      Display formula details (steps, variables, composition)
+  run       Execute a formula (pour and dispatch)
+  status    Show and advance formula runs
+  validate  Check a formula and its variables
+  create    Create a new formula template
 
 Search paths (in order):
   1. .beads/formulas/ (project)
@@ -96,12 +102,23 @@ Examples:
 var formulaRunCmd = &cobra.Command{
 	Use:   "run [name]",
 	Short: "Execute a formula",
-	Long: `Execute a formula by pouring it and dispatching work.
+	Long: `Execute a formula by instantiating it into beads and dispatching work.
 
 This command:
   1. Looks up the formula by name (or uses default from rig config)
-  2. Pours it to create a molecule (or uses existing proto)
-  3. Dispatches the molecule to available workers
+  2. Creates a convoy bead tracking the run, and a bead per step, leg
+     or aspect (plus the synthesis)
+  3. Slings each ready bead to a polecat in the rig
+  4. Watches the beads, dispatching steps as their needs close and the
+     synthesis once its legs are done
+
+Workflow steps run as their needs complete. Convoy legs and aspects run in
+parallel; each is told to write its findings to the file named by the
+formula's [output] leg_pattern, and the synthesis reads them from the
+output directory.
+
+The run's state is saved under .runtime/formula-runs/. With --detach (or
+after Ctrl-C), pick it up again with gt formula status <run-id> --watch.
 
 For PR-based workflows, use --pr to specify the GitHub PR number.
 
@@ -109,16 +126,20 @@ If no formula name is provided, uses the default formula configured in
 the rig's settings/config.json under workflow.default_formula.
 
 Options:
-  --pr=N      Run formula on GitHub PR #N
-  --rig=NAME  Target specific rig (default: current or gastown)
-  --dry-run   Show what would happen without executing
+  --pr=N           Run formula on GitHub PR #N (sets the pr variable)
+  --var key=value  Set a formula input or variable (repeatable)
+  --vars k=v,k=v   Set several variables at once (list values keep their
+                   commas; use --var for values containing "=")
+  --rig=NAME       Target specific rig (default: current or gastown)
+  --detach         Dispatch the first wave and return without watching
+  --dry-run        Show what would happen without executing
 
 Examples:
-  gt formula run shiny                    # Run formula in current rig
-  gt formula run                          # Run default formula from rig config
-  gt formula run shiny --pr=123           # Run on PR #123
-  gt formula run security-audit --rig=beads  # Run in specific rig
-  gt formula run release --dry-run        # Preview execution`,
+  gt formula run shiny --var feature="dark mode"  # Run formula in current rig
+  gt formula run                                   # Run default formula from rig config
+  gt formula run code-review --pr=123              # Review PR #123
+  gt formula run code-review --var branch=fix-auth --rig=beads
+  gt formula run release --dry-run                 # Preview execution`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runFormulaRun,
 }
@@ -155,6 +176,9 @@ func init() {
 	formulaRunCmd.Flags().IntVar(&formulaRunPR, "pr", 0, "GitHub PR number to run formula on")
 	formulaRunCmd.Flags().StringVar(&formulaRunRig, "rig", "", "Target rig (default: current or gastown)")
 	formulaRunCmd.Flags().BoolVar(&formulaRunDryRun, "dry-run", false, "Preview execution without running")
+	formulaRunCmd.Flags().StringArrayVar(&formulaRunVars, "var", nil, "Formula variable as key=value (can be used multiple times)")
+	formulaRunCmd.Flags().StringArrayVar(&formulaRunVarList, "vars", nil, "Comma-separated formula variables (key=value,key=value); list values may contain commas")
+	formulaRunCmd.Flags().BoolVar(&formulaRunDetach, "detach", false, "Dispatch ready work and return without watching the run")
 
 	// Create flags
 	formulaCreateCmd.Flags().StringVar(&formulaCreateType, "type", "task", "Formula type: task, workflow, or patrol")
@@ -195,9 +219,8 @@ func runFormulaShow(cmd *cobra.Command, args []string) error {
 	return bdCmd.Run()
 }
 
-// runFormulaRun executes a formula by spawning a convoy of polecats.
-// For convoy-type formulas, it creates a convoy bead, creates leg beads,
-// and slings each leg to a separate polecat with leg-specific prompts.
+// runFormulaRun instantiates a formula as a run and executes it: a bead per
+// step (or leg), dispatched to polecats as their needs complete.
 func runFormulaRun(cmd *cobra.Command, args []string) error {
 	// Determine target rig first (needed for default formula lookup)
 	targetRig := formulaRunRig
@@ -245,276 +268,73 @@ func runFormulaRun(cmd *cobra.Command, args []string) error {
 		fmt.Printf("%s Using default formula: %s\n", style.Dim.Render("Note:"), formulaName)
 	}
 
-	// Find the formula file
-	formulaPath, err := findFormulaFile(formulaName)
+	given, err := parseFormulaRunVars()
 	if err != nil {
-		return fmt.Errorf("finding formula: %w", err)
+		return err
 	}
 
-	// Parse the formula
-	f, err := parseFormulaFile(formulaPath)
+	// Find, parse and resolve the formula
+	f, err := loadFormula(formulaName, given)
+	if err != nil {
+		return err
+	}
+	vars, err := checkFormulaVars(f, given)
 	if err != nil {
-		return fmt.Errorf("parsing formula: %w", err)
+		return err
+	}
+	run, err := formula.NewRun(f, formulaName+"-"+generateFormulaShortID(), targetRig, rigPath, vars)
+	if err != nil {
+		return fmt.Errorf("instantiating %s: %w", formulaName, err)
 	}
 
 	// Handle dry-run mode
 	if formulaRunDryRun {
-		return dryRunFormula(f, formulaName, targetRig)
-	}
-
-	// Currently only convoy formulas are supported for execution
-	if f.Type != "convoy" {
-		fmt.Printf("%s Formula type '%s' not yet supported for execution.\n",
-			style.Dim.Render("Note:"), f.Type)
-		fmt.Printf("Currently only 'convoy' formulas can be run.\n")
-		fmt.Printf("\nTo run '%s' manually:\n", formulaName)
-		fmt.Printf("  1. View formula:   gt formula show %s\n", formulaName)
-		fmt.Printf("  2. Cook to proto:  bd cook %s\n", formulaName)
-		fmt.Printf("  3. Pour molecule:  bd pour %s\n", formulaName)
-		fmt.Printf("  4. Sling to rig:   gt sling <mol-id> %s\n", targetRig)
-		return nil
+		return dryRunFormula(f, run)
 	}
 
-	// Execute convoy formula
-	return executeConvoyFormula(f, formulaName, targetRig)
+	return executeFormulaRun(f, run)
 }
 
 // dryRunFormula shows what would happen without executing
-func dryRunFormula(f *formulaData, formulaName, targetRig string) error {
+func dryRunFormula(f *formula.Formula, run *formula.Run) error {
 	fmt.Printf("%s Would execute formula:\n", style.Dim.Render("[dry-run]"))
-	fmt.Printf("  Formula: %s\n", style.Bold.Render(formulaName))
+	fmt.Printf("  Formula: %s\n", style.Bold.Render(f.Name))
 	fmt.Printf("  Type:    %s\n", f.Type)
-	fmt.Printf("  Rig:     %s\n", targetRig)
-	if formulaRunPR > 0 {
-		fmt.Printf("  PR:      #%d\n", formulaRunPR)
-	}
-
-	if f.Type == "convoy" && len(f.Legs) > 0 {
-		fmt.Printf("\n  Legs (%d parallel):\n", len(f.Legs))
-		for _, leg := range f.Legs {
-			fmt.Printf("    • %s: %s\n", leg.ID, leg.Title)
+	fmt.Printf("  Rig:     %s\n", run.Rig)
+	if len(run.Vars) > 0 {
+		fmt.Printf("  Vars:\n")
+		names := make([]string, 0, len(run.Vars))
+		for k := range run.Vars {
+			names = append(names, k)
 		}
-		if f.Synthesis != nil {
-			fmt.Printf("\n  Synthesis:\n")
-			fmt.Printf("    • %s\n", f.Synthesis.Title)
+		sort.Strings(names)
+		for _, k := range names {
+			fmt.Printf("    %s = %s\n", k, run.Vars[k])
 		}
 	}
-
-	return nil
-}
-
-// executeConvoyFormula spawns a convoy of polecats to execute a convoy formula
-func executeConvoyFormula(f *formulaData, formulaName, targetRig string) error {
-	fmt.Printf("%s Executing convoy formula: %s\n\n",
-		style.Bold.Render("🚚"), formulaName)
-
-	// Get town beads directory for convoy creation
-	townRoot, err := workspace.FindFromCwd()
-	if err != nil {
-		return fmt.Errorf("finding town root: %w", err)
-	}
-	townBeads := filepath.Join(townRoot, ".beads")
-
-	// Step 1: Create convoy bead
-	convoyID := fmt.Sprintf("hq-cv-%s", generateFormulaShortID())
-	convoyTitle := fmt.Sprintf("%s: %s", formulaName, f.Description)
-	if len(convoyTitle) > 80 {
-		convoyTitle = convoyTitle[:77] + "..."
-	}
-
-	// Build description with formula context
-	description := fmt.Sprintf("Formula convoy: %s\n\nLegs: %d\nRig: %s",
-		formulaName, len(f.Legs), targetRig)
-	if formulaRunPR > 0 {
-		description += fmt.Sprintf("\nPR: #%d", formulaRunPR)
-	}
-
-	createArgs := []string{
-		"create",
-		"--type=convoy",
-		"--id=" + convoyID,
-		"--title=" + convoyTitle,
-		"--description=" + description,
-	}
-	if beads.NeedsForceForID(convoyID) {
-		createArgs = append(createArgs, "--force")
-	}
-
-	createCmd := exec.Command("bd", createArgs...)
-	createCmd.Dir = townBeads
-	createCmd.Stderr = os.Stderr
-	if err := createCmd.Run(); err != nil {
-		return fmt.Errorf("creating convoy bead: %w", err)
+	if run.OutputDir != "" {
+		fmt.Printf("  Output:  %s\n", run.OutputDir)
 	}
 
-	fmt.Printf("%s Created convoy: %s\n", style.Bold.Render("✓"), convoyID)
-
-	// Step 2: Create leg beads and track them
-	legBeads := make(map[string]string) // leg.ID -> bead ID
-	for _, leg := range f.Legs {
-		legBeadID := fmt.Sprintf("hq-leg-%s", generateFormulaShortID())
-
-		// Build leg description with prompt if available
-		legDesc := leg.Description
-		if f.Prompts != nil {
-			if basePrompt, ok := f.Prompts["base"]; ok {
-				legDesc = fmt.Sprintf("%s\n\n---\nBase Prompt:\n%s", leg.Description, basePrompt)
-			}
-		}
-
-		legArgs := []string{
-			"create",
-			"--type=task",
-			"--id=" + legBeadID,
-			"--title=" + leg.Title,
-			"--description=" + legDesc,
-		}
-		if beads.NeedsForceForID(legBeadID) {
-			legArgs = append(legArgs, "--force")
-		}
-
-		legCmd := exec.Command("bd", legArgs...)
-		legCmd.Dir = townBeads
-		legCmd.Stderr = os.Stderr
-		if err := legCmd.Run(); err != nil {
-			fmt.Printf("%s Failed to create leg bead for %s: %v\n",
-				style.Dim.Render("Warning:"), leg.ID, err)
+	fmt.Printf("\n  Units (%d):\n", len(run.Units))
+	for _, u := range run.Units {
+		line := fmt.Sprintf("    • %s %s: %s", u.Kind, u.ID, u.Title)
+		if u.Status == formula.UnitSkipped {
+			fmt.Println(style.Dim.Render(line + " (skipped: when " + u.When + ")"))
 			continue
 		}
-
-		// Track the leg with the convoy
-		trackArgs := []string{"dep", "add", convoyID, legBeadID, "--type=tracks"}
-		trackCmd := exec.Command("bd", trackArgs...)
-		trackCmd.Dir = townBeads
-		if err := trackCmd.Run(); err != nil {
-			fmt.Printf("%s Failed to track leg %s: %v\n",
-				style.Dim.Render("Warning:"), leg.ID, err)
-		}
-
-		legBeads[leg.ID] = legBeadID
-		fmt.Printf("  %s Created leg: %s (%s)\n", style.Dim.Render("○"), leg.ID, legBeadID)
-	}
-
-	// Step 3: Create synthesis bead if defined
-	var synthesisBeadID string
-	if f.Synthesis != nil {
-		synthesisBeadID = fmt.Sprintf("hq-syn-%s", generateFormulaShortID())
-
-		synDesc := f.Synthesis.Description
-		if synDesc == "" {
-			synDesc = "Synthesize findings from all legs into unified output"
-		}
-
-		synArgs := []string{
-			"create",
-			"--type=task",
-			"--id=" + synthesisBeadID,
-			"--title=" + f.Synthesis.Title,
-			"--description=" + synDesc,
-		}
-		if beads.NeedsForceForID(synthesisBeadID) {
-			synArgs = append(synArgs, "--force")
+		if len(u.Needs) > 0 {
+			line += style.Dim.Render(" (after " + strings.Join(u.Needs, ", ") + ")")
 		}
-
-		synCmd := exec.Command("bd", synArgs...)
-		synCmd.Dir = townBeads
-		synCmd.Stderr = os.Stderr
-		if err := synCmd.Run(); err != nil {
-			fmt.Printf("%s Failed to create synthesis bead: %v\n",
-				style.Dim.Render("Warning:"), err)
-		} else {
-			// Track synthesis with convoy
-			trackArgs := []string{"dep", "add", convoyID, synthesisBeadID, "--type=tracks"}
-			trackCmd := exec.Command("bd", trackArgs...)
-			trackCmd.Dir = townBeads
-			_ = trackCmd.Run()
-
-			// Add dependencies: synthesis depends on all legs
-			for _, legBeadID := range legBeads {
-				depArgs := []string{"dep", "add", synthesisBeadID, legBeadID}
-				depCmd := exec.Command("bd", depArgs...)
-				depCmd.Dir = townBeads
-				_ = depCmd.Run()
-			}
-
-			fmt.Printf("  %s Created synthesis: %s\n", style.Dim.Render("★"), synthesisBeadID)
+		fmt.Println(line)
+		if u.Output != "" {
+			fmt.Printf("      → %s\n", u.Output)
 		}
 	}
 
-	// Step 4: Sling each leg to a polecat
-	fmt.Printf("\n%s Dispatching legs to polecats...\n\n", style.Bold.Render("→"))
-
-	slingCount := 0
-	for _, leg := range f.Legs {
-		legBeadID, ok := legBeads[leg.ID]
-		if !ok {
-			continue
-		}
-
-		// Build context message for the polecat
-		contextMsg := fmt.Sprintf("Convoy leg: %s\nFocus: %s", leg.Title, leg.Focus)
-
-		// Use gt sling with args for leg-specific context
-		slingArgs := []string{
-			"sling", legBeadID, targetRig,
-			"-a", leg.Description,
-			"-s", leg.Title,
-		}
-
-		slingCmd := exec.Command("gt", slingArgs...)
-		slingCmd.Stdout = os.Stdout
-		slingCmd.Stderr = os.Stderr
-
-		if err := slingCmd.Run(); err != nil {
-			fmt.Printf("%s Failed to sling leg %s: %v\n",
-				style.Dim.Render("Warning:"), leg.ID, err)
-			// Add comment to bead about failure
-			commentArgs := []string{"comment", legBeadID, fmt.Sprintf("Failed to sling: %v", err)}
-			commentCmd := exec.Command("bd", commentArgs...)
-			commentCmd.Dir = townBeads
-			_ = commentCmd.Run()
-			continue
-		}
-
-		slingCount++
-		_ = contextMsg // Used in future for richer context
-	}
-
-	// Summary
-	fmt.Printf("\n%s Convoy dispatched!\n", style.Bold.Render("✓"))
-	fmt.Printf("  Convoy:  %s\n", convoyID)
-	fmt.Printf("  Legs:    %d dispatched\n", slingCount)
-	if synthesisBeadID != "" {
-		fmt.Printf("  Synthesis: %s (blocked until legs complete)\n", synthesisBeadID)
-	}
-	fmt.Printf("\n  Track progress: gt convoy status %s\n", convoyID)
-
 	return nil
 }
 
-// formulaData holds parsed formula information
-type formulaData struct {
-	Name        string
-	Description string
-	Type        string
-	Legs        []formulaLeg
-	Synthesis   *formulaSynthesis
-	Prompts     map[string]string
-}
-
-type formulaLeg struct {
-	ID          string
-	Title       string
-	Focus       string
-	Description string
-}
-
-type formulaSynthesis struct {
-	Title       string
-	Description string
-	DependsOn   []string
-}
-
 // findFormulaFile searches for a formula file by name
 func findFormulaFile(name string) (string, error) {
 	// Search paths in order
@@ -549,178 +369,26 @@ func findFormulaFile(name string) (string, error) {
 	return "", fmt.Errorf("formula '%s' not found in search paths", name)
 }
 
-// parseFormulaFile parses a formula file into formulaData
-func parseFormulaFile(path string) (*formulaData, error) {
-	data, err := os.ReadFile(path)
+// loadFormula finds and parses a formula, resolving any extends, include
+// and compose against the formulas on the search path. given supplies the
+// lists for_each templates loop over.
+func loadFormula(name string, given map[string]string) (*formula.Formula, error) {
+	path, err := findFormulaFile(name)
 	if err != nil {
-		return nil, err
-	}
-
-	// Use simple TOML parsing for the fields we need
-	// (avoids importing the full formula package which might cause cycles)
-	f := &formulaData{
-		Prompts: make(map[string]string),
-	}
-
-	content := string(data)
-
-	// Parse formula name
-	if match := extractTOMLValue(content, "formula"); match != "" {
-		f.Name = match
-	}
-
-	// Parse description
-	if match := extractTOMLMultiline(content, "description"); match != "" {
-		f.Description = match
-	}
-
-	// Parse type
-	if match := extractTOMLValue(content, "type"); match != "" {
-		f.Type = match
-	}
-
-	// Parse legs (convoy formulas)
-	f.Legs = extractLegs(content)
-
-	// Parse synthesis
-	f.Synthesis = extractSynthesis(content)
-
-	// Parse prompts
-	f.Prompts = extractPrompts(content)
-
-	return f, nil
-}
-
-// extractTOMLValue extracts a simple quoted value from TOML
-func extractTOMLValue(content, key string) string {
-	// Match: key = "value" or key = 'value'
-	for _, line := range strings.Split(content, "\n") {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, key+" =") || strings.HasPrefix(line, key+"=") {
-			parts := strings.SplitN(line, "=", 2)
-			if len(parts) == 2 {
-				val := strings.TrimSpace(parts[1])
-				// Remove quotes
-				if len(val) >= 2 && (val[0] == '"' || val[0] == '\'') {
-					return val[1 : len(val)-1]
-				}
-				return val
-			}
-		}
-	}
-	return ""
-}
-
-// extractTOMLMultiline extracts a multiline string (""" ... """)
-func extractTOMLMultiline(content, key string) string {
-	// Look for key = """
-	keyPattern := key + ` = """`
-	idx := strings.Index(content, keyPattern)
-	if idx == -1 {
-		// Try single-line
-		return extractTOMLValue(content, key)
-	}
-
-	start := idx + len(keyPattern)
-	end := strings.Index(content[start:], `"""`)
-	if end == -1 {
-		return ""
-	}
-
-	return strings.TrimSpace(content[start : start+end])
-}
-
-// extractLegs parses [[legs]] sections from TOML
-func extractLegs(content string) []formulaLeg {
-	var legs []formulaLeg
-
-	// Split by [[legs]]
-	sections := strings.Split(content, "[[legs]]")
-	for i, section := range sections {
-		if i == 0 {
-			continue // Skip content before first [[legs]]
-		}
-
-		// Find where this section ends (next [[ or EOF)
-		endIdx := strings.Index(section, "[[")
-		if endIdx == -1 {
-			endIdx = len(section)
-		}
-		section = section[:endIdx]
-
-		leg := formulaLeg{
-			ID:          extractTOMLValue(section, "id"),
-			Title:       extractTOMLValue(section, "title"),
-			Focus:       extractTOMLValue(section, "focus"),
-			Description: extractTOMLMultiline(section, "description"),
-		}
-
-		if leg.ID != "" {
-			legs = append(legs, leg)
-		}
+		return nil, fmt.Errorf("finding formula: %w", err)
 	}
-
-	return legs
-}
-
-// extractSynthesis parses [synthesis] section from TOML
-func extractSynthesis(content string) *formulaSynthesis {
-	idx := strings.Index(content, "[synthesis]")
-	if idx == -1 {
-		return nil
-	}
-
-	section := content[idx:]
-	// Find where section ends
-	if endIdx := strings.Index(section[1:], "\n["); endIdx != -1 {
-		section = section[:endIdx+1]
-	}
-
-	syn := &formulaSynthesis{
-		Title:       extractTOMLValue(section, "title"),
-		Description: extractTOMLMultiline(section, "description"),
+	f, err := formula.ParseFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("parsing formula: %w", err)
 	}
-
-	// Parse depends_on array
-	if depsLine := extractTOMLValue(section, "depends_on"); depsLine != "" {
-		// Simple array parsing: ["a", "b", "c"]
-		depsLine = strings.Trim(depsLine, "[]")
-		for _, dep := range strings.Split(depsLine, ",") {
-			dep = strings.Trim(strings.TrimSpace(dep), `"'`)
-			if dep != "" {
-				syn.DependsOn = append(syn.DependsOn, dep)
-			}
+	load := func(name string) (*formula.Formula, error) {
+		path, err := findFormulaFile(name)
+		if err != nil {
+			return nil, err
 		}
+		return formula.ParseFile(path)
 	}
-
-	if syn.Title == "" && syn.Description == "" {
-		return nil
-	}
-
-	return syn
-}
-
-// extractPrompts parses [prompts] section from TOML
-func extractPrompts(content string) map[string]string {
-	prompts := make(map[string]string)
-
-	idx := strings.Index(content, "[prompts]")
-	if idx == -1 {
-		return prompts
-	}
-
-	section := content[idx:]
-	// Find where section ends
-	if endIdx := strings.Index(section[1:], "\n["); endIdx != -1 {
-		section = section[:endIdx+1]
-	}
-
-	// Extract base prompt
-	if base := extractTOMLMultiline(section, "base"); base != "" {
-		prompts["base"] = base
-	}
-
-	return prompts
+	return f.Resolve(load, given)
 }
 
 // generateFormulaShortID generates a short random ID (5 lowercase chars)
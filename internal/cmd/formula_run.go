@@ -0,0 +1,411 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/formula"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// formulaRunPollInterval is how often a watched run checks its beads.
+const formulaRunPollInterval = 30 * time.Second
+
+var (
+	formulaStatusWatch bool
+	formulaStatusRetry bool
+	formulaStatusJSON  bool
+	formulaStatusFmt   string
+)
+
+var formulaStatusCmd = &cobra.Command{
+	Use:   "status [run-id]",
+	Short: "Show and advance formula runs",
+	Long: `Show the progress of formula runs started with gt formula run.
+
+Without a run ID, lists recent runs. With one, checks the run's beads,
+dispatches any steps whose needs have closed, and shows each unit.
+
+Options:
+  --watch  Keep advancing the run until it finishes
+  --retry  Dispatch failed units again
+  --json   Output as JSON
+  --format Render the units' dependency graph (dot, mermaid or json)
+
+Examples:
+  gt formula status
+  gt formula status code-review-abcde
+  gt formula status code-review-abcde --watch
+  gt formula status code-review-abcde --format mermaid`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runFormulaStatus,
+}
+
+// parseFormulaRunVars collects --var, --vars and --pr into formula variables.
+func parseFormulaRunVars() (map[string]string, error) {
+	vars, err := parseFormulaVars(formulaRunVars, formulaRunVarList)
+	if err != nil {
+		return nil, err
+	}
+	if formulaRunPR > 0 {
+		vars["pr"] = strconv.Itoa(formulaRunPR)
+	}
+	return vars, nil
+}
+
+// parseFormulaVars parses repeated --var key=value flags and
+// comma-separated --vars lists. In a --vars list a part without "=" continues
+// the previous value, so list values survive: "platforms=linux,darwin,os=x"
+// sets platforms to "linux,darwin". Values containing "=" need --var.
+func parseFormulaVars(pairs, lists []string) (map[string]string, error) {
+	for _, list := range lists {
+		var split []string
+		for _, part := range strings.Split(list, ",") {
+			if n := len(split); n > 0 && !strings.Contains(part, "=") {
+				split[n-1] += "," + part
+				continue
+			}
+			split = append(split, part)
+		}
+		pairs = append(pairs, split...)
+	}
+	vars := make(map[string]string)
+	for _, kv := range pairs {
+		k, v, ok := strings.Cut(kv, "=")
+		k = strings.TrimSpace(k)
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid variable %q (want key=value)", kv)
+		}
+		vars[k] = v
+	}
+	return vars, nil
+}
+
+// checkFormulaVars validates vars against f, printing every problem.
+// bead-id values are checked to exist.
+func checkFormulaVars(f *formula.Formula, given map[string]string) (map[string]string, error) {
+	vars, problems := f.ValidateVars(given, func(id string) (bool, error) {
+		return verifyBeadExists(id) == nil, nil
+	})
+	if len(problems) == 0 {
+		return vars, nil
+	}
+	for _, p := range problems {
+		fmt.Printf("  %s %v\n", style.Error.Render("✗"), p)
+	}
+	return nil, fmt.Errorf("%d problem(s) with variables for formula %s", len(problems), f.Name)
+}
+
+// executeFormulaRun creates the run's beads, dispatches the first wave and
+// (unless detached) watches the run to completion.
+func executeFormulaRun(f *formula.Formula, run *formula.Run) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	x := newFormulaExecutor(townRoot)
+
+	fmt.Printf("%s Executing %s formula: %s\n\n", style.Bold.Render("🚚"), f.Type, f.Name)
+
+	// Convoy bead tracking the whole run
+	run.ConvoyID = fmt.Sprintf("hq-cv-%s", generateFormulaShortID())
+	convoyTitle := fmt.Sprintf("%s: %s", f.Name, f.Description)
+	if len(convoyTitle) > 80 {
+		convoyTitle = convoyTitle[:77] + "..."
+	}
+	description := fmt.Sprintf("Formula run: %s\n\nFormula: %s\nUnits: %d\nRig: %s",
+		run.ID, f.Name, len(run.Units), run.Rig)
+	if pr := run.Vars["pr"]; pr != "" {
+		description += "\nPR: #" + pr
+	}
+	if err := x.bd("create", "--type=convoy", "--id="+run.ConvoyID, "--title="+convoyTitle, "--description="+description); err != nil {
+		return fmt.Errorf("creating convoy bead: %w", err)
+	}
+	fmt.Printf("%s Created convoy: %s\n", style.Bold.Render("✓"), run.ConvoyID)
+
+	err = run.Instantiate(x)
+	if saveErr := run.Save(townRoot); saveErr != nil {
+		return fmt.Errorf("saving run: %w", saveErr)
+	}
+	if err != nil {
+		return err
+	}
+	for _, u := range run.Units {
+		if u.Status == formula.UnitSkipped {
+			fmt.Printf("  %s Skipped %s: %s (when %s)\n", style.Dim.Render("○"), u.Kind, u.ID, u.When)
+			continue
+		}
+		fmt.Printf("  %s Created %s: %s (%s)\n", style.Dim.Render("○"), u.Kind, u.ID, u.BeadID)
+	}
+	if run.OutputDir != "" {
+		fmt.Printf("  %s Output: %s\n", style.Dim.Render("○"), run.OutputDir)
+	}
+
+	fmt.Printf("\n%s Dispatching to polecats...\n\n", style.Bold.Render("→"))
+	_, err = driveFormulaRun(townRoot, run, x, !formulaRunDetach)
+	return err
+}
+
+// driveFormulaRun advances run once, or until it finishes if watch is set.
+// Each step reloads and saves the run's state under its lock, so another
+// process driving the same run can't dispatch a unit twice. It returns the
+// run's latest state.
+func driveFormulaRun(townRoot string, run *formula.Run, x formula.Executor, watch bool) (*formula.Run, error) {
+	for {
+		var evts []formula.RunEvent
+		next, err := formula.UpdateRun(townRoot, run.ID, func(r *formula.Run) error {
+			var advanceErr error
+			evts, advanceErr = r.Advance(x, time.Now())
+			return advanceErr
+		})
+		printFormulaRunEvents(evts)
+		if next == nil {
+			return run, err
+		}
+		run = next
+		if err != nil {
+			if !watch {
+				return run, err
+			}
+			style.PrintWarning("%v", err)
+		}
+
+		if run.Finished() {
+			printFormulaRunSummary(run)
+			return run, nil
+		}
+		if !watch {
+			c := run.Counts()
+			fmt.Printf("\n%s %s: %d done, %d running, %d waiting\n", style.Bold.Render("→"), run.ID,
+				c[formula.UnitDone], c[formula.UnitDispatched], c[formula.UnitPending])
+			fmt.Printf("  Track progress: gt formula status %s --watch\n", run.ID)
+			return run, nil
+		}
+		time.Sleep(formulaRunPollInterval)
+	}
+}
+
+func printFormulaRunEvents(evts []formula.RunEvent) {
+	for _, e := range evts {
+		u := e.Unit
+		switch e.Kind {
+		case "dispatched":
+			fmt.Printf("  %s Dispatched %s %s (%s)\n", style.Bold.Render("→"), u.Kind, u.ID, u.BeadID)
+		case "done":
+			fmt.Printf("  %s %s %s done\n", style.Success.Render("✓"), u.Kind, u.ID)
+		case "failed":
+			fmt.Printf("  %s %s %s failed: %s\n", style.Error.Render("✗"), u.Kind, u.ID, u.Error)
+		case "missing-output":
+			fmt.Printf("  %s %s %s closed without writing %s\n", style.Warning.Render("⚠"), u.Kind, u.ID, u.Output)
+		}
+	}
+}
+
+func printFormulaRunSummary(run *formula.Run) {
+	c := run.Counts()
+	if run.Succeeded() {
+		fmt.Printf("\n%s Formula run %s complete (%d units)\n", style.Bold.Render("✓"), run.ID, len(run.Units))
+		if syn := run.Unit(formula.SynthesisID); syn != nil && syn.Output != "" {
+			fmt.Printf("  Result: %s\n", syn.Output)
+		} else if run.OutputDir != "" {
+			fmt.Printf("  Output: %s\n", run.OutputDir)
+		}
+		return
+	}
+	fmt.Printf("\n%s Formula run %s stopped: %d failed, %d blocked\n", style.Warning.Render("⚠"), run.ID,
+		c[formula.UnitFailed], c[formula.UnitPending])
+	fmt.Printf("  Retry failed units: gt formula status %s --retry --watch\n", run.ID)
+}
+
+func runFormulaStatus(cmd *cobra.Command, args []string) error {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	if err := checkGraphFormat(formulaStatusFmt); err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		if formulaStatusFmt != "" {
+			return fmt.Errorf("--format needs a run ID")
+		}
+		runs, err := formula.ListRuns(townRoot)
+		if err != nil {
+			return err
+		}
+		if formulaStatusJSON {
+			if runs == nil {
+				runs = []*formula.Run{}
+			}
+			return printFormulaJSON(runs)
+		}
+		if len(runs) == 0 {
+			fmt.Printf("%s No formula runs\n", style.Dim.Render("○"))
+			return nil
+		}
+		for _, r := range runs {
+			c := r.Counts()
+			state := "running"
+			switch {
+			case r.Succeeded():
+				state = "complete"
+			case r.FinishedAt != nil:
+				state = "stopped"
+			}
+			fmt.Printf("  %-28s %-10s %s  %d/%d done  %s\n", r.ID, state, r.CreatedAt.Local().Format("Jan 2 15:04"),
+				c[formula.UnitDone], len(r.Units), style.Dim.Render(r.Rig))
+		}
+		return nil
+	}
+
+	run, err := formula.LoadRun(townRoot, args[0])
+	if err != nil {
+		return err
+	}
+	if formulaStatusRetry {
+		n := 0
+		run, err = formula.UpdateRun(townRoot, run.ID, func(r *formula.Run) error {
+			n = r.RetryFailed()
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			fmt.Printf("%s Retrying %d failed unit(s)\n", style.Bold.Render("→"), n)
+		}
+	}
+
+	x := newFormulaExecutor(townRoot)
+	if formulaStatusJSON || formulaStatusFmt != "" {
+		run, err = formula.UpdateRun(townRoot, run.ID, func(r *formula.Run) error {
+			_, err := r.Advance(x, time.Now())
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if formulaStatusFmt != "" {
+			return printGraph(formulaRunGraph(run), formulaStatusFmt)
+		}
+		return printFormulaJSON(run)
+	}
+
+	fmt.Printf("%s %s (%s, rig %s, convoy %s)\n\n", style.Bold.Render("Formula run"), run.ID, run.Formula, run.Rig, run.ConvoyID)
+	run, err = driveFormulaRun(townRoot, run, x, formulaStatusWatch)
+	if err != nil {
+		return err
+	}
+	fmt.Println()
+	for _, u := range run.Units {
+		line := fmt.Sprintf("  %-11s %-10s %-20s %s", u.Status, u.Kind, u.ID, style.Dim.Render(u.BeadID))
+		if u.Status == formula.UnitFailed {
+			line += "  " + style.Error.Render(u.Error)
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+func printFormulaJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// formulaExecutor runs formula units as town beads slung to polecats.
+type formulaExecutor struct {
+	townBeads string
+}
+
+func newFormulaExecutor(townRoot string) *formulaExecutor {
+	return &formulaExecutor{townBeads: filepath.Join(townRoot, ".beads")}
+}
+
+// bd runs a bd command against the town beads.
+func (x *formulaExecutor) bd(args ...string) error {
+	bdCmd := exec.Command("bd", args...)
+	bdCmd.Dir = x.townBeads
+	bdCmd.Stderr = os.Stderr
+	return bdCmd.Run()
+}
+
+// Create creates a task bead for the unit, tracked by the run's convoy and
+// blocked by the beads of its needs.
+func (x *formulaExecutor) Create(run *formula.Run, u *formula.Unit) (string, error) {
+	prefix := "hq-leg-"
+	switch u.Kind {
+	case "step":
+		prefix = "hq-step-"
+	case "synthesis":
+		prefix = "hq-syn-"
+	}
+	id := prefix + generateFormulaShortID()
+
+	desc := u.Description + fmt.Sprintf("\n\n---\nFormula run: %s (%s %s)", run.ID, u.Kind, u.ID)
+	createArgs := []string{"create", "--type=task", "--id=" + id, "--title=" + u.Title, "--description=" + desc}
+	if beads.NeedsForceForID(id) {
+		createArgs = append(createArgs, "--force")
+	}
+	if err := x.bd(createArgs...); err != nil {
+		return "", err
+	}
+
+	if run.ConvoyID != "" {
+		if err := x.bd("dep", "add", run.ConvoyID, id, "--type=tracks"); err != nil {
+			style.PrintWarning("could not track %s in convoy %s: %v", id, run.ConvoyID, err)
+		}
+	}
+	for _, need := range u.Needs {
+		if n := run.Unit(need); n != nil && n.BeadID != "" {
+			if err := x.bd("dep", "add", id, n.BeadID); err != nil {
+				style.PrintWarning("could not add dependency %s → %s: %v", id, n.BeadID, err)
+			}
+		}
+	}
+	return id, nil
+}
+
+// Dispatch slings the unit's bead to a polecat in the run's rig.
+func (x *formulaExecutor) Dispatch(run *formula.Run, u *formula.Unit) error {
+	slingArgs := []string{"sling", u.BeadID, run.Rig, "-s", u.Title}
+	if u.Output != "" {
+		slingArgs = append(slingArgs, "-a", "Write your output to: "+u.Output)
+	}
+	slingCmd := exec.Command("gt", slingArgs...)
+	slingCmd.Stdout = os.Stdout
+	slingCmd.Stderr = os.Stderr
+	if err := slingCmd.Run(); err != nil {
+		_ = x.bd("comment", u.BeadID, fmt.Sprintf("Failed to sling: %v", err))
+		return fmt.Errorf("slinging %s: %w", u.BeadID, err)
+	}
+	return nil
+}
+
+// Closed reports whether the unit's bead has been closed.
+func (x *formulaExecutor) Closed(u *formula.Unit) (bool, error) {
+	issue, err := beads.New(x.townBeads).Show(u.BeadID)
+	if err != nil {
+		return false, err
+	}
+	return issue.Status == "closed", nil
+}
+
+func init() {
+	formulaStatusCmd.Flags().BoolVar(&formulaStatusWatch, "watch", false, "Keep advancing the run until it finishes")
+	formulaStatusCmd.Flags().BoolVar(&formulaStatusRetry, "retry", false, "Dispatch failed units again")
+	formulaStatusCmd.Flags().BoolVar(&formulaStatusJSON, "json", false, "Output as JSON")
+	formulaStatusCmd.Flags().StringVar(&formulaStatusFmt, "format", "", graphFormatUsage)
+
+	formulaCmd.AddCommand(formulaStatusCmd)
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFormulaVars(t *testing.T) {
+	got, err := parseFormulaVars(
+		[]string{"title=a=b", "notes=x,y"},
+		[]string{"platforms=linux,darwin,version=1.2.0", "dry_run=true"},
+	)
+	if err != nil {
+		t.Fatalf("parseFormulaVars: %v", err)
+	}
+	want := map[string]string{
+		"title":     "a=b",
+		"notes":     "x,y",
+		"platforms": "linux,darwin",
+		"version":   "1.2.0",
+		"dry_run":   "true",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseFormulaVars = %v, want %v", got, want)
+	}
+
+	for _, list := range []string{"linux,darwin", "=x"} {
+		if _, err := parseFormulaVars(nil, []string{list}); err == nil {
+			t.Errorf("parseFormulaVars(--vars %q) succeeded, want error", list)
+		}
+	}
+}
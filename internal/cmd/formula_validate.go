@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/formula"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var (
+	formulaValidateVars    []string
+	formulaValidateVarList []string
+)
+
+var formulaValidateCmd = &cobra.Command{
+	Use:   "validate <name>",
+	Short: "Check a formula and its variables",
+	Long: `Check a formula and the variables it would be run with, without creating
+any beads.
+
+Reports every problem at once: unknown variables, missing required ones,
+and values that don't match their declared type. Inputs and vars can be
+typed:
+
+  string   any value (the default)
+  int      a whole number ("number" is accepted too)
+  bool     true or false (yes/no and on/off work too)
+  enum     one of the declared values = [...]
+  path     a file path (cleaned)
+  bead-id  an existing bead, e.g. gt-abc12
+  list     comma-separated values, e.g. for for_each templates
+
+Formulas that extend, include or compose others are checked fully
+resolved. With valid variables, shows which steps or legs would run and
+which would be skipped by their when conditions.
+
+Examples:
+  gt formula validate code-review --var pr=123
+  gt formula validate release --vars version=1.2.0,dry_run=true
+  gt formula validate release --vars platforms=linux,darwin,version=1.2.0`,
+	Args: cobra.ExactArgs(1),
+	RunE: runFormulaValidate,
+}
+
+func runFormulaValidate(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	given, err := parseFormulaVars(formulaValidateVars, formulaValidateVarList)
+	if err != nil {
+		return err
+	}
+	if _, err := findFormulaFile(name); err != nil {
+		return fmt.Errorf("finding formula: %w", err)
+	}
+	f, err := loadFormula(name, given)
+	if err != nil {
+		fmt.Printf("  %s %v\n", style.Error.Render("✗"), err)
+		return fmt.Errorf("formula %s is invalid", name)
+	}
+	vars, err := checkFormulaVars(f, given)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s Formula %s (%s) is valid with these variables\n", style.Bold.Render("✓"), f.Name, f.Type)
+	if f.Type == formula.TypeExpansion || (f.Type == formula.TypeAspect && len(f.Aspects) == 0) {
+		// Expansions and advice-only aspects aren't run directly; nothing more to show.
+		return nil
+	}
+	run, err := formula.NewRun(f, name, "", "", vars)
+	if err != nil {
+		return err
+	}
+	for _, u := range run.Units {
+		if u.Status == formula.UnitSkipped {
+			fmt.Printf("  %s %s %s %s\n", style.Dim.Render("○"), u.Kind, u.ID, style.Dim.Render("(skipped: when "+u.When+")"))
+			continue
+		}
+		fmt.Printf("  %s %s %s\n", style.Bold.Render("→"), u.Kind, u.ID)
+	}
+	return nil
+}
+
+func init() {
+	formulaValidateCmd.Flags().StringArrayVar(&formulaValidateVars, "var", nil, "Formula variable as key=value (can be used multiple times)")
+	formulaValidateCmd.Flags().StringArrayVar(&formulaValidateVarList, "vars", nil, "Comma-separated formula variables (key=value,key=value); list values may contain commas")
+
+	formulaCmd.AddCommand(formulaValidateCmd)
+}
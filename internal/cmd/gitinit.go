@@ -84,6 +84,11 @@ const HQGitignore = `# Gas Town HQ .gitignore
 # =============================================================================
 **/.runtime/
 
+# Event store: append index, writer lock and rotated archive (local only)
+.events.idx
+.events.lock
+.events.d/
+
 # =============================================================================
 # Rig .beads symlinks (point to ignored mayor/rig/.beads, recreated on setup)
 # =============================================================================
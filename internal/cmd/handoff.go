@@ -137,7 +137,7 @@ func runHandoff(cmd *cobra.Command, args []string) error {
 	}
 
 	// Build the restart command
-	restartCmd, err := buildRestartCommand(targetSession)
+	restartCmd, err := buildRestartCommand(targetSession, "")
 	if err != nil {
 		return err
 	}
@@ -348,7 +348,8 @@ var claudeEnvVars = []string{
 // buildRestartCommand creates the command to run when respawning a session's pane.
 // This needs to be the actual command to execute (e.g., claude), not a session attach command.
 // The command includes a cd to the correct working directory for the role.
-func buildRestartCommand(sessionName string) (string, error) {
+// A non-empty agentOverride runs that agent instead of the default runtime.
+func buildRestartCommand(sessionName, agentOverride string) (string, error) {
 	// Detect town root from current directory
 	townRoot := detectTownRootFromCwd()
 	if townRoot == "" {
@@ -384,6 +385,12 @@ func buildRestartCommand(sessionName string) (string, error) {
 	// 4. run claude with the startup beacon (triggers immediate context loading)
 	// Use exec to ensure clean process replacement.
 	runtimeCmd := config.GetRuntimeCommandWithPrompt("", beacon)
+	if agentOverride != "" {
+		runtimeCmd, err = config.GetRuntimeCommandWithPromptAndAgentOverride("", beacon, agentOverride)
+		if err != nil {
+			return "", err
+		}
+	}
 
 	// Build environment exports - role vars first, then Claude vars
 	var exports []string
@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/cgroup"
+	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/townlog"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -333,6 +335,11 @@ func runLogCrash(cmd *cobra.Command, args []string) error {
 		// This is typically intentional user interrupt
 		eventType = townlog.EventKill
 		context = fmt.Sprintf("interrupted (exit %d)", crashExitCode)
+	} else if crashSession != "" && cgroup.OOMKilled(crashSession) {
+		// Killed at its cgroup memory limit - a distinct death reason, since
+		// restarting with the same limit will likely fail the same way
+		eventType = townlog.EventCrash
+		context = fmt.Sprintf("%s (exit %d, session: %s)", events.DeathReasonOOMKill, crashExitCode, crashSession)
 	} else {
 		// Non-zero exit = crash
 		eventType = townlog.EventCrash
@@ -76,6 +76,7 @@ ADDRESS FORMATS:
   <rig>/refinery      → Rig's Refinery
   <rig>/<polecat>     → Polecat (e.g., greenplace/Toast)
   <rig>/crew/<name>   → Crew worker (e.g., greenplace/crew/max)
+  <machine>:<addr>    → Another town (e.g., laptop:mayor/)
   --human             → Special: human overseer
 
 COMMANDS:
@@ -96,11 +97,16 @@ Addresses:
   <rig>/<polecat>  - Send to a specific polecat
   <rig>/           - Broadcast to a rig
   list:<name>      - Send to a mailing list (fans out to all members)
+  <machine>:<addr> - Send to another town (e.g., laptop:mayor/)
 
 Mailing lists are defined in ~/gt/config/messaging.json and allow
 sending to multiple recipients at once. Each recipient gets their
 own copy of the message.
 
+Mail to another town is forwarded through the federation mail repo
+configured in ~/gt/config/federation.json and delivered when that town
+syncs (see gt mail sync, gt mail outbox).
+
 Message types:
   task          - Required processing
   scavenge      - Optional first-come work
@@ -125,7 +131,8 @@ Examples:
   gt mail send mayor/ -s "Re: Status" -m "Done" --reply-to msg-abc123
   gt mail send --self -s "Handoff" -m "Context for next session"
   gt mail send greenplace/Toast -s "Update" -m "Progress report" --cc overseer
-  gt mail send list:oncall -s "Alert" -m "System down"`,
+  gt mail send list:oncall -s "Alert" -m "System down"
+  gt mail send laptop:mayor/ -s "ESCALATION: CI down" -m "Can you take a look?"`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runMailSend,
 }
@@ -289,13 +296,21 @@ SYNTAX:
 BEHAVIOR:
 1. If queue specified, claim from that queue
 2. If no queue specified, claim from any eligible queue
-3. Add claimed-by and claimed-at labels to the message
-4. Print claimed message details
+3. Requeue any claims in the queue whose lease has expired
+4. Refuse if the queue already has max_claims live claims
+5. Add claimed-by, claimed-at and lease-until labels to the message
+6. Print claimed message details
 
 ELIGIBILITY:
 The caller must match the queue's claim_pattern (stored in the queue bead).
 Pattern examples: "*" (anyone), "gastown/polecats/*" (specific rig crew).
 
+LEASES:
+A claim lasts for the queue's claim_ttl (default 30m). The daemon renews
+it while the claimant's session is running, and 'gt mail renew
+<message-id>' renews it by hand; if the lease lapses, the daemon
+heartbeat returns the message to the queue.
+
 Examples:
   gt mail claim work-requests   # Claim from specific queue
   gt mail claim                 # Claim from any eligible queue`,
@@ -314,7 +329,7 @@ SYNTAX:
 BEHAVIOR:
 1. Find the message by ID
 2. Verify caller is the one who claimed it (claimed-by label matches)
-3. Remove claimed-by and claimed-at labels
+3. Remove claimed-by, claimed-at and lease-until labels
 4. Message returns to queue for others to claim
 
 ERROR CASES:
@@ -329,6 +344,27 @@ Examples:
 	RunE: runMailRelease,
 }
 
+var mailRenewCmd = &cobra.Command{
+	Use:   "renew <message-id>",
+	Short: "Renew the lease on a claimed queue message",
+	Long: `Extend the lease on a queue message you have claimed.
+
+Claims expire after the queue's claim_ttl (default 30m) so that work held
+by a dead session goes back to the queue. The daemon renews claims whose
+claimant session is running; renew by hand when claiming from outside an
+agent session, or when the daemon isn't running.
+
+ERROR CASES:
+- Message is not a queue message
+- Message not claimed (the lease already expired and it was requeued)
+- Caller did not claim this message
+
+Examples:
+  gt mail renew hq-abc123`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMailRenew,
+}
+
 var mailClearCmd = &cobra.Command{
 	Use:   "clear [target]",
 	Short: "Clear all messages from an inbox",
@@ -480,6 +516,7 @@ func init() {
 	mailCmd.AddCommand(mailReplyCmd)
 	mailCmd.AddCommand(mailClaimCmd)
 	mailCmd.AddCommand(mailReleaseCmd)
+	mailCmd.AddCommand(mailRenewCmd)
 	mailCmd.AddCommand(mailClearCmd)
 	mailCmd.AddCommand(mailSearchCmd)
 	mailCmd.AddCommand(mailAnnouncesCmd)
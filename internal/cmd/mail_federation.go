@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var mailOutboxJSON bool
+
+var mailSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Exchange federated mail with other towns",
+	Long: `Deliver mail from other towns and collect delivery receipts.
+
+Towns federate by sharing a git mail repo, configured in
+~/gt/config/federation.json:
+
+  {
+    "type": "federation",
+    "version": 1,
+    "machine": "laptop",
+    "transport": {"type": "git", "remote": "git@host:gastown-mail.git"}
+  }
+
+Mail to <machine>:<address> (e.g., laptop:mayor/) is pushed to the mail
+repo; the other town delivers it on its next sync and sends back a receipt.
+The daemon syncs on every heartbeat; run this to sync right away.
+
+Examples:
+  gt mail sync`,
+	Args: cobra.NoArgs,
+	RunE: runMailSync,
+}
+
+var mailOutboxCmd = &cobra.Command{
+	Use:   "outbox",
+	Short: "Show mail sent to other towns",
+	Long: `List messages sent to other towns and whether they have been delivered.
+
+A message is delivered once the receiving town has synced and its receipt
+has come back (see gt mail sync).
+
+Examples:
+  gt mail outbox
+  gt mail outbox --json`,
+	Args: cobra.NoArgs,
+	RunE: runMailOutbox,
+}
+
+func loadFederation() (*mail.Federation, error) {
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return nil, fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	fed, err := mail.NewRouterWithTownRoot(townRoot, townRoot).Federation()
+	if errors.Is(err, mail.ErrFederationNotConfigured) {
+		return nil, fmt.Errorf("mail federation is not configured (see gt mail sync --help)")
+	}
+	return fed, err
+}
+
+func runMailSync(cmd *cobra.Command, args []string) error {
+	fed, err := loadFederation()
+	if err != nil {
+		return err
+	}
+	res, err := fed.Sync()
+	if err != nil {
+		return fmt.Errorf("syncing federated mail: %w", err)
+	}
+	fmt.Printf("%s Synced %s: %d delivered, %d receipt(s)", style.Bold.Render("✓"), fed.Machine, res.Delivered, res.Receipts)
+	if res.Duplicates > 0 {
+		fmt.Printf(", %d duplicate(s) skipped", res.Duplicates)
+	}
+	fmt.Println()
+	return nil
+}
+
+func runMailOutbox(cmd *cobra.Command, args []string) error {
+	fed, err := loadFederation()
+	if err != nil {
+		return err
+	}
+	entries, err := fed.Outbox()
+	if err != nil {
+		return err
+	}
+
+	if mailOutboxJSON {
+		if entries == nil {
+			entries = []*mail.OutboxEntry{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	}
+
+	if len(entries) == 0 {
+		fmt.Printf("%s No mail sent to other towns\n", style.Dim.Render("○"))
+		return nil
+	}
+	for _, e := range entries {
+		status := style.Warning.Render("pending")
+		if e.DeliveredAt != nil {
+			status = style.Success.Render("delivered " + e.DeliveredAt.Local().Format("Jan 2 15:04"))
+		}
+		fmt.Printf("  %s  %-24s %s  %s\n", style.Dim.Render(e.SentAt.Local().Format("Jan 2 15:04")), e.To, e.Subject, status)
+	}
+	return nil
+}
+
+func init() {
+	mailOutboxCmd.Flags().BoolVar(&mailOutboxJSON, "json", false, "Output as JSON")
+
+	mailCmd.AddCommand(mailSyncCmd)
+	mailCmd.AddCommand(mailOutboxCmd)
+}
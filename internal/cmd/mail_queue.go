@@ -12,6 +12,8 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/mail"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
@@ -83,148 +85,94 @@ func runMailClaim(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// List unclaimed messages in the queue
-	// Queue messages have queue:<name> label and no claimed-by label
-	messages, err := listUnclaimedQueueMessages(beadsDir, queueName)
+	maxClaims, ttl := queueClaimPolicy(townRoot, queueName, queueFields)
+
+	// Hold the queue lock from counting claims until ours is recorded, so
+	// concurrent claimers can't exceed max_claims or take the same message
+	lock, err := mail.LockQueue(beadsDir, queueName)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	// List the queue's open messages, claimed or not
+	messages, err := mail.ListQueueMessages(beadsDir, queueName)
 	if err != nil {
 		return fmt.Errorf("listing queue messages: %w", err)
 	}
 
-	if len(messages) == 0 {
+	// Requeue lapsed claims now rather than waiting for the daemon
+	now := time.Now()
+	if _, err := mail.RequeueExpired(beadsDir, messages, now, ttl); err != nil {
+		return fmt.Errorf("requeueing expired claims: %w", err)
+	}
+
+	stats := mail.CountQueue(queueName, messages, maxClaims, now, ttl)
+	if stats.Full() {
+		return fmt.Errorf("%w: %s has %d/%d messages claimed", mail.ErrQueueFull, queueName, stats.Claimed, stats.MaxClaims)
+	}
+
+	// Pick the oldest unclaimed message (messages are sorted by created)
+	var oldest *mail.BeadsMessage
+	for _, bm := range messages {
+		if bm.GetClaimedBy() == "" {
+			oldest = bm
+			break
+		}
+	}
+	if oldest == nil {
 		fmt.Printf("%s No messages to claim in queue %s\n", style.Dim.Render("○"), queueName)
 		return nil
 	}
 
-	// Pick the oldest unclaimed message (first in list, sorted by created)
-	oldest := messages[0]
-
-	// Claim the message: add claimed-by and claimed-at labels
-	if err := claimQueueMessage(beadsDir, oldest.ID, caller); err != nil {
+	// Claim the message: add claimed-by, claimed-at and lease-until labels
+	if err := claimQueueMessage(beadsDir, oldest.ID, caller, now, ttl); err != nil {
 		return fmt.Errorf("claiming message: %w", err)
 	}
 
 	// Print claimed message details
+	msg := oldest.ToMessage()
 	fmt.Printf("%s Claimed message from queue %s\n", style.Bold.Render("✓"), queueName)
-	fmt.Printf("  ID: %s\n", oldest.ID)
-	fmt.Printf("  Subject: %s\n", oldest.Title)
-	if oldest.Description != "" {
+	fmt.Printf("  ID: %s\n", msg.ID)
+	fmt.Printf("  Subject: %s\n", msg.Subject)
+	if msg.Body != "" {
 		// Show first line of description
-		lines := strings.SplitN(oldest.Description, "\n", 2)
+		lines := strings.SplitN(msg.Body, "\n", 2)
 		preview := lines[0]
 		if len(preview) > 80 {
 			preview = preview[:77] + "..."
 		}
 		fmt.Printf("  Preview: %s\n", style.Dim.Render(preview))
 	}
-	fmt.Printf("  From: %s\n", oldest.From)
-	fmt.Printf("  Created: %s\n", oldest.Created.Format("2006-01-02 15:04"))
+	fmt.Printf("  From: %s\n", msg.From)
+	fmt.Printf("  Created: %s\n", msg.Timestamp.Format("2006-01-02 15:04"))
+	fmt.Printf("  Lease: %s (renew with: gt mail renew %s)\n",
+		style.Dim.Render("until "+now.Add(ttl).Format("15:04:05")), msg.ID)
 
 	return nil
 }
 
-// queueMessage represents a message in a queue.
-type queueMessage struct {
-	ID          string
-	Title       string
-	Description string
-	From        string
-	Created     time.Time
-	Priority    int
-	ClaimedBy   string
-	ClaimedAt   *time.Time
-}
-
-// listUnclaimedQueueMessages lists unclaimed messages in a queue.
-// Unclaimed messages have queue:<name> label but no claimed-by label.
-func listUnclaimedQueueMessages(beadsDir, queueName string) ([]queueMessage, error) {
-	// Use bd list to find messages with queue:<name> label and status=open
-	args := []string{"list",
-		"--label", "queue:" + queueName,
-		"--status", "open",
-		"--type", "message",
-		"--json",
-	}
-
-	cmd := exec.Command("bd", args...)
-	cmd.Env = append(os.Environ(), "BEADS_DIR="+beadsDir)
-
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
-		errMsg := strings.TrimSpace(stderr.String())
-		if errMsg != "" {
-			return nil, fmt.Errorf("%s", errMsg)
+// queueClaimPolicy returns a queue's claim limit and lease duration.
+// messaging.json's max_claims and claim_ttl take precedence; queues not
+// configured there fall back to the queue bead's max_concurrency.
+func queueClaimPolicy(townRoot, queueName string, fields *beads.QueueFields) (int, time.Duration) {
+	cfg, err := config.LoadMessagingConfig(config.MessagingConfigPath(townRoot))
+	if err == nil {
+		if qc, ok := cfg.Queues[queueName]; ok {
+			return qc.MaxClaims, qc.GetClaimTTL()
 		}
-		return nil, err
-	}
-
-	// Parse JSON output
-	var issues []struct {
-		ID          string    `json:"id"`
-		Title       string    `json:"title"`
-		Description string    `json:"description"`
-		Labels      []string  `json:"labels"`
-		CreatedAt   time.Time `json:"created_at"`
-		Priority    int       `json:"priority"`
 	}
-
-	if err := json.Unmarshal(stdout.Bytes(), &issues); err != nil {
-		// If no messages, bd might output empty or error
-		if strings.TrimSpace(stdout.String()) == "" || strings.TrimSpace(stdout.String()) == "[]" {
-			return nil, nil
-		}
-		return nil, fmt.Errorf("parsing bd output: %w", err)
-	}
-
-	// Convert to queueMessage, filtering out already claimed messages
-	var messages []queueMessage
-	for _, issue := range issues {
-		msg := queueMessage{
-			ID:          issue.ID,
-			Title:       issue.Title,
-			Description: issue.Description,
-			Created:     issue.CreatedAt,
-			Priority:    issue.Priority,
-		}
-
-		// Extract labels
-		for _, label := range issue.Labels {
-			if strings.HasPrefix(label, "from:") {
-				msg.From = strings.TrimPrefix(label, "from:")
-			} else if strings.HasPrefix(label, "claimed-by:") {
-				msg.ClaimedBy = strings.TrimPrefix(label, "claimed-by:")
-			} else if strings.HasPrefix(label, "claimed-at:") {
-				ts := strings.TrimPrefix(label, "claimed-at:")
-				if t, err := time.Parse(time.RFC3339, ts); err == nil {
-					msg.ClaimedAt = &t
-				}
-			}
-		}
-
-		// Only include unclaimed messages
-		if msg.ClaimedBy == "" {
-			messages = append(messages, msg)
-		}
+	maxClaims := 0
+	if fields != nil {
+		maxClaims = fields.MaxConcurrency
 	}
-
-	// Sort by created time (oldest first) for FIFO ordering
-	sort.Slice(messages, func(i, j int) bool {
-		return messages[i].Created.Before(messages[j].Created)
-	})
-
-	return messages, nil
+	return maxClaims, config.DefaultClaimTTL
 }
 
-// claimQueueMessage claims a message by adding claimed-by and claimed-at labels.
-func claimQueueMessage(beadsDir, messageID, claimant string) error {
-	now := time.Now().UTC().Format(time.RFC3339)
-
-	args := []string{"label", "add", messageID,
-		"claimed-by:" + claimant,
-		"claimed-at:" + now,
-	}
+// claimQueueMessage claims a message by adding claimed-by, claimed-at and
+// lease-until labels.
+func claimQueueMessage(beadsDir, messageID, claimant string, now time.Time, ttl time.Duration) error {
+	args := append([]string{"label", "add", messageID}, mail.ClaimLabels(claimant, now, ttl)...)
 
 	cmd := exec.Command("bd", args...)
 	cmd.Env = append(os.Environ(),
@@ -294,12 +242,14 @@ func runMailRelease(cmd *cobra.Command, args []string) error {
 
 // queueMessageInfo holds details about a queue message.
 type queueMessageInfo struct {
-	ID        string
-	Title     string
-	QueueName string
-	ClaimedBy string
-	ClaimedAt *time.Time
-	Status    string
+	ID          string
+	Title       string
+	QueueName   string
+	ClaimedBy   string
+	ClaimedAt   *time.Time
+	LeaseUntil  *time.Time
+	ClaimLabels []string // raw claimed-by/claimed-at/lease-until labels
+	Status      string
 }
 
 // getQueueMessageInfo retrieves information about a queue message.
@@ -349,6 +299,9 @@ func getQueueMessageInfo(beadsDir, messageID string) (*queueMessageInfo, error)
 
 	// Extract fields from labels
 	for _, label := range issue.Labels {
+		if mail.IsClaimLabel(label) {
+			info.ClaimLabels = append(info.ClaimLabels, label)
+		}
 		if strings.HasPrefix(label, "queue:") {
 			info.QueueName = strings.TrimPrefix(label, "queue:")
 		} else if strings.HasPrefix(label, "claimed-by:") {
@@ -358,6 +311,11 @@ func getQueueMessageInfo(beadsDir, messageID string) (*queueMessageInfo, error)
 			if t, err := time.Parse(time.RFC3339, ts); err == nil {
 				info.ClaimedAt = &t
 			}
+		} else if strings.HasPrefix(label, mail.LeaseUntilLabel) {
+			ts := strings.TrimPrefix(label, mail.LeaseUntilLabel)
+			if t, err := time.Parse(time.RFC3339, ts); err == nil {
+				info.LeaseUntil = &t
+			}
 		}
 	}
 
@@ -371,48 +329,54 @@ func releaseQueueMessage(beadsDir, messageID, actor string) error {
 	if err != nil {
 		return err
 	}
+	return mail.RemoveClaimLabels(beadsDir, messageID, actor, info.ClaimLabels)
+}
 
-	// Remove claimed-by label
-	if info.ClaimedBy != "" {
-		args := []string{"label", "remove", messageID, "claimed-by:" + info.ClaimedBy}
-		cmd := exec.Command("bd", args...)
-		cmd.Env = append(os.Environ(),
-			"BEADS_DIR="+beadsDir,
-			"BD_ACTOR="+actor,
-		)
-
-		var stderr bytes.Buffer
-		cmd.Stderr = &stderr
+// runMailRenew extends the lease on a claimed queue message.
+func runMailRenew(cmd *cobra.Command, args []string) error {
+	messageID := args[0]
 
-		if err := cmd.Run(); err != nil {
-			errMsg := strings.TrimSpace(stderr.String())
-			if errMsg != "" && !strings.Contains(errMsg, "does not have label") {
-				return fmt.Errorf("%s", errMsg)
-			}
-		}
+	// Find workspace
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
 	}
 
-	// Remove claimed-at label if present
-	if info.ClaimedAt != nil {
-		claimedAtStr := info.ClaimedAt.Format(time.RFC3339)
-		args := []string{"label", "remove", messageID, "claimed-at:" + claimedAtStr}
-		cmd := exec.Command("bd", args...)
-		cmd.Env = append(os.Environ(),
-			"BEADS_DIR="+beadsDir,
-			"BD_ACTOR="+actor,
-		)
+	beadsDir := beads.ResolveBeadsDir(townRoot)
+	caller := detectSender()
+
+	msgInfo, err := getQueueMessageInfo(beadsDir, messageID)
+	if err != nil {
+		return fmt.Errorf("getting message: %w", err)
+	}
+	if msgInfo.QueueName == "" {
+		return fmt.Errorf("message %s is not a queue message (no queue label)", messageID)
+	}
 
-		var stderr bytes.Buffer
-		cmd.Stderr = &stderr
+	// Re-read the claim under the queue lock so a concurrent requeue can't
+	// strip it between the check and the renewal
+	lock, err := mail.LockQueue(beadsDir, msgInfo.QueueName)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = lock.Unlock() }()
+	if msgInfo, err = getQueueMessageInfo(beadsDir, messageID); err != nil {
+		return fmt.Errorf("getting message: %w", err)
+	}
+	if msgInfo.ClaimedBy == "" {
+		return fmt.Errorf("message %s is not claimed (its lease may have expired; claim it again)", messageID)
+	}
+	if msgInfo.ClaimedBy != caller {
+		return fmt.Errorf("message %s was claimed by %s, not %s", messageID, msgInfo.ClaimedBy, caller)
+	}
 
-		if err := cmd.Run(); err != nil {
-			errMsg := strings.TrimSpace(stderr.String())
-			if errMsg != "" && !strings.Contains(errMsg, "does not have label") {
-				return fmt.Errorf("%s", errMsg)
-			}
-		}
+	_, ttl := queueClaimPolicy(townRoot, msgInfo.QueueName, nil)
+	until, err := mail.RenewLease(beadsDir, messageID, caller, msgInfo.ClaimLabels, time.Now(), ttl)
+	if err != nil {
+		return fmt.Errorf("renewing lease: %w", err)
 	}
 
+	fmt.Printf("%s Renewed claim on %s until %s\n", style.Bold.Render("✓"), messageID, until.Local().Format("15:04:05"))
 	return nil
 }
 
@@ -435,12 +399,14 @@ COMMANDS:
   create    Create a new queue
   show      Show queue details
   list      List all queues
+  stats     Show claimed/unclaimed/expired counts
   delete    Delete a queue
 
 Examples:
   gt mail queue create work --claimers 'gastown/polecats/*'
   gt mail queue show work
   gt mail queue list
+  gt mail queue stats
   gt mail queue delete work`,
 	RunE: requireSubcommand,
 }
@@ -488,6 +454,27 @@ Examples:
 	RunE: runMailQueueList,
 }
 
+var mailQueueStatsCmd = &cobra.Command{
+	Use:   "stats [name]",
+	Short: "Show claim counts per queue",
+	Long: `Show how many open messages in each queue are unclaimed, claimed, or
+held by an expired claim.
+
+Claims are leases, renewed by the daemon while the claimant's session runs
+(or with 'gt mail renew'). A claim whose lease lapses counts as expired
+until the daemon heartbeat (or the next 'gt mail claim' on the queue)
+requeues it.
+
+Queues come from config/messaging.json and queue beads. The MAX column is
+the queue's max_claims (or the bead's max_concurrency); 0 means unlimited.
+
+Examples:
+  gt mail queue stats
+  gt mail queue stats work --json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runMailQueueStats,
+}
+
 var mailQueueDeleteCmd = &cobra.Command{
 	Use:   "delete <name>",
 	Short: "Delete a queue",
@@ -509,11 +496,13 @@ func init() {
 	// Queue show/list flags
 	mailQueueShowCmd.Flags().BoolVar(&mailQueueJSON, "json", false, "Output as JSON")
 	mailQueueListCmd.Flags().BoolVar(&mailQueueJSON, "json", false, "Output as JSON")
+	mailQueueStatsCmd.Flags().BoolVar(&mailQueueJSON, "json", false, "Output as JSON")
 
 	// Add queue subcommands
 	mailQueueCmd.AddCommand(mailQueueCreateCmd)
 	mailQueueCmd.AddCommand(mailQueueShowCmd)
 	mailQueueCmd.AddCommand(mailQueueListCmd)
+	mailQueueCmd.AddCommand(mailQueueStatsCmd)
 	mailQueueCmd.AddCommand(mailQueueDeleteCmd)
 
 	// Add queue command to mail
@@ -686,6 +675,79 @@ func runMailQueueList(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// runMailQueueStats shows claim counts for each queue.
+func runMailQueueStats(cmd *cobra.Command, args []string) error {
+	// Find workspace
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+
+	beadsDir := beads.ResolveBeadsDir(townRoot)
+
+	// Collect queue names from messaging config and queue beads
+	fieldsByName := make(map[string]*beads.QueueFields)
+	if cfg, err := config.LoadMessagingConfig(config.MessagingConfigPath(townRoot)); err == nil {
+		for name := range cfg.Queues {
+			fieldsByName[name] = nil
+		}
+	}
+	if queues, err := beads.NewWithBeadsDir(townRoot, beadsDir).ListQueueBeads(); err == nil {
+		for _, issue := range queues {
+			fields := beads.ParseQueueFields(issue.Description)
+			if fields.Name != "" {
+				fieldsByName[fields.Name] = fields
+			}
+		}
+	}
+
+	var names []string
+	if len(args) > 0 {
+		names = []string{args[0]}
+	} else {
+		for name := range fieldsByName {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	now := time.Now()
+	stats := make([]mail.QueueStats, 0, len(names))
+	for _, name := range names {
+		maxClaims, ttl := queueClaimPolicy(townRoot, name, fieldsByName[name])
+		messages, err := mail.ListQueueMessages(beadsDir, name)
+		if err != nil {
+			return fmt.Errorf("listing queue %s: %w", name, err)
+		}
+		stats = append(stats, mail.CountQueue(name, messages, maxClaims, now, ttl))
+	}
+
+	if mailQueueJSON {
+		jsonBytes, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshaling JSON: %w", err)
+		}
+		fmt.Println(string(jsonBytes))
+		return nil
+	}
+
+	if len(stats) == 0 {
+		fmt.Printf("%s No queues found\n", style.Dim.Render("○"))
+		return nil
+	}
+
+	fmt.Printf("%-24s %10s %8s %8s %5s\n", "QUEUE", "UNCLAIMED", "CLAIMED", "EXPIRED", "MAX")
+	for _, st := range stats {
+		expired := fmt.Sprintf("%8d", st.Expired)
+		if st.Expired > 0 {
+			expired = style.Warning.Render(expired)
+		}
+		fmt.Printf("%-24s %10d %8d %s %5d\n", st.Queue, st.Unclaimed, st.Claimed, expired, st.MaxClaims)
+	}
+
+	return nil
+}
+
 // runMailQueueDelete deletes a queue.
 func runMailQueueDelete(cmd *cobra.Command, args []string) error {
 	queueName := args[0]
@@ -6,7 +6,8 @@ import (
 
 // Molecule command flags
 var (
-	moleculeJSON bool
+	moleculeJSON           bool
+	moleculeProgressFormat string
 )
 
 var moleculeCmd = &cobra.Command{
@@ -52,8 +53,12 @@ Given a root issue (the parent of molecule steps), displays:
 
 This is useful for the Witness to monitor molecule execution.
 
-Example:
-  gt molecule progress gt-abc`,
+With --format, renders the steps' dependency graph (dot, mermaid or
+json) with the critical path highlighted.
+
+Examples:
+  gt molecule progress gt-abc
+  gt mol progress gt-abc --format mermaid`,
 	Args: cobra.ExactArgs(1),
 	RunE: runMoleculeProgress,
 }
@@ -227,6 +232,7 @@ close steps with 'bd close' - that skips the auto-continuation logic.`,
 func init() {
 	// Progress flags
 	moleculeProgressCmd.Flags().BoolVar(&moleculeJSON, "json", false, "Output as JSON")
+	moleculeProgressCmd.Flags().StringVar(&moleculeProgressFormat, "format", "", graphFormatUsage)
 
 	// Attachment flags
 	moleculeAttachmentCmd.Flags().BoolVar(&moleculeJSON, "json", false, "Output as JSON")
@@ -11,6 +11,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/dag"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
@@ -142,6 +143,9 @@ type MoleculeCurrentInfo struct {
 
 func runMoleculeProgress(cmd *cobra.Command, args []string) error {
 	rootID := args[0]
+	if err := checkGraphFormat(moleculeProgressFormat); err != nil {
+		return err
+	}
 
 	workDir, err := findLocalBeadsDir()
 	if err != nil {
@@ -170,6 +174,14 @@ func runMoleculeProgress(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("no steps found for %s (not a molecule root?)", rootID)
 	}
 
+	if moleculeProgressFormat != "" {
+		g := dag.New(rootID + ": " + root.Title)
+		for _, child := range children {
+			g.Add(child.ID, child.Title, child.Status, child.Assignee, beadNeeds(child, rootID)...)
+		}
+		return printGraph(g, moleculeProgressFormat)
+	}
+
 	// Build progress info
 	progress := MoleculeProgressInfo{
 		RootID:    rootID,
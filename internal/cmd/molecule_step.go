@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -27,7 +29,8 @@ This command handles the step-to-step transition for polecats:
 3. Finds the next ready step (dependency-aware)
 4. If next step exists:
    - Updates the hook to point to the next step
-   - Respawns the pane for a fresh session
+   - Respawns the pane for a fresh session, using the agent mapped by
+     tier_agents if the step has a tier hint (e.g. "tier: haiku")
 5. If molecule complete:
    - Clears the hook
    - Sends POLECAT_DONE to witness
@@ -278,9 +281,21 @@ func handleStepContinue(cwd, townRoot, _ string, nextStep *beads.Issue, dryRun b
 		return fmt.Errorf("finding git root: %w", err)
 	}
 
+	// Map the step's tier hint to an agent so cheap steps run on cheap models
+	tier := beads.StepTier(nextStep.Description)
+	rigPath := ""
+	if roleInfo.Rig != "" {
+		rigPath = filepath.Join(townRoot, roleInfo.Rig)
+	}
+	tierAgent := config.ResolveTierAgentName(tier, townRoot, rigPath)
+
 	if dryRun {
 		fmt.Printf("\n[dry-run] Would pin next step: %s\n", nextStep.ID)
-		fmt.Printf("[dry-run] Would respawn pane\n")
+		if tierAgent != "" {
+			fmt.Printf("[dry-run] Would respawn pane with agent %s (tier %s)\n", tierAgent, tier)
+		} else {
+			fmt.Printf("[dry-run] Would respawn pane\n")
+		}
 		return nil
 	}
 
@@ -313,12 +328,16 @@ func handleStepContinue(cwd, townRoot, _ string, nextStep *beads.Issue, dryRun b
 		return fmt.Errorf("getting session name: %w", err)
 	}
 
-	restartCmd, err := buildRestartCommand(currentSession)
+	restartCmd, err := buildRestartCommand(currentSession, tierAgent)
 	if err != nil {
 		return fmt.Errorf("building restart command: %w", err)
 	}
 
-	fmt.Printf("\n%s Respawning for next step...\n", style.Bold.Render("🔄"))
+	if tierAgent != "" {
+		fmt.Printf("\n%s Respawning for next step on %s (tier %s)...\n", style.Bold.Render("🔄"), tierAgent, tier)
+	} else {
+		fmt.Printf("\n%s Respawning for next step...\n", style.Bold.Render("🔄"))
+	}
 
 	t := tmux.NewTmux()
 
@@ -175,40 +175,10 @@ func runNudge(cmd *cobra.Command, args []string) error {
 
 	// Check if target is rig/polecat format or raw session name
 	if strings.Contains(target, "/") {
-		// Parse rig/polecat format
-		rigName, polecatName, err := parseAddress(target)
-		if err != nil {
+		if err := nudgeAddress(t, target, sender, message); err != nil {
 			return err
 		}
-
-		var sessionName string
-
-		// Check if this is a crew address (polecatName starts with "crew/")
-		if strings.HasPrefix(polecatName, "crew/") {
-			// Extract crew name and use crew session naming
-			crewName := strings.TrimPrefix(polecatName, "crew/")
-			sessionName = crewSessionName(rigName, crewName)
-		} else {
-			// Regular polecat - use session manager
-			mgr, _, err := getSessionManager(rigName)
-			if err != nil {
-				return err
-			}
-			sessionName = mgr.SessionName(polecatName)
-		}
-
-		// Send nudge using the reliable NudgeSession
-		if err := t.NudgeSession(sessionName, message); err != nil {
-			return fmt.Errorf("nudging session: %w", err)
-		}
-
-		fmt.Printf("%s Nudged %s/%s\n", style.Bold.Render("✓"), rigName, polecatName)
-
-		// Log nudge event
-		if townRoot, err := workspace.FindFromCwd(); err == nil && townRoot != "" {
-			_ = LogNudge(townRoot, target, message)
-		}
-		_ = events.LogFeed(events.TypeNudge, sender, events.NudgePayload(rigName, target, message))
+		fmt.Printf("%s Nudged %s\n", style.Bold.Render("✓"), target)
 	} else {
 		// Raw session name (legacy)
 		exists, err := t.HasSession(target)
@@ -235,6 +205,43 @@ func runNudge(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// nudgeAddress nudges a rig/polecat or rig/crew/name address and logs the
+// nudge. message should already carry the sender prefix.
+func nudgeAddress(t *tmux.Tmux, target, sender, message string) error {
+	rigName, polecatName, err := parseAddress(target)
+	if err != nil {
+		return err
+	}
+
+	var sessionName string
+
+	// Check if this is a crew address (polecatName starts with "crew/")
+	if strings.HasPrefix(polecatName, "crew/") {
+		// Extract crew name and use crew session naming
+		crewName := strings.TrimPrefix(polecatName, "crew/")
+		sessionName = crewSessionName(rigName, crewName)
+	} else {
+		// Regular polecat - use session manager
+		mgr, _, err := getSessionManager(rigName)
+		if err != nil {
+			return err
+		}
+		sessionName = mgr.SessionName(polecatName)
+	}
+
+	// Send nudge using the reliable NudgeSession
+	if err := t.NudgeSession(sessionName, message); err != nil {
+		return fmt.Errorf("nudging session: %w", err)
+	}
+
+	// Log nudge event
+	if townRoot, err := workspace.FindFromCwd(); err == nil && townRoot != "" {
+		_ = LogNudge(townRoot, target, message)
+	}
+	_ = events.LogFeed(events.TypeNudge, sender, events.NudgePayload(rigName, target, message))
+	return nil
+}
+
 // runNudgeChannel nudges all members of a named channel.
 func runNudgeChannel(channelName, message string) error {
 	// Find town root
@@ -56,14 +56,26 @@ func runPeek(cmd *cobra.Command, args []string) error {
 		lines = n
 	}
 
-	rigName, polecatName, err := parseAddress(address)
+	output, err := peekAddress(address, lines)
 	if err != nil {
 		return err
 	}
 
+	fmt.Print(output)
+	return nil
+}
+
+// peekAddress captures the last lines of a rig/polecat or rig/crew/name
+// session.
+func peekAddress(address string, lines int) (string, error) {
+	rigName, polecatName, err := parseAddress(address)
+	if err != nil {
+		return "", err
+	}
+
 	mgr, _, err := getSessionManager(rigName)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	var output string
@@ -79,9 +91,7 @@ func runPeek(cmd *cobra.Command, args []string) error {
 	}
 
 	if err != nil {
-		return fmt.Errorf("capturing output: %w", err)
+		return "", fmt.Errorf("capturing output: %w", err)
 	}
-
-	fmt.Print(output)
-	return nil
+	return output, nil
 }
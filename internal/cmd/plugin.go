@@ -6,6 +6,7 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/config"
@@ -23,6 +24,9 @@ var (
 	pluginRunDryRun   bool
 	pluginHistoryJSON bool
 	pluginHistoryLimit int
+	pluginDueJSON     bool
+	pluginDueAll      bool
+	pluginDueRig      string
 )
 
 var pluginCmd = &cobra.Command{
@@ -47,6 +51,7 @@ GATE TYPES:
 Examples:
   gt plugin list                    # List all discovered plugins
   gt plugin show <name>             # Show plugin details
+  gt plugin due                     # Show which plugins are due to run
   gt plugin list --json             # JSON output`,
 	RunE: requireSubcommand,
 }
@@ -113,6 +118,39 @@ Examples:
 	RunE: runPluginHistory,
 }
 
+var pluginDueCmd = &cobra.Command{
+	Use:   "due",
+	Short: "Show which plugins are due to run",
+	Long: `Evaluate time-based gates (cooldown and cron) against each plugin's
+last recorded run and report which plugins are due.
+
+Town-level and rig-level plugins are evaluated separately: a rig plugin's
+last run is the last run recorded for that rig.
+
+Cron gates use standard five-field expressions ("0 9 * * 1-5") or macros
+(@hourly, @daily, @weekly, @monthly). They are evaluated in the gate's
+timezone, or the local timezone if none is set:
+
+  [gate]
+  type = "cron"
+  schedule = "0 9 * * 1-5"
+  timezone = "America/New_York"
+  catch_up = "once"   # or "skip"
+  grace = "30m"       # with skip: how late a window may still run
+
+If the Deacon was paused across one or more windows, catch_up = "once"
+(the default) makes the plugin due once to cover all of them. With
+catch_up = "skip", missed windows older than the grace period (default 1h)
+are dropped and the plugin waits for its next window.
+
+Examples:
+  gt plugin due                 # Plugins due now
+  gt plugin due --all           # Include plugins that are not due
+  gt plugin due --rig gastown   # Only gastown's rig-level plugins
+  gt plugin due --json`,
+	RunE: runPluginDue,
+}
+
 func init() {
 	// List subcommand flags
 	pluginListCmd.Flags().BoolVar(&pluginListJSON, "json", false, "Output as JSON")
@@ -128,11 +166,17 @@ func init() {
 	pluginHistoryCmd.Flags().BoolVar(&pluginHistoryJSON, "json", false, "Output as JSON")
 	pluginHistoryCmd.Flags().IntVar(&pluginHistoryLimit, "limit", 10, "Maximum number of runs to show")
 
+	// Due subcommand flags
+	pluginDueCmd.Flags().BoolVar(&pluginDueJSON, "json", false, "Output as JSON")
+	pluginDueCmd.Flags().BoolVar(&pluginDueAll, "all", false, "Include plugins that are not due")
+	pluginDueCmd.Flags().StringVar(&pluginDueRig, "rig", "", "Only evaluate plugins of this rig")
+
 	// Add subcommands
 	pluginCmd.AddCommand(pluginListCmd)
 	pluginCmd.AddCommand(pluginShowCmd)
 	pluginCmd.AddCommand(pluginRunCmd)
 	pluginCmd.AddCommand(pluginHistoryCmd)
+	pluginCmd.AddCommand(pluginDueCmd)
 
 	rootCmd.AddCommand(pluginCmd)
 }
@@ -402,6 +446,21 @@ func runPluginRun(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Check gate status for cron gates
+	if p.Gate != nil && p.Gate.Type == plugin.GateCron && !pluginRunForce {
+		status, err := checkPluginDue(plugin.NewRecorder(townRoot), p, time.Now())
+		if err != nil {
+			// Log warning but continue
+			fmt.Fprintf(os.Stderr, "Warning: checking gate status: %v\n", err)
+		} else if !status.Due {
+			gateOpen = false
+			gateReason = status.Reason
+			if !status.NextRun.IsZero() {
+				gateReason += fmt.Sprintf("; next window %s", status.NextRun.Format("2006-01-02 15:04 MST"))
+			}
+		}
+	}
+
 	if pluginRunDryRun {
 		fmt.Printf("%s Dry run for plugin: %s\n", style.Bold.Render("Plugin:"), p.Name)
 		fmt.Printf("%s %s\n", style.Bold.Render("Location:"), p.Path)
@@ -505,3 +564,135 @@ func runPluginHistory(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// PluginDue is a plugin's due status for `gt plugin due --json`.
+type PluginDue struct {
+	plugin.PluginSummary
+	Schedule string `json:"schedule,omitempty"`
+	plugin.DueStatus
+	Error string `json:"error,omitempty"`
+}
+
+// checkPluginDue looks up a plugin's last run in its scope and evaluates its gate.
+func checkPluginDue(recorder *plugin.Recorder, p *plugin.Plugin, now time.Time) (plugin.DueStatus, error) {
+	var lastRun time.Time
+	var run *plugin.PluginRunBead
+	var err error
+	if p.RigName == "" {
+		run, err = recorder.GetLastTownRun(p.Name)
+	} else {
+		run, err = recorder.GetLastRunForRig(p.Name, p.RigName)
+	}
+	if err != nil {
+		return plugin.DueStatus{}, fmt.Errorf("querying last run: %w", err)
+	}
+	if run != nil {
+		lastRun = run.CreatedAt
+	}
+	return p.CheckDue(lastRun, now)
+}
+
+func runPluginDue(cmd *cobra.Command, args []string) error {
+	scanner, townRoot, err := getPluginScanner()
+	if err != nil {
+		return err
+	}
+
+	plugins, err := scanner.DiscoverScoped()
+	if err != nil {
+		return fmt.Errorf("discovering plugins: %w", err)
+	}
+
+	recorder := plugin.NewRecorder(townRoot)
+	now := time.Now()
+	var results []PluginDue
+	for _, p := range plugins {
+		if pluginDueRig != "" && p.RigName != pluginDueRig {
+			continue
+		}
+		entry := PluginDue{PluginSummary: p.Summary()}
+		if p.Gate != nil {
+			entry.Schedule = p.Gate.Schedule
+		}
+		if entry.GateType != plugin.GateCooldown && entry.GateType != plugin.GateCron {
+			if !pluginDueAll {
+				continue
+			}
+		}
+
+		status, err := checkPluginDue(recorder, p, now)
+		if err != nil {
+			entry.Error = err.Error()
+		}
+		entry.DueStatus = status
+		if !entry.Due && entry.Error == "" && !pluginDueAll {
+			continue
+		}
+		results = append(results, entry)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].RigName != results[j].RigName {
+			return results[i].RigName < results[j].RigName
+		}
+		return results[i].Name < results[j].Name
+	})
+
+	if pluginDueJSON {
+		if results == nil {
+			results = []PluginDue{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	}
+
+	due := 0
+	for _, r := range results {
+		if r.Due {
+			due++
+		}
+	}
+	if len(results) == 0 {
+		fmt.Printf("%s No plugins due\n", style.Dim.Render("○"))
+		return nil
+	}
+	fmt.Printf("%s %d plugin(s) due\n\n", style.Success.Render("●"), due)
+
+	for _, r := range results {
+		scope := "town"
+		if r.RigName != "" {
+			scope = r.RigName
+		}
+		gate := string(r.GateType)
+		if r.Schedule != "" {
+			gate = fmt.Sprintf("%s %q", gate, r.Schedule)
+		}
+
+		icon := style.Dim.Render("○")
+		switch {
+		case r.Error != "":
+			icon = style.Error.Render("✗")
+		case r.Due:
+			icon = style.Success.Render("●")
+		}
+
+		fmt.Printf("  %s %s %s %s\n", icon, style.Bold.Render(r.Name), style.Dim.Render("["+scope+"]"), style.Dim.Render(gate))
+		if r.Error != "" {
+			fmt.Printf("      %s\n", style.Error.Render(r.Error))
+			continue
+		}
+		detail := r.Reason
+		if r.LastRun.IsZero() {
+			detail += "; never run"
+		} else {
+			detail += "; last run " + r.LastRun.Local().Format("2006-01-02 15:04")
+		}
+		if !r.NextRun.IsZero() {
+			detail += "; next " + r.NextRun.Format("2006-01-02 15:04 MST")
+		}
+		fmt.Printf("      %s\n", style.Dim.Render(detail))
+	}
+
+	return nil
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/cgroup"
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/polecat"
 	"github.com/steveyegge/gastown/internal/rig"
@@ -631,6 +632,7 @@ type PolecatStatus struct {
 	Windows        int           `json:"windows,omitempty"`
 	CreatedAt      string        `json:"created_at,omitempty"`
 	LastActivity   string        `json:"last_activity,omitempty"`
+	Resources      *cgroup.Usage `json:"resources,omitempty"`
 }
 
 func runPolecatStatus(cmd *cobra.Command, args []string) error {
@@ -662,6 +664,10 @@ func runPolecatStatus(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Resource usage, when the session runs in a cgroup scope. A scope
+	// the OOM killer stopped outlives the session, so check it either way.
+	usage, _ := cgroup.GetUsage(cgroup.ScopeUnit(polecatMgr.SessionName(polecatName)))
+
 	// JSON output
 	if polecatStatusJSON {
 		status := PolecatStatus{
@@ -675,6 +681,7 @@ func runPolecatStatus(cmd *cobra.Command, args []string) error {
 			SessionID:      sessInfo.SessionID,
 			Attached:       sessInfo.Attached,
 			Windows:        sessInfo.Windows,
+			Resources:      usage,
 		}
 		if !sessInfo.Created.IsZero() {
 			status.CreatedAt = sessInfo.Created.Format("2006-01-02 15:04:05")
@@ -747,6 +754,13 @@ func runPolecatStatus(cmd *cobra.Command, args []string) error {
 	} else {
 		fmt.Printf("  Status:        %s\n", style.Dim.Render("not running"))
 	}
+	if usage != nil {
+		if usage.Result == cgroup.ResultOOMKill {
+			fmt.Printf("  Resources:     %s\n", style.Error.Render(usage.String()))
+		} else {
+			fmt.Printf("  Resources:     %s\n", usage)
+		}
+	}
 
 	return nil
 }
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+// routeTouchCommits is how many of the rig's newest commits are read to
+// find each identity's past touches.
+const routeTouchCommits = 2000
+
+// polecatRouteMode returns how to name a polecat spawned by sling: the
+// --route flag, else the rig/town polecat_routing setting. --explain
+// implies skill routing.
+func polecatRouteMode(townRoot, rigPath string, opts SlingSpawnOptions) string {
+	if opts.Route != "" {
+		return opts.Route
+	}
+	if opts.Explain {
+		return config.PolecatRoutingSkill
+	}
+	return config.ResolvePolecatRouting(townRoot, rigPath)
+}
+
+// validateSlingRoute checks the --route and --explain flags.
+func validateSlingRoute(route string, explain bool) error {
+	switch route {
+	case "", config.PolecatRoutingSkill:
+		return nil
+	case config.PolecatRoutingPool:
+		if explain {
+			return fmt.Errorf("--explain shows skill routing; it can't be combined with --route %s", route)
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid --route %q: must be %s or %s", route, config.PolecatRoutingPool, config.PolecatRoutingSkill)
+	}
+}
+
+// scorePolecatIdentities scores the rig's free polecat identities against
+// the bead. An identity is any polecat name with issues assigned to it in
+// the rig; it is free when it has neither a worktree nor a session.
+func scorePolecatIdentities(r *rig.Rig, mgr *polecat.Manager, t *tmux.Tmux, bead *beads.Issue) ([]polecat.RouteScore, error) {
+	issues, err := beads.New(r.Path).List(beads.ListOptions{Status: "all", Priority: -1})
+	if err != nil {
+		return nil, fmt.Errorf("listing rig issues: %w", err)
+	}
+	prefix := r.Name + "/polecats/"
+	byName := make(map[string][]*beads.Issue)
+	for _, issue := range issues {
+		if name, ok := strings.CutPrefix(issue.Assignee, prefix); ok && name != "" {
+			byName[name] = append(byName[name], issue)
+		}
+	}
+
+	touches, err := mgr.TouchHistory(routeTouchCommits)
+	if err != nil {
+		style.PrintWarning("could not read past touches: %v", err)
+	}
+
+	sessMgr := polecat.NewSessionManager(t, r)
+	var records []*polecat.IdentityRecord
+	for name, assigned := range byName {
+		if _, err := mgr.Get(name); err == nil {
+			continue // has a worktree
+		}
+		if running, _ := sessMgr.IsRunning(name); running {
+			continue
+		}
+		records = append(records, polecat.NewIdentityRecord(name, assigned, touches[name]))
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Name < records[j].Name })
+
+	return polecat.ScoreIdentities(bead, records), nil
+}
+
+// routeBead fetches the bead being slung as skill routing sees it.
+func routeBead(beadID string) (*beads.Issue, error) {
+	info, err := getBeadInfo(beadID)
+	if err != nil {
+		return nil, err
+	}
+	return &beads.Issue{ID: beadID, Title: info.Title, Description: info.Description, Labels: info.Labels}, nil
+}
+
+// routePolecatBySkill picks and claims the free identity that best fits the
+// bead. Returns "" when no identity has relevant history, or routing fails,
+// so the caller falls back to the name pool.
+func routePolecatBySkill(r *rig.Rig, mgr *polecat.Manager, t *tmux.Tmux, beadID string, explain bool) string {
+	bead, err := routeBead(beadID)
+	if err != nil {
+		style.PrintWarning("skill routing skipped: %v", err)
+		return ""
+	}
+	scores, err := scorePolecatIdentities(r, mgr, t, bead)
+	if err != nil {
+		style.PrintWarning("skill routing skipped: %v", err)
+		return ""
+	}
+	if explain {
+		printRouteScores(bead, scores)
+	}
+
+	best, ok := polecat.BestIdentity(scores)
+	if !ok {
+		fmt.Printf("No free identity has history with %s, using the name pool\n", beadID)
+		return ""
+	}
+	if err := mgr.ClaimName(best.Name); err != nil {
+		style.PrintWarning("could not claim %s: %v", best.Name, err)
+		return ""
+	}
+	fmt.Printf("Routed to returning polecat: %s (score %.2f)\n", best.Name, best.Score)
+	return best.Name
+}
+
+// previewSkillRoute shows which identity skill routing would bring back for
+// a dry run, without claiming it.
+func previewSkillRoute(rigName, beadID string) {
+	townRoot, r, err := getRig(rigName)
+	if err != nil {
+		return
+	}
+	opts := SlingSpawnOptions{Route: slingRoute, Explain: slingExplain}
+	if polecatRouteMode(townRoot, r.Path, opts) != config.PolecatRoutingSkill {
+		return
+	}
+
+	bead, err := routeBead(beadID)
+	if err != nil {
+		style.PrintWarning("skill routing skipped: %v", err)
+		return
+	}
+	t := tmux.NewTmux()
+	scores, err := scorePolecatIdentities(r, polecat.NewManager(r, git.NewGit(r.Path), t), t, bead)
+	if err != nil {
+		style.PrintWarning("skill routing skipped: %v", err)
+		return
+	}
+	if slingExplain {
+		printRouteScores(bead, scores)
+	}
+	if best, ok := polecat.BestIdentity(scores); ok {
+		fmt.Printf("Would route to returning polecat: %s (score %.2f)\n", best.Name, best.Score)
+	} else {
+		fmt.Printf("No free identity has history with %s, would use the name pool\n", beadID)
+	}
+}
+
+// printRouteScores prints the skill routing table for --explain.
+func printRouteScores(bead *beads.Issue, scores []polecat.RouteScore) {
+	fmt.Printf("%s %s\n", style.Bold.Render("Skill routing for"), bead.ID)
+	if len(bead.Labels) > 0 {
+		fmt.Printf("  labels: %s\n", strings.Join(bead.Labels, ", "))
+	}
+	if paths := polecat.MentionedPaths(bead.Title + "\n" + bead.Description); len(paths) > 0 {
+		fmt.Printf("  paths:  %s\n", strings.Join(paths, ", "))
+	}
+	if len(scores) == 0 {
+		fmt.Printf("  %s\n\n", style.Dim.Render("(no free identities with history)"))
+		return
+	}
+
+	fmt.Println()
+	for _, s := range scores {
+		score := fmt.Sprintf("%5.2f", s.Score)
+		if !s.Relevant() {
+			score = "    -"
+		}
+		fmt.Printf("  %-12s %s  labels %.2f  files %.2f  touches %.2f  success %.2f\n",
+			s.Name, score, s.Labels, s.Files, s.Touches, s.Success)
+		for _, reason := range s.Reasons {
+			fmt.Printf("  %-12s        %s\n", "", style.Dim.Render(reason))
+		}
+	}
+	fmt.Println()
+}
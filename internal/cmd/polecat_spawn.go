@@ -6,8 +6,8 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/steveyegge/gastown/internal/account"
 	"github.com/steveyegge/gastown/internal/config"
-	"github.com/steveyegge/gastown/internal/constants"
 	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/polecat"
@@ -38,6 +38,8 @@ type SlingSpawnOptions struct {
 	Create   bool   // Create polecat if it doesn't exist (currently always true for sling)
 	HookBead string // Bead ID to set as hook_bead at spawn time (atomic assignment)
 	Agent    string // Agent override for this spawn (e.g., "gemini", "codex", "claude-haiku")
+	Route    string // Naming override: "pool" or "skill" (empty: polecat_routing setting)
+	Explain  bool   // Print skill routing scores (implies skill routing)
 }
 
 // SpawnPolecatForSling creates a fresh polecat and optionally starts its session.
@@ -69,12 +71,19 @@ func SpawnPolecatForSling(rigName string, opts SlingSpawnOptions) (*SpawnedPolec
 	t := tmux.NewTmux()
 	polecatMgr := polecat.NewManager(r, polecatGit, t)
 
-	// Allocate a new polecat name
-	polecatName, err := polecatMgr.AllocateName()
-	if err != nil {
-		return nil, fmt.Errorf("allocating polecat name: %w", err)
+	// Skill routing brings back the free identity that best fits the bead;
+	// otherwise allocate a new polecat name
+	polecatName := ""
+	if opts.HookBead != "" && polecatRouteMode(townRoot, r.Path, opts) == config.PolecatRoutingSkill {
+		polecatName = routePolecatBySkill(r, polecatMgr, t, opts.HookBead, opts.Explain)
+	}
+	if polecatName == "" {
+		polecatName, err = polecatMgr.AllocateName()
+		if err != nil {
+			return nil, fmt.Errorf("allocating polecat name: %w", err)
+		}
+		fmt.Printf("Allocated polecat: %s\n", polecatName)
 	}
-	fmt.Printf("Allocated polecat: %s\n", polecatName)
 
 	// Check if polecat already exists (shouldn't happen - indicates stale state needing repair)
 	existingPolecat, err := polecatMgr.Get(polecatName)
@@ -115,9 +124,8 @@ func SpawnPolecatForSling(rigName string, opts SlingSpawnOptions) (*SpawnedPolec
 		return nil, fmt.Errorf("getting polecat after creation: %w", err)
 	}
 
-	// Resolve account for runtime config
-	accountsPath := constants.MayorAccountsPath(townRoot)
-	claudeConfigDir, accountHandle, err := config.ResolveAccountConfigDir(accountsPath, opts.Account)
+	// Resolve account for runtime config (pool mode spreads spawns across accounts)
+	claudeConfigDir, accountHandle, err := account.ResolveSpawnAccount(townRoot, t, opts.Account)
 	if err != nil {
 		return nil, fmt.Errorf("resolving account: %w", err)
 	}
@@ -18,8 +18,16 @@ import (
 
 // outputPrimeContext outputs the role-specific context using templates or fallback.
 func outputPrimeContext(ctx RoleContext) error {
-	// Try to use templates first
-	tmpl, err := templates.New()
+	// Try to use templates first, with the rig's and town's overrides
+	var rigPath string
+	if ctx.Rig != "" && ctx.TownRoot != "" {
+		rigPath = filepath.Join(ctx.TownRoot, ctx.Rig)
+	}
+	tmpl, err := templates.NewWithOverrides(ctx.TownRoot, rigPath)
+	if err != nil {
+		style.PrintWarning("ignoring template overrides: %v", err)
+		tmpl, err = templates.New()
+	}
 	if err != nil {
 		// Fall back to hardcoded output if templates fail
 		return outputPrimeContextFallback(ctx)
@@ -51,8 +59,7 @@ func outputPrimeContext(ctx RoleContext) error {
 
 	// Get default branch from rig config (default to "main" if not set)
 	defaultBranch := "main"
-	if ctx.Rig != "" && ctx.TownRoot != "" {
-		rigPath := filepath.Join(ctx.TownRoot, ctx.Rig)
+	if rigPath != "" {
 		if rigCfg, err := rig.LoadRigConfig(rigPath); err == nil && rigCfg.DefaultBranch != "" {
 			defaultBranch = rigCfg.DefaultBranch
 		}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -217,6 +218,31 @@ Examples:
 
 var refineryBlockedJSON bool
 
+var refineryTrainCmd = &cobra.Command{
+	Use:   "train [rig]",
+	Short: "Process the next batch of ready MRs as a merge train",
+	Long: `Process up to merge_queue.max_concurrent ready MRs as a merge train.
+
+The highest-scored ready MRs (sharing one target branch) are claimed and
+speculatively merged onto stacked temporary worktrees. Tests run in every
+worktree in parallel, and the longest passing prefix lands in score order.
+When a car fails, it is ejected and the MRs behind it are rebuilt on the
+new target and retested.
+
+With max_concurrent = 1 this processes a single MR, like the serial queue.
+
+Merged MRs are closed and the Witness gets MERGED for each; failed MRs get
+MERGE_FAILED (and a conflict-resolution task for conflicts). The refinery
+patrol runs this instead of its one-at-a-time steps when max_concurrent > 1
+(see 'gt refinery status').
+
+Examples:
+  gt refinery train
+  gt refinery train greenplace`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRefineryTrain,
+}
+
 func init() {
 	// Start flags
 	refineryStartCmd.Flags().BoolVar(&refineryForeground, "foreground", false, "Run in foreground (default: background)")
@@ -255,6 +281,7 @@ func init() {
 	refineryCmd.AddCommand(refineryUnclaimedCmd)
 	refineryCmd.AddCommand(refineryReadyCmd)
 	refineryCmd.AddCommand(refineryBlockedCmd)
+	refineryCmd.AddCommand(refineryTrainCmd)
 
 	rootCmd.AddCommand(refineryCmd)
 }
@@ -396,6 +423,7 @@ func runRefineryStatus(cmd *cobra.Command, args []string) error {
 		}
 	}
 	fmt.Printf("\n  Queue: %d pending\n", pendingCount)
+	fmt.Printf("  Merge train: %s\n", refineryTrainMode(rigName))
 
 	if ref.LastMergeAt != nil {
 		fmt.Printf("  Last merge: %s\n", ref.LastMergeAt.Format("2006-01-02 15:04:05"))
@@ -766,3 +794,85 @@ func runRefineryBlocked(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// refineryTrainMode describes whether the rig's queue is processed as merge
+// trains (merge_queue.max_concurrent > 1) or one MR at a time.
+func refineryTrainMode(rigName string) string {
+	_, r, err := getRig(rigName)
+	if err != nil {
+		return "unknown"
+	}
+	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		return fmt.Sprintf("unknown (%v)", err)
+	}
+	if n := eng.Config().MaxConcurrent; n > 1 {
+		return fmt.Sprintf("on (max_concurrent %d)", n)
+	}
+	return "off (max_concurrent 1)"
+}
+
+func runRefineryTrain(cmd *cobra.Command, args []string) error {
+	rigName := ""
+	if len(args) > 0 {
+		rigName = args[0]
+	}
+
+	_, r, rigName, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+
+	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		return fmt.Errorf("loading merge queue config: %w", err)
+	}
+
+	ready, err := eng.ListReadyMRs()
+	if err != nil {
+		return fmt.Errorf("listing ready MRs: %w", err)
+	}
+
+	batch := eng.SelectTrain(ready)
+	if len(batch) == 0 {
+		fmt.Printf("%s No ready MRs for '%s'\n", style.Dim.Render("○"), rigName)
+		return nil
+	}
+
+	// Claim every MR in the train so parallel workers skip them.
+	workerID := getWorkerID()
+	var claimed []*refinery.MRInfo
+	for _, mr := range batch {
+		if err := eng.ClaimMR(mr.ID, workerID); err != nil {
+			fmt.Printf("%s Could not claim %s: %v\n", style.Dim.Render("⚠"), mr.ID, err)
+			continue
+		}
+		claimed = append(claimed, mr)
+	}
+
+	results := eng.ProcessTrain(context.Background(), claimed)
+
+	var merged, failed, deferred int
+	for _, tr := range results {
+		switch {
+		case tr.Deferred:
+			deferred++
+		case tr.Result.Success:
+			merged++
+			eng.HandleMRInfoSuccess(tr.MR, tr.Result)
+		default:
+			failed++
+			eng.HandleMRInfoFailure(tr.MR, tr.Result)
+		}
+		if !tr.Result.Success {
+			// Failed and deferred MRs go back to the queue for the next pass.
+			if err := eng.ReleaseMR(tr.MR.ID); err != nil {
+				fmt.Printf("%s Could not release %s: %v\n", style.Dim.Render("⚠"), tr.MR.ID, err)
+			}
+		}
+	}
+
+	fmt.Printf("%s Train complete for '%s': %d merged, %d failed, %d deferred\n",
+		style.Bold.Render("✓"), rigName, merged, failed, deferred)
+	return nil
+}
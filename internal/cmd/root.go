@@ -30,6 +30,7 @@ var beadsExemptCommands = map[string]bool{
 	"version":    true,
 	"help":       true,
 	"completion": true,
+	"host":       true, // gt session host: headless session process
 }
 
 // Commands exempt from the town root branch warning.
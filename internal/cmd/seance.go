@@ -1,12 +1,10 @@
 package cmd
 
 import (
-	"bufio"
 	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"sort"
 	"strings"
 	"time"
@@ -234,33 +232,19 @@ func runSeanceTalk(sessionID, prompt string) error {
 
 // discoverSessions reads session_start events from our event stream.
 func discoverSessions(townRoot string) ([]sessionEvent, error) {
-	eventsPath := filepath.Join(townRoot, events.EventsFile)
-
-	file, err := os.Open(eventsPath)
+	found, err := events.Query(townRoot, events.Filter{Types: []string{events.TypeSessionStart}})
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil
-		}
 		return nil, err
 	}
-	defer file.Close()
-
-	var sessions []sessionEvent
-	scanner := bufio.NewScanner(file)
-
-	// Increase buffer for large lines
-	buf := make([]byte, 0, 64*1024)
-	scanner.Buffer(buf, 1024*1024)
 
-	for scanner.Scan() {
-		var event sessionEvent
-		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
-			continue
-		}
-
-		if event.Type == events.TypeSessionStart {
-			sessions = append(sessions, event)
-		}
+	sessions := make([]sessionEvent, 0, len(found))
+	for _, e := range found {
+		sessions = append(sessions, sessionEvent{
+			Timestamp: e.Timestamp,
+			Type:      e.Type,
+			Actor:     e.Actor,
+			Payload:   e.Payload,
+		})
 	}
 
 	// Sort by timestamp descending (most recent first)
@@ -268,7 +252,7 @@ func discoverSessions(townRoot string) ([]sessionEvent, error) {
 		return sessions[i].Timestamp > sessions[j].Timestamp
 	})
 
-	return sessions, scanner.Err()
+	return sessions, nil
 }
 
 func getPayloadString(payload map[string]interface{}, key string) string {
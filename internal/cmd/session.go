@@ -14,6 +14,7 @@ import (
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/polecat"
 	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/session"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/suggest"
 	"github.com/steveyegge/gastown/internal/tmux"
@@ -43,6 +44,10 @@ var sessionCmd = &cobra.Command{
 Sessions are tmux sessions running Claude for each polecat.
 Use the subcommands to start, stop, attach, and monitor sessions.
 
+Set GT_SESSION_BACKEND=headless to run sessions on a PTY without tmux
+(containers, CI). Headless sessions keep their scrollback for 'capture';
+'gt session at' attaches the terminal, and Ctrl-] detaches.
+
 TIP: To send messages to a running session, use 'gt nudge' (not 'session inject').
 The nudge command uses reliable delivery that works correctly with Claude Code.`,
 }
@@ -197,6 +202,7 @@ func init() {
 	sessionCmd.AddCommand(sessionRestartCmd)
 	sessionCmd.AddCommand(sessionStatusCmd)
 	sessionCmd.AddCommand(sessionCheckCmd)
+	sessionCmd.AddCommand(sessionHostCmd)
 
 	rootCmd.AddCommand(sessionCmd)
 }
@@ -230,8 +236,7 @@ func getSessionManager(rigName string) (*polecat.SessionManager, *rig.Rig, error
 		return nil, nil, err
 	}
 
-	t := tmux.NewTmux()
-	polecatMgr := polecat.NewSessionManager(t, r)
+	polecatMgr := polecat.NewSessionManager(session.NewBackend(), r)
 
 	return polecatMgr, r, nil
 }
@@ -377,11 +382,11 @@ func runSessionList(cmd *cobra.Command, args []string) error {
 	}
 
 	// Collect sessions from all rigs
-	t := tmux.NewTmux()
+	backend := session.NewBackend()
 	var allSessions []SessionListItem
 
 	for _, r := range rigs {
-		polecatMgr := polecat.NewSessionManager(t, r)
+		polecatMgr := polecat.NewSessionManager(backend, r)
 		infos, err := polecatMgr.List()
 		if err != nil {
 			continue
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/headless"
+)
+
+var (
+	sessionHostDir     string
+	sessionHostName    string
+	sessionHostWorkDir string
+	sessionHostCommand string
+)
+
+var sessionHostCmd = &cobra.Command{
+	Use:    "host",
+	Short:  "Host a headless session (internal)",
+	Hidden: true,
+	Long: `Run a headless session in the foreground.
+
+Started by the headless session backend (GT_SESSION_BACKEND=headless). Runs
+the command on a PTY, keeps its scrollback, and serves it on a unix socket
+until the command exits.`,
+	RunE: runSessionHost,
+}
+
+func init() {
+	sessionHostCmd.Flags().StringVar(&sessionHostDir, "dir", "", "Socket directory")
+	sessionHostCmd.Flags().StringVar(&sessionHostName, "name", "", "Session name")
+	sessionHostCmd.Flags().StringVar(&sessionHostWorkDir, "workdir", "", "Working directory")
+	sessionHostCmd.Flags().StringVar(&sessionHostCommand, "command", "", "Command to run")
+	_ = sessionHostCmd.MarkFlagRequired("name")
+	_ = sessionHostCmd.MarkFlagRequired("command")
+}
+
+func runSessionHost(cmd *cobra.Command, args []string) error {
+	dir := sessionHostDir
+	if dir == "" {
+		dir = headless.DefaultDir()
+	}
+	return headless.Host(headless.ServerOptions{
+		Dir:     dir,
+		Name:    sessionHostName,
+		WorkDir: sessionHostWorkDir,
+		Command: sessionHostCommand,
+	})
+}
@@ -49,6 +49,26 @@ Spawning Options (when target is a rig):
   gt sling gp-abc greenplace --create               # Create polecat if missing
   gt sling gp-abc greenplace --force                # Ignore unread mail
   gt sling gp-abc greenplace --account work         # Use specific Claude account
+  gt sling gp-abc greenplace --agent claude-haiku   # Use specific agent runtime
+
+  Without --agent, a bead carrying a molecule step tier hint ("tier: haiku")
+  spawns with the agent mapped by tier_agents in rig or town settings.
+
+Skill Routing (when target is a rig):
+  gt sling gp-abc greenplace --route skill   # Reuse the best-fit identity
+  gt sling gp-abc greenplace --explain       # ...and show the scoring
+
+  By default a spawned polecat takes the next free name from the name pool.
+  With skill routing (--route skill, or polecat_routing: "skill" in rig or
+  town settings), sling scores the rig's free polecat identities - names
+  with past assigned issues and no worktree or session - against the bead:
+    labels   the bead's labels on issues the identity completed      (x2)
+    files    the bead's paths mentioned in issues it completed       (x1)
+    touches  its commits touching the bead's paths, or their dir     (x3)
+    success  its completed share of finished issues                  (x1)
+  The best fit is brought back under its old name, so a polecat that has
+  fixed a subsystem three times gets the fourth bug. If no identity has
+  related history, the name pool picks as usual.
 
 Natural Language Args:
   gt sling gt-abc --args "patch release"
@@ -95,6 +115,8 @@ var (
 	slingAccount  string // --account: Claude Code account handle to use
 	slingAgent    string // --agent: override runtime agent for this sling/spawn
 	slingNoConvoy bool   // --no-convoy: skip auto-convoy creation
+	slingRoute    string // --route: how to name a spawned polecat (pool, skill)
+	slingExplain  bool   // --explain: show skill routing scores
 )
 
 func init() {
@@ -111,6 +133,8 @@ func init() {
 	slingCmd.Flags().StringVar(&slingAccount, "account", "", "Claude Code account handle to use")
 	slingCmd.Flags().StringVar(&slingAgent, "agent", "", "Override agent/runtime for this sling (e.g., claude, gemini, codex, or custom alias)")
 	slingCmd.Flags().BoolVar(&slingNoConvoy, "no-convoy", false, "Skip auto-convoy creation for single-issue sling")
+	slingCmd.Flags().StringVar(&slingRoute, "route", "", "How to name a spawned polecat: pool or skill (default: polecat_routing setting)")
+	slingCmd.Flags().BoolVar(&slingExplain, "explain", false, "Show how skill routing scored the rig's polecat identities (implies --route skill)")
 
 	rootCmd.AddCommand(slingCmd)
 }
@@ -133,6 +157,9 @@ func runSling(cmd *cobra.Command, args []string) error {
 	if slingOnTarget != "" && len(slingVars) > 0 {
 		return fmt.Errorf("--var cannot be used with --on (formula-on-bead mode doesn't support variables)")
 	}
+	if err := validateSlingRoute(slingRoute, slingExplain); err != nil {
+		return err
+	}
 
 	// Batch mode detection: multiple beads with rig target
 	// Pattern: gt sling gt-abc gt-def gt-ghi gastown
@@ -226,6 +253,7 @@ func runSling(cmd *cobra.Command, args []string) error {
 			if slingDryRun {
 				// Dry run - just indicate what would happen
 				fmt.Printf("Would spawn fresh polecat in rig '%s'\n", rigName)
+				previewSkillRoute(rigName, beadID)
 				targetAgent = fmt.Sprintf("%s/polecats/<new>", rigName)
 				targetPane = "<new-pane>"
 			} else {
@@ -236,7 +264,9 @@ func runSling(cmd *cobra.Command, args []string) error {
 					Account:  slingAccount,
 					Create:   slingCreate,
 					HookBead: beadID, // Set atomically at spawn time
-					Agent:    slingAgent,
+					Agent:    slingSpawnAgent(beadID, townRoot, rigName),
+					Route:    slingRoute,
+					Explain:  slingExplain,
 				}
 				spawnInfo, spawnErr := SpawnPolecatForSling(rigName, spawnOpts)
 				if spawnErr != nil {
@@ -267,7 +297,9 @@ func runSling(cmd *cobra.Command, args []string) error {
 							Account:  slingAccount,
 							Create:   slingCreate,
 							HookBead: beadID,
-							Agent:    slingAgent,
+							Agent:    slingSpawnAgent(beadID, townRoot, rigName),
+							Route:    slingRoute,
+							Explain:  slingExplain,
 						}
 						spawnInfo, spawnErr := SpawnPolecatForSling(rigName, spawnOpts)
 						if spawnErr != nil {
@@ -64,7 +64,9 @@ func runBatchSling(beadIDs []string, rigName string, townBeadsDir string) error
 			Account:  slingAccount,
 			Create:   slingCreate,
 			HookBead: beadID, // Set atomically at spawn time
-			Agent:    slingAgent,
+			Agent:    slingSpawnAgent(beadID, filepath.Dir(townBeadsDir), rigName),
+			Route:    slingRoute,
+			Explain:  slingExplain,
 		}
 		spawnInfo, err := SpawnPolecatForSling(rigName, spawnOpts)
 		if err != nil {
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -18,9 +19,11 @@ import (
 
 // beadInfo holds status and assignee for a bead.
 type beadInfo struct {
-	Title    string `json:"title"`
-	Status   string `json:"status"`
-	Assignee string `json:"assignee"`
+	Title       string   `json:"title"`
+	Status      string   `json:"status"`
+	Assignee    string   `json:"assignee"`
+	Description string   `json:"description"`
+	Labels      []string `json:"labels"`
 }
 
 // verifyBeadExists checks that the bead exists using bd show.
@@ -78,6 +81,26 @@ func getBeadInfo(beadID string) (*beadInfo, error) {
 	return &infos[0], nil
 }
 
+// slingSpawnAgent returns the agent for a polecat spawned to work on a bead:
+// --agent if given, else the tier_agents mapping for the bead's tier hint
+// (instantiated molecule steps carry "tier: haiku|sonnet|opus"), else ""
+// for the rig's usual polecat agent.
+func slingSpawnAgent(beadID, townRoot, rigName string) string {
+	if slingAgent != "" {
+		return slingAgent
+	}
+	info, err := getBeadInfo(beadID)
+	if err != nil {
+		return ""
+	}
+	tier := beads.StepTier(info.Description)
+	agent := config.ResolveTierAgentName(tier, townRoot, filepath.Join(townRoot, rigName))
+	if agent != "" {
+		fmt.Printf("%s Tier %s → agent %s\n", style.Dim.Render("○"), tier, agent)
+	}
+	return agent
+}
+
 // storeArgsInBead stores args in the bead's description using attached_args field.
 // This enables no-tmux mode where agents discover args via gt prime / bd show.
 func storeArgsInBead(beadID, args string) error {
@@ -13,6 +13,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/cgroup"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
 	"github.com/steveyegge/gastown/internal/crew"
@@ -86,6 +87,8 @@ type AgentRuntime struct {
 	State        string `json:"state,omitempty"`         // Agent state from agent bead
 	UnreadMail   int    `json:"unread_mail"`             // Number of unread messages
 	FirstSubject string `json:"first_subject,omitempty"` // Subject of first unread message
+
+	Resources *cgroup.Usage `json:"resources,omitempty"` // cgroup usage when the session has resource limits
 }
 
 // RigStatus represents status of a single rig.
@@ -680,6 +683,11 @@ func renderAgentDetails(agent AgentRuntime, indent string, hooks []AgentHookInfo
 		}
 		fmt.Printf("%s  mail: %s\n", indent, mailStr)
 	}
+
+	// Line 4: Resource usage (if the session has limits)
+	if res := formatResources(agent.Resources); res != "" {
+		fmt.Printf("%s  res:  %s\n", indent, res)
+	}
 }
 
 // formatMQSummary formats the MQ status for verbose display
@@ -809,8 +817,26 @@ func renderAgentCompact(agent AgentRuntime, indent string, hooks []AgentHookInfo
 		mailSuffix = fmt.Sprintf(" 📬%d", agent.UnreadMail)
 	}
 
-	// Print single line: name + status + hook + mail
-	fmt.Printf("%s%-12s %s%s%s\n", indent, agent.Name, statusIndicator, hookSuffix, mailSuffix)
+	// Resource usage, for sessions with limits
+	resSuffix := ""
+	if res := formatResources(agent.Resources); res != "" {
+		resSuffix = " " + res
+	}
+
+	// Print single line: name + status + hook + mail + resources
+	fmt.Printf("%s%-12s %s%s%s%s\n", indent, agent.Name, statusIndicator, hookSuffix, mailSuffix, resSuffix)
+}
+
+// formatResources formats a session's cgroup usage, highlighting an
+// OOM-killed scope. Returns "" when the session has no scope.
+func formatResources(usage *cgroup.Usage) string {
+	if usage == nil {
+		return ""
+	}
+	if usage.Result == cgroup.ResultOOMKill {
+		return style.Error.Render(usage.String())
+	}
+	return style.Dim.Render(usage.String())
 }
 
 // buildStatusIndicator creates the visual status indicator for an agent.
@@ -1122,6 +1148,11 @@ func discoverRigAgents(allSessions map[string]bool, r *rig.Rig, crews []string,
 				populateMailInfo(&agent, mailRouter)
 			}
 
+			// Workers may run in a cgroup scope with resource limits
+			if d.role == "polecat" || d.role == "crew" {
+				agent.Resources, _ = cgroup.GetUsage(cgroup.ScopeUnit(d.session))
+			}
+
 			agents[idx] = agent
 		}(i, def)
 	}
@@ -11,7 +11,9 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/dag"
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/polecat"
 	"github.com/steveyegge/gastown/internal/rig"
@@ -29,6 +31,7 @@ var (
 	swarmWorkers    []string
 	swarmStart      bool
 	swarmStatusJSON bool
+	swarmStatusFmt  string
 	swarmListRig    string
 	swarmListStatus string
 	swarmListJSON   bool
@@ -79,7 +82,14 @@ var swarmStatusCmd = &cobra.Command{
 	Long: `Show detailed status for a swarm.
 
 Displays swarm metadata, task progress, worker assignments, and integration
-branch status.`,
+branch status.
+
+With --format, renders the tasks' dependency graph (dot, mermaid or
+json) with assignees, blockers and the critical path.
+
+Examples:
+  gt swarm status gt-epic-abc
+  gt swarm status gt-epic-abc --format dot | dot -Tpng > swarm.png`,
 	Args: cobra.ExactArgs(1),
 	RunE: runSwarmStatus,
 }
@@ -157,6 +167,7 @@ func init() {
 
 	// Status flags
 	swarmStatusCmd.Flags().BoolVar(&swarmStatusJSON, "json", false, "Output as JSON")
+	swarmStatusCmd.Flags().StringVar(&swarmStatusFmt, "format", "", graphFormatUsage)
 
 	// List flags
 	swarmListCmd.Flags().StringVar(&swarmListStatus, "status", "", "Filter by status (active, landed, canceled, failed)")
@@ -548,6 +559,9 @@ func spawnSwarmWorkersFromBeads(r *rig.Rig, townRoot string, swarmID string, wor
 
 func runSwarmStatus(cmd *cobra.Command, args []string) error {
 	swarmID := args[0]
+	if err := checkGraphFormat(swarmStatusFmt); err != nil {
+		return err
+	}
 
 	// Find the swarm's rig by trying to show it in each rig
 	rigs, _, err := getAllRigs()
@@ -574,6 +588,10 @@ func runSwarmStatus(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("swarm '%s' not found in any rig", swarmID)
 	}
 
+	if swarmStatusFmt != "" {
+		return printSwarmGraph(foundRig, swarmID, swarmStatusFmt)
+	}
+
 	// Use bd swarm status to get swarm info from beads
 	bdArgs := []string{"swarm", "status", swarmID}
 	if swarmStatusJSON {
@@ -588,6 +606,34 @@ func runSwarmStatus(cmd *cobra.Command, args []string) error {
 	return bdCmd.Run()
 }
 
+// printSwarmGraph renders the dependency graph of a swarm's tasks.
+func printSwarmGraph(r *rig.Rig, swarmID, format string) error {
+	sw, err := swarm.NewManager(r).LoadSwarm(swarmID)
+	if err != nil {
+		return fmt.Errorf("loading swarm: %w", err)
+	}
+	ids := make([]string, 0, len(sw.Tasks))
+	for _, task := range sw.Tasks {
+		ids = append(ids, task.IssueID)
+	}
+	issues, _ := beads.New(r.BeadsPath()).ShowMultiple(ids)
+
+	g := dag.New(swarmID + ": " + r.Name)
+	for _, task := range sw.Tasks {
+		var needs []string
+		if issue := issues[task.IssueID]; issue != nil {
+			needs = beadNeeds(issue, swarmID)
+		}
+		g.Add(task.IssueID, task.Title, string(task.State), task.Assignee, needs...)
+	}
+	for _, task := range sw.Tasks {
+		if issue := issues[task.IssueID]; issue != nil {
+			addBeadBlockers(g, issue, swarmID)
+		}
+	}
+	return printGraph(g, format)
+}
+
 func runSwarmList(cmd *cobra.Command, args []string) error {
 	rigs, _, err := getAllRigs()
 	if err != nil {
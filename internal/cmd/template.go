@@ -0,0 +1,300 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/templates"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+// Template command flags
+var (
+	templateRig      string
+	templateListJSON bool
+	templateEmbedded bool
+	templateForce    bool
+)
+
+var templateCmd = &cobra.Command{
+	Use:     "template",
+	Aliases: []string{"templates"},
+	GroupID: GroupConfig,
+	Short:   "Customize role and message templates",
+	RunE:    requireSubcommand,
+	Long: `Customize the role and message templates behind gt prime and agent mail.
+
+gt ships its templates embedded in the binary. Each one can be overridden
+per town or per rig; gt prime picks the first it finds:
+
+  1. <rig>/.runtime/templates/{roles,messages}/<name>.md.tmpl
+  2. <town>/settings/templates/{roles,messages}/<name>.md.tmpl
+  3. embedded
+
+Eject a template to get an editable copy. The copy records which embedded
+version it came from, and gt doctor warns when a newer gt ships a changed
+template so you can merge the upstream changes.
+
+Commands:
+  list   List templates and where each one comes from
+  show   Print a template
+  diff   Compare an override with the embedded template
+  eject  Copy an embedded template out for editing
+
+Examples:
+  gt template list
+  gt template eject polecat --rig gastown   # Repo-specific polecat rules
+  gt template diff polecat --rig gastown`,
+}
+
+var templateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List templates and their sources",
+	Long: `List role and message templates and where each one resolves from:
+rig override, town override, or embedded.
+
+Uses the current rig's overrides when run inside a rig, or those of --rig.
+
+Examples:
+  gt template list
+  gt template list --rig gastown --json`,
+	Args: cobra.NoArgs,
+	RunE: runTemplateList,
+}
+
+var templateShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Print a template",
+	Long: `Print the template gt prime would use for a role or message, after
+applying the rig and town overrides.
+
+Examples:
+  gt template show polecat
+  gt template show polecat --embedded   # The built-in version`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTemplateShow,
+}
+
+var templateDiffCmd = &cobra.Command{
+	Use:   "diff <name>",
+	Short: "Compare an override with the embedded template",
+	Long: `Show a diff from the embedded template to the override in effect.
+
+Examples:
+  gt template diff polecat --rig gastown`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTemplateDiff,
+}
+
+var templateEjectCmd = &cobra.Command{
+	Use:   "eject <name>",
+	Short: "Copy an embedded template out for editing",
+	Long: `Copy an embedded template to the town's settings/templates/, or with
+--rig to the rig's .runtime/templates/, where it overrides the embedded one.
+
+Keep the copy's first line: it records the embedded version the copy came
+from so gt doctor can tell when that version changes.
+
+Examples:
+  gt template eject polecat --rig gastown
+  gt template eject handoff              # Town-wide
+  gt template eject polecat --force      # Start over from the embedded copy`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTemplateEject,
+}
+
+func init() {
+	templateListCmd.Flags().BoolVar(&templateListJSON, "json", false, "Output as JSON")
+	templateShowCmd.Flags().BoolVar(&templateEmbedded, "embedded", false, "Show the embedded template, ignoring overrides")
+	templateEjectCmd.Flags().BoolVarP(&templateForce, "force", "f", false, "Replace an existing override")
+	for _, c := range []*cobra.Command{templateListCmd, templateShowCmd, templateDiffCmd} {
+		c.Flags().StringVar(&templateRig, "rig", "", "Rig whose overrides apply (default: current rig)")
+	}
+	templateEjectCmd.Flags().StringVar(&templateRig, "rig", "", "Eject into this rig instead of the town")
+
+	templateCmd.AddCommand(templateListCmd)
+	templateCmd.AddCommand(templateShowCmd)
+	templateCmd.AddCommand(templateDiffCmd)
+	templateCmd.AddCommand(templateEjectCmd)
+
+	rootCmd.AddCommand(templateCmd)
+}
+
+// templateRoots returns the town root and the path of the rig whose
+// overrides apply: --rig, else the rig the current directory is in. The
+// rig path is empty outside a rig.
+func templateRoots(detect bool) (string, string, error) {
+	if templateRig != "" {
+		townRoot, r, err := getRig(templateRig)
+		if err != nil {
+			return "", "", err
+		}
+		return townRoot, r.Path, nil
+	}
+
+	townRoot, err := workspace.FindFromCwdOrError()
+	if err != nil {
+		return "", "", fmt.Errorf("not in a Gas Town workspace: %w", err)
+	}
+	if detect {
+		if rigName, _ := detectCurrentRigWithPath(); rigName != "" {
+			if _, r, err := getRig(rigName); err == nil {
+				return townRoot, r.Path, nil
+			}
+		}
+	}
+	return townRoot, "", nil
+}
+
+func runTemplateList(cmd *cobra.Command, args []string) error {
+	townRoot, rigPath, err := templateRoots(true)
+	if err != nil {
+		return err
+	}
+	infos, err := templates.List(townRoot, rigPath)
+	if err != nil {
+		return err
+	}
+
+	if templateListJSON {
+		out, _ := json.MarshalIndent(infos, "", "  ")
+		fmt.Println(string(out))
+		return nil
+	}
+
+	kind := ""
+	for _, info := range infos {
+		if info.Kind != kind {
+			if kind != "" {
+				fmt.Println()
+			}
+			kind = info.Kind
+			fmt.Printf("%s\n", style.Bold.Render(kind+":"))
+		}
+		source := style.Dim.Render(info.Source)
+		if info.Source != templates.SourceEmbedded {
+			source = fmt.Sprintf("%s  %s", info.Source, style.Dim.Render(info.Path))
+		}
+		fmt.Printf("  %-12s %s", info.Name, source)
+		if info.Stale {
+			fmt.Printf("  %s", style.Warning.Render("(embedded template changed since eject)"))
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+func runTemplateShow(cmd *cobra.Command, args []string) error {
+	var info templates.Info
+	if templateEmbedded {
+		embedded, _, err := templates.Embedded(args[0])
+		if err != nil {
+			return err
+		}
+		info = embedded
+	} else {
+		townRoot, rigPath, err := templateRoots(true)
+		if err != nil {
+			return err
+		}
+		if info, err = templates.Lookup(townRoot, rigPath, args[0]); err != nil {
+			return err
+		}
+	}
+
+	content, err := templates.Content(info, false)
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(content))
+	return nil
+}
+
+func runTemplateDiff(cmd *cobra.Command, args []string) error {
+	townRoot, rigPath, err := templateRoots(true)
+	if err != nil {
+		return err
+	}
+	info, err := templates.Lookup(townRoot, rigPath, args[0])
+	if err != nil {
+		return err
+	}
+	if info.Source == templates.SourceEmbedded {
+		fmt.Printf("%s is not overridden; gt uses the embedded template\n", info.File())
+		return nil
+	}
+	if info.Stale {
+		style.PrintWarning("the embedded template has changed since %s was ejected", info.Path)
+	}
+
+	override, err := templates.Content(info, true)
+	if err != nil {
+		return err
+	}
+	embedded, err := templates.Content(templates.Info{Name: info.Name, Kind: info.Kind}, false)
+	if err != nil {
+		return err
+	}
+
+	// Lay both copies out side by side so the diff headers read
+	// embedded/roles/x.md.tmpl and town/roles/x.md.tmpl.
+	dir, err := os.MkdirTemp("", "gt-template-diff-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	for source, content := range map[string][]byte{templates.SourceEmbedded: embedded, info.Source: override} {
+		path := filepath.Join(dir, source, info.File())
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, content, 0600); err != nil {
+			return err
+		}
+	}
+
+	diff := exec.Command("git", "diff", "--no-index", "--",
+		filepath.Join(templates.SourceEmbedded, info.File()), filepath.Join(info.Source, info.File()))
+	diff.Dir = dir
+	diff.Stdout = os.Stdout
+	diff.Stderr = os.Stderr
+	if err := diff.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return nil // files differ
+		}
+		return fmt.Errorf("running git diff: %w", err)
+	}
+	fmt.Printf("%s matches the embedded template\n", info.Path)
+	return nil
+}
+
+func runTemplateEject(cmd *cobra.Command, args []string) error {
+	townRoot, rigPath, err := templateRoots(false)
+	if err != nil {
+		return err
+	}
+	dir := templates.TownOverrideDir(townRoot)
+	if rigPath != "" {
+		dir = templates.RigOverrideDir(rigPath)
+	}
+
+	path, err := templates.Eject(dir, args[0], templateForce)
+	if errors.Is(err, templates.ErrOverrideExists) {
+		return fmt.Errorf("%s already exists (use --force to replace it)", path)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s Ejected %s\n", style.Success.Render("✓"), path)
+	fmt.Printf("  Edit it to customize; gt prime picks it up on the next prime.\n")
+	fmt.Printf("  Keep the first line so gt doctor can tell when the embedded template changes.\n")
+	return nil
+}
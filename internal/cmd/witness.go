@@ -31,6 +31,7 @@ var witnessCmd = &cobra.Command{
 The Witness patrols a single rig, watching over its polecats:
   - Detects stalled polecats (crashed or stuck mid-work)
   - Nudges unresponsive sessions back to life
+  - Escalates polecats that are busy but looping (gt witness thrash)
   - Cleans up zombie polecats (finished but failed to exit)
   - Nukes sandboxes when polecats complete via 'gt done'
 
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/session"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/tmux"
+	"github.com/steveyegge/gastown/internal/witness"
+)
+
+var (
+	witnessThrashJSON   bool
+	witnessThrashDryRun bool
+)
+
+var witnessThrashCmd = &cobra.Command{
+	Use:   "thrash <rig>",
+	Short: "Detect polecats that are active but looping",
+	Long: `Sample each running polecat for signs of thrashing: busy, but not
+converging.
+
+Two scores are computed per polecat:
+  repetition  share of the tool calls and test failures in the last 200
+              pane lines that repeat an earlier one (same command, same
+              failing test)
+  churn       share of the branch's line changes in the last 2 hours spent
+              rewriting the same files back and forth, raised by commits
+              that revert its own earlier commits
+
+A polecat whose repetition reaches 0.6 or churn reaches 0.5 on two
+consecutive samples is thrashing. The Witness logs a thrashing event and
+files a HELP request on its behalf, which is assessed and escalated to the
+Mayor. A thrashing polecat is escalated at most once an hour.
+
+A pane that hasn't changed since the last sample doesn't count as
+repetition; an idle polecat is stalled, not thrashing.
+
+The Witness runs this on each patrol.
+
+Examples:
+  gt witness thrash gastown
+  gt witness thrash gastown --dry-run
+  gt witness thrash gastown --json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runWitnessThrash,
+}
+
+func init() {
+	witnessThrashCmd.Flags().BoolVar(&witnessThrashJSON, "json", false, "Output reports as JSON")
+	witnessThrashCmd.Flags().BoolVar(&witnessThrashDryRun, "dry-run", false, "Score polecats without recording the sample or escalating")
+	witnessCmd.AddCommand(witnessThrashCmd)
+}
+
+func runWitnessThrash(cmd *cobra.Command, args []string) error {
+	mgr, r, err := getPolecatManager(args[0])
+	if err != nil {
+		return err
+	}
+	polecats, err := mgr.List()
+	if err != nil {
+		return fmt.Errorf("listing polecats: %w", err)
+	}
+
+	cfg := witness.DefaultThrashConfig()
+	statePath := witness.ThrashStatePath(r.Path)
+	state, err := witness.LoadThrashState(statePath)
+	if err != nil {
+		return err
+	}
+
+	t := tmux.NewTmux()
+	now := time.Now()
+	base := "origin/" + r.DefaultBranch()
+	var router *mail.Router
+	var reports []*witness.ThrashReport
+	for _, p := range polecats {
+		sessionName := session.PolecatSessionName(r.Name, p.Name)
+		if running, _ := t.HasSession(sessionName); !running {
+			continue
+		}
+		pane, err := t.CapturePane(sessionName, cfg.CaptureLines)
+		if err != nil {
+			continue
+		}
+		g := git.NewGit(p.ClonePath)
+		commits, err := g.RecentCommits(base+"..HEAD", now.Add(-cfg.Window))
+		if err != nil {
+			commits, _ = g.RecentCommits("HEAD", now.Add(-cfg.Window))
+		}
+
+		report := witness.ScoreThrash(p.Name, pane, commits)
+		escalate := state.Observe(report, pane, cfg, now)
+		reports = append(reports, report)
+		if !escalate || witnessThrashDryRun {
+			continue
+		}
+
+		address := fmt.Sprintf("%s/polecats/%s", r.Name, p.Name)
+		_ = events.LogFeed(events.TypeThrashing, address,
+			events.ThrashingPayload(r.Name, p.Name, report.Repetition, report.Churn, report.Reason))
+		if router == nil {
+			router = mail.NewRouter(r.Path)
+		}
+		assessment, mailID, err := witness.EscalateThrashing(router, r.Name, p.Issue, report)
+		if err != nil {
+			style.PrintWarning("could not escalate %s: %v", address, err)
+			continue
+		}
+		state.MarkEscalated(p.Name, now)
+		if !witnessThrashJSON {
+			if mailID != "" {
+				fmt.Printf("%s Escalated %s to the Mayor: %s\n", style.Warning.Render("⚠"), address, assessment.EscalationReason)
+			} else if assessment.CanHelp {
+				fmt.Printf("%s %s: %s\n", style.Warning.Render("⚠"), address, assessment.HelpAction)
+			}
+		}
+	}
+
+	if !witnessThrashDryRun {
+		if err := state.Save(statePath, now); err != nil {
+			return err
+		}
+	}
+
+	if witnessThrashJSON {
+		if reports == nil {
+			reports = []*witness.ThrashReport{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(reports)
+	}
+
+	if len(reports) == 0 {
+		fmt.Println("No running polecats.")
+		return nil
+	}
+	thrashing := 0
+	for _, rep := range reports {
+		icon := style.Success.Render("●")
+		switch {
+		case rep.Thrashing:
+			icon = style.Error.Render("✗")
+			thrashing++
+		case rep.Streak > 0:
+			icon = style.Warning.Render("○")
+		}
+		fmt.Printf("%s %s  repetition %.2f (%d actions)  churn %.2f (%d commits)\n",
+			icon, style.Bold.Render(rep.Polecat), rep.Repetition, rep.Actions, rep.Churn, rep.Commits)
+		if rep.Reason != "" {
+			fmt.Printf("    %s\n", rep.Reason)
+		}
+	}
+	fmt.Printf("\n%d of %d polecat(s) thrashing.\n", thrashing, len(reports))
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
@@ -212,6 +213,82 @@ func validateRigSettings(c *RigSettings) error {
 			return err
 		}
 	}
+	if c.Overlay != nil {
+		if err := validateOverlayConfig(c.Overlay); err != nil {
+			return err
+		}
+	}
+	if err := validateResourceLimits(c.ResourceLimits); err != nil {
+		return err
+	}
+	return validateLifecycleHooks(c.LifecycleHooks)
+}
+
+// cpuQuotaRe matches a systemd CPUQuota percentage.
+var cpuQuotaRe = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?%$`)
+
+// memoryMaxRe matches a systemd MemoryMax size or percentage.
+var memoryMaxRe = regexp.MustCompile(`^([0-9]+[KMGT]?|[0-9]+(\.[0-9]+)?%)$`)
+
+// validateResourceLimits checks per-role limits are in systemd's syntax.
+func validateResourceLimits(limits map[string]*ResourceLimits) error {
+	for role, l := range limits {
+		if l == nil {
+			continue
+		}
+		if l.CPU != "" && !cpuQuotaRe.MatchString(l.CPU) {
+			return fmt.Errorf("resource_limits[%s].cpu must be a percentage like \"200%%\", got %q", role, l.CPU)
+		}
+		if l.Memory != "" && !memoryMaxRe.MatchString(l.Memory) {
+			return fmt.Errorf("resource_limits[%s].memory must be a size like \"4G\", got %q", role, l.Memory)
+		}
+		if l.Pids < 0 {
+			return fmt.Errorf("resource_limits[%s].pids must be non-negative, got %d", role, l.Pids)
+		}
+	}
+	return nil
+}
+
+// validateOverlayConfig checks that the overlay port blocks fit in the port range.
+func validateOverlayConfig(c *OverlayConfig) error {
+	if c.PortBase < 0 || c.PortBase > 65535 {
+		return fmt.Errorf("overlay.port_base must be between 1 and 65535, got %d", c.PortBase)
+	}
+	if c.PortStride < 0 {
+		return fmt.Errorf("overlay.port_stride must be non-negative, got %d", c.PortStride)
+	}
+	return nil
+}
+
+// validateLifecycleHooks validates lifecycle hook phases, timeouts and policies.
+func validateLifecycleHooks(hooks map[string][]LifecycleHook) error {
+	for phase, list := range hooks {
+		known := false
+		for _, p := range LifecycleHookPhases {
+			known = known || p == phase
+		}
+		if !known {
+			return fmt.Errorf("unknown lifecycle hook phase %q (want one of %s)", phase, strings.Join(LifecycleHookPhases, ", "))
+		}
+		for i, h := range list {
+			if h.Command == "" {
+				return fmt.Errorf("%w: lifecycle_hooks[%s][%d].command", ErrMissingField, phase, i)
+			}
+			if h.Timeout != "" {
+				d, err := time.ParseDuration(h.Timeout)
+				if err != nil {
+					return fmt.Errorf("lifecycle_hooks[%s][%d]: invalid timeout: %w", phase, i, err)
+				}
+				if d <= 0 {
+					return fmt.Errorf("lifecycle_hooks[%s][%d]: timeout must be positive, got %q", phase, i, h.Timeout)
+				}
+			}
+			if h.OnFailure != "" && h.OnFailure != HookOnFailureFail && h.OnFailure != HookOnFailureWarn {
+				return fmt.Errorf("lifecycle_hooks[%s][%d]: on_failure must be %q or %q, got %q",
+					phase, i, HookOnFailureFail, HookOnFailureWarn, h.OnFailure)
+			}
+		}
+	}
 	return nil
 }
 
@@ -525,6 +602,27 @@ func (c *AccountsConfig) GetAccount(handle string) *Account {
 	return nil
 }
 
+// HandleForConfigDir returns the handle of the account whose config_dir is
+// dir, or "" if none matches.
+func (c *AccountsConfig) HandleForConfigDir(dir string) string {
+	if dir == "" {
+		return ""
+	}
+	dir = filepath.Clean(expandPath(dir))
+	for handle, acct := range c.Accounts {
+		if filepath.Clean(acct.ExpandedConfigDir()) == dir {
+			return handle
+		}
+	}
+	return ""
+}
+
+// ExpandedConfigDir returns the account's config_dir with ~ expanded, as
+// passed to CLAUDE_CONFIG_DIR.
+func (a Account) ExpandedConfigDir() string {
+	return expandPath(a.ConfigDir)
+}
+
 // GetDefaultAccount returns the default account, or nil if not set.
 func (c *AccountsConfig) GetDefaultAccount() *Account {
 	if c.Default == "" {
@@ -671,6 +769,11 @@ func validateMessagingConfig(c *MessagingConfig) error {
 		if queue.MaxClaims < 0 {
 			return fmt.Errorf("%w: queue '%s' max_claims must be non-negative", ErrMissingField, name)
 		}
+		if queue.ClaimTTL != "" {
+			if d, err := time.ParseDuration(queue.ClaimTTL); err != nil || d <= 0 {
+				return fmt.Errorf("invalid claim_ttl %q for queue '%s': must be a positive duration", queue.ClaimTTL, name)
+			}
+		}
 	}
 
 	// Validate announces have at least one reader
@@ -701,6 +804,82 @@ func MessagingConfigPath(townRoot string) string {
 	return filepath.Join(townRoot, "config", "messaging.json")
 }
 
+// LoadFederationConfig loads and validates a federation configuration file.
+func LoadFederationConfig(path string) (*FederationConfig, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is constructed internally, not from user input
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, path)
+		}
+		return nil, fmt.Errorf("reading federation config: %w", err)
+	}
+
+	var config FederationConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("parsing federation config: %w", err)
+	}
+
+	if err := validateFederationConfig(&config); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// SaveFederationConfig saves a federation configuration to a file.
+func SaveFederationConfig(path string, config *FederationConfig) error {
+	if err := validateFederationConfig(config); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding federation config: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil { //nolint:gosec // G306: federation config doesn't contain secrets
+		return fmt.Errorf("writing federation config: %w", err)
+	}
+
+	return nil
+}
+
+// validateFederationConfig validates a FederationConfig.
+func validateFederationConfig(c *FederationConfig) error {
+	if c.Type != "federation" && c.Type != "" {
+		return fmt.Errorf("%w: expected type 'federation', got '%s'", ErrInvalidType, c.Type)
+	}
+	if c.Version > CurrentFederationVersion {
+		return fmt.Errorf("%w: got %d, max supported %d", ErrInvalidVersion, c.Version, CurrentFederationVersion)
+	}
+	if c.Machine == "" {
+		return fmt.Errorf("%w: machine", ErrMissingField)
+	}
+	if c.Machine == "local" || strings.ContainsAny(c.Machine, ":/ ") {
+		return fmt.Errorf("invalid machine name %q: must not be 'local' or contain ':', '/' or spaces", c.Machine)
+	}
+	switch c.Transport.Type {
+	case "git":
+		if c.Transport.Remote == "" {
+			return fmt.Errorf("%w: transport.remote", ErrMissingField)
+		}
+	case "":
+		return fmt.Errorf("%w: transport.type", ErrMissingField)
+	default:
+		return fmt.Errorf("unknown federation transport type %q (supported: git)", c.Transport.Type)
+	}
+	return nil
+}
+
+// FederationConfigPath returns the standard path for federation config in a town.
+func FederationConfigPath(townRoot string) string {
+	return filepath.Join(townRoot, "config", "federation.json")
+}
+
 // LoadOrCreateMessagingConfig loads the messaging config, creating a default if not found.
 func LoadOrCreateMessagingConfig(path string) (*MessagingConfig, error) {
 	config, err := LoadMessagingConfig(path)
@@ -754,6 +933,9 @@ func LoadOrCreateTownSettings(path string) (*TownSettings, error) {
 	if err := json.Unmarshal(data, &settings); err != nil {
 		return nil, err
 	}
+	if err := validateResourceLimits(settings.ResourceLimits); err != nil {
+		return nil, err
+	}
 	return &settings, nil
 }
 
@@ -765,6 +947,9 @@ func SaveTownSettings(path string, settings *TownSettings) error {
 	if settings.Version > CurrentTownSettingsVersion {
 		return fmt.Errorf("%w: got %d, max supported %d", ErrInvalidVersion, settings.Version, CurrentTownSettingsVersion)
 	}
+	if err := validateResourceLimits(settings.ResourceLimits); err != nil {
+		return err
+	}
 
 	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 		return fmt.Errorf("creating directory: %w", err)
@@ -1048,6 +1233,113 @@ func ResolveRoleAgentName(role, townRoot, rigPath string) (agentName string, isR
 	return "claude", false
 }
 
+// ResolveTierAgentName returns the agent alias configured for a molecule
+// step tier hint ("haiku", "sonnet", "opus"), or "" if the tier is empty,
+// unmapped, or maps to an agent that isn't available.
+//
+// Resolution order:
+//  1. Rig's TierAgents[tier]
+//  2. Town's TierAgents[tier]
+//
+// An unavailable agent prints a warning to stderr, like role_agents, so the
+// step falls back to the normal role/default agent instead of failing.
+func ResolveTierAgentName(tier, townRoot, rigPath string) string {
+	if tier == "" {
+		return ""
+	}
+
+	var rigSettings *RigSettings
+	if rigPath != "" {
+		var err error
+		rigSettings, err = LoadRigSettings(RigSettingsPath(rigPath))
+		if err != nil {
+			rigSettings = nil
+		}
+	}
+
+	townSettings, err := LoadOrCreateTownSettings(TownSettingsPath(townRoot))
+	if err != nil {
+		townSettings = NewTownSettings()
+	}
+
+	agentName := ""
+	if rigSettings != nil && rigSettings.TierAgents[tier] != "" {
+		agentName = rigSettings.TierAgents[tier]
+	} else if townSettings.TierAgents[tier] != "" {
+		agentName = townSettings.TierAgents[tier]
+	}
+	if agentName == "" {
+		return ""
+	}
+
+	// Load custom agent registries so validation sees registered agents
+	_ = LoadAgentRegistry(DefaultAgentRegistryPath(townRoot))
+	if rigPath != "" {
+		_ = LoadRigAgentRegistry(RigAgentRegistryPath(rigPath))
+	}
+
+	if err := ValidateAgentConfig(agentName, townSettings, rigSettings); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: tier_agents[%s]=%s - %v, falling back to default\n", tier, agentName, err)
+		return ""
+	}
+	return agentName
+}
+
+// ResolvePolecatRouting returns how gt sling should name a fresh polecat in
+// the rig: PolecatRoutingPool or PolecatRoutingSkill.
+//
+// Resolution order:
+//  1. Rig's PolecatRouting
+//  2. Town's PolecatRouting
+//  3. PolecatRoutingPool
+//
+// An unknown mode prints a warning to stderr and falls back to the pool.
+func ResolvePolecatRouting(townRoot, rigPath string) string {
+	mode := ""
+	if rigPath != "" {
+		if rigSettings, err := LoadRigSettings(RigSettingsPath(rigPath)); err == nil {
+			mode = rigSettings.PolecatRouting
+		}
+	}
+	if mode == "" {
+		if townSettings, err := LoadOrCreateTownSettings(TownSettingsPath(townRoot)); err == nil {
+			mode = townSettings.PolecatRouting
+		}
+	}
+
+	switch mode {
+	case "", PolecatRoutingPool:
+		return PolecatRoutingPool
+	case PolecatRoutingSkill:
+		return PolecatRoutingSkill
+	default:
+		fmt.Fprintf(os.Stderr, "warning: unknown polecat_routing %q, falling back to %s\n", mode, PolecatRoutingPool)
+		return PolecatRoutingPool
+	}
+}
+
+// ResolveResourceLimits returns the resource limits for a role's sessions:
+// the rig's resource_limits entry for the role, else the town's. Returns
+// nil when the role is unlimited.
+func ResolveResourceLimits(townRoot, rigPath, role string) *ResourceLimits {
+	if rigPath != "" {
+		if rigSettings, err := LoadRigSettings(RigSettingsPath(rigPath)); err == nil {
+			if l, ok := rigSettings.ResourceLimits[role]; ok {
+				if l.IsZero() {
+					return nil
+				}
+				return l
+			}
+		}
+	}
+	if townSettings, err := LoadOrCreateTownSettings(TownSettingsPath(townRoot)); err == nil {
+		if l := townSettings.ResourceLimits[role]; !l.IsZero() {
+			return l
+		}
+	}
+	return nil
+}
+
 // lookupAgentConfig looks up an agent by name.
 // Checks rig-level custom agents first, then town's custom agents, then built-in presets from agents.go.
 func lookupAgentConfig(name string, townSettings *TownSettings, rigSettings *RigSettings) *RuntimeConfig {
@@ -1605,6 +1897,37 @@ func validateEscalationConfig(c *EscalationConfig) error {
 		return fmt.Errorf("%w: max_reescalations must be non-negative", ErrMissingField)
 	}
 
+	// Validate delivery channels
+	if c.Channels.RetryBackoff != "" {
+		if _, err := time.ParseDuration(c.Channels.RetryBackoff); err != nil {
+			return fmt.Errorf("invalid channels.retry_backoff: %w", err)
+		}
+	}
+	if smtp := c.Channels.SMTP; smtp != nil && (smtp.Host == "" || smtp.From == "") {
+		return fmt.Errorf("%w: channels.smtp requires host and from", ErrMissingField)
+	}
+	hooks := map[string]*EscalationHook{"channels.sms": c.Channels.SMS}
+	for name, hook := range c.Channels.Webhooks {
+		hooks["channels.webhooks."+name] = hook
+	}
+	for name, hook := range hooks {
+		if hook == nil {
+			continue
+		}
+		if (hook.URL == "") == (hook.Command == "") {
+			return fmt.Errorf("%w: %s requires exactly one of url or command", ErrMissingField, name)
+		}
+		if hook.Timeout != "" {
+			d, err := time.ParseDuration(hook.Timeout)
+			if err != nil {
+				return fmt.Errorf("invalid %s.timeout: %w", name, err)
+			}
+			if d <= 0 {
+				return fmt.Errorf("%s.timeout must be positive, got %q", name, hook.Timeout)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -696,6 +696,26 @@ func TestMessagingConfigValidation(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "queue with invalid claim_ttl",
+			config: &MessagingConfig{
+				Version: 1,
+				Queues: map[string]QueueConfig{
+					"work": {Workers: []string{"worker/"}, ClaimTTL: "soon"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "queue with valid claim_ttl",
+			config: &MessagingConfig{
+				Version: 1,
+				Queues: map[string]QueueConfig{
+					"work": {Workers: []string{"worker/"}, MaxClaims: 2, ClaimTTL: "15m"},
+				},
+			},
+			wantErr: false,
+		},
 		{
 			name: "announce with no readers",
 			config: &MessagingConfig{
@@ -749,6 +769,37 @@ func TestMessagingConfigValidation(t *testing.T) {
 	}
 }
 
+func TestFederationConfigValidation(t *testing.T) {
+	t.Parallel()
+	git := FederationTransport{Type: "git", Remote: "git@example.com:team/mail.git"}
+	tests := []struct {
+		name    string
+		config  *FederationConfig
+		wantErr bool
+	}{
+		{"valid", &FederationConfig{Type: "federation", Version: 1, Machine: "alice", Transport: git}, false},
+		{"missing machine", &FederationConfig{Transport: git}, true},
+		{"machine named local", &FederationConfig{Machine: "local", Transport: git}, true},
+		{"machine with colon", &FederationConfig{Machine: "a:b", Transport: git}, true},
+		{"missing transport", &FederationConfig{Machine: "alice"}, true},
+		{"git without remote", &FederationConfig{Machine: "alice", Transport: FederationTransport{Type: "git"}}, true},
+		{"unknown transport", &FederationConfig{Machine: "alice", Transport: FederationTransport{Type: "carrier-pigeon", Remote: "x"}}, true},
+		{"wrong type", &FederationConfig{Type: "messaging", Machine: "alice", Transport: git}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateFederationConfig(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateFederationConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if got := git.GetBranch(); got != DefaultFederationBranch {
+		t.Errorf("GetBranch() = %q, want %q", got, DefaultFederationBranch)
+	}
+}
+
 func TestLoadMessagingConfigNotFound(t *testing.T) {
 	t.Parallel()
 	_, err := LoadMessagingConfig("/nonexistent/path.json")
@@ -805,6 +856,27 @@ func TestLoadOrCreateMessagingConfig(t *testing.T) {
 	}
 }
 
+func TestQueueConfigGetClaimTTL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		ttl      string
+		expected time.Duration
+	}{
+		{"", DefaultClaimTTL},
+		{"5m", 5 * time.Minute},
+		{"invalid", DefaultClaimTTL},
+		{"-1m", DefaultClaimTTL},
+	}
+
+	for _, tt := range tests {
+		got := QueueConfig{ClaimTTL: tt.ttl}.GetClaimTTL()
+		if got != tt.expected {
+			t.Errorf("GetClaimTTL(%q) = %v, want %v", tt.ttl, got, tt.expected)
+		}
+	}
+}
+
 func TestMessagingConfigPath(t *testing.T) {
 	t.Parallel()
 	path := MessagingConfigPath("/home/user/gt")
@@ -1404,6 +1476,131 @@ func TestResolveRoleAgentConfig_FallsBackOnInvalidAgent(t *testing.T) {
 	}
 }
 
+func TestResolveTierAgentName(t *testing.T) {
+	t.Parallel()
+	townRoot := t.TempDir()
+	rigPath := filepath.Join(townRoot, "testrig")
+
+	// Custom agents backed by a binary that's always on PATH
+	townSettings := NewTownSettings()
+	townSettings.Agents = map[string]*RuntimeConfig{
+		"cheap":  {Command: "sh"},
+		"strong": {Command: "sh"},
+	}
+	townSettings.TierAgents = map[string]string{
+		"haiku":  "cheap",
+		"opus":   "strong",
+		"sonnet": "nonexistent-agent-xyz",
+	}
+	if err := SaveTownSettings(TownSettingsPath(townRoot), townSettings); err != nil {
+		t.Fatalf("SaveTownSettings: %v", err)
+	}
+
+	rigSettings := NewRigSettings()
+	rigSettings.TierAgents = map[string]string{"opus": "cheap"}
+	if err := SaveRigSettings(RigSettingsPath(rigPath), rigSettings); err != nil {
+		t.Fatalf("SaveRigSettings: %v", err)
+	}
+
+	tests := []struct {
+		tier, rigPath, want string
+	}{
+		{"haiku", rigPath, "cheap"}, // town mapping
+		{"opus", rigPath, "cheap"},  // rig overrides town
+		{"opus", "", "strong"},      // town-level role
+		{"sonnet", rigPath, ""},     // invalid agent falls back
+		{"", rigPath, ""},           // no tier hint
+	}
+	for _, tt := range tests {
+		if got := ResolveTierAgentName(tt.tier, townRoot, tt.rigPath); got != tt.want {
+			t.Errorf("ResolveTierAgentName(%q, rig=%q) = %q, want %q", tt.tier, tt.rigPath, got, tt.want)
+		}
+	}
+}
+
+func TestResolvePolecatRouting(t *testing.T) {
+	t.Parallel()
+	townRoot := t.TempDir()
+	skillRig := filepath.Join(townRoot, "skillrig")
+	poolRig := filepath.Join(townRoot, "poolrig")
+
+	if got := ResolvePolecatRouting(townRoot, skillRig); got != PolecatRoutingPool {
+		t.Errorf("default = %q, want %q", got, PolecatRoutingPool)
+	}
+
+	townSettings := NewTownSettings()
+	townSettings.PolecatRouting = PolecatRoutingSkill
+	if err := SaveTownSettings(TownSettingsPath(townRoot), townSettings); err != nil {
+		t.Fatalf("SaveTownSettings: %v", err)
+	}
+	rigSettings := NewRigSettings()
+	rigSettings.PolecatRouting = PolecatRoutingPool
+	if err := SaveRigSettings(RigSettingsPath(poolRig), rigSettings); err != nil {
+		t.Fatalf("SaveRigSettings: %v", err)
+	}
+
+	if got := ResolvePolecatRouting(townRoot, skillRig); got != PolecatRoutingSkill {
+		t.Errorf("town setting = %q, want %q", got, PolecatRoutingSkill)
+	}
+	if got := ResolvePolecatRouting(townRoot, poolRig); got != PolecatRoutingPool {
+		t.Errorf("rig override = %q, want %q", got, PolecatRoutingPool)
+	}
+}
+
+func TestResolveResourceLimits(t *testing.T) {
+	t.Parallel()
+	townRoot := t.TempDir()
+	cappedRig := filepath.Join(townRoot, "capped")
+	openRig := filepath.Join(townRoot, "open")
+
+	if got := ResolveResourceLimits(townRoot, cappedRig, "polecat"); got != nil {
+		t.Errorf("default = %+v, want nil", got)
+	}
+
+	townSettings := NewTownSettings()
+	townSettings.ResourceLimits = map[string]*ResourceLimits{
+		"polecat": {CPU: "200%", Memory: "4G", Pids: 512},
+	}
+	if err := SaveTownSettings(TownSettingsPath(townRoot), townSettings); err != nil {
+		t.Fatalf("SaveTownSettings: %v", err)
+	}
+	rigSettings := NewRigSettings()
+	rigSettings.ResourceLimits = map[string]*ResourceLimits{"polecat": {}}
+	if err := SaveRigSettings(RigSettingsPath(openRig), rigSettings); err != nil {
+		t.Fatalf("SaveRigSettings: %v", err)
+	}
+
+	if got := ResolveResourceLimits(townRoot, cappedRig, "polecat"); got == nil || got.Memory != "4G" || got.Pids != 512 {
+		t.Errorf("town setting = %+v, want memory 4G, pids 512", got)
+	}
+	if got := ResolveResourceLimits(townRoot, cappedRig, "crew"); got != nil {
+		t.Errorf("unlisted role = %+v, want nil", got)
+	}
+	if got := ResolveResourceLimits(townRoot, openRig, "polecat"); got != nil {
+		t.Errorf("rig opt-out = %+v, want nil", got)
+	}
+
+	rigSettings.ResourceLimits = map[string]*ResourceLimits{"polecat": {Memory: "lots"}}
+	if err := SaveRigSettings(RigSettingsPath(openRig), rigSettings); err == nil {
+		t.Error("SaveRigSettings accepted memory limit \"lots\"")
+	}
+	rigSettings.ResourceLimits = map[string]*ResourceLimits{"polecat": {CPU: "2"}}
+	if err := SaveRigSettings(RigSettingsPath(openRig), rigSettings); err == nil {
+		t.Error("SaveRigSettings accepted cpu limit \"2\"")
+	}
+	// A hand-edited town settings file is checked on load too.
+	bad := `{"type":"town-settings","version":1,"resource_limits":{"polecat":{"memory":"4G; rm -rf ~"}}}`
+	if err := os.WriteFile(TownSettingsPath(townRoot), []byte(bad), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadOrCreateTownSettings(TownSettingsPath(townRoot)); err == nil {
+		t.Error("LoadOrCreateTownSettings accepted memory limit \"4G; rm -rf ~\"")
+	}
+	if got := ResolveResourceLimits(townRoot, cappedRig, "polecat"); got != nil {
+		t.Errorf("invalid town limits = %+v, want nil", got)
+	}
+}
+
 func TestGetRuntimeCommand_UsesRigAgentWhenRigPathProvided(t *testing.T) {
 	t.Parallel()
 	townRoot := t.TempDir()
@@ -2334,6 +2531,30 @@ func TestEscalationConfigValidation(t *testing.T) {
 			wantErr: true,
 			errMsg:  "max_reescalations must be non-negative",
 		},
+		{
+			name: "invalid hook timeout",
+			config: &EscalationConfig{
+				Type:    "escalation",
+				Version: 1,
+				Channels: EscalationChannels{
+					SMS: &EscalationHook{Command: "send-sms", Timeout: "soon"},
+				},
+			},
+			wantErr: true,
+			errMsg:  "invalid channels.sms.timeout",
+		},
+		{
+			name: "non-positive hook timeout",
+			config: &EscalationConfig{
+				Type:    "escalation",
+				Version: 1,
+				Channels: EscalationChannels{
+					Webhooks: map[string]*EscalationHook{"pager": {URL: "https://example.com", Timeout: "0s"}},
+				},
+			},
+			wantErr: true,
+			errMsg:  "channels.webhooks.pager.timeout must be positive",
+		},
 	}
 
 	for _, tt := range tests {
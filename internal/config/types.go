@@ -57,10 +57,61 @@ type TownSettings struct {
 	// Example: {"mayor": "claude-opus", "witness": "claude-haiku", "polecat": "claude-sonnet"}
 	RoleAgents map[string]string `json:"role_agents,omitempty"`
 
+	// TierAgents maps molecule step tier hints to agent aliases.
+	// Keys are tiers from "Tier:" lines in molecule steps: "haiku", "sonnet", "opus".
+	// Values are agent names (built-in presets or custom agents defined in Agents).
+	// gt sling and gt mol step done use the mapped agent when spawning or
+	// respawning for a step carrying that tier, so cheap steps run on cheap models.
+	// Example: {"haiku": "claude-haiku", "opus": "claude-opus"}
+	TierAgents map[string]string `json:"tier_agents,omitempty"`
+
+	// PolecatRouting picks how gt sling names a fresh polecat for a rig:
+	// "pool" (default) takes the next free name from the name pool, "skill"
+	// reuses the free identity whose history best fits the bead.
+	PolecatRouting string `json:"polecat_routing,omitempty"`
+
 	// AgentEmailDomain is the domain used for agent git identity emails.
 	// Agent addresses like "gastown/crew/jack" become "gastown.crew.jack@{domain}".
 	// Default: "gastown.local"
 	AgentEmailDomain string `json:"agent_email_domain,omitempty"`
+
+	// ModelPrices overrides the per-model token prices used by gt costs.
+	// Keys are model name prefixes (longest match wins), e.g. "claude-sonnet-4".
+	// Entries here take precedence over the built-in price table.
+	ModelPrices map[string]ModelPrice `json:"model_prices,omitempty"`
+
+	// ResourceLimits caps the CPU, memory and processes of agent sessions,
+	// keyed by role ("polecat", "crew", ...). Sessions of a role with limits
+	// run in a systemd user scope (cgroup v2). Rig settings override this
+	// per role.
+	ResourceLimits map[string]*ResourceLimits `json:"resource_limits,omitempty"`
+}
+
+// ResourceLimits are cgroup v2 caps for an agent session. Empty fields
+// leave that resource unlimited.
+type ResourceLimits struct {
+	// CPU is a systemd CPUQuota, e.g. "200%" for two cores.
+	CPU string `json:"cpu,omitempty"`
+
+	// Memory is a systemd MemoryMax, e.g. "4G". The kernel OOM-kills the
+	// session when it is exceeded.
+	Memory string `json:"memory,omitempty"`
+
+	// Pids caps the number of processes and threads (systemd TasksMax).
+	Pids int `json:"pids,omitempty"`
+}
+
+// IsZero reports whether no limit is set.
+func (l *ResourceLimits) IsZero() bool {
+	return l == nil || (l.CPU == "" && l.Memory == "" && l.Pids == 0)
+}
+
+// ModelPrice is the price of a model's tokens in USD per million tokens.
+type ModelPrice struct {
+	Input      float64 `json:"input"`       // uncached input tokens
+	Output     float64 `json:"output"`      // output tokens
+	CacheWrite float64 `json:"cache_write"` // cache creation input tokens
+	CacheRead  float64 `json:"cache_read"`  // cache hit input tokens
 }
 
 // NewTownSettings creates a new TownSettings with defaults.
@@ -229,6 +280,50 @@ type RigSettings struct {
 	// Overrides TownSettings.RoleAgents for this specific rig.
 	// Example: {"witness": "claude-haiku", "polecat": "claude-sonnet"}
 	RoleAgents map[string]string `json:"role_agents,omitempty"`
+
+	// TierAgents maps molecule step tier hints to agent aliases.
+	// Overrides TownSettings.TierAgents for this specific rig.
+	// Example: {"haiku": "claude-haiku"}
+	TierAgents map[string]string `json:"tier_agents,omitempty"`
+
+	// PolecatRouting overrides TownSettings.PolecatRouting for this rig.
+	PolecatRouting string `json:"polecat_routing,omitempty"`
+
+	// LifecycleHooks maps lifecycle phases ("pre-spawn", "post-spawn",
+	// "pre-done", "pre-merge", "post-merge", "pre-nuke") to the commands
+	// run at that phase, in order.
+	LifecycleHooks map[string][]LifecycleHook `json:"lifecycle_hooks,omitempty"`
+
+	// Overlay configures how .runtime/overlay/ is copied into worktrees.
+	Overlay *OverlayConfig `json:"overlay,omitempty"`
+
+	// ResourceLimits overrides TownSettings.ResourceLimits for this rig's
+	// sessions, per role.
+	ResourceLimits map[string]*ResourceLimits `json:"resource_limits,omitempty"`
+}
+
+// Overlay port block defaults.
+const (
+	DefaultOverlayPortBase   = 20000
+	DefaultOverlayPortStride = 10
+)
+
+// OverlayConfig configures the rig's overlay: the port block handed to each
+// worker for .tmpl files, and where secret://NAME placeholders come from.
+type OverlayConfig struct {
+	// PortBase is the first port of slot 0's block. Default is 20000.
+	PortBase int `json:"port_base,omitempty"`
+
+	// PortStride is the number of ports in each worker's block. Default is 10.
+	PortStride int `json:"port_stride,omitempty"`
+
+	// SecretsFile is an env file (NAME=value lines) secrets are looked up
+	// in first. Relative paths are from the rig directory.
+	SecretsFile string `json:"secrets_file,omitempty"`
+
+	// SecretsCommand resolves secrets missing from SecretsFile. It is run
+	// with sh -c and GT_SECRET_NAME set; its trimmed stdout is the value.
+	SecretsCommand string `json:"secrets_command,omitempty"`
 }
 
 // CrewConfig represents crew workspace settings for a rig.
@@ -700,6 +795,50 @@ type NamepoolConfig struct {
 	MaxBeforeNumbering int `json:"max_before_numbering,omitempty"`
 }
 
+// Lifecycle hook phases, in the order a polecat meets them.
+const (
+	HookPreSpawn  = "pre-spawn"  // before a polecat worktree is created (runs in the rig)
+	HookPostSpawn = "post-spawn" // after the worktree is set up, before the session starts
+	HookPreDone   = "pre-done"   // gt done, before the branch is pushed and submitted
+	HookPreMerge  = "pre-merge"  // refinery worktree, on the merged tree before it is pushed
+	HookPostMerge = "post-merge" // refinery worktree, after the merge is pushed
+	HookPreNuke   = "pre-nuke"   // before a polecat worktree is removed
+)
+
+// LifecycleHookPhases lists the valid lifecycle hook phases.
+var LifecycleHookPhases = []string{HookPreSpawn, HookPostSpawn, HookPreDone, HookPreMerge, HookPostMerge, HookPreNuke}
+
+// Lifecycle hook failure policies.
+const (
+	HookOnFailureFail = "fail" // abort the operation
+	HookOnFailureWarn = "warn" // print a warning and carry on
+)
+
+// DefaultLifecycleHookTimeout bounds a hook without its own timeout.
+const DefaultLifecycleHookTimeout = 5 * time.Minute
+
+// LifecycleHook is a command run at a worktree lifecycle phase.
+type LifecycleHook struct {
+	// Name labels the hook in output. Defaults to the command.
+	Name string `json:"name,omitempty"`
+
+	// Command is run with sh -c in the phase's working directory.
+	Command string `json:"command"`
+
+	// Timeout is a Go duration (e.g., "2m"). Default is 5m.
+	Timeout string `json:"timeout,omitempty"`
+
+	// OnFailure is "fail" to abort the operation when the hook fails or
+	// times out, or "warn" (default) to print a warning and carry on.
+	OnFailure string `json:"on_failure,omitempty"`
+}
+
+// Polecat routing modes for PolecatRouting.
+const (
+	PolecatRoutingPool  = "pool"
+	PolecatRoutingSkill = "skill"
+)
+
 // DefaultNamepoolConfig returns a NamepoolConfig with sensible defaults.
 func DefaultNamepoolConfig() *NamepoolConfig {
 	return &NamepoolConfig{
@@ -714,6 +853,12 @@ type AccountsConfig struct {
 	Version  int                `json:"version"`  // schema version
 	Accounts map[string]Account `json:"accounts"` // handle -> account details
 	Default  string             `json:"default"`  // default account handle
+
+	// Pool spreads polecat spawns across all accounts instead of using the
+	// default: each spawn without --account or GT_ACCOUNT goes to the account
+	// with the fewest live sessions that isn't cooling down after hitting a
+	// usage limit.
+	Pool bool `json:"pool,omitempty"`
 }
 
 // Account represents a single Claude Code account.
@@ -767,6 +912,26 @@ type QueueConfig struct {
 
 	// MaxClaims is the maximum number of concurrent claims (0 = unlimited).
 	MaxClaims int `json:"max_claims,omitempty"`
+
+	// ClaimTTL is how long a claim lease lasts without renewal (e.g., "30m").
+	// Claims whose lease lapses are requeued by the daemon heartbeat.
+	ClaimTTL string `json:"claim_ttl,omitempty"`
+}
+
+// DefaultClaimTTL is the claim lease duration when a queue doesn't set one.
+const DefaultClaimTTL = 30 * time.Minute
+
+// GetClaimTTL returns the claim lease duration.
+// Returns DefaultClaimTTL if not configured or invalid.
+func (c QueueConfig) GetClaimTTL() time.Duration {
+	if c.ClaimTTL == "" {
+		return DefaultClaimTTL
+	}
+	d, err := time.ParseDuration(c.ClaimTTL)
+	if err != nil || d <= 0 {
+		return DefaultClaimTTL
+	}
+	return d
 }
 
 // AnnounceConfig represents a bulletin board configuration.
@@ -794,6 +959,49 @@ func NewMessagingConfig() *MessagingConfig {
 	}
 }
 
+// FederationConfig connects the town's mail to towns on other machines
+// (config/federation.json). Mail addressed to machine:address (e.g.
+// "alice:mayor/") is forwarded through the transport to that machine's
+// town, which delivers it locally and sends back a delivery receipt.
+type FederationConfig struct {
+	Type    string `json:"type"`    // "federation"
+	Version int    `json:"version"` // schema version
+
+	// Machine is this town's name in federated addresses. Other towns
+	// reach this one as <machine>:<address>.
+	Machine string `json:"machine"`
+
+	// Transport carries messages between towns.
+	Transport FederationTransport `json:"transport"`
+}
+
+// FederationTransport configures how federated mail travels.
+type FederationTransport struct {
+	// Type is the transport kind. Only "git" is supported: a repo every
+	// town can push to, holding one outbox directory per machine.
+	Type string `json:"type"`
+
+	// Remote is the git URL (or path) of the shared mail repo.
+	Remote string `json:"remote"`
+
+	// Branch is the branch messages are exchanged on (default "main").
+	Branch string `json:"branch,omitempty"`
+}
+
+// CurrentFederationVersion is the current schema version for FederationConfig.
+const CurrentFederationVersion = 1
+
+// DefaultFederationBranch is the mail repo branch used when none is set.
+const DefaultFederationBranch = "main"
+
+// GetBranch returns the mail repo branch, defaulting to DefaultFederationBranch.
+func (t FederationTransport) GetBranch() string {
+	if t.Branch == "" {
+		return DefaultFederationBranch
+	}
+	return t.Branch
+}
+
 // EscalationConfig represents escalation routing configuration (settings/escalation.json).
 // This defines severity-based routing for escalations to different channels.
 type EscalationConfig struct {
@@ -808,12 +1016,16 @@ type EscalationConfig struct {
 	//   - "email:human" → Send email to contacts.human_email
 	//   - "sms:human"   → Send SMS to contacts.human_sms
 	//   - "slack"       → Post to contacts.slack_webhook
-	//   - "log"         → Write to escalation log file
+	//   - "webhook:<name>" → Call channels.webhooks[name]
+	//   - "log"         → Append to the escalation log (logs/escalations.log)
 	Routes map[string][]string `json:"routes"`
 
 	// Contacts contains contact information for external notification actions.
 	Contacts EscalationContacts `json:"contacts"`
 
+	// Channels configures the delivery backends for external actions.
+	Channels EscalationChannels `json:"channels,omitempty"`
+
 	// StaleThreshold is how long before an unacknowledged escalation
 	// is considered stale and gets re-escalated.
 	// Format: Go duration string (e.g., "4h", "30m", "24h")
@@ -832,6 +1044,82 @@ type EscalationContacts struct {
 	SlackWebhook string `json:"slack_webhook,omitempty"` // webhook URL for slack action
 }
 
+// EscalationChannels configures how external escalation actions are delivered.
+type EscalationChannels struct {
+	// SMTP is the mail server for email:human.
+	SMTP *EscalationSMTP `json:"smtp,omitempty"`
+
+	// SMS is the gateway hook for sms:human. SMS providers differ too much
+	// for a built-in client, so this is an HTTP webhook or a local command.
+	SMS *EscalationHook `json:"sms,omitempty"`
+
+	// Webhooks are named hooks for "webhook:<name>" actions.
+	Webhooks map[string]*EscalationHook `json:"webhooks,omitempty"`
+
+	// Retries is how many times a failed delivery is retried (default 2).
+	Retries *int `json:"retries,omitempty"`
+
+	// RetryBackoff is the delay before the first retry, doubling after
+	// each attempt (default "2s").
+	RetryBackoff string `json:"retry_backoff,omitempty"`
+}
+
+// EscalationSMTP configures the SMTP server used for email escalations.
+type EscalationSMTP struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port,omitempty"` // default 587
+	Username string `json:"username,omitempty"`
+
+	// PasswordEnv names the environment variable holding the password,
+	// so the secret stays out of settings/escalation.json.
+	PasswordEnv string `json:"password_env,omitempty"`
+
+	From string `json:"from"`
+}
+
+// EscalationHook is a generic delivery hook: an HTTP request or a command.
+// Exactly one of URL or Command should be set.
+type EscalationHook struct {
+	// URL receives the notification. The body is the Body template if set,
+	// otherwise a JSON object with id, severity, title, body, to and source.
+	URL     string            `json:"url,omitempty"`
+	Method  string            `json:"method,omitempty"` // default POST
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Body is an optional Go text/template for the request body, with
+	// fields .ID, .Severity, .Title, .Body, .To and .Source.
+	Body string `json:"body,omitempty"`
+
+	// Command is run with sh -c. The message is on stdin and the fields are
+	// in GT_ESCALATION_ID, GT_SEVERITY, GT_TITLE, GT_SUBJECT, GT_TO and GT_SOURCE.
+	Command string `json:"command,omitempty"`
+
+	// Timeout bounds each attempt (default "10s").
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// GetRetries returns how many times a failed delivery is retried.
+// Returns 2 if not configured.
+func (c *EscalationChannels) GetRetries() int {
+	if c.Retries == nil || *c.Retries < 0 {
+		return 2
+	}
+	return *c.Retries
+}
+
+// GetRetryBackoff returns the delay before the first retry.
+// Returns 2 seconds if not configured or invalid.
+func (c *EscalationChannels) GetRetryBackoff() time.Duration {
+	if c.RetryBackoff == "" {
+		return 2 * time.Second
+	}
+	d, err := time.ParseDuration(c.RetryBackoff)
+	if err != nil {
+		return 2 * time.Second
+	}
+	return d
+}
+
 // CurrentEscalationVersion is the current schema version for EscalationConfig.
 const CurrentEscalationVersion = 1
 
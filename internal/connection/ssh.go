@@ -0,0 +1,472 @@
+package connection
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+// sshConnectionFailed is the exit status ssh uses for its own errors
+// (unreachable host, auth failure), as opposed to the remote command's.
+const sshConnectionFailed = 255
+
+// SSHConfig configures an SSHConnection.
+type SSHConfig struct {
+	// Name is the machine name from the registry.
+	Name string
+
+	// Host is the ssh destination (user@host or an ssh_config alias).
+	Host string
+
+	// KeyPath is an optional private key (ssh -i).
+	KeyPath string
+
+	// ControlDir holds the ControlMaster socket. Defaults to the OS temp dir.
+	// Unix socket paths are limited to ~104 bytes, so keep this short.
+	ControlDir string
+
+	// ControlPersist is how long the master stays up after the last use.
+	// Defaults to 10 minutes.
+	ControlPersist time.Duration
+
+	// ConnectTimeout bounds establishing a new master. Defaults to 10 seconds.
+	ConnectTimeout time.Duration
+
+	// Binary is the ssh executable. Defaults to "ssh"; tests substitute a fake.
+	Binary string
+}
+
+// SSHConnection implements Connection by running commands on a remote
+// machine over ssh. All operations share one persistent ControlMaster
+// connection, so only the first call pays for the handshake.
+type SSHConnection struct {
+	cfg SSHConfig
+}
+
+// NewSSHConnection creates a new SSH connection. No network activity happens
+// until the first operation.
+func NewSSHConnection(cfg SSHConfig) *SSHConnection {
+	if cfg.Name == "" {
+		cfg.Name = cfg.Host
+	}
+	if cfg.ControlDir == "" {
+		cfg.ControlDir = os.TempDir()
+	}
+	if cfg.ControlPersist == 0 {
+		cfg.ControlPersist = 10 * time.Minute
+	}
+	if cfg.ConnectTimeout == 0 {
+		cfg.ConnectTimeout = 10 * time.Second
+	}
+	if cfg.Binary == "" {
+		cfg.Binary = "ssh"
+	}
+	return &SSHConnection{cfg: cfg}
+}
+
+// Name returns the machine name.
+func (c *SSHConnection) Name() string {
+	return c.cfg.Name
+}
+
+// IsLocal returns false for SSH connections.
+func (c *SSHConnection) IsLocal() bool {
+	return false
+}
+
+// sshArgs returns the ssh options shared by every invocation.
+func (c *SSHConnection) sshArgs() []string {
+	args := []string{
+		"-o", "ControlMaster=auto",
+		// %C is a hash of the connection parameters, so every Gas Town
+		// process talking to the same host reuses the same master.
+		"-o", "ControlPath=" + path.Join(c.cfg.ControlDir, "gt-ssh-%C"),
+		"-o", fmt.Sprintf("ControlPersist=%ds", int(c.cfg.ControlPersist.Seconds())),
+		"-o", fmt.Sprintf("ConnectTimeout=%d", int(c.cfg.ConnectTimeout.Seconds())),
+		"-o", "BatchMode=yes",
+	}
+	if c.cfg.KeyPath != "" {
+		args = append(args, "-i", c.cfg.KeyPath)
+	}
+	return args
+}
+
+// run executes a shell command line on the remote host.
+// stdout and stderr are returned separately; a non-nil error means either
+// the command exited non-zero or ssh itself failed (*ConnectionError).
+func (c *SSHConnection) run(op string, stdin []byte, script string) ([]byte, []byte, error) {
+	args := append(c.sshArgs(), "--", c.cfg.Host, script)
+	cmd := exec.Command(c.cfg.Binary, args...) //nolint:gosec // G204: binary and host come from the machine registry
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+
+	err := cmd.Run()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() == sshConnectionFailed {
+			msg := strings.TrimSpace(stderr.String())
+			if msg == "" {
+				msg = err.Error()
+			}
+			return stdout.Bytes(), stderr.Bytes(), &ConnectionError{Op: op, Machine: c.cfg.Name, Err: errors.New(msg)}
+		}
+	}
+	return stdout.Bytes(), stderr.Bytes(), err
+}
+
+// fileError converts a failed remote file operation into the same error
+// types LocalConnection returns.
+func (c *SSHConnection) fileError(op, p string, stderr []byte, err error) error {
+	var connErr *ConnectionError
+	if errors.As(err, &connErr) {
+		return err
+	}
+	msg := strings.TrimSpace(string(stderr))
+	switch {
+	case strings.Contains(msg, "No such file or directory"):
+		return &NotFoundError{Path: p}
+	case strings.Contains(msg, "Permission denied"):
+		return &PermissionError{Path: p, Op: op}
+	case msg != "":
+		return fmt.Errorf("%s %s on %s: %s", op, p, c.cfg.Name, msg)
+	default:
+		return fmt.Errorf("%s %s on %s: %w", op, p, c.cfg.Name, err)
+	}
+}
+
+// ReadFile reads the named file.
+func (c *SSHConnection) ReadFile(p string) ([]byte, error) {
+	stdout, stderr, err := c.run("read", nil, "cat -- "+shellQuote(p))
+	if err != nil {
+		return nil, c.fileError("read", p, stderr, err)
+	}
+	return stdout, nil
+}
+
+// WriteFile writes data to the named file.
+func (c *SSHConnection) WriteFile(p string, data []byte, perm fs.FileMode) error {
+	q := shellQuote(p)
+	script := fmt.Sprintf("cat > %s && chmod %o %s", q, perm.Perm(), q)
+	if _, stderr, err := c.run("write", data, script); err != nil {
+		return c.fileError("write", p, stderr, err)
+	}
+	return nil
+}
+
+// MkdirAll creates a directory and all parent directories.
+func (c *SSHConnection) MkdirAll(p string, perm fs.FileMode) error {
+	script := fmt.Sprintf("mkdir -p -m %o -- %s", perm.Perm(), shellQuote(p))
+	if _, stderr, err := c.run("mkdir", nil, script); err != nil {
+		return c.fileError("mkdir", p, stderr, err)
+	}
+	return nil
+}
+
+// Remove removes the named file or empty directory.
+// A missing path is not an error, matching LocalConnection.
+func (c *SSHConnection) Remove(p string) error {
+	q := shellQuote(p)
+	script := fmt.Sprintf("if [ -d %s ] && [ ! -L %s ]; then rmdir -- %s; else rm -f -- %s; fi", q, q, q, q)
+	if _, stderr, err := c.run("remove", nil, script); err != nil {
+		return c.fileError("remove", p, stderr, err)
+	}
+	return nil
+}
+
+// RemoveAll removes the named file or directory and any children.
+func (c *SSHConnection) RemoveAll(p string) error {
+	if _, stderr, err := c.run("remove", nil, "rm -rf -- "+shellQuote(p)); err != nil {
+		return c.fileError("remove", p, stderr, err)
+	}
+	return nil
+}
+
+// Stat returns file info for the named file.
+func (c *SSHConnection) Stat(p string) (FileInfo, error) {
+	// Size, raw st_mode in hex, and mtime: GNU stat first, BSD stat as fallback.
+	q := shellQuote(p)
+	script := fmt.Sprintf("stat -L -c '%%s %%f %%Y' -- %s 2>/dev/null || stat -L -f '%%z %%Xp %%m' -- %s", q, q)
+	stdout, stderr, err := c.run("stat", nil, script)
+	if err != nil {
+		return nil, c.fileError("stat", p, stderr, err)
+	}
+	return parseStat(p, string(stdout))
+}
+
+// parseStat parses "<size> <hex st_mode> <mtime>" into a FileInfo.
+func parseStat(p, out string) (FileInfo, error) {
+	fields := strings.Fields(out)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("stat %s: unexpected output %q", p, strings.TrimSpace(out))
+	}
+	size, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("stat %s: bad size %q", p, fields[0])
+	}
+	raw, err := strconv.ParseUint(fields[1], 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("stat %s: bad mode %q", p, fields[1])
+	}
+	mtime, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("stat %s: bad mtime %q", p, fields[2])
+	}
+
+	mode := unixModeToFileMode(uint32(raw))
+	return BasicFileInfo{
+		FileName:    path.Base(p),
+		FileSize:    size,
+		FileMode:    mode,
+		FileModTime: time.Unix(mtime, 0),
+		FileIsDir:   mode.IsDir(),
+	}, nil
+}
+
+// unixModeToFileMode converts a raw st_mode into an fs.FileMode.
+func unixModeToFileMode(m uint32) fs.FileMode {
+	mode := fs.FileMode(m & 0777)
+	switch m & 0170000 {
+	case 0040000:
+		mode |= fs.ModeDir
+	case 0120000:
+		mode |= fs.ModeSymlink
+	case 0010000:
+		mode |= fs.ModeNamedPipe
+	case 0140000:
+		mode |= fs.ModeSocket
+	case 0020000:
+		mode |= fs.ModeDevice | fs.ModeCharDevice
+	case 0060000:
+		mode |= fs.ModeDevice
+	}
+	if m&04000 != 0 {
+		mode |= fs.ModeSetuid
+	}
+	if m&02000 != 0 {
+		mode |= fs.ModeSetgid
+	}
+	if m&01000 != 0 {
+		mode |= fs.ModeSticky
+	}
+	return mode
+}
+
+// Glob returns the names of all files matching the pattern.
+// The pattern is expanded by the remote shell, so only the glob
+// metacharacters *, ? and [...] are special.
+func (c *SSHConnection) Glob(pattern string) ([]string, error) {
+	script := fmt.Sprintf(`for f in %s; do [ -e "$f" ] || [ -L "$f" ] && printf '%%s\n' "$f"; done; true`, globQuote(pattern))
+	stdout, stderr, err := c.run("glob", nil, script)
+	if err != nil {
+		return nil, c.fileError("glob", pattern, stderr, err)
+	}
+	return splitLines(string(stdout)), nil
+}
+
+// Exists returns true if the path exists.
+func (c *SSHConnection) Exists(p string) (bool, error) {
+	_, stderr, err := c.run("stat", nil, "test -e "+shellQuote(p))
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return false, nil
+		}
+		return false, c.fileError("stat", p, stderr, err)
+	}
+	return true, nil
+}
+
+// Exec runs a command and returns its combined output.
+func (c *SSHConnection) Exec(cmd string, args ...string) ([]byte, error) {
+	return c.execScript(shellJoin(cmd, args))
+}
+
+// ExecDir runs a command in the specified directory.
+func (c *SSHConnection) ExecDir(dir, cmd string, args ...string) ([]byte, error) {
+	return c.execScript("cd " + shellQuote(dir) + " && " + shellJoin(cmd, args))
+}
+
+// ExecEnv runs a command with additional environment variables.
+func (c *SSHConnection) ExecEnv(env map[string]string, cmd string, args ...string) ([]byte, error) {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := []string{"env"}
+	for _, k := range keys {
+		parts = append(parts, shellQuote(k+"="+env[k]))
+	}
+	return c.execScript(strings.Join(parts, " ") + " " + shellJoin(cmd, args))
+}
+
+// execScript runs a remote command line and returns its combined output,
+// like exec.Cmd.CombinedOutput does for LocalConnection.
+func (c *SSHConnection) execScript(script string) ([]byte, error) {
+	stdout, _, err := c.run("exec", nil, "exec 2>&1; "+script)
+	return stdout, err
+}
+
+// tmux runs a tmux command on the remote host and returns trimmed stdout,
+// mapping tmux failures to the errors the local tmux wrapper uses.
+func (c *SSHConnection) tmux(args ...string) (string, error) {
+	stdout, stderr, err := c.run("tmux", nil, shellJoin("tmux", args))
+	if err != nil {
+		var connErr *ConnectionError
+		if errors.As(err, &connErr) {
+			return "", err
+		}
+		msg := strings.TrimSpace(string(stderr))
+		switch {
+		case strings.Contains(msg, "no server running"), strings.Contains(msg, "error connecting to"):
+			return "", tmux.ErrNoServer
+		case strings.Contains(msg, "duplicate session"):
+			return "", tmux.ErrSessionExists
+		case strings.Contains(msg, "session not found"), strings.Contains(msg, "can't find session"):
+			return "", tmux.ErrSessionNotFound
+		case msg != "":
+			return "", fmt.Errorf("tmux %s on %s: %s", args[0], c.cfg.Name, msg)
+		default:
+			return "", fmt.Errorf("tmux %s on %s: %w", args[0], c.cfg.Name, err)
+		}
+	}
+	return strings.TrimSpace(string(stdout)), nil
+}
+
+// TmuxNewSession creates a new tmux session on the remote host.
+func (c *SSHConnection) TmuxNewSession(name, dir string) error {
+	args := []string{"new-session", "-d", "-s", name}
+	if dir != "" {
+		args = append(args, "-c", dir)
+	}
+	_, err := c.tmux(args...)
+	return err
+}
+
+// TmuxKillSession terminates a tmux session on the remote host.
+func (c *SSHConnection) TmuxKillSession(name string) error {
+	_, err := c.tmux("kill-session", "-t", name)
+	return err
+}
+
+// TmuxSendKeys sends keys followed by Enter, debounced like the local wrapper.
+func (c *SSHConnection) TmuxSendKeys(session, keys string) error {
+	if _, err := c.tmux("send-keys", "-t", session, "-l", keys); err != nil {
+		return err
+	}
+	time.Sleep(time.Duration(constants.DefaultDebounceMs) * time.Millisecond)
+	_, err := c.tmux("send-keys", "-t", session, "Enter")
+	return err
+}
+
+// TmuxCapturePane captures the last N lines from a tmux pane.
+func (c *SSHConnection) TmuxCapturePane(session string, lines int) (string, error) {
+	return c.tmux("capture-pane", "-p", "-t", session, "-S", fmt.Sprintf("-%d", lines))
+}
+
+// TmuxHasSession returns true if the session exists.
+func (c *SSHConnection) TmuxHasSession(name string) (bool, error) {
+	_, err := c.tmux("has-session", "-t", "="+name)
+	if err != nil {
+		if errors.Is(err, tmux.ErrSessionNotFound) || errors.Is(err, tmux.ErrNoServer) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// TmuxListSessions returns all tmux session names on the remote host.
+func (c *SSHConnection) TmuxListSessions() ([]string, error) {
+	out, err := c.tmux("list-sessions", "-F", "#{session_name}")
+	if err != nil {
+		if errors.Is(err, tmux.ErrNoServer) {
+			return nil, nil // No server = no sessions
+		}
+		return nil, err
+	}
+	return splitLines(out), nil
+}
+
+// Close shuts down the ControlMaster, if one is running.
+func (c *SSHConnection) Close() error {
+	args := append(c.sshArgs(), "-O", "exit", "--", c.cfg.Host)
+	out, err := exec.Command(c.cfg.Binary, args...).CombinedOutput() //nolint:gosec // G204: see run
+	if err != nil {
+		msg := string(out)
+		if strings.Contains(msg, "No such file or directory") || strings.Contains(msg, "Control socket connect") {
+			return nil // No master running
+		}
+		return &ConnectionError{Op: "close", Machine: c.cfg.Name, Err: fmt.Errorf("%s", strings.TrimSpace(msg))}
+	}
+	return nil
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("/._-=:,+@%", r))
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// shellJoin quotes a command and its arguments into a shell command line.
+func shellJoin(cmd string, args []string) string {
+	parts := make([]string, 0, len(args)+1)
+	parts = append(parts, shellQuote(cmd))
+	for _, a := range args {
+		parts = append(parts, shellQuote(a))
+	}
+	return strings.Join(parts, " ")
+}
+
+// globQuote escapes everything in a glob pattern except the glob
+// metacharacters, so the remote shell expands the pattern and nothing else.
+func globQuote(pattern string) string {
+	var sb strings.Builder
+	for _, r := range pattern {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9',
+			strings.ContainsRune("/._-*?[]", r):
+			sb.WriteRune(r)
+		case r == '\n':
+			sb.WriteString("'\n'") // A backslash-newline would be a line continuation
+		default:
+			sb.WriteRune('\\')
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// splitLines splits command output into non-empty lines.
+func splitLines(s string) []string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// Verify SSHConnection implements Connection.
+var _ Connection = (*SSHConnection)(nil)
@@ -0,0 +1,263 @@
+package connection
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSSH writes an ssh stand-in that skips options up to "--", drops the
+// host, and runs the remote command line with the local shell. It records
+// each invocation's arguments to log.
+func fakeSSH(t *testing.T) (binary, log string) {
+	t.Helper()
+	dir := t.TempDir()
+	binary = filepath.Join(dir, "ssh")
+	log = filepath.Join(dir, "ssh.log")
+	script := `#!/bin/sh
+printf '%s\n' "$*" >> "` + log + `"
+while [ $# -gt 0 ]; do
+	case "$1" in
+		--) shift; break ;;
+		-O) shift 2 ;;
+		*) shift ;;
+	esac
+done
+if [ "$1" = "unreachable" ]; then
+	echo "ssh: connect to host unreachable port 22: Connection refused" >&2
+	exit 255
+fi
+shift
+[ $# -eq 0 ] && exit 0
+exec sh -c "$1"
+`
+	if err := os.WriteFile(binary, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return binary, log
+}
+
+func newTestSSH(t *testing.T) (*SSHConnection, string) {
+	t.Helper()
+	binary, log := fakeSSH(t)
+	return NewSSHConnection(SSHConfig{Name: "buildbox", Host: "me@buildbox", KeyPath: "/keys/id", Binary: binary}), log
+}
+
+func TestSSHConnection_Identity(t *testing.T) {
+	c, log := newTestSSH(t)
+	if c.Name() != "buildbox" || c.IsLocal() {
+		t.Errorf("Name/IsLocal = %q/%v", c.Name(), c.IsLocal())
+	}
+
+	if _, err := c.Exec("true"); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(log)
+	args := string(data)
+	for _, want := range []string{"ControlMaster=auto", "ControlPath=", "ControlPersist=600s", "BatchMode=yes", "-i /keys/id", "-- me@buildbox exec 2>&1; true"} {
+		if !strings.Contains(args, want) {
+			t.Errorf("ssh args %q missing %q", args, want)
+		}
+	}
+}
+
+func TestSSHConnection_FileOps(t *testing.T) {
+	c, _ := newTestSSH(t)
+	dir := filepath.Join(t.TempDir(), "it's a dir")
+
+	if err := c.MkdirAll(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	file := filepath.Join(dir, "sub", "hello $world.txt")
+	if err := c.WriteFile(file, []byte("hi\nthere\n"), 0600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	got, err := c.ReadFile(file)
+	if err != nil || string(got) != "hi\nthere\n" {
+		t.Fatalf("ReadFile = %q, %v", got, err)
+	}
+
+	fi, err := c.Stat(file)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if fi.Name() != "hello $world.txt" || fi.Size() != 9 || fi.IsDir() || fi.Mode().Perm() != 0600 {
+		t.Errorf("Stat = %+v", fi)
+	}
+	if time.Since(fi.ModTime()) > time.Minute {
+		t.Errorf("ModTime = %v", fi.ModTime())
+	}
+	if fi, err := c.Stat(dir); err != nil || !fi.IsDir() {
+		t.Errorf("Stat(dir) = %+v, %v", fi, err)
+	}
+
+	matches, err := c.Glob(filepath.Join(dir, "sub", "*.txt"))
+	if err != nil || len(matches) != 1 || matches[0] != file {
+		t.Errorf("Glob = %v, %v", matches, err)
+	}
+	if matches, err := c.Glob(filepath.Join(dir, "*.none")); err != nil || len(matches) != 0 {
+		t.Errorf("Glob(no match) = %v, %v", matches, err)
+	}
+
+	if ok, err := c.Exists(file); err != nil || !ok {
+		t.Errorf("Exists = %v, %v", ok, err)
+	}
+	if err := c.Remove(file); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if ok, err := c.Exists(file); err != nil || ok {
+		t.Errorf("Exists after Remove = %v, %v", ok, err)
+	}
+	if err := c.Remove(file); err != nil {
+		t.Errorf("Remove(missing) = %v, want nil", err)
+	}
+
+	var notFound *NotFoundError
+	if _, err := c.ReadFile(file); !errors.As(err, &notFound) {
+		t.Errorf("ReadFile(missing) err = %v, want NotFoundError", err)
+	}
+	if _, err := c.Stat(file); !errors.As(err, &notFound) {
+		t.Errorf("Stat(missing) err = %v, want NotFoundError", err)
+	}
+
+	if err := c.RemoveAll(dir); err != nil {
+		t.Fatalf("RemoveAll: %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("dir still exists after RemoveAll")
+	}
+}
+
+func TestSSHConnection_Exec(t *testing.T) {
+	c, _ := newTestSSH(t)
+	dir := t.TempDir()
+
+	out, err := c.Exec("printf", "%s|", "a b", "it's", "$HOME")
+	if err != nil || string(out) != "a b|it's|$HOME|" {
+		t.Errorf("Exec = %q, %v", out, err)
+	}
+
+	out, err = c.ExecDir(dir, "pwd")
+	if err != nil || strings.TrimSpace(string(out)) != dir {
+		t.Errorf("ExecDir = %q, %v", out, err)
+	}
+
+	out, err = c.ExecEnv(map[string]string{"GT_A": "1", "GT_B": "two words"}, "sh", "-c", `echo "$GT_A $GT_B"`)
+	if err != nil || strings.TrimSpace(string(out)) != "1 two words" {
+		t.Errorf("ExecEnv = %q, %v", out, err)
+	}
+
+	// Combined output, and the remote exit status surfaces as an ExitError.
+	out, err = c.Exec("sh", "-c", "echo out; echo err >&2; exit 3")
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+		t.Errorf("Exec err = %v, want exit status 3", err)
+	}
+	if string(out) != "out\nerr\n" {
+		t.Errorf("Exec output = %q", out)
+	}
+}
+
+func TestSSHConnection_Unreachable(t *testing.T) {
+	binary, _ := fakeSSH(t)
+	c := NewSSHConnection(SSHConfig{Name: "down", Host: "unreachable", Binary: binary})
+
+	var connErr *ConnectionError
+	if _, err := c.Exec("true"); !errors.As(err, &connErr) || connErr.Machine != "down" {
+		t.Errorf("Exec err = %v, want ConnectionError", err)
+	}
+	if _, err := c.Exists("/tmp"); !errors.As(err, &connErr) {
+		t.Errorf("Exists err = %v, want ConnectionError", err)
+	}
+	if _, err := c.TmuxHasSession("x"); !errors.As(err, &connErr) {
+		t.Errorf("TmuxHasSession err = %v, want ConnectionError", err)
+	}
+}
+
+func TestSSHConnection_Tmux(t *testing.T) {
+	if _, err := exec.LookPath("tmux"); err != nil {
+		t.Skip("tmux not installed")
+	}
+	c, _ := newTestSSH(t)
+	session := "gt-ssh-test-" + strings.ReplaceAll(filepath.Base(t.TempDir()), "_", "-")
+
+	if ok, err := c.TmuxHasSession(session); err != nil || ok {
+		t.Fatalf("TmuxHasSession before create = %v, %v", ok, err)
+	}
+	if err := c.TmuxNewSession(session, t.TempDir()); err != nil {
+		t.Fatalf("TmuxNewSession: %v", err)
+	}
+	defer func() { _ = c.TmuxKillSession(session) }()
+
+	if ok, err := c.TmuxHasSession(session); err != nil || !ok {
+		t.Errorf("TmuxHasSession = %v, %v", ok, err)
+	}
+	sessions, err := c.TmuxListSessions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(sessions)
+	if i := sort.SearchStrings(sessions, session); i == len(sessions) || sessions[i] != session {
+		t.Errorf("TmuxListSessions = %v, missing %s", sessions, session)
+	}
+
+	if err := c.TmuxSendKeys(session, "echo gt-marker-$((6*7))"); err != nil {
+		t.Fatalf("TmuxSendKeys: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		out, err := c.TmuxCapturePane(session, 50)
+		if err == nil && strings.Contains(out, "gt-marker-42") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("pane never showed command output: %q, %v", out, err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	if err := c.TmuxKillSession(session); err != nil {
+		t.Fatalf("TmuxKillSession: %v", err)
+	}
+	if ok, _ := c.TmuxHasSession(session); ok {
+		t.Error("session still exists after kill")
+	}
+}
+
+func TestMachineRegistry_SSHConnection(t *testing.T) {
+	r, err := NewMachineRegistry(filepath.Join(t.TempDir(), "machines.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Add(&Machine{Name: "buildbox", Type: "ssh", Host: "me@buildbox"}); err != nil {
+		t.Fatal(err)
+	}
+	conn, err := r.Connection("buildbox")
+	if err != nil {
+		t.Fatalf("Connection: %v", err)
+	}
+	if conn.IsLocal() || conn.Name() != "buildbox" {
+		t.Errorf("Connection = %s (local=%v)", conn.Name(), conn.IsLocal())
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := map[string]string{
+		"plain/path-1.txt": "plain/path-1.txt",
+		"":                 "''",
+		"a b":              "'a b'",
+		"it's":             `'it'\''s'`,
+		"$HOME":            "'$HOME'",
+	}
+	for in, want := range tests {
+		if got := shellQuote(in); got != want {
+			t.Errorf("shellQuote(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package costs
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func TestParseTranscript_Claude(t *testing.T) {
+	// Two lines for the same message (one per content block) must be counted once.
+	transcript := strings.Join([]string{
+		`{"type":"user","message":{"role":"user","content":"hi"}}`,
+		`{"type":"assistant","message":{"id":"msg_1","model":"claude-sonnet-4-5","usage":{"input_tokens":10,"output_tokens":5,"cache_creation_input_tokens":100,"cache_read_input_tokens":1000}}}`,
+		`{"type":"assistant","message":{"id":"msg_1","model":"claude-sonnet-4-5","usage":{"input_tokens":10,"output_tokens":20,"cache_creation_input_tokens":100,"cache_read_input_tokens":1000}}}`,
+		`not json`,
+		`{"type":"assistant","message":{"id":"msg_2","model":"claude-sonnet-4-5","usage":{"input_tokens":1,"output_tokens":2}}}`,
+		`{"type":"assistant","message":{"id":"msg_3","model":"<synthetic>","usage":{"input_tokens":0,"output_tokens":0}}}`,
+	}, "\n")
+
+	usage, err := ParseTranscript(strings.NewReader(transcript))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Usage{InputTokens: 11, OutputTokens: 22, CacheCreationTokens: 100, CacheReadTokens: 1000}
+	if got := usage["claude-sonnet-4-5"]; got != want {
+		t.Errorf("usage = %+v, want %+v", got, want)
+	}
+	if _, ok := usage["<synthetic>"]; ok {
+		t.Error("synthetic model with zero usage should be dropped")
+	}
+}
+
+func TestParseTranscript_Codex(t *testing.T) {
+	// token_count events are cumulative; only the last one counts.
+	transcript := strings.Join([]string{
+		`{"type":"turn_context","payload":{"type":"turn_context","model":"gpt-5-codex"}}`,
+		`{"type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":100,"cached_input_tokens":40,"output_tokens":10}}}}`,
+		`{"type":"event_msg","payload":{"type":"token_count","info":null}}`,
+		`{"type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":300,"cached_input_tokens":200,"output_tokens":30}}}}`,
+	}, "\n")
+
+	usage, err := ParseTranscript(strings.NewReader(transcript))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Usage{InputTokens: 100, OutputTokens: 30, CacheReadTokens: 200}
+	if got := usage["gpt-5-codex"]; got != want {
+		t.Errorf("usage = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseTranscript_Gemini(t *testing.T) {
+	transcript := strings.Join([]string{
+		`{"modelVersion":"gemini-2.5-pro","usageMetadata":{"promptTokenCount":50,"candidatesTokenCount":5,"cachedContentTokenCount":20}}`,
+		`{"modelVersion":"gemini-2.5-pro","usageMetadata":{"promptTokenCount":10,"candidatesTokenCount":1}}`,
+	}, "\n")
+
+	usage, err := ParseTranscript(strings.NewReader(transcript))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Usage{InputTokens: 40, OutputTokens: 6, CacheReadTokens: 20}
+	if got := usage["gemini-2.5-pro"]; got != want {
+		t.Errorf("usage = %+v, want %+v", got, want)
+	}
+}
+
+func TestPriceTable(t *testing.T) {
+	table := NewPriceTable(map[string]config.ModelPrice{
+		"claude-sonnet-4-5": {Input: 1, Output: 2},
+	})
+
+	// Longest prefix wins: the override beats the built-in claude-sonnet-4 entry.
+	if p, ok := table.Lookup("claude-sonnet-4-5-20250929"); !ok || p.Input != 1 {
+		t.Errorf("Lookup(claude-sonnet-4-5-...) = %+v, %v", p, ok)
+	}
+	if p, ok := table.Lookup("claude-sonnet-4-20250514"); !ok || p.Input != 3 {
+		t.Errorf("Lookup(claude-sonnet-4-...) = %+v, %v", p, ok)
+	}
+
+	cost, unpriced := table.Cost(ModelUsage{
+		"claude-sonnet-4-20250514": {InputTokens: 1_000_000, OutputTokens: 1_000_000, CacheCreationTokens: 1_000_000, CacheReadTokens: 1_000_000},
+		"mystery-model":            {InputTokens: 500},
+	})
+	if want := 3 + 15 + 3.75 + 0.30; math.Abs(cost-want) > 1e-9 {
+		t.Errorf("Cost = %v, want %v", cost, want)
+	}
+	if len(unpriced) != 1 || unpriced[0] != "mystery-model" {
+		t.Errorf("unpriced = %v, want [mystery-model]", unpriced)
+	}
+}
+
+func TestCursorAdvance(t *testing.T) {
+	path := CursorPath(t.TempDir(), "gt-gastown-nux")
+
+	c, err := LoadCursor(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := ModelUsage{"m": {InputTokens: 10, OutputTokens: 5}}
+	if d := c.Advance("a.jsonl", first); d["m"] != first["m"] {
+		t.Errorf("first delta = %+v, want %+v", d, first)
+	}
+	if err := c.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err = LoadCursor(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second := ModelUsage{"m": {InputTokens: 25, OutputTokens: 5}}
+	d := c.Advance("a.jsonl", second)
+	if want := (Usage{InputTokens: 15}); d["m"] != want {
+		t.Errorf("second delta = %+v, want %+v", d["m"], want)
+	}
+	if d := c.Advance("a.jsonl", second); len(d) != 0 {
+		t.Errorf("repeat delta = %+v, want empty", d)
+	}
+
+	// A new transcript (session restart) starts from zero.
+	third := ModelUsage{"m": {InputTokens: 3}}
+	if d := c.Advance("b.jsonl", third); d["m"] != third["m"] {
+		t.Errorf("new transcript delta = %+v, want %+v", d, third)
+	}
+}
+
+func TestLocatorFind(t *testing.T) {
+	l := &Locator{ClaudeConfigDir: t.TempDir(), CodexHome: t.TempDir()}
+	workDir := "/home/me/gt/gastown/polecats/nux"
+
+	projectDir := l.ClaudeProjectDir(workDir)
+	if want := filepath.Join(l.ClaudeConfigDir, "projects", "-home-me-gt-gastown-polecats-nux"); projectDir != want {
+		t.Errorf("ClaudeProjectDir = %q, want %q", projectDir, want)
+	}
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	session := filepath.Join(projectDir, "abc-123.jsonl")
+	if err := os.WriteFile(session, []byte("{}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := l.Find("abc-123", workDir); err != nil || got != session {
+		t.Errorf("Find(id) = %q, %v", got, err)
+	}
+	if got, err := l.Find("", workDir); err != nil || got != session {
+		t.Errorf("Find(no id) = %q, %v", got, err)
+	}
+	if _, err := l.Find("missing", "/elsewhere"); err != ErrTranscriptNotFound {
+		t.Errorf("Find(missing) err = %v, want ErrTranscriptNotFound", err)
+	}
+
+	codexDir := filepath.Join(l.CodexHome, "sessions", "2025", "01", "02")
+	if err := os.MkdirAll(codexDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	rollout := filepath.Join(codexDir, "rollout-2025-01-02T03-04-05-xyz-789.jsonl")
+	if err := os.WriteFile(rollout, []byte("{}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := l.Find("xyz-789", ""); err != nil || got != rollout {
+		t.Errorf("Find(codex) = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,71 @@
+package costs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/constants"
+)
+
+// Cursor remembers how much of a session's transcript has already been
+// recorded. The Stop hook fires at the end of every turn, so each
+// `gt costs record` only records the usage added since the last one.
+type Cursor struct {
+	Transcript string     `json:"transcript"`
+	Usage      ModelUsage `json:"usage"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// CursorPath returns the cursor file for a session: <town>/.runtime/costs/<session>.json.
+func CursorPath(townRoot, session string) string {
+	return filepath.Join(constants.TownRuntimePath(townRoot), "costs", session+".json")
+}
+
+// LoadCursor loads a session's cursor. A missing file returns an empty cursor.
+func LoadCursor(path string) (*Cursor, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Cursor{Usage: make(ModelUsage)}, nil
+		}
+		return nil, fmt.Errorf("reading cost cursor: %w", err)
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("parsing cost cursor: %w", err)
+	}
+	if c.Usage == nil {
+		c.Usage = make(ModelUsage)
+	}
+	return &c, nil
+}
+
+// Save writes the cursor to path.
+func (c *Cursor) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating cost cursor dir: %w", err)
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding cost cursor: %w", err)
+	}
+	return os.WriteFile(path, data, 0644) //nolint:gosec // G306: token counts are not sensitive
+}
+
+// Advance returns usage not yet covered by the cursor and moves the cursor
+// to cover all of usage. A different transcript (new session after a
+// handoff or restart) starts from zero.
+func (c *Cursor) Advance(transcript string, usage ModelUsage) ModelUsage {
+	prev := c.Usage
+	if c.Transcript != transcript {
+		prev = nil
+	}
+	delta := usage.Sub(prev)
+	c.Transcript = transcript
+	c.Usage = usage
+	c.UpdatedAt = time.Now()
+	return delta
+}
@@ -0,0 +1,78 @@
+package costs
+
+import (
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// DefaultPrices is the built-in price table in USD per million tokens,
+// keyed by model name prefix. Override or extend it with model_prices in
+// the town settings (settings/config.json).
+var DefaultPrices = map[string]config.ModelPrice{
+	// Anthropic
+	"claude-opus-4-5":   {Input: 5, Output: 25, CacheWrite: 6.25, CacheRead: 0.50},
+	"claude-opus-4":     {Input: 15, Output: 75, CacheWrite: 18.75, CacheRead: 1.50},
+	"claude-sonnet-4":   {Input: 3, Output: 15, CacheWrite: 3.75, CacheRead: 0.30},
+	"claude-haiku-4-5":  {Input: 1, Output: 5, CacheWrite: 1.25, CacheRead: 0.10},
+	"claude-3-7-sonnet": {Input: 3, Output: 15, CacheWrite: 3.75, CacheRead: 0.30},
+	"claude-3-5-sonnet": {Input: 3, Output: 15, CacheWrite: 3.75, CacheRead: 0.30},
+	"claude-3-5-haiku":  {Input: 0.80, Output: 4, CacheWrite: 1, CacheRead: 0.08},
+
+	// OpenAI
+	"gpt-5":      {Input: 1.25, Output: 10, CacheRead: 0.125},
+	"gpt-5-mini": {Input: 0.25, Output: 2, CacheRead: 0.025},
+
+	// Google
+	"gemini-2.5-pro":   {Input: 1.25, Output: 10, CacheRead: 0.31},
+	"gemini-2.5-flash": {Input: 0.30, Output: 2.50, CacheRead: 0.075},
+}
+
+// PriceTable resolves model names to prices.
+type PriceTable map[string]config.ModelPrice
+
+// NewPriceTable returns the default prices with overrides applied on top.
+func NewPriceTable(overrides map[string]config.ModelPrice) PriceTable {
+	table := make(PriceTable, len(DefaultPrices)+len(overrides))
+	for k, v := range DefaultPrices {
+		table[k] = v
+	}
+	for k, v := range overrides {
+		table[k] = v
+	}
+	return table
+}
+
+// Lookup returns the price for a model using the longest matching prefix.
+func (p PriceTable) Lookup(model string) (config.ModelPrice, bool) {
+	best := ""
+	for prefix := range p {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return config.ModelPrice{}, false
+	}
+	return p[best], true
+}
+
+// Cost returns the USD cost of usage, plus the models that had no price.
+// Unpriced models contribute nothing to the total.
+func (p PriceTable) Cost(usage ModelUsage) (float64, []string) {
+	var total float64
+	var unpriced []string
+	for _, model := range usage.Models() {
+		price, ok := p.Lookup(model)
+		if !ok {
+			unpriced = append(unpriced, model)
+			continue
+		}
+		u := usage[model]
+		total += (float64(u.InputTokens)*price.Input +
+			float64(u.OutputTokens)*price.Output +
+			float64(u.CacheCreationTokens)*price.CacheWrite +
+			float64(u.CacheReadTokens)*price.CacheRead) / 1_000_000
+	}
+	return total, unpriced
+}
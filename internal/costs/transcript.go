@@ -0,0 +1,306 @@
+// Package costs derives agent session token usage and cost from the
+// per-session JSONL transcripts that agent runtimes write to disk.
+//
+// Supported transcript formats:
+//   - Claude Code: ~/.claude/projects/<escaped-cwd>/<session-id>.jsonl
+//   - Codex: ~/.codex/sessions/YYYY/MM/DD/rollout-*-<session-id>.jsonl
+//   - Gemini-style lines carrying a usageMetadata object
+package costs
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// ErrTranscriptNotFound is returned when no transcript matches a session.
+var ErrTranscriptNotFound = errors.New("transcript not found")
+
+// Usage holds token counts for one model.
+type Usage struct {
+	InputTokens         int64 `json:"input_tokens"`
+	OutputTokens        int64 `json:"output_tokens"`
+	CacheCreationTokens int64 `json:"cache_creation_tokens"`
+	CacheReadTokens     int64 `json:"cache_read_tokens"`
+}
+
+// Add returns the sum of two usages.
+func (u Usage) Add(o Usage) Usage {
+	return Usage{
+		InputTokens:         u.InputTokens + o.InputTokens,
+		OutputTokens:        u.OutputTokens + o.OutputTokens,
+		CacheCreationTokens: u.CacheCreationTokens + o.CacheCreationTokens,
+		CacheReadTokens:     u.CacheReadTokens + o.CacheReadTokens,
+	}
+}
+
+// Sub returns u minus o, clamping each count at zero.
+func (u Usage) Sub(o Usage) Usage {
+	clamp := func(n int64) int64 {
+		if n < 0 {
+			return 0
+		}
+		return n
+	}
+	return Usage{
+		InputTokens:         clamp(u.InputTokens - o.InputTokens),
+		OutputTokens:        clamp(u.OutputTokens - o.OutputTokens),
+		CacheCreationTokens: clamp(u.CacheCreationTokens - o.CacheCreationTokens),
+		CacheReadTokens:     clamp(u.CacheReadTokens - o.CacheReadTokens),
+	}
+}
+
+// IsZero reports whether no tokens were used.
+func (u Usage) IsZero() bool {
+	return u == Usage{}
+}
+
+// ModelUsage maps model names to their token usage.
+type ModelUsage map[string]Usage
+
+// Total sums usage across all models.
+func (m ModelUsage) Total() Usage {
+	var total Usage
+	for _, u := range m {
+		total = total.Add(u)
+	}
+	return total
+}
+
+// Sub returns per-model usage of m minus prev (usage already accounted for).
+// Models with no remaining usage are omitted.
+func (m ModelUsage) Sub(prev ModelUsage) ModelUsage {
+	delta := make(ModelUsage)
+	for model, u := range m {
+		if d := u.Sub(prev[model]); !d.IsZero() {
+			delta[model] = d
+		}
+	}
+	return delta
+}
+
+// Models returns the model names in sorted order.
+func (m ModelUsage) Models() []string {
+	models := make([]string, 0, len(m))
+	for model := range m {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+	return models
+}
+
+// transcriptLine covers the fields we need from every supported format.
+type transcriptLine struct {
+	// Claude Code
+	Type    string `json:"type"`
+	Message *struct {
+		ID    string `json:"id"`
+		Model string `json:"model"`
+		Usage *struct {
+			InputTokens              int64 `json:"input_tokens"`
+			OutputTokens             int64 `json:"output_tokens"`
+			CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+			CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+		} `json:"usage"`
+	} `json:"message"`
+
+	// Codex
+	Payload *struct {
+		Type  string `json:"type"`
+		Model string `json:"model"`
+		Info  *struct {
+			TotalTokenUsage *struct {
+				InputTokens       int64 `json:"input_tokens"`
+				CachedInputTokens int64 `json:"cached_input_tokens"`
+				OutputTokens      int64 `json:"output_tokens"`
+			} `json:"total_token_usage"`
+		} `json:"info"`
+	} `json:"payload"`
+
+	// Gemini
+	ModelVersion  string `json:"modelVersion"`
+	UsageMetadata *struct {
+		PromptTokenCount        int64 `json:"promptTokenCount"`
+		CandidatesTokenCount    int64 `json:"candidatesTokenCount"`
+		CachedContentTokenCount int64 `json:"cachedContentTokenCount"`
+	} `json:"usageMetadata"`
+}
+
+// ParseTranscript sums token usage per model from a JSONL transcript.
+// Malformed lines are skipped; transcripts are appended to while we read them.
+func ParseTranscript(r io.Reader) (ModelUsage, error) {
+	usage := make(ModelUsage)
+
+	// Claude Code writes one line per content block, each repeating the
+	// message's usage, so count each message ID once (last line wins).
+	claudeByID := make(map[string]Usage)
+	claudeModel := make(map[string]string)
+
+	// Codex reports cumulative totals; only the last report counts.
+	var codexTotal Usage
+	codexModel := ""
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	anon := 0
+	for scanner.Scan() {
+		var line transcriptLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			continue
+		}
+
+		switch {
+		case line.Message != nil && line.Message.Usage != nil:
+			u := line.Message.Usage
+			id := line.Message.ID
+			if id == "" {
+				anon++
+				id = fmt.Sprintf("anon-%d", anon)
+			}
+			claudeByID[id] = Usage{
+				InputTokens:         u.InputTokens,
+				OutputTokens:        u.OutputTokens,
+				CacheCreationTokens: u.CacheCreationInputTokens,
+				CacheReadTokens:     u.CacheReadInputTokens,
+			}
+			claudeModel[id] = line.Message.Model
+
+		case line.Payload != nil && line.Payload.Type == "turn_context":
+			codexModel = line.Payload.Model
+
+		case line.Payload != nil && line.Payload.Type == "token_count" &&
+			line.Payload.Info != nil && line.Payload.Info.TotalTokenUsage != nil:
+			t := line.Payload.Info.TotalTokenUsage
+			// Codex input_tokens includes the cached portion.
+			codexTotal = Usage{
+				InputTokens:     t.InputTokens - t.CachedInputTokens,
+				OutputTokens:    t.OutputTokens,
+				CacheReadTokens: t.CachedInputTokens,
+			}
+
+		case line.UsageMetadata != nil:
+			m := line.UsageMetadata
+			usage[line.ModelVersion] = usage[line.ModelVersion].Add(Usage{
+				InputTokens:     m.PromptTokenCount - m.CachedContentTokenCount,
+				OutputTokens:    m.CandidatesTokenCount,
+				CacheReadTokens: m.CachedContentTokenCount,
+			})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading transcript: %w", err)
+	}
+
+	for id, u := range claudeByID {
+		model := claudeModel[id]
+		usage[model] = usage[model].Add(u)
+	}
+	if !codexTotal.IsZero() {
+		usage[codexModel] = usage[codexModel].Add(codexTotal)
+	}
+
+	// Synthetic entries (e.g., "<synthetic>" messages) carry no real usage.
+	for model, u := range usage {
+		if u.IsZero() {
+			delete(usage, model)
+		}
+	}
+	return usage, nil
+}
+
+// ParseTranscriptFile parses the transcript at path.
+func ParseTranscriptFile(path string) (ModelUsage, error) {
+	f, err := os.Open(path) //nolint:gosec // G304: path comes from the transcript locator
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseTranscript(f)
+}
+
+// Locator finds transcript files for agent sessions.
+type Locator struct {
+	// ClaudeConfigDir is the Claude Code config dir (CLAUDE_CONFIG_DIR or ~/.claude).
+	ClaudeConfigDir string
+
+	// CodexHome is the Codex home dir (CODEX_HOME or ~/.codex).
+	CodexHome string
+}
+
+// NewLocator returns a Locator using the current environment.
+func NewLocator() *Locator {
+	home, _ := os.UserHomeDir()
+	l := &Locator{
+		ClaudeConfigDir: os.Getenv("CLAUDE_CONFIG_DIR"),
+		CodexHome:       os.Getenv("CODEX_HOME"),
+	}
+	if l.ClaudeConfigDir == "" {
+		l.ClaudeConfigDir = filepath.Join(home, ".claude")
+	}
+	if l.CodexHome == "" {
+		l.CodexHome = filepath.Join(home, ".codex")
+	}
+	return l
+}
+
+// unsafeProjectChars matches characters Claude Code replaces in project dir names.
+var unsafeProjectChars = regexp.MustCompile(`[^a-zA-Z0-9]`)
+
+// ClaudeProjectDir returns the Claude Code project directory for a working dir.
+// Claude Code names it after the absolute cwd with non-alphanumerics as dashes.
+func (l *Locator) ClaudeProjectDir(workDir string) string {
+	return filepath.Join(l.ClaudeConfigDir, "projects", unsafeProjectChars.ReplaceAllString(workDir, "-"))
+}
+
+// Find returns the transcript path for a session.
+//
+// With a session ID, it looks for <id>.jsonl in the Claude project dir for
+// workDir, then in any Claude project, then for a Codex rollout containing
+// the ID. Without one (e.g., runtimes that don't export a session ID env
+// var), it falls back to the most recently written transcript for workDir.
+func (l *Locator) Find(sessionID, workDir string) (string, error) {
+	if sessionID != "" {
+		if workDir != "" {
+			path := filepath.Join(l.ClaudeProjectDir(workDir), sessionID+".jsonl")
+			if _, err := os.Stat(path); err == nil {
+				return path, nil
+			}
+		}
+		if matches, _ := filepath.Glob(filepath.Join(l.ClaudeConfigDir, "projects", "*", sessionID+".jsonl")); len(matches) > 0 {
+			return newest(matches), nil
+		}
+		if matches, _ := filepath.Glob(filepath.Join(l.CodexHome, "sessions", "*", "*", "*", "*"+sessionID+".jsonl")); len(matches) > 0 {
+			return newest(matches), nil
+		}
+	}
+
+	if workDir != "" {
+		if matches, _ := filepath.Glob(filepath.Join(l.ClaudeProjectDir(workDir), "*.jsonl")); len(matches) > 0 {
+			return newest(matches), nil
+		}
+	}
+
+	return "", ErrTranscriptNotFound
+}
+
+// newest returns the most recently modified path.
+func newest(paths []string) string {
+	best := ""
+	var bestMod int64
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			continue
+		}
+		if mod := info.ModTime().UnixNano(); best == "" || mod > bestMod {
+			best, bestMod = p, mod
+		}
+	}
+	return best
+}
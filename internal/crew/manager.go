@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/cgroup"
 	"github.com/steveyegge/gastown/internal/claude"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/git"
@@ -183,7 +184,7 @@ func (m *Manager) Add(name string, createBranch bool) (*CrewWorker, error) {
 
 	// Copy overlay files from .runtime/overlay/ to crew root.
 	// This allows services to have .env and other config files at their root.
-	if err := rig.CopyOverlay(m.rig.Path, crewPath); err != nil {
+	if err := rig.CopyWorkerOverlay(m.rig.Path, "crew", name, crewPath); err != nil {
 		// Non-fatal - log warning but continue
 		fmt.Printf("Warning: could not copy overlay files: %v\n", err)
 	}
@@ -210,6 +211,7 @@ func (m *Manager) Add(name string, createBranch bool) (*CrewWorker, error) {
 	// Save state
 	if err := m.saveState(crew); err != nil {
 		_ = os.RemoveAll(crewPath) // best-effort cleanup
+		_ = rig.ReleaseOverlaySlot(m.rig.Path, "crew", name)
 		return nil, fmt.Errorf("saving state: %w", err)
 	}
 
@@ -240,6 +242,9 @@ func (m *Manager) Remove(name string, force bool) error {
 		return fmt.Errorf("removing crew dir: %w", err)
 	}
 
+	// Free the worker's overlay port block (non-fatal: state file update)
+	_ = rig.ReleaseOverlaySlot(m.rig.Path, "crew", name)
+
 	return nil
 }
 
@@ -514,6 +519,12 @@ func (m *Manager) Start(name string, opts StartOptions) error {
 		claudeCmd = strings.Replace(claudeCmd, " --dangerously-skip-permissions", "", 1)
 	}
 
+	// Run in a cgroup scope when the rig caps crew resources
+	limits := config.ResolveResourceLimits(filepath.Dir(m.rig.Path), m.rig.Path, "crew")
+	if claudeCmd, err = cgroup.WrapSession(sessionID, limits, claudeCmd); err != nil {
+		fmt.Printf("Warning: starting %s without resource limits: %v\n", sessionID, err)
+	}
+
 	// Create session with command directly to avoid send-keys race condition.
 	// See: https://github.com/anthropics/gastown/issues/280
 	if err := t.NewSessionWithCommand(sessionID, worker.ClonePath, claudeCmd); err != nil {
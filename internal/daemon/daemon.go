@@ -18,11 +18,13 @@ import (
 	"github.com/gofrs/flock"
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/boot"
+	"github.com/steveyegge/gastown/internal/cgroup"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
 	"github.com/steveyegge/gastown/internal/deacon"
 	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/feed"
+	"github.com/steveyegge/gastown/internal/mail"
 	"github.com/steveyegge/gastown/internal/polecat"
 	"github.com/steveyegge/gastown/internal/refinery"
 	"github.com/steveyegge/gastown/internal/rig"
@@ -40,7 +42,7 @@ import (
 type Daemon struct {
 	config       *Config
 	patrolConfig *DaemonPatrolConfig
-	tmux         *tmux.Tmux
+	backend      session.SessionBackend
 	logger       *log.Logger
 	ctx          context.Context
 	cancel       context.CancelFunc
@@ -56,6 +58,11 @@ type Daemon struct {
 	// See: https://github.com/steveyegge/gastown/issues/567
 	// Note: Only accessed from heartbeat loop goroutine - no sync needed.
 	deaconLastStarted time.Time
+
+	// OOM-killed polecat sessions already reported to their witness, so a
+	// dead session isn't reported again on every heartbeat.
+	// Note: Only accessed from heartbeat loop goroutine - no sync needed.
+	oomReported map[string]bool
 }
 
 // sessionDeath records a detected session death for mass death analysis.
@@ -96,7 +103,7 @@ func New(config *Config) (*Daemon, error) {
 	return &Daemon{
 		config:       config,
 		patrolConfig: patrolConfig,
-		tmux:         tmux.NewTmux(),
+		backend:      session.NewBackend(),
 		logger:       logger,
 		ctx:          ctx,
 		cancel:       cancel,
@@ -274,6 +281,33 @@ func (d *Daemon) heartbeat(state *State) {
 	// This is a safety net - Deacon patrol also does this more frequently.
 	d.cleanupOrphanedProcesses()
 
+	// 13. Rotate the events log by age and prune expired segments.
+	// Size-based rotation happens as events are written; this catches quiet towns.
+	if err := events.Compact(d.config.TownRoot, events.DefaultRotation); err != nil {
+		d.logger.Printf("Warning: compacting events log: %v", err)
+	}
+
+	// 14. Renew mail queue claims held by running sessions and requeue
+	// those whose lease expired (worker session died without releasing).
+	sweep, err := mail.SweepClaims(d.config.TownRoot, d.claimantAlive)
+	if err != nil {
+		d.logger.Printf("Warning: sweeping queue claims: %v", err)
+	}
+	if sweep.Renewed > 0 {
+		d.logger.Printf("Renewed %d queue claim(s) held by running sessions", sweep.Renewed)
+	}
+	if sweep.Requeued > 0 {
+		d.logger.Printf("Requeued %d expired queue claim(s)", sweep.Requeued)
+	}
+
+	// 15. Exchange federated mail with other towns (no-op unless
+	// config/federation.json exists).
+	if res, err := mail.SyncFederation(d.config.TownRoot); err != nil {
+		d.logger.Printf("Warning: syncing federated mail: %v", err)
+	} else if res.Delivered > 0 || res.Receipts > 0 {
+		d.logger.Printf("Federated mail: %d delivered, %d receipt(s)", res.Delivered, res.Receipts)
+	}
+
 	// Update state
 	state.LastHeartbeat = time.Now()
 	state.HeartbeatCount++
@@ -307,7 +341,9 @@ func (d *Daemon) ensureBootRunning() {
 
 	// Check for degraded mode
 	degraded := os.Getenv("GT_DEGRADED") == "true"
-	if degraded || !d.tmux.IsAvailable() {
+	// Boot runs in tmux; without it, triage mechanically
+	t, ok := d.backend.(*tmux.Tmux)
+	if degraded || !ok || !t.IsAvailable() {
 		// In degraded mode, run mechanical triage directly
 		d.logger.Println("Degraded mode: running mechanical Boot triage")
 		d.runDegradedBootTriage(b)
@@ -336,7 +372,7 @@ func (d *Daemon) runDegradedBootTriage(b *boot.Boot) {
 	}
 
 	// Simple check: is Deacon session alive?
-	hasDeacon, err := d.tmux.HasSession(d.getDeaconSessionName())
+	hasDeacon, err := d.backend.HasSession(d.getDeaconSessionName())
 	if err != nil {
 		d.logger.Printf("Error checking Deacon session: %v", err)
 		status.LastAction = "error"
@@ -416,7 +452,7 @@ func (d *Daemon) checkDeaconHeartbeat() {
 	sessionName := d.getDeaconSessionName()
 
 	// Check if session exists
-	hasSession, err := d.tmux.HasSession(sessionName)
+	hasSession, err := d.backend.HasSession(sessionName)
 	if err != nil {
 		d.logger.Printf("Error checking Deacon session: %v", err)
 		return
@@ -432,14 +468,14 @@ func (d *Daemon) checkDeaconHeartbeat() {
 	if age > 30*time.Minute {
 		// Very stuck - restart the session
 		d.logger.Printf("Deacon stuck for %s - restarting session", age.Round(time.Minute))
-		if err := d.tmux.KillSession(sessionName); err != nil {
+		if err := d.backend.KillSession(sessionName); err != nil {
 			d.logger.Printf("Error killing stuck Deacon: %v", err)
 		}
 		// ensureDeaconRunning will restart on next heartbeat
 	} else {
 		// Stuck but not critically - nudge to wake up
 		d.logger.Printf("Deacon stuck for %s - nudging session", age.Round(time.Minute))
-		if err := d.tmux.NudgeSession(sessionName, "HEALTH_CHECK: heartbeat stale, respond to confirm responsiveness"); err != nil {
+		if err := d.backend.NudgeSession(sessionName, "HEALTH_CHECK: heartbeat stale, respond to confirm responsiveness"); err != nil {
 			d.logger.Printf("Error nudging stuck Deacon: %v", err)
 		}
 	}
@@ -800,7 +836,7 @@ func (d *Daemon) checkPolecatHealth(rigName, polecatName string) {
 	sessionName := fmt.Sprintf("gt-%s-%s", rigName, polecatName)
 
 	// Check if tmux session exists
-	sessionAlive, err := d.tmux.HasSession(sessionName)
+	sessionAlive, err := d.backend.HasSession(sessionName)
 	if err != nil {
 		d.logger.Printf("Error checking session %s: %v", sessionName, err)
 		return
@@ -808,6 +844,7 @@ func (d *Daemon) checkPolecatHealth(rigName, polecatName string) {
 
 	if sessionAlive {
 		// Session is alive - nothing to do
+		delete(d.oomReported, sessionName)
 		return
 	}
 
@@ -829,6 +866,25 @@ func (d *Daemon) checkPolecatHealth(rigName, polecatName string) {
 		return
 	}
 
+	// A polecat the OOM killer stopped at its memory limit would likely be
+	// killed again if restarted with the same limit, so leave it to the
+	// witness instead of auto-restarting.
+	if usage, err := cgroup.GetUsage(cgroup.ScopeUnit(sessionName)); err == nil && usage.Result == cgroup.ResultOOMKill {
+		if d.oomReported[sessionName] {
+			return
+		}
+		if d.oomReported == nil {
+			d.oomReported = make(map[string]bool)
+		}
+		d.oomReported[sessionName] = true
+
+		d.logger.Printf("OOM KILL DETECTED: polecat %s/%s has hook_bead=%s but session %s hit its memory limit",
+			rigName, polecatName, info.HookBead, sessionName)
+		d.recordOOMKill(rigName, polecatName, sessionName)
+		d.notifyWitnessOfOOMKilledPolecat(rigName, polecatName, sessionName, info.HookBead, usage)
+		return
+	}
+
 	// Polecat has work but session is dead - this is a crash!
 	d.logger.Printf("CRASH DETECTED: polecat %s/%s has hook_bead=%s but session %s is dead",
 		rigName, polecatName, info.HookBead, sessionName)
@@ -923,12 +979,6 @@ func (d *Daemon) restartPolecatSession(rigName, polecatName, sessionName string)
 	// Pre-sync workspace (ensure beads are current)
 	d.syncWorkspace(workDir)
 
-	// Create new tmux session
-	// Use EnsureSessionFresh to handle zombie sessions that exist but have dead Claude
-	if err := d.tmux.EnsureSessionFresh(sessionName, workDir); err != nil {
-		return fmt.Errorf("creating session: %w", err)
-	}
-
 	// Set environment variables using centralized AgentEnv
 	envVars := config.AgentEnv(config.AgentEnvConfig{
 		Role:          "polecat",
@@ -938,32 +988,40 @@ func (d *Daemon) restartPolecatSession(rigName, polecatName, sessionName string)
 		BeadsNoDaemon: true,
 	})
 
-	// Set all env vars in tmux session (for debugging) and they'll also be exported to Claude
-	for k, v := range envVars {
-		_ = d.tmux.SetEnvironment(sessionName, k, v)
+	// Launch Claude with environment exported inline
+	// Pass rigPath so rig agent settings are honored (not town-level defaults)
+	startCmd := config.BuildStartupCommand(envVars, rigPath, "")
+
+	// Keep the rig's resource limits across restarts
+	limits := config.ResolveResourceLimits(d.config.TownRoot, rigPath, "polecat")
+	if wrapped, err := cgroup.WrapSession(sessionName, limits, startCmd); err != nil {
+		d.logger.Printf("Warning: restarting %s without resource limits: %v", sessionName, err)
+	} else {
+		startCmd = wrapped
+	}
+	// Create the session, replacing a zombie that exists but has dead Claude
+	if err := d.startSession(sessionName, workDir, startCmd); err != nil {
+		return err
 	}
 
-	// Apply theme
-	theme := tmux.AssignTheme(rigName)
-	_ = d.tmux.ConfigureGasTownSession(sessionName, theme, rigName, polecatName, "polecat")
+	// Set all env vars in the session (for debugging); they're also exported to Claude
+	for k, v := range envVars {
+		_ = d.backend.SetEnvironment(sessionName, k, v)
+	}
 
-	// Set pane-died hook for future crash detection
-	agentID := fmt.Sprintf("%s/%s", rigName, polecatName)
-	_ = d.tmux.SetPaneDiedHook(sessionName, agentID)
+	if t, ok := d.backend.(*tmux.Tmux); ok {
+		// Apply theme
+		theme := tmux.AssignTheme(rigName)
+		_ = t.ConfigureGasTownSession(sessionName, theme, rigName, polecatName, "polecat")
 
-	// Launch Claude with environment exported inline
-	// Pass rigPath so rig agent settings are honored (not town-level defaults)
-	startCmd := config.BuildStartupCommand(envVars, rigPath, "")
-	if err := d.tmux.SendKeys(sessionName, startCmd); err != nil {
-		return fmt.Errorf("sending startup command: %w", err)
+		// Set pane-died hook for future crash detection
+		agentID := fmt.Sprintf("%s/%s", rigName, polecatName)
+		_ = t.SetPaneDiedHook(sessionName, agentID)
 	}
 
 	// Wait for Claude to start, then accept bypass permissions warning if it appears.
 	// This ensures automated restarts aren't blocked by the warning dialog.
-	if err := d.tmux.WaitForCommand(sessionName, constants.SupportedShells, constants.ClaudeStartTimeout); err != nil {
-		// Non-fatal - Claude might still start
-	}
-	_ = d.tmux.AcceptBypassPermissionsWarning(sessionName)
+	d.waitForAgent(sessionName)
 
 	return nil
 }
@@ -987,6 +1045,38 @@ Manual intervention may be required.`,
 	}
 }
 
+// recordOOMKill records an OOM-killed polecat's session death, with reason
+// oom-kill so the feed tells it apart from a crash.
+func (d *Daemon) recordOOMKill(rigName, polecatName, sessionName string) {
+	d.recordSessionDeath(sessionName)
+	_ = events.LogFeed(events.TypeSessionDeath, rigName+"/"+polecatName,
+		events.SessionDeathPayload(sessionName, rigName+"/polecats/"+polecatName, events.DeathReasonOOMKill, "daemon"))
+}
+
+// notifyWitnessOfOOMKilledPolecat tells the witness a polecat was OOM-killed,
+// so it can decide whether to restart, re-sling or escalate the work.
+func (d *Daemon) notifyWitnessOfOOMKilledPolecat(rigName, polecatName, sessionName, hookBead string, usage *cgroup.Usage) {
+	witnessAddr := rigName + "/witness"
+	subject := fmt.Sprintf("OOM_KILLED %s", polecatName)
+	memoryMax := "unlimited"
+	if usage.MemoryMax > 0 {
+		memoryMax = cgroup.FormatBytes(usage.MemoryMax)
+	}
+	body := fmt.Sprintf(`Polecat %s was killed by the OOM killer at its memory limit.
+It was not restarted automatically.
+
+Session: %s
+Issue: %s
+MemoryMax: %s`,
+		polecatName, sessionName, hookBead, memoryMax)
+
+	cmd := exec.Command("gt", "mail", "send", witnessAddr, "-s", subject, "-m", body) //nolint:gosec // G204: args are constructed internally
+	cmd.Dir = d.config.TownRoot
+	if err := cmd.Run(); err != nil {
+		d.logger.Printf("Warning: failed to notify witness of OOM-killed polecat: %v", err)
+	}
+}
+
 // cleanupOrphanedProcesses kills orphaned claude subagent processes.
 // These are Task tool subagents that didn't clean up after completion.
 // Detection uses TTY column: processes with TTY "?" have no controlling terminal.
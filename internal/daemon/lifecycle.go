@@ -171,7 +171,7 @@ func (d *Daemon) executeLifecycleAction(request *LifecycleRequest) error {
 	}
 
 	// Check if session exists (tmux detection still needed for lifecycle actions)
-	running, err := d.tmux.HasSession(sessionName)
+	running, err := d.backend.HasSession(sessionName)
 	if err != nil {
 		return fmt.Errorf("checking session: %w", err)
 	}
@@ -179,7 +179,7 @@ func (d *Daemon) executeLifecycleAction(request *LifecycleRequest) error {
 	switch request.Action {
 	case ActionShutdown:
 		if running {
-			if err := d.tmux.KillSession(sessionName); err != nil {
+			if err := d.backend.KillSession(sessionName); err != nil {
 				return fmt.Errorf("killing session: %w", err)
 			}
 			d.logger.Printf("Killed session %s", sessionName)
@@ -189,7 +189,7 @@ func (d *Daemon) executeLifecycleAction(request *LifecycleRequest) error {
 	case ActionCycle, ActionRestart:
 		if running {
 			// Kill the session first
-			if err := d.tmux.KillSession(sessionName); err != nil {
+			if err := d.backend.KillSession(sessionName); err != nil {
 				return fmt.Errorf("killing session: %w", err)
 			}
 			d.logger.Printf("Killed session %s for restart", sessionName)
@@ -332,6 +332,17 @@ func (d *Daemon) identityToSession(identity string) string {
 	}
 }
 
+// claimantAlive reports whether the session of a queue claimant (a mail
+// address like "gastown/nux") is running.
+func (d *Daemon) claimantAlive(claimant string) bool {
+	identity, err := session.ParseAddress(claimant)
+	if err != nil {
+		return false
+	}
+	running, err := d.backend.HasSession(identity.SessionName())
+	return err == nil && running
+}
+
 // restartSession starts a new session for the given agent.
 // Uses role bead config if available, falls back to hardcoded defaults.
 func (d *Daemon) restartSession(sessionName, identity string) error {
@@ -365,10 +376,11 @@ func (d *Daemon) restartSession(sessionName, identity string) error {
 		d.syncWorkspace(workDir)
 	}
 
-	// Create session
-	// Use EnsureSessionFresh to handle zombie sessions that exist but have dead Claude
-	if err := d.tmux.EnsureSessionFresh(sessionName, workDir); err != nil {
-		return fmt.Errorf("creating session: %w", err)
+	// Create session running the startup command, replacing a zombie
+	// session that exists but has dead Claude
+	startCmd := d.getStartCommand(config, parsed)
+	if err := d.startSession(sessionName, workDir, startCmd); err != nil {
+		return err
 	}
 
 	// Set environment variables
@@ -377,24 +389,15 @@ func (d *Daemon) restartSession(sessionName, identity string) error {
 	// Apply theme (non-fatal: theming failure doesn't affect operation)
 	d.applySessionTheme(sessionName, parsed)
 
-	// Get and send startup command
-	startCmd := d.getStartCommand(config, parsed)
-	if err := d.tmux.SendKeys(sessionName, startCmd); err != nil {
-		return fmt.Errorf("sending startup command: %w", err)
-	}
-
 	// Wait for Claude to start, then accept bypass permissions warning if it appears.
 	// This ensures automated role starts aren't blocked by the warning dialog.
-	if err := d.tmux.WaitForCommand(sessionName, constants.SupportedShells, constants.ClaudeStartTimeout); err != nil {
-		// Non-fatal - Claude might still start
-	}
-	_ = d.tmux.AcceptBypassPermissionsWarning(sessionName)
+	d.waitForAgent(sessionName)
 	time.Sleep(constants.ShutdownNotifyDelay)
 
 	// GUPP: Gas Town Universal Propulsion Principle
 	// Send startup nudge for predecessor discovery via /resume
 	recipient := identityToBDActor(identity)
-	_ = session.StartupNudge(d.tmux, sessionName, session.StartupNudgeConfig{
+	_ = session.StartupNudge(d.backend, sessionName, session.StartupNudgeConfig{
 		Recipient: recipient,
 		Sender:    "deacon",
 		Topic:     "lifecycle-restart",
@@ -403,7 +406,7 @@ func (d *Daemon) restartSession(sessionName, identity string) error {
 	// Send propulsion nudge to trigger autonomous execution.
 	// Wait for beacon to be fully processed (needs to be separate prompt)
 	time.Sleep(2 * time.Second)
-	_ = d.tmux.NudgeSession(sessionName, session.PropulsionNudgeForRole(parsed.RoleType, workDir)) // Non-fatal
+	_ = d.backend.NudgeSession(sessionName, session.PropulsionNudgeForRole(parsed.RoleType, workDir)) // Non-fatal
 
 	return nil
 }
@@ -526,27 +529,86 @@ func (d *Daemon) setSessionEnvironment(sessionName string, roleConfig *beads.Rol
 		TownRoot:  d.config.TownRoot,
 	})
 	for k, v := range envVars {
-		_ = d.tmux.SetEnvironment(sessionName, k, v)
+		_ = d.backend.SetEnvironment(sessionName, k, v)
 	}
 
 	// Set any custom env vars from role config (bead-defined overrides)
 	if roleConfig != nil {
 		for k, v := range roleConfig.EnvVars {
 			expanded := beads.ExpandRolePattern(v, d.config.TownRoot, parsed.RigName, parsed.AgentName, parsed.RoleType)
-			_ = d.tmux.SetEnvironment(sessionName, k, expanded)
+			_ = d.backend.SetEnvironment(sessionName, k, expanded)
 		}
 	}
 }
 
 // applySessionTheme applies tmux theming to the session.
 func (d *Daemon) applySessionTheme(sessionName string, parsed *ParsedIdentity) {
+	t, ok := d.backend.(*tmux.Tmux)
+	if !ok {
+		return
+	}
 	if parsed.RoleType == "mayor" {
 		theme := tmux.MayorTheme()
-		_ = d.tmux.ConfigureGasTownSession(sessionName, theme, "", "Mayor", "coordinator")
+		_ = t.ConfigureGasTownSession(sessionName, theme, "", "Mayor", "coordinator")
 	} else if parsed.RigName != "" {
 		theme := tmux.AssignTheme(parsed.RigName)
-		_ = d.tmux.ConfigureGasTownSession(sessionName, theme, parsed.RigName, parsed.RoleType, parsed.RoleType)
+		_ = t.ConfigureGasTownSession(sessionName, theme, parsed.RigName, parsed.RoleType, parsed.RoleType)
+	}
+}
+
+// startSession starts a session running command. A zombie session (alive,
+// but the agent is dead) is killed and replaced; a healthy one is left alone.
+// tmux sessions start with a shell and have the command typed in, so the
+// pane survives the agent for crash detection.
+func (d *Daemon) startSession(sessionName, workDir, command string) error {
+	if t, ok := d.backend.(*tmux.Tmux); ok {
+		if err := t.EnsureSessionFresh(sessionName, workDir); err != nil {
+			return fmt.Errorf("creating session: %w", err)
+		}
+		if err := t.SendKeys(sessionName, command); err != nil {
+			return fmt.Errorf("sending startup command: %w", err)
+		}
+		return nil
+	}
+
+	exists, err := d.backend.HasSession(sessionName)
+	if err != nil {
+		return fmt.Errorf("checking session: %w", err)
+	}
+	if exists {
+		if d.backend.IsAgentRunning(sessionName) {
+			return nil
+		}
+		if err := d.backend.KillSession(sessionName); err != nil {
+			return fmt.Errorf("killing zombie session: %w", err)
+		}
+	}
+	if err := d.backend.NewSessionWithCommand(sessionName, workDir, command); err != nil {
+		return fmt.Errorf("creating session: %w", err)
+	}
+	return nil
+}
+
+// waitForAgent waits for the agent to replace the startup shell and, under
+// tmux, accepts the bypass permissions warning. Non-fatal: the agent might
+// still start.
+func (d *Daemon) waitForAgent(sessionName string) {
+	if t, ok := d.backend.(*tmux.Tmux); ok {
+		_ = t.WaitForCommand(sessionName, constants.SupportedShells, constants.ClaudeStartTimeout)
+		_ = t.AcceptBypassPermissionsWarning(sessionName)
+		return
+	}
+	_ = session.WaitForAgent(d.backend, sessionName, constants.ClaudeStartTimeout)
+}
+
+// isClaudeRunning checks for a live agent in a session. tmux can also
+// recognize Claude running under a wrapper shell; other backends check for
+// any non-shell foreground command.
+func (d *Daemon) isClaudeRunning(sessionName string) bool {
+	if t, ok := d.backend.(*tmux.Tmux); ok {
+		return t.IsClaudeRunning(sessionName)
 	}
+	return d.backend.IsAgentRunning(sessionName)
 }
 
 // syncWorkspace syncs a git workspace before starting a new session.
@@ -839,7 +901,7 @@ func (d *Daemon) checkRigGUPPViolations(rigName string) {
 		sessionName := fmt.Sprintf("gt-%s-%s", rigName, polecatName)
 
 		// Check if tmux session exists and Claude is running
-		if d.tmux.IsClaudeRunning(sessionName) {
+		if d.isClaudeRunning(sessionName) {
 			// Session is alive - check if it's been stuck too long
 			updatedAt, err := time.Parse(time.RFC3339, agent.UpdatedAt)
 			if err != nil {
@@ -931,7 +993,7 @@ func (d *Daemon) checkRigOrphanedWork(rigName string) {
 		sessionName := fmt.Sprintf("gt-%s-%s", rigName, polecatName)
 
 		// Session running = not orphaned (work is being processed)
-		if d.tmux.IsClaudeRunning(sessionName) {
+		if d.isClaudeRunning(sessionName) {
 			continue
 		}
 
@@ -6,6 +6,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/steveyegge/gastown/internal/events"
 )
 
 // testDaemon creates a minimal Daemon for testing.
@@ -252,3 +254,25 @@ func TestBeadsMessage_Serialization(t *testing.T) {
 		t.Errorf("From mismatch")
 	}
 }
+
+func TestRecordOOMKill(t *testing.T) {
+	d, _ := testDaemonWithTown(t, "oomtown")
+	t.Chdir(d.config.TownRoot)
+
+	d.recordOOMKill("gastown", "nux", "gt-gastown-nux")
+
+	evts, err := events.Query(d.config.TownRoot, events.Filter{Types: []string{events.TypeSessionDeath}})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(evts) != 1 {
+		t.Fatalf("got %d session_death events, want 1", len(evts))
+	}
+	p := evts[0].Payload
+	if p["reason"] != events.DeathReasonOOMKill || p["session"] != "gt-gastown-nux" || p["agent"] != "gastown/polecats/nux" {
+		t.Errorf("payload = %v", p)
+	}
+	if len(d.recentDeaths) != 1 {
+		t.Errorf("recentDeaths = %d, want 1", len(d.recentDeaths))
+	}
+}
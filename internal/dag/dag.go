@@ -0,0 +1,223 @@
+// Package dag builds dependency graphs of beads, formula units and swarm
+// tasks, works out what is blocked and by what, finds the critical path,
+// and renders the result as Graphviz DOT, Mermaid or JSON.
+package dag
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Status is a node's state, normalized across beads, formula units and
+// swarm tasks.
+type Status string
+
+const (
+	// StatusDone means the work is finished.
+	StatusDone Status = "done"
+	// StatusActive means someone is working on it.
+	StatusActive Status = "active"
+	// StatusReady means it is open and nothing it needs is outstanding.
+	StatusReady Status = "ready"
+	// StatusBlocked means it is waiting on unfinished work.
+	StatusBlocked Status = "blocked"
+	// StatusFailed means the work failed.
+	StatusFailed Status = "failed"
+	// StatusSkipped means the work won't happen (e.g. a false when).
+	StatusSkipped Status = "skipped"
+)
+
+// NormalizeStatus maps a bead status, formula unit status or swarm task
+// state to a Status. Open and pending work comes back as ready; Analyze
+// decides whether it is blocked.
+func NormalizeStatus(raw string) Status {
+	switch raw {
+	case "closed", "done", "merged", "tombstone":
+		return StatusDone
+	case "in_progress", "hooked", "dispatched", "assigned", "review":
+		return StatusActive
+	case "failed":
+		return StatusFailed
+	case "skipped":
+		return StatusSkipped
+	case "blocked":
+		return StatusBlocked
+	}
+	return StatusReady
+}
+
+// finished reports whether a node no longer holds anything up.
+func (s Status) finished() bool {
+	return s == StatusDone || s == StatusSkipped
+}
+
+// Node is one piece of work in a graph.
+type Node struct {
+	ID        string   `json:"id"`
+	Title     string   `json:"title,omitempty"`
+	Status    Status   `json:"status"`
+	Assignee  string   `json:"assignee,omitempty"`
+	Needs     []string `json:"needs,omitempty"`      // what this waits on
+	BlockedBy []string `json:"blocked_by,omitempty"` // unfinished needs
+	Reason    string   `json:"reason,omitempty"`     // why it is blocked or failed
+	Critical  bool     `json:"critical,omitempty"`   // on the critical path
+}
+
+// Graph is a dependency graph, in the order its nodes were added.
+type Graph struct {
+	Title        string   `json:"title"`
+	Nodes        []*Node  `json:"nodes"`
+	CriticalPath []string `json:"critical_path"`
+
+	byID map[string]*Node
+}
+
+// New returns an empty graph.
+func New(title string) *Graph {
+	return &Graph{Title: title, byID: make(map[string]*Node)}
+}
+
+// Add adds a node with a raw status (see NormalizeStatus). Adding an ID
+// twice keeps the first.
+func (g *Graph) Add(id, title, status, assignee string, needs ...string) *Node {
+	if n, ok := g.byID[id]; ok {
+		return n
+	}
+	n := &Node{ID: id, Title: title, Status: NormalizeStatus(status), Assignee: assignee, Needs: needs}
+	g.Nodes = append(g.Nodes, n)
+	g.byID[id] = n
+	return n
+}
+
+// Node returns the node with the given ID, or nil.
+func (g *Graph) Node(id string) *Node {
+	return g.byID[id]
+}
+
+// Analyze drops needs outside the graph, marks open nodes blocked when a
+// need is unfinished (recording which and why), and computes the critical
+// path. Call it once every node has been added. A Reason set beforehand
+// (say, a failure message) is kept unless the node turns out blocked.
+func (g *Graph) Analyze() {
+	for _, n := range g.Nodes {
+		var needs []string
+		for _, id := range n.Needs {
+			if _, ok := g.byID[id]; ok && id != n.ID && !contains(needs, id) {
+				needs = append(needs, id)
+			}
+		}
+		n.Needs = needs
+	}
+
+	for _, n := range g.Nodes {
+		n.BlockedBy = nil
+		var waiting []string
+		for _, id := range n.Needs {
+			need := g.byID[id]
+			if need.Status.finished() {
+				continue
+			}
+			n.BlockedBy = append(n.BlockedBy, id)
+			if need.Status == StatusFailed || need.Status == StatusBlocked {
+				waiting = append(waiting, fmt.Sprintf("%s (%s)", id, need.Status))
+			} else {
+				waiting = append(waiting, id)
+			}
+		}
+		switch {
+		case n.Status.finished() || n.Status == StatusActive || n.Status == StatusFailed:
+		case len(waiting) > 0:
+			n.Status = StatusBlocked
+			n.Reason = "waiting on " + strings.Join(waiting, ", ")
+		case n.Status == StatusBlocked && n.Reason == "":
+			n.Reason = "marked blocked"
+		}
+	}
+
+	g.criticalPath()
+}
+
+// criticalPath finds the longest chain of unfinished nodes, counting each
+// node as one unit of work. Nodes on dependency cycles are left out.
+func (g *Graph) criticalPath() {
+	order := g.topoOrder()
+	length := make(map[string]int)
+	prev := make(map[string]string)
+	end := ""
+	for _, id := range order {
+		n := g.byID[id]
+		n.Critical = false
+		if n.Status.finished() {
+			continue
+		}
+		length[id] = 1
+		for _, need := range n.Needs {
+			if l, ok := length[need]; ok && l+1 > length[id] {
+				length[id], prev[id] = l+1, need
+			}
+		}
+		if end == "" || length[id] > length[end] {
+			end = id
+		}
+	}
+
+	g.CriticalPath = nil
+	for id := end; id != ""; id = prev[id] {
+		g.CriticalPath = append([]string{id}, g.CriticalPath...)
+		g.byID[id].Critical = true
+	}
+}
+
+// topoOrder returns node IDs with needs before dependents, in insertion
+// order where there is a choice.
+func (g *Graph) topoOrder() []string {
+	indegree := make(map[string]int)
+	dependents := make(map[string][]string)
+	for _, n := range g.Nodes {
+		indegree[n.ID] += 0
+		for _, need := range n.Needs {
+			indegree[n.ID]++
+			dependents[need] = append(dependents[need], n.ID)
+		}
+	}
+	index := make(map[string]int, len(g.Nodes))
+	var ready []string
+	for i, n := range g.Nodes {
+		index[n.ID] = i
+		if indegree[n.ID] == 0 {
+			ready = append(ready, n.ID)
+		}
+	}
+	var order []string
+	for len(ready) > 0 {
+		sort.Slice(ready, func(i, j int) bool { return index[ready[i]] < index[ready[j]] })
+		id := ready[0]
+		ready = ready[1:]
+		order = append(order, id)
+		for _, d := range dependents[id] {
+			if indegree[d]--; indegree[d] == 0 {
+				ready = append(ready, d)
+			}
+		}
+	}
+	return order
+}
+
+// Counts returns how many nodes are in each status.
+func (g *Graph) Counts() map[Status]int {
+	counts := make(map[Status]int)
+	for _, n := range g.Nodes {
+		counts[n.Status]++
+	}
+	return counts
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package dag
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// testGraph is a small convoy: design is done, api and ui hang off it,
+// api's tests failed, and release waits on everything.
+func testGraph() *Graph {
+	g := New("hq-cv-abc: Ship login")
+	g.Add("gt-1", "Design login", "closed", "")
+	g.Add("gt-2", "Build API", "in_progress", "gastown/polecats/nux", "gt-1")
+	g.Add("gt-3", "Build UI", "open", "", "gt-1")
+	g.Add("gt-4", "API tests", "failed", "", "gt-2")
+	g.Add("gt-5", "Release \"v2\"", "open", "", "gt-3", "gt-4", "hq-external")
+	g.Analyze()
+	return g
+}
+
+func TestAnalyze(t *testing.T) {
+	g := testGraph()
+
+	if s := g.Node("gt-3").Status; s != StatusReady {
+		t.Errorf("gt-3 = %s, want ready (its need is done)", s)
+	}
+	rel := g.Node("gt-5")
+	if rel.Status != StatusBlocked || strings.Join(rel.BlockedBy, ",") != "gt-3,gt-4" {
+		t.Errorf("gt-5 = %s blocked by %v", rel.Status, rel.BlockedBy)
+	}
+	if rel.Reason != "waiting on gt-3, gt-4 (failed)" {
+		t.Errorf("reason = %q", rel.Reason)
+	}
+	if len(rel.Needs) != 2 {
+		t.Errorf("need outside the graph kept: %v", rel.Needs)
+	}
+	if got := strings.Join(g.CriticalPath, ","); got != "gt-2,gt-4,gt-5" {
+		t.Errorf("critical path = %s", got)
+	}
+	if !g.Node("gt-4").Critical || g.Node("gt-3").Critical || g.Node("gt-1").Critical {
+		t.Error("critical flags don't match the path")
+	}
+	if c := g.Counts(); c[StatusDone] != 1 || c[StatusBlocked] != 1 || c[StatusFailed] != 1 {
+		t.Errorf("counts = %v", c)
+	}
+
+	// A cycle doesn't hang the analysis.
+	c := New("cycle")
+	c.Add("a", "", "open", "", "b")
+	c.Add("b", "", "open", "", "a")
+	c.Add("c", "", "open", "")
+	c.Analyze()
+	if strings.Join(c.CriticalPath, ",") != "c" {
+		t.Errorf("cycle critical path = %v", c.CriticalPath)
+	}
+}
+
+func TestRender(t *testing.T) {
+	g := testGraph()
+
+	var dot bytes.Buffer
+	if err := g.Render(&dot, "dot"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`digraph "hq-cv-abc: Ship login" {`,
+		`"gt-2" [label="gt-2\nBuild API\n@gastown/polecats/nux", fillcolor="#bbdefb", color="#d32f2f", penwidth=3];`,
+		`"gt-5" [label="gt-5\nRelease \"v2\"\nwaiting on gt-3, gt-4 (failed)"`,
+		`"gt-4" -> "gt-5" [color="#d32f2f", penwidth=3];`,
+		`"gt-3" -> "gt-5";`,
+	} {
+		if !strings.Contains(dot.String(), want) {
+			t.Errorf("dot missing %s\n%s", want, dot.String())
+		}
+	}
+
+	var mmd bytes.Buffer
+	if err := g.Render(&mmd, "mermaid"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"flowchart LR",
+		`n4["gt-5<br/>Release #quot;v2#quot;<br/>waiting on gt-3, gt-4 (failed)"]`,
+		"n3 ==> n4",
+		"n2 --> n4",
+		"class n3 failed",
+		"class n3 critical",
+		"classDef blocked fill:#ffcdd2",
+	} {
+		if !strings.Contains(mmd.String(), want) {
+			t.Errorf("mermaid missing %s\n%s", want, mmd.String())
+		}
+	}
+
+	var js bytes.Buffer
+	if err := g.Render(&js, "json"); err != nil {
+		t.Fatal(err)
+	}
+	var decoded Graph
+	if err := json.Unmarshal(js.Bytes(), &decoded); err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	if len(decoded.Nodes) != 5 || len(decoded.CriticalPath) != 3 || decoded.Nodes[4].Reason == "" {
+		t.Errorf("decoded = %+v", decoded)
+	}
+
+	if err := g.Render(&js, "png"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
@@ -0,0 +1,163 @@
+package dag
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Formats are the output formats Render accepts.
+var Formats = []string{"dot", "mermaid", "json"}
+
+// statusColors are the fill colors for each status.
+var statusColors = map[Status]string{
+	StatusDone:    "#c8e6c9",
+	StatusActive:  "#bbdefb",
+	StatusReady:   "#fff9c4",
+	StatusBlocked: "#ffcdd2",
+	StatusFailed:  "#ef9a9a",
+	StatusSkipped: "#eeeeee",
+}
+
+// criticalColor outlines the critical path.
+const criticalColor = "#d32f2f"
+
+// maxLabelTitle is how much of a title goes into a node label.
+const maxLabelTitle = 40
+
+// Render writes the graph in the given format: dot, mermaid or json.
+func (g *Graph) Render(w io.Writer, format string) error {
+	switch format {
+	case "dot":
+		return g.WriteDOT(w)
+	case "mermaid":
+		return g.WriteMermaid(w)
+	case "json":
+		return g.WriteJSON(w)
+	}
+	return fmt.Errorf("unknown format %q (must be %s)", format, strings.Join(Formats, ", "))
+}
+
+// labelLines returns a node's label: ID, title, assignee and why it is
+// blocked.
+func labelLines(n *Node) []string {
+	lines := []string{n.ID}
+	if title := strings.TrimSpace(n.Title); title != "" && title != n.ID {
+		if r := []rune(title); len(r) > maxLabelTitle {
+			title = string(r[:maxLabelTitle-1]) + "…"
+		}
+		lines = append(lines, title)
+	}
+	if n.Assignee != "" {
+		lines = append(lines, "@"+n.Assignee)
+	}
+	if n.Reason != "" {
+		lines = append(lines, n.Reason)
+	}
+	return lines
+}
+
+// criticalEdge reports whether need → n is a step along the critical path.
+func (g *Graph) criticalEdge(need string, n *Node) bool {
+	for i := 1; i < len(g.CriticalPath); i++ {
+		if g.CriticalPath[i] == n.ID && g.CriticalPath[i-1] == need {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteDOT writes the graph in Graphviz DOT.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", dotQuote(g.Title))
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, style=\"rounded,filled\", fontname=\"Helvetica\"];\n")
+	for _, n := range g.Nodes {
+		attrs := fmt.Sprintf("label=%s, fillcolor=%s", dotQuote(strings.Join(labelLines(n), "\n")), dotQuote(statusColors[n.Status]))
+		if n.Critical {
+			attrs += fmt.Sprintf(", color=%s, penwidth=3", dotQuote(criticalColor))
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", dotQuote(n.ID), attrs)
+	}
+	for _, n := range g.Nodes {
+		for _, need := range n.Needs {
+			attrs := ""
+			if g.criticalEdge(need, n) {
+				attrs = fmt.Sprintf(" [color=%s, penwidth=3]", dotQuote(criticalColor))
+			}
+			fmt.Fprintf(&b, "  %s -> %s%s;\n", dotQuote(need), dotQuote(n.ID), attrs)
+		}
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + strings.ReplaceAll(s, "\n", `\n`) + `"`
+}
+
+// WriteMermaid writes the graph as a Mermaid flowchart.
+func (g *Graph) WriteMermaid(w io.Writer) error {
+	var b strings.Builder
+	ids := make(map[string]string, len(g.Nodes))
+	for i, n := range g.Nodes {
+		ids[n.ID] = fmt.Sprintf("n%d", i)
+	}
+
+	b.WriteString("flowchart LR\n")
+	if g.Title != "" {
+		fmt.Fprintf(&b, "  %%%% %s\n", g.Title)
+	}
+	for _, n := range g.Nodes {
+		lines := labelLines(n)
+		for i, l := range lines {
+			lines[i] = mermaidEscape(l)
+		}
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", ids[n.ID], strings.Join(lines, "<br/>"))
+	}
+	for _, n := range g.Nodes {
+		for _, need := range n.Needs {
+			arrow := "-->"
+			if g.criticalEdge(need, n) {
+				arrow = "==>"
+			}
+			fmt.Fprintf(&b, "  %s %s %s\n", ids[need], arrow, ids[n.ID])
+		}
+	}
+
+	for _, s := range []Status{StatusDone, StatusActive, StatusReady, StatusBlocked, StatusFailed, StatusSkipped} {
+		fmt.Fprintf(&b, "  classDef %s fill:%s\n", s, statusColors[s])
+	}
+	fmt.Fprintf(&b, "  classDef critical stroke:%s,stroke-width:3px\n", criticalColor)
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, "  class %s %s\n", ids[n.ID], n.Status)
+		if n.Critical {
+			fmt.Fprintf(&b, "  class %s critical\n", ids[n.ID])
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;").Replace(s)
+}
+
+// WriteJSON writes the graph as indented JSON.
+func (g *Graph) WriteJSON(w io.Writer) error {
+	out := *g
+	if out.Nodes == nil {
+		out.Nodes = []*Node{}
+	}
+	if out.CriticalPath == nil {
+		out.CriticalPath = []string{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
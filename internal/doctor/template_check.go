@@ -0,0 +1,94 @@
+package doctor
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/steveyegge/gastown/internal/templates"
+)
+
+// TemplateOverridesCheck warns when an ejected role or message template was
+// copied from an older version of the embedded template, so upstream fixes
+// to it aren't reaching agents.
+type TemplateOverridesCheck struct {
+	BaseCheck
+}
+
+// NewTemplateOverridesCheck creates a new template overrides check.
+func NewTemplateOverridesCheck() *TemplateOverridesCheck {
+	return &TemplateOverridesCheck{
+		BaseCheck: BaseCheck{
+			CheckName:        "template-overrides",
+			CheckDescription: "Check ejected templates are based on the current embedded versions",
+			CheckCategory:    CategoryConfig,
+		},
+	}
+}
+
+// Run checks the town's and each rig's template overrides.
+func (c *TemplateOverridesCheck) Run(ctx *CheckContext) *CheckResult {
+	dirs := map[string]string{"town": templates.TownOverrideDir(ctx.TownRoot)}
+	rigs, _ := discoverRigs(ctx.TownRoot)
+	for _, rigName := range rigs {
+		dirs[rigName] = templates.RigOverrideDir(filepath.Join(ctx.TownRoot, rigName))
+	}
+
+	var total int
+	var stale []string
+	var errs []string
+	for owner, dir := range dirs {
+		overrides, err := templates.Overrides(dir, owner)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", owner, err))
+			continue
+		}
+		total += len(overrides)
+		for _, o := range overrides {
+			if o.Stale {
+				stale = append(stale, fmt.Sprintf("%s: %s ejected from sha256:%s, embedded is now sha256:%s (gt template diff %s%s)",
+					owner, o.File(), o.Ejected, o.Current, o.Name, rigFlag(owner)))
+			}
+		}
+	}
+	sort.Strings(stale)
+	sort.Strings(errs)
+
+	if len(errs) > 0 {
+		return &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusWarning,
+			Message: "Could not read template overrides",
+			Details: errs,
+		}
+	}
+	if len(stale) > 0 {
+		return &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusWarning,
+			Message: fmt.Sprintf("%d ejected template(s) older than the embedded version", len(stale)),
+			Details: stale,
+			FixHint: "Merge the upstream changes and update the sha256 in the override's first line, or re-eject with 'gt template eject <name> --force'",
+		}
+	}
+	if total == 0 {
+		return &CheckResult{
+			Name:    c.Name(),
+			Status:  StatusOK,
+			Message: "No template overrides",
+		}
+	}
+	return &CheckResult{
+		Name:    c.Name(),
+		Status:  StatusOK,
+		Message: fmt.Sprintf("%d template override(s) up to date", total),
+	}
+}
+
+// rigFlag returns the --rig flag that targets an override's owner.
+func rigFlag(owner string) string {
+	if owner == "town" {
+		return ""
+	}
+	return " --rig " + owner
+}
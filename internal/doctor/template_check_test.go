@@ -0,0 +1,63 @@
+package doctor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/templates"
+)
+
+func TestTemplateOverridesCheck(t *testing.T) {
+	tmpDir := t.TempDir()
+	mayorDir := filepath.Join(tmpDir, "mayor")
+	if err := os.MkdirAll(mayorDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	rigsContent := `{"version": 1, "rigs": {"gastown": {"git_url": "https://github.com/example/gastown"}}}`
+	if err := os.WriteFile(filepath.Join(mayorDir, "rigs.json"), []byte(rigsContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	check := NewTemplateOverridesCheck()
+	ctx := &CheckContext{TownRoot: tmpDir}
+
+	if result := check.Run(ctx); result.Status != StatusOK {
+		t.Errorf("no overrides: got %v: %s", result.Status, result.Message)
+	}
+
+	if _, err := templates.Eject(templates.TownOverrideDir(tmpDir), "handoff", false); err != nil {
+		t.Fatal(err)
+	}
+	rigOverride, err := templates.Eject(templates.RigOverrideDir(filepath.Join(tmpDir, "gastown")), "polecat", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := check.Run(ctx); result.Status != StatusOK || !strings.Contains(result.Message, "2 template override(s)") {
+		t.Errorf("fresh overrides: got %v: %s", result.Status, result.Message)
+	}
+
+	// Pretend the rig's polecat template was ejected from an older gt.
+	info, err := templates.Lookup(tmpDir, filepath.Join(tmpDir, "gastown"), "polecat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(rigOverride)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = []byte(strings.Replace(string(data), "sha256:"+info.Ejected, "sha256:000000000000", 1))
+	if err := os.WriteFile(rigOverride, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := check.Run(ctx)
+	if result.Status != StatusWarning {
+		t.Fatalf("stale override: got %v: %s", result.Status, result.Message)
+	}
+	if len(result.Details) != 1 || !strings.Contains(result.Details[0], "gastown: roles/polecat.md.tmpl") ||
+		!strings.Contains(result.Details[0], "--rig gastown") {
+		t.Errorf("details = %v", result.Details)
+	}
+}
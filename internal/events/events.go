@@ -1,14 +1,15 @@
 // Package events provides event logging for the gt activity feed.
 //
 // Events are written to ~/gt/.events.jsonl (raw audit log) and later
-// curated by the feed daemon into ~/.feed.jsonl (user-facing).
+// curated by the feed daemon into ~/.feed.jsonl (user-facing). The log is
+// indexed as it is appended and rotated into gzipped segments under
+// ~/gt/.events.d; read it with Query rather than scanning the file.
 package events
 
 import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"path/filepath"
 	"sync"
 	"time"
 
@@ -62,18 +63,26 @@ const (
 	TypeEscalationAcked  = "escalation_acked"
 	TypeEscalationClosed = "escalation_closed"
 	TypePatrolComplete   = "patrol_complete"
+	TypeThrashing        = "thrashing" // polecat active but looping
 
 	// Merge queue events (emitted by refinery)
 	TypeMergeStarted = "merge_started"
 	TypeMerged       = "merged"
 	TypeMergeFailed  = "merge_failed"
 	TypeMergeSkipped = "merge_skipped"
+
+	// Dashboard control actions (audit only)
+	TypeDashboardAction = "dashboard_action"
+
+	// Account pool events
+	TypeAccountRotated = "account_rotated" // session moved off a rate-limited account
 )
 
 // EventsFile is the name of the raw events log.
 const EventsFile = ".events.jsonl"
 
-// mutex protects concurrent writes to the events file.
+// mutex serializes writes and rotation within this process; the store
+// lock file does the same across processes.
 var mutex sync.Mutex
 
 // Log writes an event to the events log.
@@ -110,8 +119,6 @@ func write(event Event) error {
 		return nil
 	}
 
-	eventsPath := filepath.Join(townRoot, EventsFile)
-
 	// Marshal event to JSON
 	data, err := json.Marshal(event)
 	if err != nil {
@@ -119,21 +126,7 @@ func write(event Event) error {
 	}
 	data = append(data, '\n')
 
-	// Append to file with proper locking
-	mutex.Lock()
-	defer mutex.Unlock()
-
-	f, err := os.OpenFile(eventsPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644) //nolint:gosec // G302: events file is non-sensitive operational data
-	if err != nil {
-		return fmt.Errorf("opening events file: %w", err)
-	}
-	defer f.Close()
-
-	if _, err := f.Write(data); err != nil {
-		return fmt.Errorf("writing event: %w", err)
-	}
-
-	return nil
+	return appendEvent(townRoot, event, data)
 }
 
 // Payload helpers for common event structures.
@@ -257,6 +250,19 @@ func EscalationPayload(rig, target, to, reason string) map[string]interface{} {
 	}
 }
 
+// ThrashingPayload creates a payload for thrashing events.
+// repetition and churn are the detector's scores (0-1) and reason says
+// what crossed its threshold.
+func ThrashingPayload(rig, polecat string, repetition, churn float64, reason string) map[string]interface{} {
+	return map[string]interface{}{
+		"rig":        rig,
+		"polecat":    polecat,
+		"repetition": repetition,
+		"churn":      churn,
+		"reason":     reason,
+	}
+}
+
 // UnhookPayload creates a payload for unhook events.
 func UnhookPayload(beadID string) map[string]interface{} {
 	return map[string]interface{}{
@@ -280,10 +286,16 @@ func HaltPayload(services []string) map[string]interface{} {
 	}
 }
 
+// DeathReasonOOMKill is the session death reason when the kernel OOM killer
+// stopped a session at its cgroup memory limit. Unlike a crash, restarting
+// the session with the same limit will likely fail the same way.
+const DeathReasonOOMKill = "oom-kill"
+
 // SessionDeathPayload creates a payload for session death events.
 // session: tmux session name that died
 // agent: Gas Town agent identity (e.g., "gastown/polecats/Toast")
-// reason: why the session was killed (e.g., "zombie cleanup", "user request", "doctor fix")
+// reason: why the session was killed (e.g., "zombie cleanup", "user request", "doctor fix",
+// or DeathReasonOOMKill)
 // caller: what initiated the kill (e.g., "daemon", "doctor", "gt down")
 func SessionDeathPayload(session, agent, reason, caller string) map[string]interface{} {
 	return map[string]interface{}{
@@ -294,6 +306,21 @@ func SessionDeathPayload(session, agent, reason, caller string) map[string]inter
 	}
 }
 
+// AccountRotatedPayload creates a payload for account rotation events.
+// from is the limited account (empty if the session's account is not in
+// accounts.json), to the account the session was respawned on, and until
+// when from's limit resets.
+func AccountRotatedPayload(session, agent, from, to string, until time.Time, reason string) map[string]interface{} {
+	return map[string]interface{}{
+		"session": session,
+		"agent":   agent,
+		"from":    from,
+		"to":      to,
+		"until":   until.UTC().Format(time.RFC3339),
+		"reason":  reason,
+	}
+}
+
 // MassDeathPayload creates a payload for mass death events.
 // count: number of sessions that died
 // window: time window in which deaths occurred (e.g., "5s")
@@ -0,0 +1,277 @@
+package events
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Filter selects events for Query. Zero fields match everything.
+type Filter struct {
+	// Types and Actors match exactly; an event must match one of each
+	// non-empty list.
+	Types  []string
+	Actors []string
+
+	// Since and Until bound the event timestamp (inclusive). Events whose
+	// timestamp doesn't parse are excluded when either is set.
+	Since time.Time
+	Until time.Time
+
+	// Match is an optional extra predicate applied after the others.
+	Match func(Event) bool
+
+	// Limit keeps only the newest Limit matching events.
+	Limit int
+}
+
+func (f Filter) timed() bool {
+	return !f.Since.IsZero() || !f.Until.IsZero()
+}
+
+func (f Filter) matchesTime(unix int64) bool {
+	if !f.timed() {
+		return true
+	}
+	if unix == 0 {
+		return false
+	}
+	if !f.Since.IsZero() && unix < f.Since.Unix() {
+		return false
+	}
+	if !f.Until.IsZero() && unix > f.Until.Unix() {
+		return false
+	}
+	return true
+}
+
+// matchesKey checks the fields the index and segment summaries carry.
+func (f Filter) matchesKey(eventType, actor string, unix int64) bool {
+	return containsOrEmpty(f.Types, eventType) && containsOrEmpty(f.Actors, actor) && f.matchesTime(unix)
+}
+
+func (f Filter) matches(e Event) bool {
+	if !f.matchesKey(e.Type, e.Actor, eventTime(e.Timestamp)) {
+		return false
+	}
+	return f.Match == nil || f.Match(e)
+}
+
+// mayMatch reports whether a segment can contain matching events, using
+// its summary.
+func (f Filter) mayMatch(s Segment) bool {
+	if !anyKey(f.Types, s.Types) || !anyKey(f.Actors, s.Actors) {
+		return false
+	}
+	if !f.timed() {
+		return true
+	}
+	if len(s.Buckets) == 0 {
+		return !s.Start.IsZero() // summary predates buckets: scan if datable
+	}
+	for bucket := range s.Buckets {
+		end := bucket + int64(bucketSize/time.Second) - 1
+		if (f.Since.IsZero() || end >= f.Since.Unix()) && (f.Until.IsZero() || bucket <= f.Until.Unix()) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsOrEmpty(list []string, v string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+func anyKey(list []string, counts map[string]int) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, s := range list {
+		if counts[s] > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Query returns the events in the town's log matching f, oldest first.
+// It reads the active log through its index and only opens archived
+// segments whose summaries can match, newest first, stopping once Limit
+// events are found.
+func Query(townRoot string, f Filter) ([]Event, error) {
+	lock := lockStore(townRoot)
+	if err := lock.RLock(); err != nil {
+		return nil, err
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	result, err := queryActive(townRoot, f)
+	if err != nil {
+		return nil, err
+	}
+	if f.Limit > 0 && len(result) >= f.Limit {
+		return result[len(result)-f.Limit:], nil
+	}
+
+	segments, err := loadManifest(townRoot)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(segments) - 1; i >= 0; i-- {
+		if !f.mayMatch(segments[i]) {
+			continue
+		}
+		older, err := querySegment(townRoot, segments[i], f)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue // pruned concurrently or deleted by hand
+			}
+			return nil, err
+		}
+		result = append(older, result...)
+		if f.Limit > 0 && len(result) >= f.Limit {
+			return result[len(result)-f.Limit:], nil
+		}
+	}
+	return result, nil
+}
+
+// queryActive reads matching events from the active log. Indexed events
+// are selected from the index and read by offset; anything past the end
+// of the index (written by an older gt, or a failed index append) is
+// scanned.
+func queryActive(townRoot string, f Filter) ([]Event, error) {
+	file, err := os.Open(filepath.Join(townRoot, EventsFile)) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, covered := readIndex(townRoot, info.Size())
+	filtered := len(f.Types) > 0 || len(f.Actors) > 0 || f.timed()
+
+	var result []Event
+	if filtered && covered > 0 {
+		var ok bool
+		if result, ok = readIndexed(file, entries, f); !ok {
+			result, covered = nil, 0
+		}
+	} else {
+		covered = 0
+	}
+
+	if _, err := file.Seek(covered, io.SeekStart); err != nil {
+		return nil, err
+	}
+	tail, err := scanEvents(file, f)
+	if err != nil {
+		return nil, err
+	}
+	return append(result, tail...), nil
+}
+
+// readIndexed reads the indexed events matching f by offset. It reports
+// false if an entry doesn't describe the line it points at, meaning the
+// log was changed behind the index's back.
+func readIndexed(file *os.File, entries []indexEntry, f Filter) ([]Event, bool) {
+	var result []Event
+	for _, entry := range entries {
+		if !f.matchesKey(entry.Type, entry.Actor, entry.Time) {
+			continue
+		}
+		line := make([]byte, entry.Len)
+		if _, err := file.ReadAt(line, entry.Offset); err != nil || line[len(line)-1] != '\n' {
+			return nil, false
+		}
+		e, ok := parseLine(line)
+		if !ok || e.Type != entry.Type || e.Actor != entry.Actor {
+			return nil, false
+		}
+		if f.matches(e) {
+			result = append(result, e)
+		}
+	}
+	return result, true
+}
+
+// readIndex loads the active log's index and returns the entries plus the
+// log offset they cover. The index is ignored (coverage 0) if it doesn't
+// line up with a log of the given size, e.g. after a crash mid-rotation.
+func readIndex(townRoot string, logSize int64) ([]indexEntry, int64) {
+	data, err := os.ReadFile(filepath.Join(townRoot, IndexFile)) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		return nil, 0
+	}
+	var entries []indexEntry
+	var covered int64
+	for _, line := range bytes.Split(data, []byte{'\n'}) {
+		if len(line) == 0 {
+			continue
+		}
+		var e indexEntry
+		if json.Unmarshal(line, &e) != nil || e.Offset != covered || e.Len <= 0 {
+			return nil, 0
+		}
+		covered = e.Offset + e.Len
+		entries = append(entries, e)
+	}
+	if covered > logSize {
+		return nil, 0
+	}
+	return entries, covered
+}
+
+func querySegment(townRoot string, seg Segment, f Filter) ([]Event, error) {
+	r, err := openSegment(townRoot, seg)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return scanEvents(r, f)
+}
+
+// scanEvents parses event lines from r, returning those matching f.
+func scanEvents(r io.Reader, f Filter) ([]Event, error) {
+	var result []Event
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if e, ok := parseLine(scanner.Bytes()); ok && f.matches(e) {
+			result = append(result, e)
+		}
+	}
+	return result, scanner.Err()
+}
+
+func parseLine(line []byte) (Event, bool) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return Event{}, false
+	}
+	var e Event
+	if err := json.Unmarshal(line, &e); err != nil {
+		return Event{}, false
+	}
+	return e, true
+}
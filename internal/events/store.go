@@ -0,0 +1,414 @@
+package events
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/gofrs/flock"
+
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// Files that make up the event store in the town root. The active log
+// stays at .events.jsonl so tailers keep working; rotated segments and
+// their manifest live under ArchiveDir.
+const (
+	// IndexFile is the append-side index of the active log: one
+	// indexEntry per event with its offset, time, type and actor.
+	IndexFile = ".events.idx"
+
+	// ArchiveDir holds gzipped segments rotated out of the active log.
+	ArchiveDir = ".events.d"
+
+	// lockFile serializes appends and rotation across gt processes.
+	lockFile = ".events.lock"
+
+	// manifestFile lists archived segments with their summaries.
+	manifestFile = "segments.json"
+)
+
+// bucketSize is the time bucket granularity of segment summaries.
+const bucketSize = time.Hour
+
+// RotationPolicy controls when the active log is rotated and how long
+// archived segments are kept.
+type RotationPolicy struct {
+	// MaxBytes rotates the active log once it reaches this size.
+	MaxBytes int64
+	// MaxAge rotates the active log once its oldest event is this old.
+	MaxAge time.Duration
+	// Retain deletes segments whose newest event is older than this.
+	// Zero keeps segments forever.
+	Retain time.Duration
+}
+
+// DefaultRotation is the policy applied when events are written.
+var DefaultRotation = RotationPolicy{
+	MaxBytes: 16 << 20,
+	MaxAge:   7 * 24 * time.Hour,
+	Retain:   90 * 24 * time.Hour,
+}
+
+// indexEntry locates one event in the active log.
+type indexEntry struct {
+	Offset int64  `json:"o"`
+	Len    int64  `json:"n"`
+	Time   int64  `json:"ts"` // unix seconds; 0 if the timestamp didn't parse
+	Type   string `json:"t"`
+	Actor  string `json:"a"`
+}
+
+// Segment describes an archived, gzipped slice of the event log.
+type Segment struct {
+	File   string         `json:"file"`
+	Start  time.Time      `json:"start"`
+	End    time.Time      `json:"end"`
+	Count  int            `json:"count"`
+	Bytes  int64          `json:"bytes"` // uncompressed size
+	Types  map[string]int `json:"types"`
+	Actors map[string]int `json:"actors"`
+	// Buckets counts events per hour, keyed by the bucket's unix start.
+	Buckets map[int64]int `json:"buckets"`
+}
+
+func eventTime(ts string) int64 {
+	t, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		return 0
+	}
+	return t.Unix()
+}
+
+func lockStore(townRoot string) *flock.Flock {
+	return flock.New(filepath.Join(townRoot, lockFile))
+}
+
+// appendEvent writes one marshaled event line to the active log, indexes
+// it and rotates the log if the policy says so.
+func appendEvent(townRoot string, event Event, data []byte) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	lock := lockStore(townRoot)
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("locking events file: %w", err)
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	eventsPath := filepath.Join(townRoot, EventsFile)
+	f, err := os.OpenFile(eventsPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644) //nolint:gosec // G302: events file is non-sensitive operational data
+	if err != nil {
+		return fmt.Errorf("opening events file: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("stat events file: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("writing event: %w", err)
+	}
+
+	// The index is an accelerator; queries fall back to scanning the log
+	// when it is missing or behind, so failures here are not fatal.
+	entry := indexEntry{
+		Offset: info.Size(),
+		Len:    int64(len(data)),
+		Time:   eventTime(event.Timestamp),
+		Type:   event.Type,
+		Actor:  event.Actor,
+	}
+	_ = appendIndex(townRoot, entry)
+
+	size := info.Size() + int64(len(data))
+	if rotationDue(townRoot, size, DefaultRotation, time.Now()) {
+		_ = rotateLocked(townRoot, DefaultRotation, time.Now())
+	}
+	return nil
+}
+
+func appendIndex(townRoot string, entry indexEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(townRoot, IndexFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644) //nolint:gosec // G302: index is non-sensitive
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// rotationDue reports whether the active log (size bytes) should rotate.
+func rotationDue(townRoot string, size int64, policy RotationPolicy, now time.Time) bool {
+	if size == 0 {
+		return false
+	}
+	if policy.MaxBytes > 0 && size >= policy.MaxBytes {
+		return true
+	}
+	if policy.MaxAge > 0 {
+		if first, ok := firstIndexTime(townRoot); ok {
+			return now.Sub(time.Unix(first, 0)) >= policy.MaxAge
+		}
+	}
+	return false
+}
+
+// firstIndexTime returns the time of the oldest indexed event.
+func firstIndexTime(townRoot string) (int64, bool) {
+	f, err := os.Open(filepath.Join(townRoot, IndexFile)) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+	line, err := bufio.NewReader(f).ReadBytes('\n')
+	if err != nil {
+		return 0, false
+	}
+	var e indexEntry
+	if json.Unmarshal(line, &e) != nil || e.Time == 0 {
+		return 0, false
+	}
+	return e.Time, true
+}
+
+// Compact rotates the active log if policy says it is due and deletes
+// segments past the retention period. The daemon calls it periodically so
+// quiet towns still rotate by age.
+func Compact(townRoot string, policy RotationPolicy) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	lock := lockStore(townRoot)
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("locking events file: %w", err)
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	now := time.Now()
+	info, err := os.Stat(filepath.Join(townRoot, EventsFile))
+	if err == nil && rotationDue(townRoot, info.Size(), policy, now) {
+		return rotateLocked(townRoot, policy, now) // also prunes
+	}
+	return pruneLocked(townRoot, policy, now)
+}
+
+// Rotate archives the active log now, regardless of policy.
+func Rotate(townRoot string) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	lock := lockStore(townRoot)
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("locking events file: %w", err)
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	return rotateLocked(townRoot, DefaultRotation, time.Now())
+}
+
+// rotateLocked gzips the active log into a dated segment, records it in
+// the manifest and truncates the log and index. The caller holds the lock.
+// The log is truncated rather than renamed so processes tailing it by
+// path see it shrink and start over.
+func rotateLocked(townRoot string, policy RotationPolicy, now time.Time) error {
+	eventsPath := filepath.Join(townRoot, EventsFile)
+	src, err := os.Open(eventsPath) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	defer src.Close()
+
+	archive := filepath.Join(townRoot, ArchiveDir)
+	if err := os.MkdirAll(archive, 0755); err != nil {
+		return fmt.Errorf("creating event archive: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(archive, ".segment-*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating segment: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	seg := Segment{Types: map[string]int{}, Actors: map[string]int{}, Buckets: map[int64]int{}}
+	zw := gzip.NewWriter(tmp)
+	r := bufio.NewReader(src)
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) > 0 && line[len(line)-1] == '\n' {
+			if _, werr := zw.Write(line); werr != nil {
+				tmp.Close()
+				return fmt.Errorf("writing segment: %w", werr)
+			}
+			seg.Bytes += int64(len(line))
+			seg.add(line)
+		}
+		if err != nil {
+			// A trailing partial line is dropped with the truncate; with
+			// the append lock held this only happens after a crash.
+			break
+		}
+	}
+	if err := zw.Close(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("compressing segment: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if seg.Count == 0 {
+		return truncateActive(townRoot)
+	}
+
+	stamp := seg.Start
+	if stamp.IsZero() {
+		stamp = now
+	}
+	name := "events-" + stamp.UTC().Format("20060102T150405Z")
+	seg.File = name + ".jsonl.gz"
+	for i := 1; fileExists(filepath.Join(archive, seg.File)); i++ {
+		seg.File = fmt.Sprintf("%s-%d.jsonl.gz", name, i)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(archive, seg.File)); err != nil {
+		return fmt.Errorf("saving segment: %w", err)
+	}
+
+	segments, err := loadManifest(townRoot)
+	if err != nil {
+		return err
+	}
+	segments = append(segments, seg)
+	if err := saveManifest(townRoot, segments); err != nil {
+		return err
+	}
+	if err := truncateActive(townRoot); err != nil {
+		return err
+	}
+	return pruneLocked(townRoot, policy, now)
+}
+
+// add accounts for one event line in the segment summary.
+func (s *Segment) add(line []byte) {
+	var e Event
+	if json.Unmarshal(line, &e) != nil {
+		return
+	}
+	s.Count++
+	s.Types[e.Type]++
+	s.Actors[e.Actor]++
+	ts := eventTime(e.Timestamp)
+	if ts == 0 {
+		return
+	}
+	t := time.Unix(ts, 0).UTC()
+	if s.Start.IsZero() || t.Before(s.Start) {
+		s.Start = t
+	}
+	if t.After(s.End) {
+		s.End = t
+	}
+	s.Buckets[t.Truncate(bucketSize).Unix()]++
+}
+
+func truncateActive(townRoot string) error {
+	if err := os.Truncate(filepath.Join(townRoot, EventsFile), 0); err != nil {
+		return fmt.Errorf("truncating events file: %w", err)
+	}
+	if err := os.Truncate(filepath.Join(townRoot, IndexFile), 0); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("truncating events index: %w", err)
+	}
+	return nil
+}
+
+// pruneLocked deletes segments older than the retention period.
+func pruneLocked(townRoot string, policy RotationPolicy, now time.Time) error {
+	if policy.Retain <= 0 {
+		return nil
+	}
+	segments, err := loadManifest(townRoot)
+	if err != nil || len(segments) == 0 {
+		return err
+	}
+	cutoff := now.Add(-policy.Retain)
+	var kept []Segment
+	for _, s := range segments {
+		if !s.End.IsZero() && s.End.Before(cutoff) {
+			_ = os.Remove(filepath.Join(townRoot, ArchiveDir, s.File))
+			continue
+		}
+		kept = append(kept, s)
+	}
+	if len(kept) == len(segments) {
+		return nil
+	}
+	return saveManifest(townRoot, kept)
+}
+
+// Segments returns the archived segments, oldest first.
+func Segments(townRoot string) ([]Segment, error) {
+	return loadManifest(townRoot)
+}
+
+func loadManifest(townRoot string) ([]Segment, error) {
+	data, err := os.ReadFile(filepath.Join(townRoot, ArchiveDir, manifestFile)) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading segment manifest: %w", err)
+	}
+	var segments []Segment
+	if err := json.Unmarshal(data, &segments); err != nil {
+		return nil, fmt.Errorf("parsing segment manifest: %w", err)
+	}
+	sort.SliceStable(segments, func(i, j int) bool { return segments[i].Start.Before(segments[j].Start) })
+	return segments, nil
+}
+
+func saveManifest(townRoot string, segments []Segment) error {
+	if segments == nil {
+		segments = []Segment{}
+	}
+	return util.AtomicWriteJSON(filepath.Join(townRoot, ArchiveDir, manifestFile), segments)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// openSegment returns a reader over a segment's decompressed lines.
+func openSegment(townRoot string, seg Segment) (io.ReadCloser, error) {
+	f, err := os.Open(filepath.Join(townRoot, ArchiveDir, seg.File)) //nolint:gosec // G304: segment names come from the manifest
+	if err != nil {
+		return nil, err
+	}
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{zr, f}, nil
+}
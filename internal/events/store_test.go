@@ -0,0 +1,216 @@
+package events
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testEvent(eventType, actor string, ts time.Time) Event {
+	return Event{
+		Timestamp:  ts.UTC().Format(time.RFC3339),
+		Source:     "gt",
+		Type:       eventType,
+		Actor:      actor,
+		Visibility: VisibilityFeed,
+	}
+}
+
+func appendTest(t *testing.T, townRoot string, evts ...Event) {
+	t.Helper()
+	for _, e := range evts {
+		data, _ := json.Marshal(e)
+		if err := appendEvent(townRoot, e, append(data, '\n')); err != nil {
+			t.Fatalf("appendEvent: %v", err)
+		}
+	}
+}
+
+func actors(evts []Event) string {
+	var names []string
+	for _, e := range evts {
+		names = append(names, e.Actor)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestQuery_ActiveLog(t *testing.T) {
+	town := t.TempDir()
+	now := time.Now()
+	appendTest(t, town,
+		testEvent(TypeSling, "mayor", now.Add(-3*time.Hour)),
+		testEvent(TypeDone, "gastown/nux", now.Add(-2*time.Hour)),
+		testEvent(TypeSling, "deacon", now.Add(-time.Hour)),
+		testEvent(TypeSessionStart, "gastown/crew/max", now),
+	)
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   string
+	}{
+		{"all", Filter{}, "mayor,gastown/nux,deacon,gastown/crew/max"},
+		{"type", Filter{Types: []string{TypeSling}}, "mayor,deacon"},
+		{"actor", Filter{Actors: []string{"gastown/nux"}}, "gastown/nux"},
+		{"since", Filter{Since: now.Add(-90 * time.Minute)}, "deacon,gastown/crew/max"},
+		{"until", Filter{Until: now.Add(-150 * time.Minute)}, "mayor"},
+		{"limit", Filter{Limit: 2}, "deacon,gastown/crew/max"},
+		{"match", Filter{Match: func(e Event) bool { return strings.HasPrefix(e.Actor, "gastown/") }}, "gastown/nux,gastown/crew/max"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Query(town, tt.filter)
+			if err != nil {
+				t.Fatalf("Query: %v", err)
+			}
+			if actors(got) != tt.want {
+				t.Errorf("Query = %s, want %s", actors(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestQuery_UnindexedTail(t *testing.T) {
+	town := t.TempDir()
+	appendTest(t, town, testEvent(TypeSling, "indexed", time.Now()))
+
+	// Lines written without the index (older gt, failed index append) are
+	// still found.
+	data, _ := json.Marshal(testEvent(TypeSling, "unindexed", time.Now()))
+	f, err := os.OpenFile(filepath.Join(town, EventsFile), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.Write(append(data, '\n'))
+	f.Close()
+
+	got, err := Query(town, Filter{Types: []string{TypeSling}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actors(got) != "indexed,unindexed" {
+		t.Errorf("Query = %s", actors(got))
+	}
+
+	// A stale index (log replaced underneath it) is ignored.
+	if err := os.WriteFile(filepath.Join(town, EventsFile), append(data, '\n'), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err = Query(town, Filter{Types: []string{TypeSling}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actors(got) != "unindexed" {
+		t.Errorf("Query with stale index = %s", actors(got))
+	}
+}
+
+func TestRotate(t *testing.T) {
+	town := t.TempDir()
+	base := time.Now().UTC().Truncate(time.Hour).Add(-3 * time.Hour)
+	appendTest(t, town,
+		testEvent(TypeSling, "mayor", base),
+		testEvent(TypeDone, "gastown/nux", base.Add(90*time.Minute)),
+	)
+
+	if err := Rotate(town); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(town, EventsFile))
+	if err != nil || info.Size() != 0 {
+		t.Errorf("active log after rotate: %v, size %d", err, info.Size())
+	}
+	segments, err := Segments(town)
+	if err != nil || len(segments) != 1 {
+		t.Fatalf("Segments = %+v, %v", segments, err)
+	}
+	seg := segments[0]
+	if seg.File != "events-"+base.Format("20060102T150405Z")+".jsonl.gz" {
+		t.Errorf("segment file = %s", seg.File)
+	}
+	if seg.Count != 2 || seg.Types[TypeSling] != 1 || seg.Actors["gastown/nux"] != 1 {
+		t.Errorf("segment summary = %+v", seg)
+	}
+	if len(seg.Buckets) != 2 || !seg.Start.Equal(base) || !seg.End.Equal(base.Add(90*time.Minute)) {
+		t.Errorf("segment time summary = %v..%v %v", seg.Start, seg.End, seg.Buckets)
+	}
+
+	// Queries span the archive and the active log.
+	appendTest(t, town, testEvent(TypeSling, "deacon", time.Now()))
+	got, err := Query(town, Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actors(got) != "mayor,gastown/nux,deacon" {
+		t.Errorf("Query across rotation = %s", actors(got))
+	}
+	got, _ = Query(town, Filter{Types: []string{TypeSling}, Limit: 1})
+	if actors(got) != "deacon" {
+		t.Errorf("Query with limit = %s", actors(got))
+	}
+}
+
+func TestQuery_SkipsSegmentsBySummary(t *testing.T) {
+	town := t.TempDir()
+	base := time.Now().UTC().Truncate(time.Hour).Add(-3 * time.Hour)
+	appendTest(t, town, testEvent(TypeSling, "mayor", base))
+	if err := Rotate(town); err != nil {
+		t.Fatal(err)
+	}
+	segments, _ := Segments(town)
+
+	// Corrupt the segment: only queries that need it will fail.
+	if err := os.WriteFile(filepath.Join(town, ArchiveDir, segments[0].File), []byte("not gzip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []Filter{
+		{Types: []string{TypeDone}},
+		{Actors: []string{"deacon"}},
+		{Since: base.Add(2 * time.Hour)},
+		{Until: base.Add(-2 * time.Hour)},
+	} {
+		if _, err := Query(town, f); err != nil {
+			t.Errorf("Query(%+v) opened a non-matching segment: %v", f, err)
+		}
+	}
+	if _, err := Query(town, Filter{Types: []string{TypeSling}}); err == nil {
+		t.Error("expected an error reading the corrupt matching segment")
+	}
+}
+
+func TestRotation_SizeAndRetention(t *testing.T) {
+	town := t.TempDir()
+	saved := DefaultRotation
+	defer func() { DefaultRotation = saved }()
+	DefaultRotation = RotationPolicy{MaxBytes: 300}
+
+	old := time.Now().Add(-48 * time.Hour)
+	for i := 0; i < 6; i++ {
+		appendTest(t, town, testEvent(TypeSling, "mayor", old.Add(time.Duration(i)*time.Minute)))
+	}
+	segments, _ := Segments(town)
+	if len(segments) == 0 {
+		t.Fatal("size-based rotation did not happen")
+	}
+	got, _ := Query(town, Filter{})
+	if len(got) != 6 {
+		t.Errorf("Query after size rotation = %d events, want 6", len(got))
+	}
+
+	// Age-based rotation and retention via Compact.
+	appendTest(t, town, testEvent(TypeDone, "nux", old))
+	if err := Compact(town, RotationPolicy{MaxAge: time.Hour, Retain: 24 * time.Hour}); err != nil {
+		t.Fatalf("Compact: %v", err)
+	}
+	segments, _ = Segments(town)
+	if len(segments) != 0 {
+		t.Errorf("segments past retention = %+v", segments)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(town, ArchiveDir)); len(entries) != 1 {
+		t.Errorf("archive should only hold the manifest, has %d entries", len(entries))
+	}
+}
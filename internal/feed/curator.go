@@ -109,19 +109,36 @@ func (c *Curator) run(file *os.File) {
 	ticker := time.NewTicker(100 * time.Millisecond)
 	defer ticker.Stop()
 
+	// pos is the offset of the next unread byte; partial holds a line
+	// whose newline hasn't been written yet.
+	pos, _ := file.Seek(0, io.SeekCurrent)
+	var partial string
+
 	for {
 		select {
 		case <-c.ctx.Done():
 			return
 
 		case <-ticker.C:
+			// Rotation truncates the log; start over from the top.
+			if info, err := file.Stat(); err == nil && info.Size() < pos {
+				if _, err := file.Seek(0, io.SeekStart); err == nil {
+					reader.Reset(file)
+					pos = 0
+					partial = ""
+				}
+			}
+
 			// Read available lines
 			for {
 				line, err := reader.ReadString('\n')
+				pos += int64(len(line))
 				if err != nil {
+					partial += line
 					break // No more data available
 				}
-				c.processLine(line)
+				c.processLine(partial + line)
+				partial = ""
 			}
 		}
 	}
@@ -217,49 +234,14 @@ func (c *Curator) readRecentFeedEvents(window time.Duration) []FeedEvent {
 	return result
 }
 
-// readRecentEvents reads events from the events file within the given time window.
+// readRecentEvents reads events from the events log within the given time window.
 // ZFC: This is the observable state that replaces in-memory caching.
-// Uses tail-like reading for performance (reads last N lines).
+// Uses events.Query so only the index and recent segments are read.
 func (c *Curator) readRecentEvents(window time.Duration) []events.Event {
-	eventsPath := filepath.Join(c.townRoot, events.EventsFile)
-
-	// Read the file (for small files, this is fine; for large files, consider tail-like reading)
-	data, err := os.ReadFile(eventsPath)
+	result, err := events.Query(c.townRoot, events.Filter{Since: time.Now().Add(-window)})
 	if err != nil {
 		return nil
 	}
-
-	now := time.Now()
-	cutoff := now.Add(-window)
-	var result []events.Event
-
-	// Parse lines from the end (most recent first) for efficiency
-	lines := strings.Split(string(data), "\n")
-	for i := len(lines) - 1; i >= 0; i-- {
-		line := strings.TrimSpace(lines[i])
-		if line == "" {
-			continue
-		}
-
-		var event events.Event
-		if err := json.Unmarshal([]byte(line), &event); err != nil {
-			continue
-		}
-
-		// Parse timestamp
-		ts, err := time.Parse(time.RFC3339, event.Timestamp)
-		if err != nil {
-			continue
-		}
-
-		// Stop if we've gone past the window
-		if ts.Before(cutoff) {
-			break
-		}
-
-		result = append(result, event)
-	}
-
 	return result
 }
 
@@ -368,6 +350,9 @@ func (c *Curator) generateSummary(event *events.Event) string {
 	case events.TypeSessionDeath:
 		session, _ := event.Payload["session"].(string)
 		reason, _ := event.Payload["reason"].(string)
+		if session != "" && reason == events.DeathReasonOOMKill {
+			return fmt.Sprintf("Session %s OOM-killed at its memory limit", session)
+		}
 		if session != "" && reason != "" {
 			return fmt.Sprintf("Session %s terminated: %s", session, reason)
 		}
@@ -387,6 +372,20 @@ func (c *Curator) generateSummary(event *events.Event) string {
 		}
 		return "Multiple sessions died simultaneously"
 
+	case events.TypeThrashing:
+		polecat, _ := event.Payload["polecat"].(string)
+		reason, _ := event.Payload["reason"].(string)
+		return fmt.Sprintf("%s is thrashing: %s", polecat, reason)
+
+	case events.TypeAccountRotated:
+		agent, _ := event.Payload["agent"].(string)
+		from, _ := event.Payload["from"].(string)
+		to, _ := event.Payload["to"].(string)
+		if from != "" {
+			return fmt.Sprintf("%s moved from account %s to %s (usage limit)", agent, from, to)
+		}
+		return fmt.Sprintf("%s moved to account %s (usage limit)", agent, to)
+
 	default:
 		return fmt.Sprintf("%s: %s", event.Actor, event.Type)
 	}
@@ -0,0 +1,661 @@
+package formula
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Composition lets a formula build on others instead of copying them:
+//
+//	extends = "shiny"              # inherit steps, vars and settings
+//
+//	[[steps]]
+//	id = "implement"               # same id: override the inherited step
+//	title = "Implement carefully"
+//
+//	[[steps]]
+//	id = "lint"
+//	after = "implement"            # new step spliced into the chain
+//
+//	[[include]]                    # another formula's steps as backend.*
+//	formula = "release"
+//	prefix = "backend"
+//	needs = ["design"]
+//	vars = { component = "api", version = "{{version}}" }
+//
+//	[[compose.expand]]             # replace a step with an expansion
+//	target = "test"
+//	with = "platform-matrix"       # templates may loop: for_each = "platforms"
+//	                               # ({item}, {item.N}, {index}, {count}, {prev})
+//
+//	[compose]
+//	aspects = ["security-audit"]   # wrap matching steps with advice
+//
+// Resolve applies all of it and returns a plain formula that validates,
+// sorts and runs like any other.
+
+// StringList is a TOML value written either as one string or as an array
+// of strings.
+type StringList []string
+
+// UnmarshalTOML implements toml.Unmarshaler.
+func (l *StringList) UnmarshalTOML(v interface{}) error {
+	switch v := v.(type) {
+	case string:
+		*l = StringList{v}
+	case []interface{}:
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("expected a string, got %T", item)
+			}
+			*l = append(*l, s)
+		}
+	default:
+		return fmt.Errorf("expected a string or array of strings, got %T", v)
+	}
+	return nil
+}
+
+// Loader returns the parsed, unresolved formula with the given name.
+type Loader func(name string) (*Formula, error)
+
+// DirLoader returns a Loader that reads <name>.formula.toml from the first
+// of dirs that has it.
+func DirLoader(dirs ...string) Loader {
+	return func(name string) (*Formula, error) {
+		for _, dir := range dirs {
+			p := filepath.Join(dir, name+".formula.toml")
+			if _, err := os.Stat(p); err == nil {
+				return ParseFile(p)
+			}
+		}
+		return nil, fmt.Errorf("formula %q not found", name)
+	}
+}
+
+// Composed reports whether f extends, includes or composes other formulas
+// (or inserts steps with before/after) and so must be resolved before it
+// can be sorted or run.
+func (f *Formula) Composed() bool {
+	if len(f.Extends) > 0 || len(f.Includes) > 0 || f.Compose != nil {
+		return true
+	}
+	for _, step := range f.Steps {
+		if step.Before != "" || step.After != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve returns f with its composition applied, validated as a whole.
+// vars supplies the lists that for_each templates loop over; declared
+// defaults are used for the rest. A formula that isn't composed is
+// returned as is.
+func (f *Formula) Resolve(load Loader, vars map[string]string) (*Formula, error) {
+	return f.resolve(load, vars, nil)
+}
+
+func (f *Formula) resolve(load Loader, vars map[string]string, stack []string) (*Formula, error) {
+	if !f.Composed() {
+		return f, nil
+	}
+	stack = append(stack, f.Name)
+	loadResolvedWith := func(name string, vars map[string]string) (*Formula, error) {
+		if slices.Contains(stack, name) {
+			return nil, fmt.Errorf("composition cycle: %s -> %s", strings.Join(stack, " -> "), name)
+		}
+		g, err := load(name)
+		if err != nil {
+			return nil, err
+		}
+		return g.resolve(load, vars, stack)
+	}
+	loadResolved := func(name string) (*Formula, error) {
+		return loadResolvedWith(name, vars)
+	}
+
+	r := &Formula{
+		Name:        f.Name,
+		Description: f.Description,
+		Type:        f.Type,
+		Version:     f.Version,
+		Prompts:     f.Prompts,
+		Output:      f.Output,
+		Synthesis:   f.Synthesis,
+		Template:    f.Template,
+		Advice:      f.Advice,
+		Pointcuts:   f.Pointcuts,
+	}
+	r.inheritDecls(f, nil)
+
+	var steps []Step
+	var legs []Leg
+	var aspects []Aspect
+	// A parent's for_each loops use the defaults f redeclares, so a
+	// variant can change a list without repeating the composition.
+	parentVars := f.withDefaults(vars)
+	for _, name := range f.Extends {
+		p, err := loadResolvedWith(name, parentVars)
+		if err != nil {
+			return nil, fmt.Errorf("extends %s: %w", name, err)
+		}
+		steps = append(steps, p.Steps...)
+		legs = append(legs, p.Legs...)
+		aspects = append(aspects, p.Aspects...)
+		r.inheritDecls(p, nil)
+		if r.Description == "" {
+			r.Description = p.Description
+		}
+		if r.Type == "" {
+			r.Type = p.Type
+		}
+		if r.Prompts == nil {
+			r.Prompts = p.Prompts
+		}
+		if r.Output == nil {
+			r.Output = p.Output
+		}
+		if r.Synthesis == nil {
+			r.Synthesis = p.Synthesis
+		}
+	}
+
+	var err error
+	for _, step := range f.Steps {
+		if steps, err = mergeStep(steps, step); err != nil {
+			return nil, err
+		}
+	}
+	legs = overrideByID(legs, f.Legs, func(l Leg) string { return l.ID })
+	aspects = overrideByID(aspects, f.Aspects, func(a Aspect) string { return a.ID })
+
+	for _, inc := range f.Includes {
+		sub, err := loadResolved(inc.Formula)
+		if err != nil {
+			return nil, fmt.Errorf("include %s: %w", inc.Formula, err)
+		}
+		if len(sub.Steps) == 0 {
+			return nil, fmt.Errorf("include %s: not a workflow formula", inc.Formula)
+		}
+		included, err := includeSteps(sub, inc)
+		if err != nil {
+			return nil, fmt.Errorf("include %s: %w", inc.Formula, err)
+		}
+		steps = append(steps, included...)
+		r.inheritDecls(sub, inc.Vars)
+	}
+
+	if f.Compose != nil {
+		for _, rule := range f.Compose.Expand {
+			exp, err := loadResolved(rule.With)
+			if err != nil {
+				return nil, fmt.Errorf("compose.expand %s: %w", rule.With, err)
+			}
+			if exp.Type != TypeExpansion {
+				return nil, fmt.Errorf("compose.expand %s: not an expansion formula", rule.With)
+			}
+			r.inheritDecls(exp, nil)
+			if steps, err = expandStep(steps, rule.Target, exp, r.listVar(vars)); err != nil {
+				return nil, fmt.Errorf("compose.expand %s: %w", rule.With, err)
+			}
+		}
+		for _, name := range f.Compose.Aspects {
+			a, err := loadResolved(name)
+			if err != nil {
+				return nil, fmt.Errorf("compose.aspects %s: %w", name, err)
+			}
+			if len(a.Advice) == 0 {
+				return nil, fmt.Errorf("compose.aspects %s: formula has no advice", name)
+			}
+			if steps, err = applyAdvice(steps, a); err != nil {
+				return nil, fmt.Errorf("compose.aspects %s: %w", name, err)
+			}
+		}
+	}
+
+	r.Steps, r.Legs, r.Aspects = steps, legs, aspects
+	r.inferType()
+	if err := r.Validate(); err != nil {
+		return nil, fmt.Errorf("resolving %s: %w", f.Name, err)
+	}
+	return r, nil
+}
+
+// inheritDecls copies g's inputs and vars that f doesn't declare yet,
+// leaving out the names bound by an include.
+func (f *Formula) inheritDecls(g *Formula, bound map[string]string) {
+	for name, in := range g.Inputs {
+		if _, ok := bound[name]; ok || f.declares(name) {
+			continue
+		}
+		if f.Inputs == nil {
+			f.Inputs = make(map[string]Input)
+		}
+		f.Inputs[name] = in
+	}
+	for name, v := range g.Vars {
+		if _, ok := bound[name]; ok || f.declares(name) {
+			continue
+		}
+		if f.Vars == nil {
+			f.Vars = make(map[string]Var)
+		}
+		f.Vars[name] = v
+	}
+}
+
+func (f *Formula) declares(name string) bool {
+	_, isInput := f.Inputs[name]
+	_, isVar := f.Vars[name]
+	return isInput || isVar
+}
+
+// withDefaults returns vars plus the defaults f declares for the rest.
+func (f *Formula) withDefaults(vars map[string]string) map[string]string {
+	out := make(map[string]string)
+	for name, d := range f.varDecls() {
+		if d.Default != "" {
+			out[name] = d.Default
+		}
+	}
+	for name, v := range vars {
+		out[name] = v
+	}
+	return out
+}
+
+// listVar returns a lookup of for_each lists: the given value, else the
+// declared default.
+func (f *Formula) listVar(vars map[string]string) func(name string) ([]string, error) {
+	return func(name string) ([]string, error) {
+		d, ok := f.varDecls()[name]
+		if !ok {
+			return nil, fmt.Errorf("for_each refers to undeclared variable: %s", name)
+		}
+		v, given := vars[name]
+		if !given {
+			v = d.Default
+		}
+		items := listItems(v)
+		if len(items) == 0 {
+			return nil, fmt.Errorf("for_each list %s is empty", name)
+		}
+		return items, nil
+	}
+}
+
+// listItems splits a comma-separated list value.
+func listItems(v string) []string {
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func stepIndex(steps []Step, id string) int {
+	return slices.IndexFunc(steps, func(s Step) bool { return s.ID == id })
+}
+
+// overrideByID appends own to base, replacing base items with the same ID.
+func overrideByID[T any](base, own []T, id func(T) string) []T {
+	for _, item := range own {
+		i := slices.IndexFunc(base, func(b T) bool { return id(b) == id(item) })
+		if i >= 0 {
+			base[i] = item
+		} else {
+			base = append(base, item)
+		}
+	}
+	return base
+}
+
+// replaceNeed returns needs with old replaced by repl.
+func replaceNeed(needs []string, old string, repl ...string) []string {
+	if !slices.Contains(needs, old) {
+		return needs
+	}
+	var out []string
+	for _, n := range needs {
+		if n != old {
+			out = append(out, n)
+			continue
+		}
+		for _, r := range repl {
+			if !slices.Contains(out, r) {
+				out = append(out, r)
+			}
+		}
+	}
+	return out
+}
+
+// mergeStep adds a formula's own step to the inherited ones: a step with
+// an inherited ID overrides the fields it sets, before/after splice a new
+// step into the chain, and anything else is appended.
+func mergeStep(steps []Step, step Step) ([]Step, error) {
+	if i := stepIndex(steps, step.ID); i >= 0 {
+		if step.Before != "" || step.After != "" {
+			return nil, fmt.Errorf("step %q: before/after only apply to new steps", step.ID)
+		}
+		o := &steps[i]
+		if step.Title != "" {
+			o.Title = step.Title
+		}
+		if step.Description != "" {
+			o.Description = step.Description
+		}
+		if step.When != "" {
+			o.When = step.When
+		}
+		if step.Needs != nil {
+			o.Needs = step.Needs
+		}
+		return steps, nil
+	}
+	switch {
+	case step.After != "":
+		return insertAfter(steps, step.After, step)
+	case step.Before != "":
+		return insertBefore(steps, step.Before, step)
+	}
+	return append(steps, step), nil
+}
+
+// insertAfter splices step in behind target: it needs target, and
+// whatever needed target now needs it.
+func insertAfter(steps []Step, target string, step Step) ([]Step, error) {
+	i := stepIndex(steps, target)
+	if i < 0 {
+		return nil, fmt.Errorf("step %q: after unknown step: %s", step.ID, target)
+	}
+	for j := range steps {
+		steps[j].Needs = replaceNeed(steps[j].Needs, target, step.ID)
+	}
+	step.Before, step.After = "", ""
+	needs := []string{target}
+	for _, n := range step.Needs {
+		if !slices.Contains(needs, n) {
+			needs = append(needs, n)
+		}
+	}
+	step.Needs = needs
+	return slices.Insert(steps, i+1, step), nil
+}
+
+// insertBefore splices step in ahead of target: it takes over target's
+// needs, and target needs it.
+func insertBefore(steps []Step, target string, step Step) ([]Step, error) {
+	i := stepIndex(steps, target)
+	if i < 0 {
+		return nil, fmt.Errorf("step %q: before unknown step: %s", step.ID, target)
+	}
+	step.Before, step.After = "", ""
+	needs := append([]string(nil), steps[i].Needs...)
+	for _, n := range step.Needs {
+		if !slices.Contains(needs, n) {
+			needs = append(needs, n)
+		}
+	}
+	step.Needs = needs
+	steps[i].Needs = []string{step.ID}
+	return slices.Insert(steps, i, step), nil
+}
+
+// includeSteps returns sub's steps renamed under the include's prefix,
+// with its vars bound. Root steps wait on the include's needs.
+func includeSteps(sub *Formula, inc Include) ([]Step, error) {
+	prefix := inc.Prefix
+	if prefix == "" {
+		prefix = inc.Formula
+	}
+	names := make([]string, 0, len(inc.Vars))
+	for name := range inc.Vars {
+		if !sub.declares(name) {
+			return nil, fmt.Errorf("binds unknown variable: %s", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var pairs []string
+	for _, name := range names {
+		pairs = append(pairs, "{{"+name+"}}", inc.Vars[name])
+	}
+	bind := strings.NewReplacer(pairs...)
+
+	var out []Step
+	for _, step := range sub.Steps {
+		s := Step{
+			ID:          prefix + "." + step.ID,
+			Title:       bind.Replace(step.Title),
+			Description: bind.Replace(step.Description),
+		}
+		for _, n := range step.Needs {
+			s.Needs = append(s.Needs, prefix+"."+n)
+		}
+		if len(s.Needs) == 0 {
+			s.Needs = append(s.Needs, inc.Needs...)
+		}
+		when, err := bindWhen(step.When, inc.Vars)
+		if err != nil {
+			return nil, fmt.Errorf("step %q: %w", step.ID, err)
+		}
+		s.When = when
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// varRefPattern matches a binding that is just a reference to an outer
+// variable, like "{{version}}".
+var varRefPattern = regexp.MustCompile(`^\{\{(\w+)\}\}$`)
+
+// bindWhen rewrites a condition for the including formula: a variable
+// bound to "{{outer}}" becomes outer, and one bound to anything else
+// becomes that value as a string.
+func bindWhen(cond string, bindings map[string]string) (string, error) {
+	if strings.TrimSpace(cond) == "" || len(bindings) == 0 {
+		return cond, nil
+	}
+	toks, err := tokenizeWhen(cond)
+	if err != nil {
+		return "", fmt.Errorf("invalid when %q: %w", cond, err)
+	}
+	for i, tok := range toks {
+		v, ok := bindings[tok]
+		if !ok || !isWhenIdent(tok) {
+			continue
+		}
+		if m := varRefPattern.FindStringSubmatch(v); m != nil {
+			toks[i] = m[1]
+		} else if strings.Contains(v, `"`) {
+			toks[i] = "'" + v + "'"
+		} else {
+			toks[i] = strconv.Quote(v)
+		}
+	}
+	return strings.Join(toks, " "), nil
+}
+
+// expandStep replaces the target step with exp's templates. {target},
+// {target.title} and {target.description} refer to the replaced step;
+// templates with for_each repeat once per list item as {item} (see
+// itemReplacer). Root templates take over the target's needs, and
+// whatever needed the target now needs the expansion's last steps.
+func expandStep(steps []Step, target string, exp *Formula, list func(string) ([]string, error)) ([]Step, error) {
+	i := stepIndex(steps, target)
+	if i < 0 {
+		return nil, fmt.Errorf("unknown target step: %s", target)
+	}
+	t := steps[i]
+	sub := strings.NewReplacer("{target}", t.ID, "{target.title}", t.Title, "{target.description}", t.Description)
+
+	type generated struct {
+		step  Step
+		needs []string // template needs, {target} replaced
+		key   string   // template ID, {target} replaced
+		item  string
+		index int
+		loop  bool
+	}
+	var gens []generated
+	copies := make(map[string][]string) // template ID → generated step IDs
+	for _, tmpl := range exp.Template {
+		items, loop := []string{""}, tmpl.ForEach != ""
+		if loop {
+			var err error
+			if items, err = list(tmpl.ForEach); err != nil {
+				return nil, fmt.Errorf("template %q: %w", tmpl.ID, err)
+			}
+		}
+		key := sub.Replace(tmpl.ID)
+		for k, item := range items {
+			rep, err := itemReplacer(item, k, len(items), tmpl)
+			if err != nil {
+				return nil, fmt.Errorf("template %q: %w", tmpl.ID, err)
+			}
+			g := generated{
+				step: Step{
+					ID:          rep.Replace(key),
+					Title:       rep.Replace(sub.Replace(tmpl.Title)),
+					Description: rep.Replace(sub.Replace(tmpl.Description)),
+					When:        t.When,
+				},
+				key:   key,
+				item:  item,
+				index: k,
+				loop:  loop,
+			}
+			for _, n := range tmpl.Needs {
+				g.needs = append(g.needs, sub.Replace(n))
+			}
+			gens = append(gens, g)
+			copies[key] = append(copies[key], g.step.ID)
+		}
+	}
+
+	// A loop template needing another loop template pairs up by item, and
+	// one needing {prev} chains onto its own previous copy; any other
+	// reference to a loop template needs every copy.
+	needed := make(map[string]bool)
+	out := make([]Step, 0, len(gens))
+	for _, g := range gens {
+		for _, n := range g.needs {
+			switch {
+			case g.loop && n == "{prev}":
+				if g.index > 0 {
+					g.step.Needs = append(g.step.Needs, copies[g.key][g.index-1])
+				}
+			case g.loop && strings.Contains(n, "{item}"):
+				g.step.Needs = append(g.step.Needs, strings.ReplaceAll(n, "{item}", g.item))
+			case copies[n] != nil:
+				g.step.Needs = append(g.step.Needs, copies[n]...)
+			default:
+				return nil, fmt.Errorf("template needs unknown template: %s", n)
+			}
+		}
+		if len(g.step.Needs) == 0 {
+			g.step.Needs = append(g.step.Needs, t.Needs...)
+		}
+		for _, n := range g.step.Needs {
+			needed[n] = true
+		}
+		out = append(out, g.step)
+	}
+	var leaves []string
+	for _, s := range out {
+		if !needed[s.ID] {
+			leaves = append(leaves, s.ID)
+		}
+	}
+
+	steps = slices.Replace(steps, i, i+1, out...)
+	for j := range steps {
+		steps[j].Needs = replaceNeed(steps[j].Needs, target, leaves...)
+	}
+	return steps, nil
+}
+
+// itemFieldPattern matches a reference to one colon-separated field of a
+// list item, like {item.2}.
+var itemFieldPattern = regexp.MustCompile(`\{item\.(\d+)\}`)
+
+// itemReplacer fills in a loop template's copy for the item at index k of
+// count: {item} is the item, {item.N} its Nth colon-separated field,
+// {index} its 1-based position and {count} the list's length.
+func itemReplacer(item string, k, count int, tmpl Template) (*strings.Replacer, error) {
+	pairs := []string{"{item}", item, "{index}", strconv.Itoa(k + 1), "{count}", strconv.Itoa(count)}
+	fields := strings.Split(item, ":")
+	for _, text := range []string{tmpl.ID, tmpl.Title, tmpl.Description} {
+		for _, m := range itemFieldPattern.FindAllStringSubmatch(text, -1) {
+			n, _ := strconv.Atoi(m[1])
+			if n < 1 || n > len(fields) {
+				return nil, fmt.Errorf("item %q has no field %d", item, n)
+			}
+			pairs = append(pairs, m[0], strings.TrimSpace(fields[n-1]))
+		}
+	}
+	return strings.NewReplacer(pairs...), nil
+}
+
+// applyAdvice wraps the steps matched by a's advice (and pointcuts, when
+// it has any) with the advice's before and after steps.
+func applyAdvice(steps []Step, a *Formula) ([]Step, error) {
+	original := make([]Step, len(steps))
+	copy(original, steps)
+
+	for _, adv := range a.Advice {
+		for _, s := range original {
+			if !globMatch(adv.Target, s.ID) || !a.inPointcut(s.ID) {
+				continue
+			}
+			rep := strings.NewReplacer("{step.id}", s.ID, "{step.title}", s.Title)
+			var err error
+			for _, b := range adv.Around.Before {
+				st := Step{ID: rep.Replace(b.ID), Title: rep.Replace(b.Title), Description: rep.Replace(b.Description), When: s.When}
+				if steps, err = insertBefore(steps, s.ID, st); err != nil {
+					return nil, err
+				}
+			}
+			prev := s.ID
+			for _, af := range adv.Around.After {
+				st := Step{ID: rep.Replace(af.ID), Title: rep.Replace(af.Title), Description: rep.Replace(af.Description), When: s.When}
+				if steps, err = insertAfter(steps, prev, st); err != nil {
+					return nil, err
+				}
+				prev = st.ID
+			}
+		}
+	}
+	return steps, nil
+}
+
+// inPointcut reports whether advice may apply to the step: it matches
+// one of the formula's pointcuts, or the formula declares none.
+func (f *Formula) inPointcut(id string) bool {
+	if len(f.Pointcuts) == 0 {
+		return true
+	}
+	for _, pc := range f.Pointcuts {
+		if globMatch(pc.Glob, id) {
+			return true
+		}
+	}
+	return false
+}
+
+func globMatch(pattern, id string) bool {
+	ok, err := path.Match(pattern, id)
+	return err == nil && ok
+}
@@ -0,0 +1,341 @@
+package formula
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// mapLoader parses formulas from TOML sources keyed by name.
+func mapLoader(t *testing.T, sources map[string]string) Loader {
+	return func(name string) (*Formula, error) {
+		src, ok := sources[name]
+		if !ok {
+			return nil, fmt.Errorf("formula %q not found", name)
+		}
+		f, err := Parse([]byte(src))
+		if err != nil {
+			t.Fatalf("Parse %s: %v", name, err)
+		}
+		return f, nil
+	}
+}
+
+func stepNeeds(t *testing.T, f *Formula, id string) string {
+	t.Helper()
+	s := f.GetStep(id)
+	if s == nil {
+		t.Fatalf("no step %s in %v", id, f.GetAllIDs())
+	}
+	return strings.Join(s.Needs, ",")
+}
+
+func TestResolveEmbeddedComposition(t *testing.T) {
+	load := DirLoader("formulas")
+
+	enterprise, err := load("shiny-enterprise")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if _, err := enterprise.TopologicalSort(); err == nil {
+		t.Error("TopologicalSort of an unresolved formula should fail")
+	}
+	r, err := enterprise.Resolve(load, nil)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if r.GetStep("implement") != nil || r.GetStep("implement.draft").Title != "Draft: Implement {{feature}}" {
+		t.Errorf("implement not expanded: %v", r.GetAllIDs())
+	}
+	if got := stepNeeds(t, r, "implement.draft"); got != "design" {
+		t.Errorf("draft needs %s", got)
+	}
+	if got := stepNeeds(t, r, "review"); got != "implement.refine-4" {
+		t.Errorf("review needs %s", got)
+	}
+	if _, ok := r.Vars["feature"]; !ok {
+		t.Error("vars not inherited from shiny")
+	}
+	order, err := r.TopologicalSort()
+	if err != nil || len(order) != 9 || order[0] != "design" || order[8] != "submit" {
+		t.Errorf("order = %v, %v", order, err)
+	}
+
+	secure, err := load("shiny-secure")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	r, err = secure.Resolve(load, nil)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	for id, want := range map[string]string{
+		"implement-security-prescan":  "design",
+		"implement":                   "implement-security-prescan",
+		"implement-security-postscan": "implement",
+		"review":                      "implement-security-postscan",
+		"submit-security-prescan":     "test",
+		"submit-security-postscan":    "submit",
+	} {
+		if got := stepNeeds(t, r, id); got != want {
+			t.Errorf("%s needs %s, want %s", id, got, want)
+		}
+	}
+}
+
+func TestResolveExtendsOverrideInsert(t *testing.T) {
+	load := mapLoader(t, map[string]string{
+		"base": `
+formula = "base"
+[vars.feature]
+required = true
+[[steps]]
+id = "design"
+[[steps]]
+id = "build"
+title = "Build {{feature}}"
+needs = ["design"]
+[[steps]]
+id = "ship"
+needs = ["build"]
+`,
+	})
+	f, err := Parse([]byte(`
+formula = "child"
+extends = "base"
+[vars.fast]
+type = "bool"
+[[steps]]
+id = "build"
+title = "Build {{feature}} carefully"
+[[steps]]
+id = "lint"
+after = "build"
+when = "!fast"
+[[steps]]
+id = "plan"
+before = "design"
+[[steps]]
+id = "announce"
+needs = ["ship"]
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	r, err := f.Resolve(load, nil)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if got := strings.Join(r.GetAllIDs(), ","); got != "plan,design,build,lint,ship,announce" {
+		t.Errorf("steps = %s", got)
+	}
+	if r.GetStep("build").Title != "Build {{feature}} carefully" || stepNeeds(t, r, "build") != "design" {
+		t.Errorf("override: %+v", r.GetStep("build"))
+	}
+	if stepNeeds(t, r, "lint") != "build" || stepNeeds(t, r, "ship") != "lint" || stepNeeds(t, r, "design") != "plan" {
+		t.Errorf("insertions not spliced into the chain: %+v", r.Steps)
+	}
+	if r.Type != TypeWorkflow || len(r.Vars) != 2 {
+		t.Errorf("type %s, vars %v", r.Type, r.Vars)
+	}
+
+	bad, _ := Parse([]byte("formula = \"c\"\nextends = \"base\"\n[[steps]]\nid = \"x\"\nafter = \"nope\"\n"))
+	if _, err := bad.Resolve(load, nil); err == nil || !strings.Contains(err.Error(), "after unknown step: nope") {
+		t.Errorf("unknown after: err = %v", err)
+	}
+	loop, _ := Parse([]byte("formula = \"base\"\nextends = \"base\"\n"))
+	if _, err := loop.Resolve(load, nil); err == nil || !strings.Contains(err.Error(), "composition cycle") {
+		t.Errorf("cycle: err = %v", err)
+	}
+}
+
+func TestResolveInclude(t *testing.T) {
+	load := mapLoader(t, map[string]string{
+		"release": `
+formula = "release"
+[vars.component]
+required = true
+[vars.version]
+[vars.dry_run]
+type = "bool"
+[[steps]]
+id = "build"
+title = "Build {{component}} {{version}}"
+[[steps]]
+id = "publish"
+needs = ["build"]
+when = "!dry_run && component != \"docs\""
+`,
+	})
+	f, err := Parse([]byte(`
+formula = "platform"
+[vars.version]
+required = true
+[[steps]]
+id = "design"
+[[include]]
+formula = "release"
+prefix = "api"
+needs = ["design"]
+vars = { component = "api", version = "{{version}}" }
+[[include]]
+formula = "release"
+prefix = "docs"
+vars = { component = "docs" }
+[[steps]]
+id = "announce"
+needs = ["api.publish", "docs.publish"]
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	r, err := f.Resolve(load, nil)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if got := strings.Join(r.GetAllIDs(), ","); got != "design,announce,api.build,api.publish,docs.build,docs.publish" {
+		t.Errorf("steps = %s", got)
+	}
+	if r.GetStep("api.build").Title != "Build api {{version}}" || stepNeeds(t, r, "api.build") != "design" || stepNeeds(t, r, "api.publish") != "api.build" {
+		t.Errorf("api.build = %+v", r.GetStep("api.build"))
+	}
+	if _, ok := r.Vars["component"]; ok {
+		t.Error("bound var leaked into the including formula")
+	}
+	if _, ok := r.Vars["dry_run"]; !ok {
+		t.Error("unbound var not inherited")
+	}
+	run, err := NewRun(r, "p-1", "gastown", "", map[string]string{"version": "1.2"})
+	if err != nil {
+		t.Fatalf("NewRun: %v", err)
+	}
+	if run.Unit("api.build").Title != "Build api 1.2" || run.Unit("api.publish").Status != UnitPending || run.Unit("docs.publish").Status != UnitSkipped {
+		t.Errorf("run units: %q %s %s", run.Unit("api.build").Title, run.Unit("api.publish").Status, run.Unit("docs.publish").Status)
+	}
+}
+
+func TestResolveForEach(t *testing.T) {
+	load := mapLoader(t, map[string]string{
+		"matrix": `
+formula = "matrix"
+type = "expansion"
+[vars.platforms]
+type = "list"
+default = "linux, darwin"
+[[template]]
+id = "{target}.build-{item}"
+title = "Build {target.title} on {item}"
+for_each = "platforms"
+[[template]]
+id = "{target}.test-{item}"
+needs = ["{target}.build-{item}"]
+for_each = "platforms"
+[[template]]
+id = "{target}.report"
+needs = ["{target}.test-{item}"]
+`,
+	})
+	f, err := Parse([]byte(`
+formula = "ci"
+[[steps]]
+id = "checkout"
+[[steps]]
+id = "test"
+title = "tests"
+needs = ["checkout"]
+[[steps]]
+id = "merge"
+needs = ["test"]
+[[compose.expand]]
+target = "test"
+with = "matrix"
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	r, err := f.Resolve(load, nil)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	want := "checkout,test.build-linux,test.build-darwin,test.test-linux,test.test-darwin,test.report,merge"
+	if got := strings.Join(r.GetAllIDs(), ","); got != want {
+		t.Errorf("steps = %s", got)
+	}
+	if r.GetStep("test.build-darwin").Title != "Build tests on darwin" || stepNeeds(t, r, "test.build-darwin") != "checkout" {
+		t.Errorf("build-darwin = %+v", r.GetStep("test.build-darwin"))
+	}
+	if stepNeeds(t, r, "test.test-darwin") != "test.build-darwin" || stepNeeds(t, r, "test.report") != "test.test-linux,test.test-darwin" || stepNeeds(t, r, "merge") != "test.report" {
+		t.Errorf("needs: %+v", r.Steps)
+	}
+
+	r, err = f.Resolve(load, map[string]string{"platforms": "windows"})
+	if err != nil || r.GetStep("test.build-windows") == nil || r.GetStep("test.build-linux") != nil {
+		t.Errorf("given list not used: %v, %v", r.GetAllIDs(), err)
+	}
+	if _, err := f.Resolve(load, map[string]string{"platforms": " , "}); err == nil || !strings.Contains(err.Error(), "empty") {
+		t.Errorf("empty list: err = %v", err)
+	}
+}
+
+// hanoiMoves appends the moves that carry n disks from one peg to another.
+func hanoiMoves(n int, from, to, via string, moves [][3]string) [][3]string {
+	if n == 0 {
+		return moves
+	}
+	moves = hanoiMoves(n-1, from, via, to, moves)
+	moves = append(moves, [3]string{fmt.Sprint(n), from, to})
+	return hanoiMoves(n-1, via, to, from, moves)
+}
+
+// TestTowersOfHanoiFormulas checks the formulas generated by
+// scripts/gen_hanoi.py still spell out the full solution as a chain of
+// steps. They stay flat so bd can cook them.
+func TestTowersOfHanoiFormulas(t *testing.T) {
+	load := DirLoader("formulas")
+	for name, disks := range map[string]int{
+		"towers-of-hanoi-7":  7,
+		"towers-of-hanoi-9":  9,
+		"towers-of-hanoi-10": 10,
+	} {
+		t.Run(name, func(t *testing.T) {
+			f, err := load(name)
+			if err != nil {
+				t.Fatalf("load: %v", err)
+			}
+			if f.Composed() {
+				t.Fatalf("%s uses gt-only composition; bd can't cook it", name)
+			}
+
+			moves := hanoiMoves(disks, "A", "C", "B", nil)
+			want := []Step{{
+				ID:          "setup",
+				Title:       "Verify initial state",
+				Description: fmt.Sprintf("All %d disks stacked on peg A. Largest on bottom.", disks),
+			}}
+			for k, m := range moves {
+				want = append(want, Step{
+					ID:          fmt.Sprintf("move-%d", k+1),
+					Title:       fmt.Sprintf("Move disk %s: %s → %s", m[0], m[1], m[2]),
+					Description: fmt.Sprintf("Move disk %s from peg %s to peg %s. (Move %d/%d)", m[0], m[1], m[2], k+1, len(moves)),
+					Needs:       []string{want[k].ID},
+				})
+			}
+			want = append(want, Step{
+				ID:          "verify",
+				Title:       "Verify final state",
+				Description: fmt.Sprintf("All %d disks now on peg C. Tower intact, all moves were legal.", disks),
+				Needs:       []string{want[len(want)-1].ID},
+			})
+
+			if len(f.Steps) != len(want) {
+				t.Fatalf("%d steps, want %d", len(f.Steps), len(want))
+			}
+			for i, s := range f.Steps {
+				w := want[i]
+				if s.ID != w.ID || s.Title != w.Title || s.Description != w.Description || strings.Join(s.Needs, ",") != strings.Join(w.Needs, ",") {
+					t.Fatalf("step %d = %+v, want %+v", i, s, w)
+				}
+			}
+		})
+	}
+}
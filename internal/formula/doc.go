@@ -114,6 +114,37 @@
 //	ready := f.ReadySteps(completed)
 //	// Returns: ["build"] (test is done, build can run)
 //
+// # Composition
+//
+// A formula can extend another (overriding steps by ID or splicing new
+// ones in with before/after), include another's steps under a prefix with
+// its vars bound, replace a step with an expansion formula (whose
+// templates may loop with for_each over a list var), and wrap steps with
+// an aspect's advice. Such formulas report Composed and are validated once
+// Resolve has flattened them:
+//
+//	f, err = f.Resolve(formula.DirLoader(dir), vars)
+//
+// # Running Formulas
+//
+// NewRun instantiates a workflow, convoy or aspect formula as a Run: one
+// Unit per step, leg or aspect, plus the synthesis. Convoy prompts and
+// output patterns are rendered with the run's variables, and each leg is
+// given the file its Output.LegPattern names. An Executor creates and
+// dispatches the units' beads; Advance dispatches units as their needs
+// finish:
+//
+//	run, err := formula.NewRun(f, "review-1", "gastown", rigPath, vars)
+//	err = run.Instantiate(executor)
+//	for !run.Finished() {
+//	    events, err := run.Advance(executor, time.Now())
+//	    // ...wait...
+//	}
+//
+// gt formula run drives runs with beads and gt sling, saving their state
+// under RunsDir. UpdateRun applies each step under a file lock, so several
+// processes can drive the same saved run.
+//
 // # Embedded Formulas
 //
 // The package includes embedded formula files that can be provisioned
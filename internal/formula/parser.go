@@ -46,7 +46,7 @@ func (f *Formula) inferType() {
 		f.Type = TypeConvoy
 	} else if len(f.Template) > 0 {
 		f.Type = TypeExpansion
-	} else if len(f.Aspects) > 0 {
+	} else if len(f.Aspects) > 0 || len(f.Advice) > 0 {
 		f.Type = TypeAspect
 	}
 }
@@ -58,10 +58,22 @@ func (f *Formula) Validate() error {
 		return fmt.Errorf("formula field is required")
 	}
 
+	// Composed formulas are checked as a whole once resolved.
+	if f.Composed() {
+		return nil
+	}
+
 	if !f.Type.IsValid() {
 		return fmt.Errorf("invalid formula type %q (must be convoy, workflow, expansion, or aspect)", f.Type)
 	}
 
+	if err := f.validateVarDecls(); err != nil {
+		return err
+	}
+	if err := f.validateConditions(); err != nil {
+		return err
+	}
+
 	// Type-specific validation
 	switch f.Type {
 	case TypeConvoy:
@@ -160,6 +172,9 @@ func (f *Formula) validateExpansion() error {
 	// Validate template needs references
 	for _, tmpl := range f.Template {
 		for _, need := range tmpl.Needs {
+			if need == "{prev}" && tmpl.ForEach != "" {
+				continue // the previous copy of a loop template
+			}
 			if !seen[need] {
 				return fmt.Errorf("template %q needs unknown template: %s", tmpl.ID, need)
 			}
@@ -170,8 +185,13 @@ func (f *Formula) validateExpansion() error {
 }
 
 func (f *Formula) validateAspect() error {
-	if len(f.Aspects) == 0 {
-		return fmt.Errorf("aspect formula requires at least one aspect")
+	if len(f.Aspects) == 0 && len(f.Advice) == 0 {
+		return fmt.Errorf("aspect formula requires at least one aspect or advice")
+	}
+	for _, adv := range f.Advice {
+		if adv.Target == "" {
+			return fmt.Errorf("advice missing required target field")
+		}
 	}
 
 	// Check aspect IDs are unique
@@ -235,6 +255,10 @@ func (f *Formula) checkCycles() error {
 // Only applicable to workflow and expansion formulas.
 // Returns an error if there are cycles.
 func (f *Formula) TopologicalSort() ([]string, error) {
+	if f.Composed() {
+		return nil, fmt.Errorf("formula %s must be resolved before sorting", f.Name)
+	}
+
 	var items []string
 	var deps map[string][]string
 
@@ -0,0 +1,560 @@
+package formula
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// UnitStatus is the state of one unit of work in a formula run.
+type UnitStatus string
+
+const (
+	// UnitPending units are waiting on their needs.
+	UnitPending UnitStatus = "pending"
+	// UnitDispatched units have been handed to a worker.
+	UnitDispatched UnitStatus = "dispatched"
+	// UnitDone units' beads are closed.
+	UnitDone UnitStatus = "done"
+	// UnitFailed units could not be created or dispatched.
+	UnitFailed UnitStatus = "failed"
+	// UnitSkipped units' when conditions were false. They count as done.
+	UnitSkipped UnitStatus = "skipped"
+)
+
+// SynthesisID is the unit ID of a convoy or aspect formula's synthesis step.
+const SynthesisID = "synthesis"
+
+// Unit is one dispatchable piece of a formula run: a workflow step, a
+// convoy leg, an aspect, or the synthesis.
+type Unit struct {
+	ID           string     `json:"id"`
+	Kind         string     `json:"kind"` // step, leg, aspect or synthesis
+	Title        string     `json:"title"`
+	Description  string     `json:"description"`      // rendered instructions
+	Needs        []string   `json:"needs,omitempty"`  // unit IDs that must finish first
+	When         string     `json:"when,omitempty"`   // condition the unit ran under
+	Output       string     `json:"output,omitempty"` // file the worker writes its result to
+	BeadID       string     `json:"bead_id,omitempty"`
+	Status       UnitStatus `json:"status"`
+	Error        string     `json:"error,omitempty"`
+	DispatchedAt *time.Time `json:"dispatched_at,omitempty"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+}
+
+// Run is an instantiated formula being executed by gt formula run.
+type Run struct {
+	ID         string            `json:"id"`
+	Formula    string            `json:"formula"`
+	Type       FormulaType       `json:"type"`
+	Rig        string            `json:"rig"`
+	Vars       map[string]string `json:"vars,omitempty"`
+	OutputDir  string            `json:"output_dir,omitempty"`
+	ConvoyID   string            `json:"convoy_id,omitempty"` // bead tracking the whole run
+	Units      []*Unit           `json:"units"`
+	CreatedAt  time.Time         `json:"created_at"`
+	FinishedAt *time.Time        `json:"finished_at,omitempty"`
+}
+
+// Executor carries out a run's side effects. gt formula run implements it
+// with beads and gt sling.
+type Executor interface {
+	// Create creates the bead for a unit and returns its ID. The beads of
+	// the unit's needs already exist.
+	Create(run *Run, u *Unit) (string, error)
+	// Dispatch hands a unit's bead to a worker.
+	Dispatch(run *Run, u *Unit) error
+	// Closed reports whether a unit's bead has been closed.
+	Closed(u *Unit) (bool, error)
+}
+
+// RunEvent reports a change made by Advance.
+type RunEvent struct {
+	Unit *Unit
+	Kind string // dispatched, done, failed or missing-output
+}
+
+// NewRun instantiates f as a run in rig. Relative output directories are
+// resolved against rigPath. Expansion formulas are applied to other
+// formulas and can't be run on their own.
+func NewRun(f *Formula, id, rig, rigPath string, vars map[string]string) (*Run, error) {
+	if f.Composed() {
+		return nil, fmt.Errorf("formula %s must be resolved before running", f.Name)
+	}
+	resolved, err := f.ResolveVars(vars)
+	if err != nil {
+		return nil, err
+	}
+	r := &Run{
+		ID:        id,
+		Formula:   f.Name,
+		Type:      f.Type,
+		Rig:       rig,
+		Vars:      resolved,
+		CreatedAt: time.Now(),
+	}
+
+	switch f.Type {
+	case TypeWorkflow:
+		for _, step := range f.Steps {
+			title := step.Title
+			if title == "" {
+				title = step.ID
+			}
+			r.Units = append(r.Units, &Unit{
+				ID:          step.ID,
+				Kind:        "step",
+				Title:       expandVars(title, resolved),
+				Description: expandVars(step.Description, resolved),
+				Needs:       step.Needs,
+				Status:      UnitPending,
+				When:        step.When,
+			})
+		}
+	case TypeConvoy, TypeAspect:
+		if f.Type == TypeAspect && len(f.Aspects) == 0 {
+			return nil, fmt.Errorf("%s only has advice; apply it with compose.aspects", f.Name)
+		}
+		r.addParallelUnits(f, rigPath)
+	default:
+		return nil, fmt.Errorf("%s formulas can't be run directly", f.Type)
+	}
+
+	for _, u := range r.Units {
+		run, err := EvalWhen(u.When, resolved)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", u.Kind, u.ID, err)
+		}
+		if !run {
+			u.Status = UnitSkipped
+		}
+	}
+	return r, nil
+}
+
+// addParallelUnits adds a convoy's legs (or an aspect formula's aspects)
+// and the synthesis that waits on them.
+func (r *Run) addParallelUnits(f *Formula, rigPath string) {
+	data := r.templateData(f)
+	if f.Output != nil && f.Output.Directory != "" {
+		r.OutputDir = renderTemplate(f.Output.Directory, data)
+		if !filepath.IsAbs(r.OutputDir) {
+			r.OutputDir = filepath.Join(rigPath, r.OutputDir)
+		}
+		data["output"] = map[string]string{
+			"directory": r.OutputDir,
+			"synthesis": f.Output.Synthesis,
+		}
+	}
+
+	kind, legs := "leg", f.Legs
+	if f.Type == TypeAspect {
+		kind = "aspect"
+		legs = nil
+		for _, a := range f.Aspects {
+			legs = append(legs, Leg(a))
+		}
+	}
+
+	var legIDs []string
+	for _, leg := range legs {
+		legData := make(map[string]any, len(data)+2)
+		for k, v := range data {
+			legData[k] = v
+		}
+		legData["leg"] = map[string]string{
+			"id":          leg.ID,
+			"title":       leg.Title,
+			"focus":       leg.Focus,
+			"description": renderTemplate(leg.Description, data),
+		}
+
+		u := &Unit{ID: leg.ID, Kind: kind, Title: leg.Title, Status: UnitPending, When: leg.When}
+		if u.Title == "" {
+			u.Title = leg.ID
+		}
+		if r.OutputDir != "" {
+			name := leg.ID + ".md"
+			if f.Output.LegPattern != "" {
+				name = renderTemplate(f.Output.LegPattern, legData)
+			}
+			u.Output = filepath.Join(r.OutputDir, name)
+		}
+		legData["output_path"] = u.Output
+
+		if base := f.Prompts["base"]; base != "" {
+			u.Description = renderTemplate(base, legData)
+		} else {
+			u.Description = legData["leg"].(map[string]string)["description"]
+			if leg.Focus != "" {
+				u.Description = "Focus: " + leg.Focus + "\n\n" + u.Description
+			}
+			if u.Output != "" {
+				u.Description += "\n\nWrite your output to: " + u.Output
+			}
+		}
+		r.Units = append(r.Units, u)
+		legIDs = append(legIDs, leg.ID)
+	}
+
+	if f.Synthesis == nil {
+		return
+	}
+	u := &Unit{
+		ID:          SynthesisID,
+		Kind:        "synthesis",
+		Title:       f.Synthesis.Title,
+		Description: renderTemplate(f.Synthesis.Description, data),
+		Needs:       f.Synthesis.DependsOn,
+		Status:      UnitPending,
+		When:        f.Synthesis.When,
+	}
+	if u.Title == "" {
+		u.Title = "Synthesis"
+	}
+	if u.Description == "" {
+		u.Description = "Synthesize findings from all legs into unified output."
+	}
+	if len(u.Needs) == 0 {
+		u.Needs = legIDs
+	}
+	if r.OutputDir != "" && f.Output.Synthesis != "" {
+		u.Output = filepath.Join(r.OutputDir, f.Output.Synthesis)
+	}
+	r.Units = append(r.Units, u)
+}
+
+// templateData is what convoy prompts and output patterns can refer to:
+// the run's variables plus formula_name, run_id, review_id and
+// target_description.
+func (r *Run) templateData(f *Formula) map[string]any {
+	data := make(map[string]any, len(r.Vars)+4)
+	for k, v := range r.Vars {
+		data[k] = v
+	}
+	if files := r.Vars["files"]; files != "" {
+		data["files"] = strings.Fields(files)
+	}
+	if pr := r.Vars["pr"]; pr != "" {
+		data["pr_number"] = pr
+	}
+	data["formula_name"] = f.Name
+	data["run_id"] = r.ID
+	data["review_id"] = r.ID
+
+	target := f.Name
+	switch {
+	case r.Vars["pr"] != "":
+		target = "PR #" + r.Vars["pr"]
+	case r.Vars["branch"] != "":
+		target = "branch " + r.Vars["branch"]
+	case r.Vars["files"] != "":
+		target = "files " + r.Vars["files"]
+	}
+	data["target_description"] = target
+	return data
+}
+
+// renderTemplate renders Go text/template text, leaving it as is if it
+// doesn't parse. Missing values render as empty.
+func renderTemplate(text string, data map[string]any) string {
+	if !strings.Contains(text, "{{") {
+		return text
+	}
+	tmpl, err := template.New("formula").Parse(text)
+	if err != nil {
+		return text
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return text
+	}
+	return strings.ReplaceAll(buf.String(), "<no value>", "")
+}
+
+// Unit returns the unit with the given ID, or nil.
+func (r *Run) Unit(id string) *Unit {
+	for _, u := range r.Units {
+		if u.ID == id {
+			return u
+		}
+	}
+	return nil
+}
+
+// ready reports whether every unit u needs is done or skipped.
+func (r *Run) ready(u *Unit) bool {
+	for _, need := range u.Needs {
+		if n := r.Unit(need); n == nil || (n.Status != UnitDone && n.Status != UnitSkipped) {
+			return false
+		}
+	}
+	return true
+}
+
+// Instantiate creates the beads for every unit that doesn't have one,
+// needs first, so the whole run is visible (and its dependencies tracked)
+// before anything is dispatched.
+func (r *Run) Instantiate(x Executor) error {
+	created := make(map[string]bool, len(r.Units))
+	for _, u := range r.Units {
+		if u.BeadID != "" || u.Status == UnitSkipped {
+			created[u.ID] = true
+		}
+	}
+	for len(created) < len(r.Units) {
+		progress := false
+		for _, u := range r.Units {
+			if created[u.ID] || !allIn(u.Needs, created) {
+				continue
+			}
+			id, err := x.Create(r, u)
+			if err != nil {
+				return fmt.Errorf("creating bead for %s: %w", u.ID, err)
+			}
+			u.BeadID = id
+			created[u.ID] = true
+			progress = true
+		}
+		if !progress {
+			return fmt.Errorf("run %s has units with unsatisfiable needs", r.ID)
+		}
+	}
+	return nil
+}
+
+func allIn(ids []string, set map[string]bool) bool {
+	for _, id := range ids {
+		if !set[id] {
+			return false
+		}
+	}
+	return true
+}
+
+// Advance moves the run forward: dispatched units whose beads have closed
+// are marked done, then every pending unit whose needs are done is
+// dispatched. Call it periodically until Finished.
+func (r *Run) Advance(x Executor, now time.Time) ([]RunEvent, error) {
+	var evts []RunEvent
+	for _, u := range r.Units {
+		if u.Status != UnitDispatched {
+			continue
+		}
+		closed, err := x.Closed(u)
+		if err != nil {
+			return evts, fmt.Errorf("checking %s: %w", u.ID, err)
+		}
+		if !closed {
+			continue
+		}
+		u.Status = UnitDone
+		u.CompletedAt = &now
+		evts = append(evts, RunEvent{Unit: u, Kind: "done"})
+		if u.Output != "" {
+			if _, err := os.Stat(u.Output); err != nil {
+				evts = append(evts, RunEvent{Unit: u, Kind: "missing-output"})
+			}
+		}
+	}
+
+	for _, u := range r.Units {
+		if u.Status != UnitPending || !r.ready(u) {
+			continue
+		}
+		if err := r.dispatch(x, u, now); err != nil {
+			u.Status = UnitFailed
+			u.Error = err.Error()
+			evts = append(evts, RunEvent{Unit: u, Kind: "failed"})
+			continue
+		}
+		evts = append(evts, RunEvent{Unit: u, Kind: "dispatched"})
+	}
+
+	if r.FinishedAt == nil && r.Finished() {
+		r.FinishedAt = &now
+	}
+	return evts, nil
+}
+
+func (r *Run) dispatch(x Executor, u *Unit, now time.Time) error {
+	if u.BeadID == "" {
+		id, err := x.Create(r, u)
+		if err != nil {
+			return err
+		}
+		u.BeadID = id
+	}
+	if u.Output != "" {
+		if err := os.MkdirAll(filepath.Dir(u.Output), 0755); err != nil {
+			return fmt.Errorf("creating output directory: %w", err)
+		}
+	}
+	if err := x.Dispatch(r, u); err != nil {
+		return err
+	}
+	u.Status = UnitDispatched
+	u.DispatchedAt = &now
+	u.Error = ""
+	return nil
+}
+
+// Finished reports whether the run can make no more progress: nothing is
+// dispatched and nothing pending can become ready.
+func (r *Run) Finished() bool {
+	for _, u := range r.Units {
+		if u.Status == UnitDispatched {
+			return false
+		}
+	}
+	for _, u := range r.Units {
+		if u.Status == UnitPending && !r.blocked(u, make(map[string]bool)) {
+			return false
+		}
+	}
+	return true
+}
+
+// blocked reports whether u waits, directly or transitively, on a failed unit.
+func (r *Run) blocked(u *Unit, seen map[string]bool) bool {
+	if seen[u.ID] {
+		return false
+	}
+	seen[u.ID] = true
+	for _, need := range u.Needs {
+		n := r.Unit(need)
+		if n == nil || n.Status == UnitFailed || r.blocked(n, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// Succeeded reports whether every unit is done or skipped.
+func (r *Run) Succeeded() bool {
+	for _, u := range r.Units {
+		if u.Status != UnitDone && u.Status != UnitSkipped {
+			return false
+		}
+	}
+	return true
+}
+
+// Counts returns how many units are in each status.
+func (r *Run) Counts() map[UnitStatus]int {
+	counts := make(map[UnitStatus]int)
+	for _, u := range r.Units {
+		counts[u.Status]++
+	}
+	return counts
+}
+
+// RetryFailed puts failed units back to pending so the next Advance
+// dispatches them again. It returns how many were reset.
+func (r *Run) RetryFailed() int {
+	n := 0
+	for _, u := range r.Units {
+		if u.Status == UnitFailed {
+			u.Status = UnitPending
+			n++
+		}
+	}
+	if n > 0 {
+		r.FinishedAt = nil
+	}
+	return n
+}
+
+// RunsDir returns the directory formula run state is kept in.
+func RunsDir(townRoot string) string {
+	return filepath.Join(constants.TownRuntimePath(townRoot), "formula-runs")
+}
+
+// Save writes the run's state under townRoot.
+func (r *Run) Save(townRoot string) error {
+	dir := RunsDir(townRoot)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating runs directory: %w", err)
+	}
+	return util.AtomicWriteJSON(filepath.Join(dir, r.ID+".json"), r)
+}
+
+// LoadRun reads a saved run.
+func LoadRun(townRoot, id string) (*Run, error) {
+	if strings.ContainsAny(id, `/\`) {
+		return nil, fmt.Errorf("invalid run id %q", id)
+	}
+	data, err := os.ReadFile(filepath.Join(RunsDir(townRoot), id+".json")) //nolint:gosec // G304: path is within the town runtime dir
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("formula run %s not found", id)
+		}
+		return nil, err
+	}
+	var r Run
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("parsing formula run %s: %w", id, err)
+	}
+	return &r, nil
+}
+
+// UpdateRun reloads a saved run under an exclusive file lock, applies fn and
+// saves the result, so concurrent `gt formula run` and `gt formula status`
+// processes never act on stale state (e.g. dispatch a unit twice). The run
+// is saved even when fn fails, since a failed step may have made progress;
+// fn's error is returned with the updated run. A nil run means the state
+// itself could not be locked, read or written.
+func UpdateRun(townRoot, id string, fn func(*Run) error) (*Run, error) {
+	if strings.ContainsAny(id, `/\`) {
+		return nil, fmt.Errorf("invalid run id %q", id)
+	}
+	dir := RunsDir(townRoot)
+	if _, err := os.Stat(filepath.Join(dir, id+".json")); os.IsNotExist(err) {
+		return nil, fmt.Errorf("formula run %s not found", id)
+	}
+	lock := flock.New(filepath.Join(dir, id+".lock"))
+	if err := lock.Lock(); err != nil {
+		return nil, fmt.Errorf("locking formula run %s: %w", id, err)
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	r, err := LoadRun(townRoot, id)
+	if err != nil {
+		return nil, err
+	}
+	err = fn(r)
+	if saveErr := r.Save(townRoot); saveErr != nil {
+		return nil, fmt.Errorf("saving run: %w", saveErr)
+	}
+	return r, err
+}
+
+// ListRuns returns the saved runs, newest first.
+func ListRuns(townRoot string) ([]*Run, error) {
+	paths, err := filepath.Glob(filepath.Join(RunsDir(townRoot), "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var runs []*Run
+	for _, path := range paths {
+		r, err := LoadRun(townRoot, strings.TrimSuffix(filepath.Base(path), ".json"))
+		if err != nil {
+			continue
+		}
+		runs = append(runs, r)
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].CreatedAt.After(runs[j].CreatedAt)
+	})
+	return runs, nil
+}
@@ -0,0 +1,261 @@
+package formula
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeExecutor records bead creation and dispatch; beads close when the
+// test says so.
+type fakeExecutor struct {
+	created    []string
+	dispatched []string
+	closed     map[string]bool
+	failOn     string // unit ID whose dispatch fails
+}
+
+func (x *fakeExecutor) Create(run *Run, u *Unit) (string, error) {
+	for _, need := range u.Needs {
+		if n := run.Unit(need); n.BeadID == "" && n.Status != UnitSkipped {
+			return "", fmt.Errorf("%s created before its need %s", u.ID, need)
+		}
+	}
+	x.created = append(x.created, u.ID)
+	return "bd-" + u.ID, nil
+}
+
+func (x *fakeExecutor) Dispatch(run *Run, u *Unit) error {
+	if u.ID == x.failOn {
+		return fmt.Errorf("no polecats available")
+	}
+	x.dispatched = append(x.dispatched, u.ID)
+	return nil
+}
+
+func (x *fakeExecutor) Closed(u *Unit) (bool, error) {
+	return x.closed[u.BeadID], nil
+}
+
+func TestRunConvoy(t *testing.T) {
+	f, err := ParseFile("formulas/code-review.formula.toml")
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	if _, err := NewRun(f, "cr-1", "gastown", "/town/gastown", nil); err == nil || !strings.Contains(err.Error(), "pr") {
+		t.Errorf("NewRun without a target: err = %v", err)
+	}
+
+	rigPath := t.TempDir()
+	r, err := NewRun(f, "cr-1", "gastown", rigPath, map[string]string{"pr": "123"})
+	if err != nil {
+		t.Fatalf("NewRun: %v", err)
+	}
+	if len(r.Units) != len(f.Legs)+1 {
+		t.Fatalf("got %d units, want %d legs + synthesis", len(r.Units), len(f.Legs))
+	}
+	if r.OutputDir != filepath.Join(rigPath, ".reviews", "cr-1") {
+		t.Errorf("OutputDir = %s", r.OutputDir)
+	}
+	leg := r.Unit("security")
+	if leg.Output != filepath.Join(r.OutputDir, "security-findings.md") {
+		t.Errorf("leg output = %s", leg.Output)
+	}
+	for _, want := range []string{"PR #123", leg.Output, "**Leg ID**: security", "code-review"} {
+		if !strings.Contains(leg.Description, want) {
+			t.Errorf("leg prompt missing %q", want)
+		}
+	}
+	syn := r.Unit(SynthesisID)
+	if len(syn.Needs) != len(f.Legs) || syn.Output != filepath.Join(r.OutputDir, "review-summary.md") {
+		t.Errorf("synthesis = %+v", syn)
+	}
+	if !strings.Contains(syn.Description, r.OutputDir+"/") {
+		t.Errorf("synthesis description doesn't name the output directory:\n%s", syn.Description)
+	}
+
+	x := &fakeExecutor{closed: make(map[string]bool)}
+	if err := r.Instantiate(x); err != nil {
+		t.Fatalf("Instantiate: %v", err)
+	}
+	if len(x.created) != len(r.Units) || x.created[len(x.created)-1] != SynthesisID {
+		t.Errorf("created = %v", x.created)
+	}
+
+	now := time.Now()
+	if _, err := r.Advance(x, now); err != nil {
+		t.Fatalf("Advance: %v", err)
+	}
+	if len(x.dispatched) != len(f.Legs) || syn.Status != UnitPending {
+		t.Fatalf("first wave dispatched %v, synthesis %s", x.dispatched, syn.Status)
+	}
+	if _, err := os.Stat(r.OutputDir); err != nil {
+		t.Errorf("output directory not created: %v", err)
+	}
+
+	// Synthesis waits for every leg.
+	for _, u := range r.Units[:len(r.Units)-2] {
+		x.closed[u.BeadID] = true
+	}
+	if err := os.WriteFile(r.Unit("correctness").Output, []byte("# findings\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	evts, _ := r.Advance(x, now)
+	if syn.Status != UnitPending {
+		t.Fatal("synthesis dispatched before every leg finished")
+	}
+	missing := 0
+	for _, e := range evts {
+		if e.Kind == "missing-output" {
+			missing++
+		}
+	}
+	if missing != len(f.Legs)-2 {
+		t.Errorf("missing-output events = %d, want %d", missing, len(f.Legs)-2)
+	}
+
+	x.closed[r.Units[len(r.Units)-2].BeadID] = true
+	_, _ = r.Advance(x, now)
+	if syn.Status != UnitDispatched || r.Finished() {
+		t.Fatalf("synthesis %s, finished %v", syn.Status, r.Finished())
+	}
+	x.closed[syn.BeadID] = true
+	_, _ = r.Advance(x, now)
+	if !r.Finished() || !r.Succeeded() || r.FinishedAt == nil {
+		t.Errorf("run not finished: %+v", r.Counts())
+	}
+}
+
+func TestRunWorkflowFailure(t *testing.T) {
+	f, err := Parse([]byte(`
+formula = "ship"
+[vars.issue]
+required = true
+[[steps]]
+id = "build"
+title = "Build {{issue}}"
+[[steps]]
+id = "test"
+needs = ["build"]
+[[steps]]
+id = "docs"
+[[steps]]
+id = "release"
+needs = ["test", "docs"]
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if _, err := NewRun(f, "ship-1", "gastown", "", nil); err == nil {
+		t.Error("expected missing issue var error")
+	}
+	r, err := NewRun(f, "ship-1", "gastown", "", map[string]string{"issue": "gt-42"})
+	if err != nil {
+		t.Fatalf("NewRun: %v", err)
+	}
+	if r.Unit("build").Title != "Build gt-42" {
+		t.Errorf("title = %q", r.Unit("build").Title)
+	}
+
+	x := &fakeExecutor{closed: make(map[string]bool), failOn: "docs"}
+	now := time.Now()
+	_, _ = r.Advance(x, now)
+	if r.Unit("build").Status != UnitDispatched || r.Unit("docs").Status != UnitFailed || r.Unit("test").Status != UnitPending {
+		t.Fatalf("statuses: %+v", r.Counts())
+	}
+	x.closed["bd-build"] = true
+	_, _ = r.Advance(x, now)
+	x.closed["bd-test"] = true
+	_, _ = r.Advance(x, now)
+	if !r.Finished() || r.Succeeded() || r.Unit("release").Status != UnitPending {
+		t.Fatalf("run should be stuck on failed docs: %+v", r.Counts())
+	}
+
+	x.failOn = ""
+	if n := r.RetryFailed(); n != 1 || r.FinishedAt != nil {
+		t.Fatalf("RetryFailed = %d", n)
+	}
+	_, _ = r.Advance(x, now)
+	x.closed["bd-docs"] = true
+	_, _ = r.Advance(x, now)
+	x.closed["bd-release"] = true
+	_, _ = r.Advance(x, now)
+	if !r.Succeeded() {
+		t.Errorf("run did not succeed after retry: %+v", r.Counts())
+	}
+}
+
+func TestRunSaveLoad(t *testing.T) {
+	townRoot := t.TempDir()
+	r := &Run{ID: "ship-1", Formula: "ship", Units: []*Unit{{ID: "a", Status: UnitDone}}, CreatedAt: time.Now()}
+	if err := r.Save(townRoot); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	loaded, err := LoadRun(townRoot, "ship-1")
+	if err != nil || loaded.Unit("a").Status != UnitDone {
+		t.Fatalf("LoadRun = %+v, %v", loaded, err)
+	}
+	runs, err := ListRuns(townRoot)
+	if err != nil || len(runs) != 1 {
+		t.Errorf("ListRuns = %d, %v", len(runs), err)
+	}
+	if _, err := LoadRun(townRoot, "../x"); err == nil {
+		t.Error("expected error for a path in the run id")
+	}
+}
+
+func TestUpdateRunConcurrent(t *testing.T) {
+	townRoot := t.TempDir()
+	r := &Run{ID: "ship-1", Formula: "ship", Units: []*Unit{{ID: "a", Status: UnitPending}}, CreatedAt: time.Now()}
+	if err := r.Save(townRoot); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	// Several drivers advancing the same saved run dispatch the unit once.
+	var mu sync.Mutex
+	dispatched := 0
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := UpdateRun(townRoot, "ship-1", func(r *Run) error {
+				for _, u := range r.Units {
+					if u.Status == UnitPending {
+						u.Status = UnitDispatched
+						mu.Lock()
+						dispatched++
+						mu.Unlock()
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				t.Errorf("UpdateRun: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if dispatched != 1 {
+		t.Errorf("dispatched %d times, want 1", dispatched)
+	}
+
+	// fn's error is returned after the run is saved.
+	got, err := UpdateRun(townRoot, "ship-1", func(r *Run) error {
+		r.Unit("a").Status = UnitDone
+		return fmt.Errorf("boom")
+	})
+	if err == nil || got == nil {
+		t.Fatalf("UpdateRun = %v, %v; want run and error", got, err)
+	}
+	if loaded, _ := LoadRun(townRoot, "ship-1"); loaded.Unit("a").Status != UnitDone {
+		t.Errorf("status after failed update = %s, want done", loaded.Unit("a").Status)
+	}
+	if _, err := UpdateRun(townRoot, "missing", func(*Run) error { return nil }); err == nil {
+		t.Error("expected error for a missing run")
+	}
+}
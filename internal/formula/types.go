@@ -46,6 +46,15 @@ type Formula struct {
 
 	// Aspect-specific (similar to convoy but for analysis)
 	Aspects []Aspect `toml:"aspects"`
+
+	// Advice and pointcuts wrap other formulas' steps (see compose.aspects)
+	Advice    []Advice   `toml:"advice"`
+	Pointcuts []Pointcut `toml:"pointcuts"`
+
+	// Composition, applied by Resolve
+	Extends  StringList `toml:"extends"`
+	Includes []Include  `toml:"include"`
+	Compose  *Compose   `toml:"compose"`
 }
 
 // Aspect represents a parallel analysis aspect in an aspect formula.
@@ -54,15 +63,17 @@ type Aspect struct {
 	Title       string `toml:"title"`
 	Focus       string `toml:"focus"`
 	Description string `toml:"description"`
+	When        string `toml:"when"` // condition on inputs; skipped when false
 }
 
 // Input represents an input parameter for a formula.
 type Input struct {
 	Description    string   `toml:"description"`
-	Type           string   `toml:"type"`
+	Type           string   `toml:"type"` // see VarTypes; defaults to string
 	Required       bool     `toml:"required"`
 	RequiredUnless []string `toml:"required_unless"`
 	Default        string   `toml:"default"`
+	Values         []string `toml:"values"` // allowed values for enum
 }
 
 // Output configures where formula outputs are written.
@@ -78,6 +89,7 @@ type Leg struct {
 	Title       string `toml:"title"`
 	Focus       string `toml:"focus"`
 	Description string `toml:"description"`
+	When        string `toml:"when"` // condition on inputs; skipped when false
 }
 
 // Synthesis represents the synthesis step that combines leg outputs.
@@ -85,6 +97,7 @@ type Synthesis struct {
 	Title       string   `toml:"title"`
 	Description string   `toml:"description"`
 	DependsOn   []string `toml:"depends_on"`
+	When        string   `toml:"when"` // condition on inputs; skipped when false
 }
 
 // Step represents a sequential step in a workflow formula.
@@ -93,6 +106,9 @@ type Step struct {
 	Title       string   `toml:"title"`
 	Description string   `toml:"description"`
 	Needs       []string `toml:"needs"`
+	When        string   `toml:"when"`   // condition on vars; skipped when false
+	Before      string   `toml:"before"` // insert ahead of this inherited step
+	After       string   `toml:"after"`  // insert behind this inherited step
 }
 
 // Template represents a template step in an expansion formula.
@@ -101,13 +117,62 @@ type Template struct {
 	Title       string   `toml:"title"`
 	Description string   `toml:"description"`
 	Needs       []string `toml:"needs"`
+	ForEach     string   `toml:"for_each"` // list var; one copy per {item}, {index} of {count}
+}
+
+// Include pulls another workflow formula's steps into this one.
+type Include struct {
+	Formula string            `toml:"formula"`
+	Prefix  string            `toml:"prefix"` // step ID prefix; defaults to the formula name
+	Needs   []string          `toml:"needs"`  // what the included root steps wait for
+	Vars    map[string]string `toml:"vars"`   // bindings for the included formula's vars
+}
+
+// Compose applies expansion and aspect formulas to this formula's steps.
+type Compose struct {
+	Expand  []ExpandRule `toml:"expand"`
+	Aspects []string     `toml:"aspects"`
+}
+
+// ExpandRule replaces the Target step with the templates of the With
+// expansion formula.
+type ExpandRule struct {
+	Target string `toml:"target"`
+	With   string `toml:"with"`
+}
+
+// Advice wraps the steps matching Target (a glob) with extra steps.
+type Advice struct {
+	Target string       `toml:"target"`
+	Around AdviceAround `toml:"around"`
+}
+
+// AdviceAround lists the steps advice inserts before and after a target.
+type AdviceAround struct {
+	Before []AdviceStep `toml:"before"`
+	After  []AdviceStep `toml:"after"`
+}
+
+// AdviceStep is a step inserted by advice. {step.id} and {step.title}
+// refer to the advised step.
+type AdviceStep struct {
+	ID          string `toml:"id"`
+	Title       string `toml:"title"`
+	Description string `toml:"description"`
+}
+
+// Pointcut limits advice to the steps matching Glob.
+type Pointcut struct {
+	Glob string `toml:"glob"`
 }
 
 // Var represents a variable definition for formulas.
 type Var struct {
-	Description string `toml:"description"`
-	Required    bool   `toml:"required"`
-	Default     string `toml:"default"`
+	Description string   `toml:"description"`
+	Type        string   `toml:"type"` // see VarTypes; defaults to string
+	Required    bool     `toml:"required"`
+	Default     string   `toml:"default"`
+	Values      []string `toml:"values"` // allowed values for enum
 }
 
 // IsValid returns true if the formula type is recognized.
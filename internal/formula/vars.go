@@ -0,0 +1,233 @@
+package formula
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// VarTypes are the types an input or var can declare. "number" and
+// "integer" are accepted as aliases for int; a list is comma-separated.
+var VarTypes = []string{"string", "int", "bool", "enum", "path", "bead-id", "list"}
+
+// beadIDPattern matches bead IDs like gt-abc12, hq-cv-x7k2m or gt-abc.1.
+var beadIDPattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9]*-[a-zA-Z0-9][a-zA-Z0-9.-]*$`)
+
+// varDecl is an input or var declaration.
+type varDecl struct {
+	Type           string
+	Values         []string
+	Required       bool
+	RequiredUnless []string
+	Default        string
+}
+
+// varDecls returns the formula's inputs and vars by name.
+func (f *Formula) varDecls() map[string]varDecl {
+	decls := make(map[string]varDecl, len(f.Inputs)+len(f.Vars))
+	for name, in := range f.Inputs {
+		decls[name] = varDecl{Type: in.Type, Values: in.Values, Required: in.Required, RequiredUnless: in.RequiredUnless, Default: in.Default}
+	}
+	for name, v := range f.Vars {
+		decls[name] = varDecl{Type: v.Type, Values: v.Values, Required: v.Required, Default: v.Default}
+	}
+	return decls
+}
+
+// normalizeVarType maps a declared type to one of VarTypes.
+func normalizeVarType(t string) string {
+	switch t {
+	case "":
+		return "string"
+	case "number", "integer":
+		return "int"
+	}
+	return t
+}
+
+// validateVarDecls checks input and var declarations: known types, enums
+// with values, and defaults of the declared type.
+func (f *Formula) validateVarDecls() error {
+	decls := f.varDecls()
+	names := make([]string, 0, len(decls))
+	for name := range decls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		d := decls[name]
+		typ := normalizeVarType(d.Type)
+		known := false
+		for _, t := range VarTypes {
+			known = known || t == typ
+		}
+		if !known {
+			return fmt.Errorf("variable %q has unknown type %q (must be one of %s)", name, d.Type, strings.Join(VarTypes, ", "))
+		}
+		if typ == "enum" && len(d.Values) == 0 {
+			return fmt.Errorf("enum variable %q has no values", name)
+		}
+		if d.Default != "" {
+			if _, err := checkVarValue(d, d.Default, nil); err != nil {
+				return fmt.Errorf("variable %q default: %w", name, err)
+			}
+		}
+		for _, other := range d.RequiredUnless {
+			if _, ok := decls[other]; !ok {
+				return fmt.Errorf("variable %q required_unless references unknown variable: %s", name, other)
+			}
+		}
+	}
+	return nil
+}
+
+// checkVarValue checks v against d's type and returns it normalized.
+// beadExists, if set, is used to check bead-id values exist.
+func checkVarValue(d varDecl, v string, beadExists func(id string) (bool, error)) (string, error) {
+	switch normalizeVarType(d.Type) {
+	case "int":
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return "", fmt.Errorf("expected an int, got %q", v)
+		}
+		return strconv.Itoa(n), nil
+	case "bool":
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "yes", "on":
+			return "true", nil
+		case "no", "off":
+			return "false", nil
+		}
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err != nil {
+			return "", fmt.Errorf("expected true or false, got %q", v)
+		}
+		return strconv.FormatBool(b), nil
+	case "enum":
+		for _, allowed := range d.Values {
+			if v == allowed {
+				return v, nil
+			}
+		}
+		return "", fmt.Errorf("%q is not one of %s", v, strings.Join(d.Values, ", "))
+	case "path":
+		if strings.ContainsRune(v, 0) || strings.TrimSpace(v) == "" {
+			return "", fmt.Errorf("invalid path %q", v)
+		}
+		return filepath.Clean(v), nil
+	case "list":
+		return strings.Join(listItems(v), ","), nil
+	case "bead-id":
+		v = strings.TrimSpace(v)
+		if !beadIDPattern.MatchString(v) {
+			return "", fmt.Errorf("%q is not a bead ID", v)
+		}
+		if beadExists != nil {
+			ok, err := beadExists(v)
+			if err != nil {
+				return "", fmt.Errorf("checking bead %s: %w", v, err)
+			}
+			if !ok {
+				return "", fmt.Errorf("bead %s does not exist", v)
+			}
+		}
+		return v, nil
+	}
+	return v, nil
+}
+
+// ValidateVars checks the values given for a formula's inputs and vars,
+// fills in defaults, and returns the resolved values along with every
+// problem found: unknown names, missing required values, and values of
+// the wrong type. beadExists, if set, checks bead-id values exist.
+func (f *Formula) ValidateVars(given map[string]string, beadExists func(id string) (bool, error)) (map[string]string, []error) {
+	decls := f.varDecls()
+	vars := make(map[string]string, len(given)+len(decls))
+	var problems []error
+
+	for name, v := range given {
+		d, declared := decls[name]
+		if !declared {
+			if len(decls) > 0 {
+				problems = append(problems, fmt.Errorf("%s: unknown variable", name))
+			}
+			vars[name] = v
+			continue
+		}
+		if v == "" {
+			vars[name] = v
+			continue
+		}
+		normalized, err := checkVarValue(d, v, beadExists)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		vars[name] = normalized
+	}
+
+	for name, d := range decls {
+		if vars[name] != "" {
+			continue
+		}
+		if d.Default != "" {
+			vars[name], _ = checkVarValue(d, d.Default, nil)
+			continue
+		}
+		if given[name] != "" {
+			continue // already reported as a bad value
+		}
+		if d.Required {
+			problems = append(problems, fmt.Errorf("%s: required", name))
+			continue
+		}
+		// required_unless: the input is required unless one of the others is set.
+		if len(d.RequiredUnless) > 0 {
+			satisfied := false
+			for _, other := range d.RequiredUnless {
+				if given[other] != "" || decls[other].Default != "" {
+					satisfied = true
+					break
+				}
+			}
+			if !satisfied {
+				problems = append(problems, fmt.Errorf("%s: required unless one of %s is set", name, strings.Join(d.RequiredUnless, ", ")))
+			}
+		}
+	}
+
+	sort.Slice(problems, func(i, j int) bool {
+		return problems[i].Error() < problems[j].Error()
+	})
+	return vars, problems
+}
+
+// ResolveVars checks the values given for a formula's inputs and vars and
+// fills in defaults, failing with every problem found.
+func (f *Formula) ResolveVars(given map[string]string) (map[string]string, error) {
+	vars, problems := f.ValidateVars(given, nil)
+	if len(problems) > 0 {
+		msgs := make([]string, len(problems))
+		for i, p := range problems {
+			msgs[i] = p.Error()
+		}
+		return nil, fmt.Errorf("invalid variables: %s", strings.Join(msgs, "; "))
+	}
+	return vars, nil
+}
+
+// expandVars substitutes {{name}} placeholders in workflow step text.
+// Placeholders without a value are left as they are.
+func expandVars(text string, vars map[string]string) string {
+	if !strings.Contains(text, "{{") {
+		return text
+	}
+	for name, value := range vars {
+		text = strings.ReplaceAll(text, "{{"+name+"}}", value)
+	}
+	return text
+}
@@ -0,0 +1,149 @@
+package formula
+
+import (
+	"strings"
+	"testing"
+)
+
+const typedFormula = `
+formula = "deploy"
+
+[inputs.issue]
+type = "bead-id"
+required = true
+
+[inputs.replicas]
+type = "int"
+default = "2"
+
+[inputs.canary]
+type = "bool"
+
+[inputs.env]
+type = "enum"
+values = ["staging", "prod"]
+default = "staging"
+
+[inputs.manifest]
+type = "path"
+
+[[steps]]
+id = "build"
+
+[[steps]]
+id = "canary"
+needs = ["build"]
+when = "canary && env == \"prod\""
+
+[[steps]]
+id = "rollout"
+needs = ["canary"]
+when = "replicas > 0"
+`
+
+func TestValidateVars(t *testing.T) {
+	f, err := Parse([]byte(typedFormula))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	vars, problems := f.ValidateVars(map[string]string{
+		"issue":    "gt-abc12",
+		"canary":   "TRUE",
+		"manifest": "deploy/../deploy/app.yaml",
+	}, nil)
+	if len(problems) != 0 {
+		t.Fatalf("problems: %v", problems)
+	}
+	if vars["canary"] != "true" || vars["replicas"] != "2" || vars["env"] != "staging" || vars["manifest"] != "deploy/app.yaml" {
+		t.Errorf("resolved vars = %v", vars)
+	}
+
+	// Every problem is reported, not just the first.
+	exists := func(id string) (bool, error) { return id == "gt-abc12", nil }
+	_, problems = f.ValidateVars(map[string]string{
+		"issue":    "gt-zzz99",
+		"replicas": "three",
+		"canary":   "maybe",
+		"env":      "dev",
+		"colour":   "blue",
+	}, exists)
+	var msgs []string
+	for _, p := range problems {
+		msgs = append(msgs, p.Error())
+	}
+	got := strings.Join(msgs, "\n")
+	for _, want := range []string{
+		"canary: expected true or false",
+		"colour: unknown variable",
+		`env: "dev" is not one of staging, prod`,
+		"issue: bead gt-zzz99 does not exist",
+		"replicas: expected an int",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("problems missing %q:\n%s", want, got)
+		}
+	}
+	if len(problems) != 5 {
+		t.Errorf("got %d problems, want 5:\n%s", len(problems), got)
+	}
+
+	_, problems = f.ValidateVars(map[string]string{"issue": "not a bead"}, nil)
+	if len(problems) != 1 || !strings.Contains(problems[0].Error(), "not a bead ID") {
+		t.Errorf("bad bead ID: %v", problems)
+	}
+	if _, err := f.ResolveVars(nil); err == nil || !strings.Contains(err.Error(), "issue: required") {
+		t.Errorf("ResolveVars(nil) = %v", err)
+	}
+}
+
+func TestVarDeclValidation(t *testing.T) {
+	tests := []struct {
+		decl string
+		want string
+	}{
+		{"[vars.x]\ntype = \"float\"", "unknown type"},
+		{"[vars.x]\ntype = \"enum\"", "has no values"},
+		{"[vars.x]\ntype = \"int\"\ndefault = \"many\"", "default: expected an int"},
+		{"[inputs.x]\nrequired_unless = [\"y\"]", "unknown variable: y"},
+	}
+	for _, tt := range tests {
+		_, err := Parse([]byte("formula = \"f\"\n" + tt.decl + "\n[[steps]]\nid = \"a\"\n"))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: err = %v, want %q", tt.decl, err, tt.want)
+		}
+	}
+}
+
+func TestRunSkipsStepsByCondition(t *testing.T) {
+	f, err := Parse([]byte(typedFormula))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	r, err := NewRun(f, "deploy-1", "gastown", "", map[string]string{"issue": "gt-abc12", "canary": "true"})
+	if err != nil {
+		t.Fatalf("NewRun: %v", err)
+	}
+	if r.Unit("canary").Status != UnitSkipped || r.Unit("rollout").Status != UnitPending {
+		t.Fatalf("statuses: canary %s, rollout %s", r.Unit("canary").Status, r.Unit("rollout").Status)
+	}
+
+	x := &fakeExecutor{closed: make(map[string]bool)}
+	if err := r.Instantiate(x); err != nil {
+		t.Fatalf("Instantiate: %v", err)
+	}
+	if strings.Join(x.created, ",") != "build,rollout" {
+		t.Errorf("created = %v", x.created)
+	}
+	_, _ = r.Advance(x, r.CreatedAt)
+	x.closed["bd-build"] = true
+	_, _ = r.Advance(x, r.CreatedAt)
+	if r.Unit("rollout").Status != UnitDispatched {
+		t.Errorf("rollout should follow build past the skipped canary, got %s", r.Unit("rollout").Status)
+	}
+	x.closed["bd-rollout"] = true
+	_, _ = r.Advance(x, r.CreatedAt)
+	if !r.Succeeded() {
+		t.Errorf("run should succeed with skipped units: %v", r.Counts())
+	}
+}
@@ -0,0 +1,326 @@
+package formula
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// When conditions decide whether a step, leg or aspect runs. They are
+// small expressions over the formula's inputs and vars:
+//
+//	when = "pr"                        # pr is set (and not false/0)
+//	when = "!quick"                    # quick is unset, false or 0
+//	when = "depth >= 2 && mode != \"lite\""
+//	when = "(target == \"prod\" || force) && !dry_run"
+//
+// Comparisons are numeric when both sides are numbers and string
+// comparisons otherwise. Unset names compare as "".
+
+// whenExpr is a parsed when condition.
+type whenExpr interface {
+	value(vars map[string]string) string
+}
+
+type whenVar string
+type whenLit string
+type whenNot struct{ x whenExpr }
+type whenBinary struct {
+	op   string
+	l, r whenExpr
+}
+
+func (v whenVar) value(vars map[string]string) string { return vars[string(v)] }
+func (l whenLit) value(map[string]string) string      { return string(l) }
+func (n whenNot) value(vars map[string]string) string { return boolValue(!truthy(n.x.value(vars))) }
+
+func (b whenBinary) value(vars map[string]string) string {
+	switch b.op {
+	case "&&":
+		return boolValue(truthy(b.l.value(vars)) && truthy(b.r.value(vars)))
+	case "||":
+		return boolValue(truthy(b.l.value(vars)) || truthy(b.r.value(vars)))
+	}
+	l, r := b.l.value(vars), b.r.value(vars)
+	cmp := strings.Compare(l, r)
+	if lf, err := strconv.ParseFloat(l, 64); err == nil {
+		if rf, err := strconv.ParseFloat(r, 64); err == nil {
+			switch {
+			case lf < rf:
+				cmp = -1
+			case lf > rf:
+				cmp = 1
+			default:
+				cmp = 0
+			}
+		}
+	}
+	switch b.op {
+	case "==":
+		return boolValue(cmp == 0)
+	case "!=":
+		return boolValue(cmp != 0)
+	case "<":
+		return boolValue(cmp < 0)
+	case "<=":
+		return boolValue(cmp <= 0)
+	case ">":
+		return boolValue(cmp > 0)
+	default: // ">="
+		return boolValue(cmp >= 0)
+	}
+}
+
+// truthy reports whether a value counts as true: set, and not false or 0.
+func truthy(s string) bool {
+	switch strings.ToLower(s) {
+	case "", "false", "0", "no":
+		return false
+	}
+	return true
+}
+
+func boolValue(b bool) string {
+	if b {
+		return "true"
+	}
+	return ""
+}
+
+// whenParser is a recursive-descent parser over when tokens.
+type whenParser struct {
+	toks []string
+	pos  int
+	vars []string // names referenced
+}
+
+// parseWhen parses a when condition, returning it and the names it uses.
+func parseWhen(s string) (whenExpr, []string, error) {
+	toks, err := tokenizeWhen(s)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(toks) == 0 {
+		return nil, nil, fmt.Errorf("empty condition")
+	}
+	p := &whenParser{toks: toks}
+	x, err := p.or()
+	if err != nil {
+		return nil, nil, err
+	}
+	if p.pos < len(p.toks) {
+		return nil, nil, fmt.Errorf("unexpected %q", p.toks[p.pos])
+	}
+	return x, p.vars, nil
+}
+
+// EvalWhen evaluates a when condition against vars. An empty condition
+// is true.
+func EvalWhen(cond string, vars map[string]string) (bool, error) {
+	if strings.TrimSpace(cond) == "" {
+		return true, nil
+	}
+	x, _, err := parseWhen(cond)
+	if err != nil {
+		return false, fmt.Errorf("when %q: %w", cond, err)
+	}
+	return truthy(x.value(vars)), nil
+}
+
+func (p *whenParser) peek() string {
+	if p.pos < len(p.toks) {
+		return p.toks[p.pos]
+	}
+	return ""
+}
+
+func (p *whenParser) or() (whenExpr, error) {
+	l, err := p.and()
+	for err == nil && p.peek() == "||" {
+		p.pos++
+		var r whenExpr
+		if r, err = p.and(); err == nil {
+			l = whenBinary{op: "||", l: l, r: r}
+		}
+	}
+	return l, err
+}
+
+func (p *whenParser) and() (whenExpr, error) {
+	l, err := p.comparison()
+	for err == nil && p.peek() == "&&" {
+		p.pos++
+		var r whenExpr
+		if r, err = p.comparison(); err == nil {
+			l = whenBinary{op: "&&", l: l, r: r}
+		}
+	}
+	return l, err
+}
+
+func (p *whenParser) comparison() (whenExpr, error) {
+	l, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	switch op := p.peek(); op {
+	case "==", "!=", "<", "<=", ">", ">=":
+		p.pos++
+		r, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return whenBinary{op: op, l: l, r: r}, nil
+	}
+	return l, nil
+}
+
+func (p *whenParser) unary() (whenExpr, error) {
+	tok := p.peek()
+	switch {
+	case tok == "":
+		return nil, fmt.Errorf("unexpected end of condition")
+	case tok == "!":
+		p.pos++
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return whenNot{x}, nil
+	case tok == "(":
+		p.pos++
+		x, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("missing )")
+		}
+		p.pos++
+		return x, nil
+	case tok[0] == '"' || tok[0] == '\'':
+		p.pos++
+		return whenLit(tok[1 : len(tok)-1]), nil
+	case tok == "true":
+		p.pos++
+		return whenLit("true"), nil
+	case tok == "false":
+		p.pos++
+		return whenLit(""), nil
+	case isWhenNumber(tok):
+		p.pos++
+		return whenLit(tok), nil
+	case isWhenIdent(tok):
+		p.pos++
+		p.vars = append(p.vars, tok)
+		return whenVar(tok), nil
+	}
+	return nil, fmt.Errorf("unexpected %q", tok)
+}
+
+// tokenizeWhen splits a condition into operators, parentheses, quoted
+// strings (kept with their quotes) and words.
+func tokenizeWhen(s string) ([]string, error) {
+	var toks []string
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(' || c == ')':
+			toks = append(toks, string(c))
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(s[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			toks = append(toks, s[i:i+end+2])
+			i += end + 2
+		case strings.ContainsRune("=!<>&|", rune(c)):
+			if i+1 < len(s) {
+				if two := s[i : i+2]; two == "==" || two == "!=" || two == "<=" || two == ">=" || two == "&&" || two == "||" {
+					toks = append(toks, two)
+					i += 2
+					continue
+				}
+			}
+			if c != '!' && c != '<' && c != '>' {
+				return nil, fmt.Errorf("unexpected %q", string(c))
+			}
+			toks = append(toks, string(c))
+			i++
+		default:
+			j := i
+			for j < len(s) && (isWhenWordRune(rune(s[j]))) {
+				j++
+			}
+			if j == i {
+				return nil, fmt.Errorf("unexpected %q", string(c))
+			}
+			toks = append(toks, s[i:j])
+			i = j
+		}
+	}
+	return toks, nil
+}
+
+func isWhenWordRune(r rune) bool {
+	return r == '_' || r == '.' || r == '-' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func isWhenNumber(tok string) bool {
+	_, err := strconv.ParseFloat(tok, 64)
+	return err == nil
+}
+
+func isWhenIdent(tok string) bool {
+	for i, r := range tok {
+		if !(r == '_' || unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r))) {
+			return false
+		}
+	}
+	return tok != ""
+}
+
+// validateConditions checks that every when condition parses and only
+// refers to declared inputs and vars.
+func (f *Formula) validateConditions() error {
+	decls := f.varDecls()
+	check := func(kind, id, cond string) error {
+		if strings.TrimSpace(cond) == "" {
+			return nil
+		}
+		_, names, err := parseWhen(cond)
+		if err != nil {
+			return fmt.Errorf("%s %q: invalid when %q: %w", kind, id, cond, err)
+		}
+		for _, name := range names {
+			if _, ok := decls[name]; !ok {
+				return fmt.Errorf("%s %q: when refers to undeclared variable: %s", kind, id, name)
+			}
+		}
+		return nil
+	}
+
+	for _, step := range f.Steps {
+		if err := check("step", step.ID, step.When); err != nil {
+			return err
+		}
+	}
+	for _, leg := range f.Legs {
+		if err := check("leg", leg.ID, leg.When); err != nil {
+			return err
+		}
+	}
+	for _, aspect := range f.Aspects {
+		if err := check("aspect", aspect.ID, aspect.When); err != nil {
+			return err
+		}
+	}
+	if f.Synthesis != nil {
+		return check("synthesis", SynthesisID, f.Synthesis.When)
+	}
+	return nil
+}
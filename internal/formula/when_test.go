@@ -0,0 +1,74 @@
+package formula
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEvalWhen(t *testing.T) {
+	vars := map[string]string{
+		"pr":     "123",
+		"quick":  "false",
+		"mode":   "full",
+		"depth":  "10",
+		"target": "prod",
+	}
+	tests := []struct {
+		cond string
+		want bool
+	}{
+		{"", true},
+		{"pr", true},
+		{"quick", false},
+		{"!quick", true},
+		{"missing", false},
+		{"!missing", true},
+		{`mode == "full"`, true},
+		{`mode != 'full'`, false},
+		{"depth > 9", true},   // numeric, not "10" < "9"
+		{"depth >= 10", true}, // numeric equality
+		{"depth < 2", false},
+		{`pr && mode == "lite"`, false},
+		{`pr && mode == "lite" || target == "prod"`, true},
+		{`pr && (mode == "lite" || target == "prod")`, true},
+		{`!(pr && quick)`, true},
+		{"true && !false", true},
+	}
+	for _, tt := range tests {
+		got, err := EvalWhen(tt.cond, vars)
+		if err != nil {
+			t.Errorf("EvalWhen(%q): %v", tt.cond, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("EvalWhen(%q) = %v, want %v", tt.cond, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"pr &&", "(pr", `mode == "full`, "pr = 1", "pr mode", "a & b"} {
+		if _, err := EvalWhen(bad, vars); err == nil {
+			t.Errorf("EvalWhen(%q): expected error", bad)
+		}
+	}
+}
+
+func TestValidateConditions(t *testing.T) {
+	base := `
+formula = "ship"
+[vars.fast]
+type = "bool"
+[[steps]]
+id = "lint"
+`
+	if _, err := Parse([]byte(base + `when = "!fast"`)); err != nil {
+		t.Errorf("valid when: %v", err)
+	}
+	_, err := Parse([]byte(base + `when = "slow"`))
+	if err == nil || !strings.Contains(err.Error(), "undeclared variable: slow") {
+		t.Errorf("undeclared variable: err = %v", err)
+	}
+	_, err = Parse([]byte(base + `when = "fast =="`))
+	if err == nil || !strings.Contains(err.Error(), "invalid when") {
+		t.Errorf("bad syntax: err = %v", err)
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // GitError contains raw output from a git command for agent observation.
@@ -571,6 +573,12 @@ func (g *Git) ListBranches(pattern string) ([]string, error) {
 	return strings.Split(out, "\n"), nil
 }
 
+// ResetHard resets the current branch, index and working tree to ref.
+func (g *Git) ResetHard(ref string) error {
+	_, err := g.run("reset", "--hard", ref)
+	return err
+}
+
 // ResetBranch force-updates a branch to point to a ref.
 // This is useful for resetting stale polecat branches to main.
 func (g *Git) ResetBranch(name, ref string) error {
@@ -908,6 +916,90 @@ func (g *Git) CountCommitsBehind(ref string) (int, error) {
 	return count, nil
 }
 
+// CommitStat is a commit with its per-file line counts.
+type CommitStat struct {
+	SHA     string
+	Subject string
+	Reverts string // commit named in a "This reverts commit" line, if any
+	Files   []FileStat
+}
+
+// FileStat is the lines a commit added to and deleted from a file.
+// Binary files count as zero lines.
+type FileStat struct {
+	Path    string
+	Added   int
+	Deleted int
+}
+
+// RecentCommits returns the commits in revRange (e.g. "origin/main..HEAD")
+// made since the given time, newest first, with their numstat. A zero
+// since means no time limit.
+func (g *Git) RecentCommits(revRange string, since time.Time) ([]CommitStat, error) {
+	args := []string{"log", "--no-merges", "--numstat", "--format=%x00%H%x1f%s%x1f%b%x1e"}
+	if !since.IsZero() {
+		args = append(args, "--since="+since.Format(time.RFC3339))
+	}
+	out, err := g.run(append(args, revRange, "--")...)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []CommitStat
+	for _, record := range strings.Split(out, "\x00") {
+		header, numstat, ok := strings.Cut(record, "\x1e")
+		if !ok {
+			continue
+		}
+		fields := strings.SplitN(header, "\x1f", 3)
+		if len(fields) < 3 {
+			continue
+		}
+		c := CommitStat{SHA: fields[0], Subject: fields[1]}
+		for _, line := range strings.Split(fields[2], "\n") {
+			if rest, ok := strings.CutPrefix(strings.TrimSpace(line), "This reverts commit "); ok {
+				c.Reverts = strings.TrimRight(rest, ".")
+			}
+		}
+		for _, line := range strings.Split(numstat, "\n") {
+			parts := strings.SplitN(line, "\t", 3)
+			if len(parts) < 3 {
+				continue
+			}
+			added, _ := strconv.Atoi(parts[0]) // "-" for binary files
+			deleted, _ := strconv.Atoi(parts[1])
+			c.Files = append(c.Files, FileStat{Path: parts[2], Added: added, Deleted: deleted})
+		}
+		commits = append(commits, c)
+	}
+	return commits, nil
+}
+
+// CommitFilesByAuthor returns, for the newest limit commits on all refs,
+// the files each commit touched, grouped by author name.
+func (g *Git) CommitFilesByAuthor(limit int) (map[string][][]string, error) {
+	out, err := g.run("log", "--all", "--no-merges", "--name-only", "--format=%x00%an", fmt.Sprintf("-n%d", limit))
+	if err != nil {
+		return nil, err
+	}
+
+	byAuthor := make(map[string][][]string)
+	for _, record := range strings.Split(out, "\x00") {
+		lines := strings.Split(strings.TrimSpace(record), "\n")
+		if len(lines) == 0 || lines[0] == "" {
+			continue
+		}
+		var files []string
+		for _, line := range lines[1:] {
+			if line = strings.TrimSpace(line); line != "" {
+				files = append(files, line)
+			}
+		}
+		byAuthor[lines[0]] = append(byAuthor[lines[0]], files)
+	}
+	return byAuthor, nil
+}
+
 // StashCount returns the number of stashes in the repository.
 func (g *Git) StashCount() (int, error) {
 	out, err := g.run("stash", "list")
@@ -5,6 +5,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func initTestRepo(t *testing.T) string {
@@ -162,6 +163,96 @@ func TestAddAndCommit(t *testing.T) {
 	}
 }
 
+func TestRecentCommits(t *testing.T) {
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+	base, err := g.Rev("HEAD")
+	if err != nil {
+		t.Fatalf("Rev: %v", err)
+	}
+
+	// Two edits to one file, then a revert of the second.
+	for _, content := range []string{"one\ntwo\n", "one\nthree\n"} {
+		if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte(content), 0644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+		if err := g.Add("notes.txt"); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+		if err := g.Commit("edit notes"); err != nil {
+			t.Fatalf("Commit: %v", err)
+		}
+	}
+	head, _ := g.Rev("HEAD")
+	cmd := exec.Command("git", "revert", "--no-edit", "HEAD")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git revert: %v\n%s", err, out)
+	}
+
+	commits, err := g.RecentCommits(base+"..HEAD", time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("RecentCommits: %v", err)
+	}
+	if len(commits) != 3 {
+		t.Fatalf("got %d commits, want 3", len(commits))
+	}
+	revert := commits[0]
+	if revert.Reverts != head || !stringContains(revert.Subject, "Revert") {
+		t.Errorf("revert = %+v, want it to revert %s", revert, head)
+	}
+	if len(revert.Files) != 1 || revert.Files[0] != (FileStat{Path: "notes.txt", Added: 1, Deleted: 1}) {
+		t.Errorf("revert files = %+v", revert.Files)
+	}
+	if first := commits[2]; first.Reverts != "" || first.Files[0].Added != 2 {
+		t.Errorf("first commit = %+v", first)
+	}
+
+	// Nothing since the future.
+	if commits, err := g.RecentCommits("HEAD", time.Now().Add(time.Hour)); err != nil || len(commits) != 0 {
+		t.Errorf("future since: %d commits, err %v", len(commits), err)
+	}
+}
+
+func TestCommitFilesByAuthor(t *testing.T) {
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+
+	// A polecat's commit on a branch that was never merged still counts.
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	run("checkout", "-q", "-b", "polecat/nux-1")
+	if err := os.MkdirAll(filepath.Join(dir, "auth"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"auth/login.go", "auth/session.go"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("package auth\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run("add", ".")
+	run("commit", "-q", "--author=nux <nux@example.com>", "-m", "fix login")
+	run("checkout", "-q", "-")
+
+	byAuthor, err := g.CommitFilesByAuthor(100)
+	if err != nil {
+		t.Fatalf("CommitFilesByAuthor: %v", err)
+	}
+	nux := byAuthor["nux"]
+	if len(nux) != 1 || len(nux[0]) != 2 || nux[0][0] != "auth/login.go" {
+		t.Errorf("nux commits = %v", nux)
+	}
+	if initial := byAuthor["Test User"]; len(initial) != 1 || initial[0][0] != "README.md" {
+		t.Errorf("Test User commits = %v", initial)
+	}
+}
+
 func TestHasUncommittedChanges(t *testing.T) {
 	dir := initTestRepo(t)
 	g := NewGit(dir)
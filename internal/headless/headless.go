@@ -0,0 +1,369 @@
+// Package headless runs agent sessions on a pseudo-terminal without tmux.
+//
+// Each session is served by a host process (the hidden `gt session host`
+// command) that owns the PTY, keeps a scrollback ring buffer and listens on
+// a unix socket in the session directory. Backend talks to those sockets and
+// implements the same session operations gt uses on tmux, so Gas Town can
+// run in containers and CI where tmux isn't available.
+package headless
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+// DirEnv overrides the directory holding session sockets.
+const DirEnv = "GT_HEADLESS_DIR"
+
+// DetachKey ends an attached session (Ctrl-]).
+const DetachKey = 0x1d
+
+// startTimeout bounds how long NewSessionWithCommand waits for the host.
+const startTimeout = 5 * time.Second
+
+// DefaultDir returns the session socket directory: $GT_HEADLESS_DIR, or a
+// per-user directory under the system temp dir.
+func DefaultDir() string {
+	if dir := os.Getenv(DirEnv); dir != "" {
+		return dir
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("gt-headless-%d", os.Getuid()))
+}
+
+// Backend manages headless sessions through their host sockets.
+type Backend struct {
+	dir string
+
+	// spawn starts a session host. The default runs `gt session host`
+	// detached; tests run the server in-process instead.
+	spawn func(opts ServerOptions) error
+}
+
+// NewBackend returns a backend using dir for session sockets
+// (DefaultDir if empty).
+func NewBackend(dir string) *Backend {
+	if dir == "" {
+		dir = DefaultDir()
+	}
+	return &Backend{dir: dir, spawn: spawnHost}
+}
+
+// Dir returns the session socket directory.
+func (b *Backend) Dir() string {
+	return b.dir
+}
+
+// spawnHost runs `gt session host` as a detached process.
+func spawnHost(opts ServerOptions) error {
+	gtPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("finding executable: %w", err)
+	}
+	if err := os.MkdirAll(opts.Dir, 0700); err != nil {
+		return fmt.Errorf("creating socket dir: %w", err)
+	}
+	logFile, err := os.OpenFile(filepath.Join(opts.Dir, opts.Name+".log"), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("opening host log: %w", err)
+	}
+	defer logFile.Close()
+
+	cmd := exec.Command(gtPath, "session", "host", //nolint:gosec // G204: args are constructed internally
+		"--dir", opts.Dir, "--name", opts.Name, "--workdir", opts.WorkDir, "--command", opts.Command)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	detach(cmd)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("starting session host: %w", err)
+	}
+	return cmd.Process.Release()
+}
+
+// Host runs a session host in the foreground until its command exits.
+// It is the body of `gt session host`.
+func Host(opts ServerOptions) error {
+	s, err := StartServer(opts)
+	if err != nil {
+		return err
+	}
+	return s.Wait()
+}
+
+// call sends one request to a session and returns the response data.
+func (b *Backend) call(session string, req request) (string, error) {
+	conn, err := b.dial(session)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	resp, err := roundTrip(conn, bufio.NewReader(conn), req)
+	if err != nil {
+		return "", err
+	}
+	return resp.Data, nil
+}
+
+func (b *Backend) dial(session string) (net.Conn, error) {
+	conn, err := net.DialTimeout("unix", socketPath(b.dir, session), time.Second)
+	if err != nil {
+		return nil, tmux.ErrSessionNotFound
+	}
+	return conn, nil
+}
+
+func roundTrip(conn net.Conn, r *bufio.Reader, req request) (response, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return response{}, err
+	}
+	if _, err := conn.Write(append(data, '\n')); err != nil {
+		return response{}, err
+	}
+	line, err := r.ReadBytes('\n')
+	if err != nil {
+		return response{}, fmt.Errorf("reading response: %w", err)
+	}
+	var resp response
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return response{}, fmt.Errorf("parsing response: %w", err)
+	}
+	if !resp.OK {
+		return resp, errors.New(resp.Error)
+	}
+	return resp, nil
+}
+
+// NewSessionWithCommand starts a host running command in workDir and waits
+// until its socket accepts connections.
+func (b *Backend) NewSessionWithCommand(name, workDir, command string) error {
+	if running, _ := b.HasSession(name); running {
+		return tmux.ErrSessionExists
+	}
+	if err := b.spawn(ServerOptions{Dir: b.dir, Name: name, WorkDir: workDir, Command: command}); err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(startTimeout)
+	for time.Now().Before(deadline) {
+		if running, _ := b.HasSession(name); running {
+			return nil
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return fmt.Errorf("session host for %s did not start (see %s)", name, filepath.Join(b.dir, name+".log"))
+}
+
+// HasSession reports whether a session host is answering on its socket.
+func (b *Backend) HasSession(name string) (bool, error) {
+	if _, err := b.call(name, request{Op: "ping"}); err != nil {
+		if errors.Is(err, tmux.ErrSessionNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// ListSessions returns the names of live sessions.
+func (b *Backend) ListSessions() ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(b.dir, "*.sock"))
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, m := range matches {
+		name := strings.TrimSuffix(filepath.Base(m), ".sock")
+		if running, _ := b.HasSession(name); running {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// SetEnvironment records a session variable, like tmux set-environment.
+func (b *Backend) SetEnvironment(session, key, value string) error {
+	_, err := b.call(session, request{Op: "setenv", Key: key, Value: value})
+	return err
+}
+
+// GetEnvironment returns a variable set with SetEnvironment.
+func (b *Backend) GetEnvironment(session, key string) (string, error) {
+	return b.call(session, request{Op: "getenv", Key: key})
+}
+
+// SendKeysRaw writes input to the session's terminal as-is.
+func (b *Backend) SendKeysRaw(session, keys string) error {
+	_, err := b.call(session, request{Op: "input", Data: keys})
+	return err
+}
+
+// SendKeys types keys into the session and presses Enter after a short
+// debounce, like tmux.SendKeys.
+func (b *Backend) SendKeys(session, keys string) error {
+	return b.SendKeysDebounced(session, keys, constants.DefaultDebounceMs)
+}
+
+// SendKeysDebounced types keys, waits debounceMs, then presses Enter.
+func (b *Backend) SendKeysDebounced(session, keys string, debounceMs int) error {
+	if err := b.SendKeysRaw(session, keys); err != nil {
+		return err
+	}
+	if debounceMs > 0 {
+		time.Sleep(time.Duration(debounceMs) * time.Millisecond)
+	}
+	return b.SendKeysRaw(session, "\r")
+}
+
+// nudgeLocks serializes nudges per session, as tmux.NudgeSession does.
+var nudgeLocks sync.Map // map[string]*sync.Mutex
+
+// NudgeSession delivers a message to the agent using the same sequence as
+// tmux.NudgeSession: text, a pause, Escape, then Enter.
+func (b *Backend) NudgeSession(session, message string) error {
+	lock, _ := nudgeLocks.LoadOrStore(session, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	if err := b.SendKeysRaw(session, message); err != nil {
+		return err
+	}
+	time.Sleep(500 * time.Millisecond)
+	_ = b.SendKeysRaw(session, "\x1b")
+	time.Sleep(100 * time.Millisecond)
+	return b.SendKeysRaw(session, "\r")
+}
+
+// CapturePane returns the last lines of scrollback as plain text.
+func (b *Backend) CapturePane(session string, lines int) (string, error) {
+	return b.call(session, request{Op: "capture", Lines: lines})
+}
+
+// GetPaneCommand returns the name of the session's foreground process.
+func (b *Backend) GetPaneCommand(session string) (string, error) {
+	return b.call(session, request{Op: "command"})
+}
+
+// IsAgentRunning checks the foreground process against
+// expectedPaneCommands, or for any non-shell process if none are given.
+// Same rules as tmux.IsAgentRunning.
+func (b *Backend) IsAgentRunning(session string, expectedPaneCommands ...string) bool {
+	cmd, err := b.GetPaneCommand(session)
+	if err != nil {
+		return false
+	}
+	if len(expectedPaneCommands) > 0 {
+		for _, expected := range expectedPaneCommands {
+			if expected != "" && cmd == expected {
+				return true
+			}
+		}
+		return false
+	}
+	for _, shell := range constants.SupportedShells {
+		if cmd == shell {
+			return false
+		}
+	}
+	return cmd != ""
+}
+
+// KillSession terminates the session. A headless session has no server
+// to outlive its command, so this is the same as KillSessionWithProcesses.
+func (b *Backend) KillSession(name string) error {
+	return b.KillSessionWithProcesses(name)
+}
+
+// KillSessionWithProcesses terminates the session's process group; the
+// host exits once the command is gone.
+func (b *Backend) KillSessionWithProcesses(name string) error {
+	if _, err := b.call(name, request{Op: "kill"}); err != nil {
+		return err
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if running, _ := b.HasSession(name); !running {
+			return nil
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return fmt.Errorf("session %s still running after kill", name)
+}
+
+// AttachSession connects the current terminal to a session until the
+// session ends or the user presses Ctrl-] to detach.
+func (b *Backend) AttachSession(session string) error {
+	return b.Attach(session, os.Stdin, os.Stdout)
+}
+
+// Attach streams a session to out and forwards in to it. When in is a
+// terminal it is put in raw mode for the duration.
+func (b *Backend) Attach(session string, in io.Reader, out io.Writer) error {
+	conn, err := b.dial(session)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	req := request{Op: "attach"}
+	if f, ok := in.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		if cols, rows, err := term.GetSize(int(f.Fd())); err == nil {
+			req.Rows, req.Cols = rows, cols
+		}
+		state, err := term.MakeRaw(int(f.Fd()))
+		if err != nil {
+			return fmt.Errorf("setting raw mode: %w", err)
+		}
+		defer func() { _ = term.Restore(int(f.Fd()), state) }()
+	}
+
+	r := bufio.NewReader(conn)
+	if _, err := roundTrip(conn, r, req); err != nil {
+		return err
+	}
+
+	outDone := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(out, r)
+		close(outDone)
+	}()
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, err := in.Read(buf)
+			if n > 0 {
+				if i := strings.IndexByte(string(buf[:n]), DetachKey); i >= 0 {
+					_, _ = conn.Write(buf[:i])
+					conn.Close()
+					return
+				}
+				if _, werr := conn.Write(buf[:n]); werr != nil {
+					return
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	<-outDone
+	return nil
+}
@@ -0,0 +1,192 @@
+package headless
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+func TestRing(t *testing.T) {
+	r := NewRing(8)
+	_, _ = r.Write([]byte("abc"))
+	if got := string(r.Bytes()); got != "abc" {
+		t.Errorf("Bytes = %q, want abc", got)
+	}
+	_, _ = r.Write([]byte("defgh"))
+	if got := string(r.Bytes()); got != "abcdefgh" {
+		t.Errorf("Bytes = %q, want abcdefgh", got)
+	}
+	_, _ = r.Write([]byte("ij"))
+	if got := string(r.Bytes()); got != "cdefghij" {
+		t.Errorf("Bytes after wrap = %q, want cdefghij", got)
+	}
+	_, _ = r.Write([]byte("0123456789"))
+	if got := string(r.Bytes()); got != "23456789" {
+		t.Errorf("Bytes after oversized write = %q, want 23456789", got)
+	}
+}
+
+func TestRingLines(t *testing.T) {
+	r := NewRing(32)
+	_, _ = r.Write([]byte("one\r\ntwo\r\nthree\r\n\r\n"))
+	if got := r.Lines(2); strings.Join(got, "|") != "two|three" {
+		t.Errorf("Lines(2) = %q", got)
+	}
+
+	// Once wrapped, the partial first line is dropped.
+	_, _ = r.Write([]byte("four\r\nfive\r\nsix\r\nseven\r\n"))
+	got := r.Lines(0)
+	if len(got) == 0 || strings.HasPrefix(got[0], "ee") || got[len(got)-1] != "seven" {
+		t.Errorf("Lines(0) after wrap = %q", got)
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"\x1b[1;32mgreen\x1b[0m text", "green text"},
+		{"\x1b]0;window title\x07prompt$ ", "prompt$"},
+		{"progress 10%\rprogress 99%\r\ndone", "progress 99%\ndone"},
+		{"typo\b\bo!", "tyo!"},
+		{"\x1b(Bplain\x1b=", "plain"},
+	}
+	for _, tt := range tests {
+		if got := Render([]byte(tt.in)); got != tt.want {
+			t.Errorf("Render(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// newTestBackend returns a backend whose hosts run in-process.
+func newTestBackend(t *testing.T) *Backend {
+	t.Helper()
+	if runtime.GOOS != "linux" {
+		t.Skip("headless sessions require Linux")
+	}
+	b := NewBackend(t.TempDir())
+	b.spawn = func(opts ServerOptions) error {
+		_, err := StartServer(opts)
+		return err
+	}
+	return b
+}
+
+func waitForOutput(t *testing.T, b *Backend, session, want string) string {
+	t.Helper()
+	var out string
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); {
+		out, _ = b.CapturePane(session, 50)
+		if strings.Contains(out, want) {
+			return out
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("output never contained %q:\n%s", want, out)
+	return ""
+}
+
+func TestBackend_Session(t *testing.T) {
+	b := newTestBackend(t)
+	workDir := t.TempDir()
+
+	if err := b.NewSessionWithCommand("gt-test-Toast", workDir, "echo started in $PWD; exec cat"); err != nil {
+		t.Fatalf("NewSessionWithCommand: %v", err)
+	}
+	defer func() { _ = b.KillSessionWithProcesses("gt-test-Toast") }()
+
+	if err := b.NewSessionWithCommand("gt-test-Toast", workDir, "cat"); !errors.Is(err, tmux.ErrSessionExists) {
+		t.Errorf("duplicate session: err = %v, want ErrSessionExists", err)
+	}
+	if names, _ := b.ListSessions(); len(names) != 1 || names[0] != "gt-test-Toast" {
+		t.Errorf("ListSessions = %v", names)
+	}
+
+	waitForOutput(t, b, "gt-test-Toast", "started in "+workDir)
+
+	if err := b.SendKeys("gt-test-Toast", "hello from gt"); err != nil {
+		t.Fatalf("SendKeys: %v", err)
+	}
+	waitForOutput(t, b, "gt-test-Toast", "hello from gt")
+
+	if !b.IsAgentRunning("gt-test-Toast", "cat") {
+		cmd, err := b.GetPaneCommand("gt-test-Toast")
+		t.Errorf("IsAgentRunning(cat) = false; foreground command %q, %v", cmd, err)
+	}
+
+	if err := b.SetEnvironment("gt-test-Toast", "GT_ROLE", "polecat"); err != nil {
+		t.Fatalf("SetEnvironment: %v", err)
+	}
+	if v, err := b.GetEnvironment("gt-test-Toast", "GT_ROLE"); err != nil || v != "polecat" {
+		t.Errorf("GetEnvironment = %q, %v", v, err)
+	}
+
+	if err := b.KillSessionWithProcesses("gt-test-Toast"); err != nil {
+		t.Fatalf("KillSessionWithProcesses: %v", err)
+	}
+	if running, _ := b.HasSession("gt-test-Toast"); running {
+		t.Error("session still running after kill")
+	}
+	if _, err := b.CapturePane("gt-test-Toast", 10); !errors.Is(err, tmux.ErrSessionNotFound) {
+		t.Errorf("CapturePane after kill: err = %v, want ErrSessionNotFound", err)
+	}
+}
+
+func TestBackend_Attach(t *testing.T) {
+	b := newTestBackend(t)
+	if err := b.NewSessionWithCommand("gt-test-attach", "", "echo ready; exec cat"); err != nil {
+		t.Fatalf("NewSessionWithCommand: %v", err)
+	}
+	defer func() { _ = b.KillSessionWithProcesses("gt-test-attach") }()
+	waitForOutput(t, b, "gt-test-attach", "ready")
+
+	// Type a line, then detach with Ctrl-].
+	r, w := io.Pipe()
+	in := io.MultiReader(strings.NewReader("ping\r"), r)
+	var out syncBuffer
+	done := make(chan error, 1)
+	go func() { done <- b.Attach("gt-test-attach", in, &out) }()
+
+	waitForOutput(t, b, "gt-test-attach", "ping")
+	_, _ = w.Write([]byte{DetachKey})
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Attach: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Attach did not return after detach key")
+	}
+	if !strings.Contains(out.String(), "ready") {
+		t.Errorf("attach output missing scrollback: %q", out.String())
+	}
+	if running, _ := b.HasSession("gt-test-attach"); !running {
+		t.Error("detaching ended the session")
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
@@ -0,0 +1,111 @@
+//go:build linux
+
+package headless
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// openPTY allocates a pseudo-terminal pair.
+func openPTY() (master, tty *os.File, err error) {
+	master, err = os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	var n uint32
+	if cerr := control(master, func(fd int) error {
+		if err := unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0); err != nil {
+			return fmt.Errorf("unlockpt: %w", err)
+		}
+		var err error
+		n, err = unix.IoctlGetUint32(fd, unix.TIOCGPTN)
+		return err
+	}); cerr != nil {
+		master.Close()
+		return nil, nil, cerr
+	}
+
+	tty, err = os.OpenFile("/dev/pts/"+strconv.FormatUint(uint64(n), 10), os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+	return master, tty, nil
+}
+
+// control runs fn on the file's descriptor without switching it to
+// blocking mode (which os.File.Fd would do).
+func control(f *os.File, fn func(fd int) error) error {
+	rc, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var ferr error
+	if err := rc.Control(func(fd uintptr) { ferr = fn(int(fd)) }); err != nil {
+		return err
+	}
+	return ferr
+}
+
+func setWinsize(f *os.File, rows, cols int) error {
+	return control(f, func(fd int) error {
+		return unix.IoctlSetWinsize(fd, unix.TIOCSWINSZ, &unix.Winsize{Row: uint16(rows), Col: uint16(cols)}) //nolint:gosec // G115: terminal sizes are small
+	})
+}
+
+// setControllingTTY makes the command a session leader with its stdin as
+// controlling terminal, so job control and SIGHUP behave as in tmux.
+func setControllingTTY(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true, Ctty: 0}
+}
+
+// detach starts the host in its own session so it survives the caller's
+// terminal closing.
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
+
+// killGroup signals the process group led by pid.
+func killGroup(pid int, force bool) error {
+	sig := syscall.SIGTERM
+	if force {
+		sig = syscall.SIGKILL
+	}
+	return syscall.Kill(-pid, sig)
+}
+
+// foregroundCommand returns the name of the terminal's foreground process
+// (falling back to pid), matching tmux's #{pane_current_command}.
+func foregroundCommand(master *os.File, pid int) (string, error) {
+	pgrp := pid
+	_ = control(master, func(fd int) error {
+		if p, err := unix.IoctlGetInt(fd, unix.TIOCGPGRP); err == nil && p > 0 {
+			pgrp = p
+		}
+		return nil
+	})
+	return processName(pgrp)
+}
+
+func processName(pid int) (string, error) {
+	cmdline, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+	if err == nil {
+		if arg0, _, _ := bytes.Cut(cmdline, []byte{0}); len(arg0) > 0 {
+			return filepath.Base(string(arg0)), nil
+		}
+	}
+	comm, err := os.ReadFile(fmt.Sprintf("/proc/%d/comm", pid))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(comm)), nil
+}
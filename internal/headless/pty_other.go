@@ -0,0 +1,23 @@
+//go:build !linux
+
+package headless
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+var errUnsupported = errors.New("headless sessions are only supported on Linux")
+
+func openPTY() (master, tty *os.File, err error) { return nil, nil, errUnsupported }
+
+func setWinsize(*os.File, int, int) error { return errUnsupported }
+
+func setControllingTTY(*exec.Cmd) {}
+
+func detach(*exec.Cmd) {}
+
+func killGroup(int, bool) error { return errUnsupported }
+
+func foregroundCommand(*os.File, int) (string, error) { return "", errUnsupported }
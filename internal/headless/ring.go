@@ -0,0 +1,141 @@
+package headless
+
+import (
+	"strings"
+)
+
+// Ring is a fixed-size scrollback buffer. Once full, each write discards
+// the oldest bytes.
+type Ring struct {
+	buf  []byte
+	size int
+	full bool
+	pos  int // next write offset
+}
+
+// NewRing creates a ring buffer holding the last size bytes written.
+func NewRing(size int) *Ring {
+	return &Ring{buf: make([]byte, size), size: size}
+}
+
+// Write appends p, overwriting the oldest data when the buffer is full.
+func (r *Ring) Write(p []byte) (int, error) {
+	n := len(p)
+	if n >= r.size {
+		copy(r.buf, p[n-r.size:])
+		r.pos = 0
+		r.full = true
+		return n, nil
+	}
+	end := r.pos + n
+	if end <= r.size {
+		copy(r.buf[r.pos:], p)
+	} else {
+		k := copy(r.buf[r.pos:], p)
+		copy(r.buf, p[k:])
+		r.full = true
+	}
+	r.pos = end % r.size
+	if end == r.size {
+		r.full = true
+	}
+	return n, nil
+}
+
+// Bytes returns the buffered output, oldest first.
+func (r *Ring) Bytes() []byte {
+	if !r.full {
+		return append([]byte(nil), r.buf[:r.pos]...)
+	}
+	out := make([]byte, 0, r.size)
+	out = append(out, r.buf[r.pos:]...)
+	return append(out, r.buf[:r.pos]...)
+}
+
+// Lines renders the scrollback as plain text and returns the last n lines
+// (all lines if n <= 0), like tmux capture-pane. When the buffer has
+// wrapped, the first partial line is dropped.
+func (r *Ring) Lines(n int) []string {
+	data := r.Bytes()
+	if r.full {
+		if i := strings.IndexByte(string(data), '\n'); i >= 0 {
+			data = data[i+1:]
+		}
+	}
+	lines := strings.Split(Render(data), "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if n > 0 && len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
+
+// Render converts raw terminal output to plain text. It drops escape
+// sequences, applies backspaces and treats a bare carriage return as
+// returning to the start of the line. Cursor movement is not emulated, so
+// full-screen programs render approximately.
+func Render(data []byte) string {
+	var out strings.Builder
+	var line []rune
+	s := []rune(string(data))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == 0x1b: // ESC
+			i = skipEscape(s, i)
+		case c == '\n':
+			out.WriteString(strings.TrimRight(string(line), " "))
+			out.WriteByte('\n')
+			line = line[:0]
+		case c == '\r':
+			if i+1 < len(s) && s[i+1] == '\n' {
+				continue
+			}
+			line = line[:0]
+		case c == '\b':
+			if len(line) > 0 {
+				line = line[:len(line)-1]
+			}
+		case c == '\t' || c >= 0x20 && c != 0x7f:
+			line = append(line, c)
+		}
+	}
+	out.WriteString(strings.TrimRight(string(line), " "))
+	return out.String()
+}
+
+// skipEscape returns the index of the last rune of the escape sequence
+// starting at s[i].
+func skipEscape(s []rune, i int) int {
+	if i+1 >= len(s) {
+		return i
+	}
+	switch s[i+1] {
+	case '[': // CSI: parameters, then a final byte in @..~
+		for j := i + 2; j < len(s); j++ {
+			if s[j] >= 0x40 && s[j] <= 0x7e {
+				return j
+			}
+		}
+		return len(s) - 1
+	case ']', 'P', '_', '^': // OSC/DCS/APC/PM: terminated by BEL or ST
+		for j := i + 2; j < len(s); j++ {
+			if s[j] == 0x07 {
+				return j
+			}
+			if s[j] == 0x1b && j+1 < len(s) && s[j+1] == '\\' {
+				return j + 1
+			}
+		}
+		return len(s) - 1
+	case '(', ')', '*', '+': // charset designation takes one more byte
+		if i+2 < len(s) {
+			return i + 2
+		}
+		return len(s) - 1
+	default:
+		return i + 1
+	}
+}
@@ -0,0 +1,315 @@
+package headless
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+// DefaultScrollback is the scrollback kept per session, in bytes.
+const DefaultScrollback = 1 << 20
+
+// Default PTY size. Agents render for this size until someone attaches.
+const (
+	defaultRows = 50
+	defaultCols = 200
+)
+
+// request is one client call on the session socket. Each connection
+// carries a single request; "attach" then switches to a raw byte stream.
+type request struct {
+	Op    string `json:"op"` // ping, input, capture, setenv, getenv, command, kill, attach
+	Data  string `json:"data,omitempty"`
+	Lines int    `json:"lines,omitempty"`
+	Key   string `json:"key,omitempty"`
+	Value string `json:"value,omitempty"`
+	Rows  int    `json:"rows,omitempty"`
+	Cols  int    `json:"cols,omitempty"`
+}
+
+type response struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+	Data  string `json:"data,omitempty"`
+}
+
+// ServerOptions configures a session host.
+type ServerOptions struct {
+	Dir        string // Socket directory
+	Name       string // Session name
+	WorkDir    string // Working directory for the command
+	Command    string // Run with sh -c
+	Scrollback int    // Ring buffer size in bytes (default DefaultScrollback)
+}
+
+// Server hosts one headless session: a command on a PTY, its scrollback,
+// and a unix socket that clients use to drive it.
+type Server struct {
+	sockPath string
+	pty      *os.File
+	cmd      *exec.Cmd
+	listener net.Listener
+
+	mu     sync.Mutex
+	ring   *Ring
+	env    map[string]string
+	subs   map[net.Conn]struct{}
+	inputM sync.Mutex // serializes writes to the PTY
+
+	done    chan struct{}
+	waitErr error
+}
+
+// StartServer starts the session command and begins serving its socket.
+// The server shuts down when the command exits.
+func StartServer(opts ServerOptions) (*Server, error) {
+	if opts.Scrollback <= 0 {
+		opts.Scrollback = DefaultScrollback
+	}
+	if err := os.MkdirAll(opts.Dir, 0700); err != nil {
+		return nil, fmt.Errorf("creating socket dir: %w", err)
+	}
+	sockPath := socketPath(opts.Dir, opts.Name)
+	if conn, err := net.DialTimeout("unix", sockPath, time.Second); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("session %s: %w", opts.Name, tmux.ErrSessionExists)
+	}
+	_ = os.Remove(sockPath) // stale socket from a crashed host
+
+	master, tty, err := openPTY()
+	if err != nil {
+		return nil, fmt.Errorf("opening pty: %w", err)
+	}
+	_ = setWinsize(master, defaultRows, defaultCols)
+
+	cmd := exec.Command("sh", "-c", opts.Command) //nolint:gosec // G204: command is the agent startup command
+	cmd.Dir = opts.WorkDir
+	cmd.Env = append(os.Environ(), "TERM=xterm-256color", "GT_SESSION="+opts.Name)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = tty, tty, tty
+	setControllingTTY(cmd)
+	if err := cmd.Start(); err != nil {
+		master.Close()
+		tty.Close()
+		return nil, fmt.Errorf("starting command: %w", err)
+	}
+	tty.Close() // the child holds its own copy
+
+	listener, err := net.Listen("unix", sockPath)
+	if err != nil {
+		_ = killGroup(cmd.Process.Pid, true)
+		master.Close()
+		return nil, fmt.Errorf("listening on %s: %w", sockPath, err)
+	}
+
+	s := &Server{
+		sockPath: sockPath,
+		pty:      master,
+		cmd:      cmd,
+		listener: listener,
+		ring:     NewRing(opts.Scrollback),
+		env:      make(map[string]string),
+		subs:     make(map[net.Conn]struct{}),
+		done:     make(chan struct{}),
+	}
+	go s.readLoop()
+	go s.acceptLoop()
+	go s.waitLoop()
+	return s, nil
+}
+
+// Wait blocks until the session command exits and returns its result.
+func (s *Server) Wait() error {
+	<-s.done
+	return s.waitErr
+}
+
+// readLoop copies PTY output into the scrollback and to attached clients.
+func (s *Server) readLoop() {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := s.pty.Read(buf)
+		if n > 0 {
+			s.mu.Lock()
+			_, _ = s.ring.Write(buf[:n])
+			for c := range s.subs {
+				_ = c.SetWriteDeadline(time.Now().Add(time.Second))
+				if _, err := c.Write(buf[:n]); err != nil {
+					c.Close()
+					delete(s.subs, c)
+				}
+			}
+			s.mu.Unlock()
+		}
+		if err != nil {
+			return // EIO once the child side is closed
+		}
+	}
+}
+
+func (s *Server) acceptLoop() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+// waitLoop reaps the command and tears the server down.
+func (s *Server) waitLoop() {
+	s.waitErr = s.cmd.Wait()
+	s.listener.Close()
+	_ = os.Remove(s.sockPath)
+
+	// Give readLoop a moment to drain output written just before exit.
+	time.Sleep(50 * time.Millisecond)
+	s.mu.Lock()
+	for c := range s.subs {
+		c.Close()
+	}
+	s.subs = nil
+	s.mu.Unlock()
+	s.pty.Close()
+	close(s.done)
+}
+
+func (s *Server) handle(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	line, err := r.ReadBytes('\n')
+	if err != nil {
+		conn.Close()
+		return
+	}
+	var req request
+	if err := json.Unmarshal(line, &req); err != nil {
+		reply(conn, response{Error: "bad request"})
+		conn.Close()
+		return
+	}
+	if req.Op == "attach" {
+		s.attach(conn, r, req)
+		return
+	}
+	defer conn.Close()
+	reply(conn, s.do(req))
+}
+
+func (s *Server) do(req request) response {
+	switch req.Op {
+	case "ping":
+		return response{OK: true}
+	case "input":
+		if err := s.write([]byte(req.Data)); err != nil {
+			return response{Error: err.Error()}
+		}
+		return response{OK: true}
+	case "capture":
+		s.mu.Lock()
+		lines := s.ring.Lines(req.Lines)
+		s.mu.Unlock()
+		return response{OK: true, Data: strings.Join(lines, "\n")}
+	case "setenv":
+		s.mu.Lock()
+		s.env[req.Key] = req.Value
+		s.mu.Unlock()
+		return response{OK: true}
+	case "getenv":
+		s.mu.Lock()
+		v, ok := s.env[req.Key]
+		s.mu.Unlock()
+		if !ok {
+			return response{Error: fmt.Sprintf("unknown variable: %s", req.Key)}
+		}
+		return response{OK: true, Data: v}
+	case "command":
+		name, err := foregroundCommand(s.pty, s.cmd.Process.Pid)
+		if err != nil {
+			return response{Error: err.Error()}
+		}
+		return response{OK: true, Data: name}
+	case "kill":
+		s.kill()
+		return response{OK: true}
+	default:
+		return response{Error: fmt.Sprintf("unknown op: %s", req.Op)}
+	}
+}
+
+// write sends input to the PTY.
+func (s *Server) write(p []byte) error {
+	s.inputM.Lock()
+	defer s.inputM.Unlock()
+	_, err := s.pty.Write(p)
+	return err
+}
+
+// kill terminates the command's process group: SIGTERM, a short grace
+// period, then SIGKILL.
+func (s *Server) kill() {
+	pid := s.cmd.Process.Pid
+	_ = killGroup(pid, false)
+	select {
+	case <-s.done:
+		return
+	case <-time.After(100 * time.Millisecond):
+	}
+	_ = killGroup(pid, true)
+}
+
+// attach streams the scrollback and live output to conn and forwards
+// everything the client sends to the PTY until it disconnects.
+func (s *Server) attach(conn net.Conn, r *bufio.Reader, req request) {
+	defer conn.Close()
+	if req.Rows > 0 && req.Cols > 0 {
+		_ = setWinsize(s.pty, req.Rows, req.Cols)
+	}
+	reply(conn, response{OK: true})
+
+	s.mu.Lock()
+	if s.subs == nil {
+		s.mu.Unlock()
+		return
+	}
+	_, _ = conn.Write(s.ring.Bytes())
+	s.subs[conn] = struct{}{}
+	s.mu.Unlock()
+
+	buf := make([]byte, 4096)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if werr := s.write(buf[:n]); werr != nil {
+				break
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	s.mu.Lock()
+	delete(s.subs, conn)
+	s.mu.Unlock()
+	_ = setWinsize(s.pty, defaultRows, defaultCols)
+}
+
+func reply(w io.Writer, resp response) {
+	data, _ := json.Marshal(resp)
+	_, _ = w.Write(append(data, '\n'))
+}
+
+// socketPath returns the control socket for a session.
+func socketPath(dir, name string) string {
+	return filepath.Join(dir, name+".sock")
+}
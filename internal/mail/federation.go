@@ -0,0 +1,396 @@
+package mail
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/connection"
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// Federated mail connects towns on different machines. A message addressed
+// to machine:address (e.g. "alice:mayor/") is wrapped in an Envelope and
+// handed to a Transport. The destination town picks it up on its next
+// sync, delivers it locally with the sender qualified as
+// <sending-machine>:<from> so replies route back, and returns a receipt.
+// Delivery is at-least-once on the wire; each town remembers the
+// envelopes it has delivered and never delivers one twice.
+
+// ErrFederationNotConfigured indicates the town has no config/federation.json.
+var ErrFederationNotConfigured = errors.New("mail federation not configured (config/federation.json)")
+
+// Envelope kinds.
+const (
+	EnvelopeMessage = "message"
+	EnvelopeReceipt = "receipt"
+)
+
+// federationRetention is how long delivered outbox entries and the
+// received-envelope ledger are kept.
+const federationRetention = 30 * 24 * time.Hour
+
+// Envelope is what a Transport carries between towns: a message, or a
+// receipt acknowledging delivery of one.
+type Envelope struct {
+	ID     string    `json:"id"`
+	Kind   string    `json:"kind"`
+	From   string    `json:"from"` // sending machine
+	To     string    `json:"to"`   // destination machine
+	SentAt time.Time `json:"sent_at"`
+
+	// Message is the message to deliver (EnvelopeMessage). Its To is the
+	// address on the destination town.
+	Message *Message `json:"message,omitempty"`
+
+	// ReceiptFor and DeliveredAt acknowledge a message (EnvelopeReceipt).
+	ReceiptFor  string     `json:"receipt_for,omitempty"`
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+}
+
+// Transport carries envelopes between towns.
+type Transport interface {
+	// Send publishes envelopes to their destination machines.
+	Send(envs []*Envelope) error
+
+	// Receive returns the envelopes waiting for machine, oldest first.
+	Receive(machine string) ([]*Envelope, error)
+
+	// Ack removes handled envelopes from machine's queue.
+	Ack(machine string, ids []string) error
+}
+
+// OutboxEntry tracks a message this town forwarded to another town.
+type OutboxEntry struct {
+	ID          string     `json:"id"` // envelope ID
+	To          string     `json:"to"` // full machine:address
+	Subject     string     `json:"subject"`
+	SentAt      time.Time  `json:"sent_at"`
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+}
+
+// federationState is the town's federation ledger.
+type federationState struct {
+	Outbox   map[string]*OutboxEntry `json:"outbox"`   // envelope ID -> sent message
+	Received map[string]time.Time    `json:"received"` // envelope ID -> delivery time
+}
+
+// SyncResult summarizes a federation sync.
+type SyncResult struct {
+	Delivered  int // messages delivered into this town
+	Duplicates int // messages seen again after delivery (not redelivered)
+	Receipts   int // delivery receipts for messages this town sent
+}
+
+// Federation sends and receives this town's federated mail.
+type Federation struct {
+	Machine   string    // this town's machine name
+	Transport Transport // carries envelopes between towns
+
+	dir     string               // <town>/.runtime/federation
+	deliver func(*Message) error // local delivery of received messages
+}
+
+// FederationDir returns the town's federation state directory.
+func FederationDir(townRoot string) string {
+	return filepath.Join(constants.TownRuntimePath(townRoot), "federation")
+}
+
+// NewFederation creates the federation described by cfg for the town at
+// townRoot. Received messages are delivered with deliver (usually the
+// town's Router.Send).
+func NewFederation(townRoot string, cfg *config.FederationConfig, deliver func(*Message) error) *Federation {
+	dir := FederationDir(townRoot)
+	return &Federation{
+		Machine:   cfg.Machine,
+		Transport: NewGitTransport(cfg.Transport.Remote, cfg.Transport.GetBranch(), filepath.Join(dir, "repo"), cfg.Machine),
+		dir:       dir,
+		deliver:   deliver,
+	}
+}
+
+// Federation returns the town's mail federation, or
+// ErrFederationNotConfigured.
+func (r *Router) Federation() (*Federation, error) {
+	if r.townRoot == "" {
+		return nil, ErrFederationNotConfigured
+	}
+	cfg, err := config.LoadFederationConfig(config.FederationConfigPath(r.townRoot))
+	if err != nil {
+		if errors.Is(err, config.ErrNotFound) {
+			return nil, ErrFederationNotConfigured
+		}
+		return nil, err
+	}
+	return NewFederation(r.townRoot, cfg, r.Send), nil
+}
+
+// SyncFederation syncs the town's federated mail. It does nothing if
+// federation is not configured.
+func SyncFederation(townRoot string) (*SyncResult, error) {
+	fed, err := NewRouterWithTownRoot(townRoot, townRoot).Federation()
+	if errors.Is(err, ErrFederationNotConfigured) {
+		return &SyncResult{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return fed.Sync()
+}
+
+// isRemoteAddress reports whether address has a machine: prefix. The
+// list:, queue:, announce: and channel: schemes are not machines.
+func isRemoteAddress(address string) bool {
+	if isListAddress(address) || isQueueAddress(address) || isAnnounceAddress(address) || isChannelAddress(address) {
+		return false
+	}
+	idx := strings.Index(address, ":")
+	return idx > 0 && !strings.Contains(address[:idx], "/") && !strings.HasPrefix(address, "@")
+}
+
+// splitRemoteAddress splits machine:address into the machine and the
+// address on that machine.
+func splitRemoteAddress(address string) (machine, local string, err error) {
+	addr, err := connection.ParseAddress(address)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid address %q: %w", address, err)
+	}
+	return addr.Machine, strings.TrimPrefix(address, addr.Machine+":"), nil
+}
+
+// sendToRemote forwards a machine:address message to its town.
+func (r *Router) sendToRemote(msg *Message) error {
+	machine, local, err := splitRemoteAddress(msg.To)
+	if err != nil {
+		return err
+	}
+	if machine == "local" {
+		localMsg := *msg
+		localMsg.To = local
+		return r.Send(&localMsg)
+	}
+
+	fed, err := r.Federation()
+	if err != nil {
+		return fmt.Errorf("sending to %s: %w", msg.To, err)
+	}
+	return fed.Send(msg)
+}
+
+// Send forwards msg, addressed to machine:address, to that machine's
+// town. Mail addressed to this town's own machine is delivered locally.
+//
+// CC and ReplyTo refer to the sending town's addresses and beads, so
+// they are not forwarded.
+func (f *Federation) Send(msg *Message) error {
+	machine, local, err := splitRemoteAddress(msg.To)
+	if err != nil {
+		return err
+	}
+	if machine == f.Machine || machine == "local" {
+		localMsg := *msg
+		localMsg.To = local
+		return f.deliver(&localMsg)
+	}
+
+	lock, err := f.lock()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	now := time.Now().UTC()
+	remoteMsg := *msg
+	remoteMsg.To = local
+	remoteMsg.CC = nil
+	remoteMsg.ReplyTo = ""
+	if remoteMsg.Timestamp.IsZero() {
+		remoteMsg.Timestamp = now
+	}
+	env := &Envelope{
+		ID:      f.Machine + "-" + generateID(),
+		Kind:    EnvelopeMessage,
+		From:    f.Machine,
+		To:      machine,
+		SentAt:  now,
+		Message: &remoteMsg,
+	}
+	if err := f.Transport.Send([]*Envelope{env}); err != nil {
+		return fmt.Errorf("forwarding to %s: %w", machine, err)
+	}
+
+	state, err := f.loadState()
+	if err != nil {
+		return err
+	}
+	state.Outbox[env.ID] = &OutboxEntry{ID: env.ID, To: msg.To, Subject: msg.Subject, SentAt: now}
+	return f.saveState(state, now)
+}
+
+// Sync delivers the messages waiting for this town, answers them with
+// receipts, and records receipts for messages this town sent.
+func (f *Federation) Sync() (*SyncResult, error) {
+	lock, err := f.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	envs, err := f.Transport.Receive(f.Machine)
+	if err != nil {
+		return nil, fmt.Errorf("receiving federated mail: %w", err)
+	}
+	state, err := f.loadState()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SyncResult{}
+	var receipts []*Envelope
+	var handled []string
+	var errs []error
+	now := time.Now().UTC()
+	for _, env := range envs {
+		switch env.Kind {
+		case EnvelopeMessage:
+			deliveredAt, seen := state.Received[env.ID]
+			if seen {
+				result.Duplicates++
+			} else {
+				if env.Message == nil {
+					handled = append(handled, env.ID) // malformed: drop it
+					continue
+				}
+				msg := *env.Message
+				msg.From = env.From + ":" + msg.From
+				if err := f.deliver(&msg); err != nil {
+					errs = append(errs, fmt.Errorf("delivering %s to %s: %w", env.ID, msg.To, err))
+					continue // left in the queue for the next sync
+				}
+				deliveredAt = now
+				state.Received[env.ID] = deliveredAt
+				// Record each delivery as it happens so a failure later in
+				// the sync can't cause a redelivery.
+				if err := f.saveState(state, now); err != nil {
+					return result, err
+				}
+				result.Delivered++
+			}
+			receipts = append(receipts, &Envelope{
+				ID:          "receipt-" + env.ID,
+				Kind:        EnvelopeReceipt,
+				From:        f.Machine,
+				To:          env.From,
+				SentAt:      now,
+				ReceiptFor:  env.ID,
+				DeliveredAt: &deliveredAt,
+			})
+			handled = append(handled, env.ID)
+
+		case EnvelopeReceipt:
+			if entry := state.Outbox[env.ReceiptFor]; entry != nil && entry.DeliveredAt == nil && env.DeliveredAt != nil {
+				deliveredAt := *env.DeliveredAt
+				entry.DeliveredAt = &deliveredAt
+			}
+			result.Receipts++
+			handled = append(handled, env.ID)
+
+		default:
+			handled = append(handled, env.ID) // corrupt, or a kind from a newer gt: drop it
+		}
+	}
+
+	if err := f.saveState(state, now); err != nil {
+		return result, err
+	}
+	// Receipts go out before the messages are acked: if the ack is lost
+	// the messages come back as duplicates and are receipted again.
+	if len(receipts) > 0 {
+		if err := f.Transport.Send(receipts); err != nil {
+			return result, fmt.Errorf("sending receipts: %w", err)
+		}
+	}
+	if len(handled) > 0 {
+		if err := f.Transport.Ack(f.Machine, handled); err != nil {
+			return result, fmt.Errorf("acknowledging federated mail: %w", err)
+		}
+	}
+	return result, errors.Join(errs...)
+}
+
+// Outbox returns the messages this town has forwarded, newest first.
+func (f *Federation) Outbox() ([]*OutboxEntry, error) {
+	state, err := f.loadState()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*OutboxEntry, 0, len(state.Outbox))
+	for _, e := range state.Outbox {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].SentAt.After(entries[j].SentAt)
+	})
+	return entries, nil
+}
+
+// lock serializes federation operations within the town.
+func (f *Federation) lock() (*flock.Flock, error) {
+	if err := os.MkdirAll(f.dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating federation dir: %w", err)
+	}
+	lock := flock.New(filepath.Join(f.dir, "federation.lock"))
+	if err := lock.Lock(); err != nil {
+		return nil, fmt.Errorf("locking federation state: %w", err)
+	}
+	return lock, nil
+}
+
+func (f *Federation) statePath() string {
+	return filepath.Join(f.dir, "state.json")
+}
+
+func (f *Federation) loadState() (*federationState, error) {
+	state := &federationState{}
+	data, err := os.ReadFile(f.statePath())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading federation state: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, state); err != nil {
+			return nil, fmt.Errorf("parsing federation state: %w", err)
+		}
+	}
+	if state.Outbox == nil {
+		state.Outbox = make(map[string]*OutboxEntry)
+	}
+	if state.Received == nil {
+		state.Received = make(map[string]time.Time)
+	}
+	return state, nil
+}
+
+// saveState writes the ledger, forgetting deliveries older than the
+// retention period.
+func (f *Federation) saveState(state *federationState, now time.Time) error {
+	cutoff := now.Add(-federationRetention)
+	for id, at := range state.Received {
+		if at.Before(cutoff) {
+			delete(state.Received, id)
+		}
+	}
+	for id, e := range state.Outbox {
+		if e.DeliveredAt != nil && e.DeliveredAt.Before(cutoff) {
+			delete(state.Outbox, id)
+		}
+	}
+	return util.AtomicWriteJSON(f.statePath(), state)
+}
@@ -0,0 +1,198 @@
+package mail
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func TestIsRemoteAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		want    bool
+	}{
+		{"alice:mayor/", true},
+		{"alice:gastown/polecats/nux", true},
+		{"alice:list:oncall", true},
+		{"mayor/", false},
+		{"gastown/witness", false},
+		{"list:oncall", false},
+		{"queue:work", false},
+		{"announce:alerts", false},
+		{"channel:builds", false},
+		{"@town", false},
+		{":mayor/", false},
+	}
+	for _, tt := range tests {
+		if got := isRemoteAddress(tt.address); got != tt.want {
+			t.Errorf("isRemoteAddress(%q) = %v, want %v", tt.address, got, tt.want)
+		}
+	}
+}
+
+// testTown is one side of a federation, with local delivery captured.
+type testTown struct {
+	fed       *Federation
+	delivered []*Message
+}
+
+func newTestTown(t *testing.T, machine, remote string) *testTown {
+	t.Helper()
+	town := &testTown{}
+	cfg := &config.FederationConfig{
+		Machine:   machine,
+		Transport: config.FederationTransport{Type: "git", Remote: remote},
+	}
+	town.fed = NewFederation(t.TempDir(), cfg, func(msg *Message) error {
+		town.delivered = append(town.delivered, msg)
+		return nil
+	})
+	return town
+}
+
+func newMailRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	remote := filepath.Join(t.TempDir(), "mail.git")
+	if out, err := exec.Command("git", "init", "-q", "--bare", remote).CombinedOutput(); err != nil {
+		t.Fatalf("git init --bare: %v: %s", err, out)
+	}
+	return remote
+}
+
+func TestFederation_DeliveryAndReceipts(t *testing.T) {
+	remote := newMailRepo(t)
+	alice := newTestTown(t, "alice", remote)
+	bob := newTestTown(t, "bob", remote)
+
+	msg := NewMessage("mayor/", "bob:mayor/", "ESCALATION: build broken", "Can your refinery take a look?")
+	msg.CC = []string{"gastown/witness"}
+	if err := alice.fed.Send(msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if len(alice.delivered) != 0 {
+		t.Fatal("remote mail was delivered locally")
+	}
+
+	// Keep a copy of the envelope to replay later, as if an ack were lost.
+	pending, err := bob.fed.Transport.Receive("bob")
+	if err != nil || len(pending) != 1 {
+		t.Fatalf("Receive = %d envelopes, %v", len(pending), err)
+	}
+
+	res, err := bob.fed.Sync()
+	if err != nil {
+		t.Fatalf("bob Sync: %v", err)
+	}
+	if res.Delivered != 1 || len(bob.delivered) != 1 {
+		t.Fatalf("bob Sync = %+v, delivered %d", res, len(bob.delivered))
+	}
+	got := bob.delivered[0]
+	if got.From != "alice:mayor/" || got.To != "mayor/" || got.Subject != msg.Subject || got.Body != msg.Body {
+		t.Errorf("delivered %+v", got)
+	}
+	if len(got.CC) != 0 {
+		t.Errorf("CC forwarded: %v", got.CC)
+	}
+
+	// Handled envelopes are gone from the queue.
+	if res, err := bob.fed.Sync(); err != nil || res.Delivered != 0 || len(bob.delivered) != 1 {
+		t.Errorf("second bob Sync = %+v, %v", res, err)
+	}
+
+	// Alice gets the receipt.
+	outbox, _ := alice.fed.Outbox()
+	if len(outbox) != 1 || outbox[0].DeliveredAt != nil || outbox[0].To != "bob:mayor/" {
+		t.Fatalf("outbox before receipt = %+v", outbox)
+	}
+	if res, err := alice.fed.Sync(); err != nil || res.Receipts != 1 {
+		t.Fatalf("alice Sync = %+v, %v", res, err)
+	}
+	outbox, _ = alice.fed.Outbox()
+	if outbox[0].DeliveredAt == nil {
+		t.Error("receipt did not mark the message delivered")
+	}
+
+	// A replayed envelope is receipted again but not redelivered.
+	if err := alice.fed.Transport.Send(pending); err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	res, err = bob.fed.Sync()
+	if err != nil || res.Duplicates != 1 || res.Delivered != 0 || len(bob.delivered) != 1 {
+		t.Errorf("bob Sync of duplicate = %+v, %v, delivered %d", res, err, len(bob.delivered))
+	}
+	if res, err := alice.fed.Sync(); err != nil || res.Receipts != 1 {
+		t.Errorf("alice Sync of duplicate receipt = %+v, %v", res, err)
+	}
+}
+
+func TestFederation_OwnMachineDeliversLocally(t *testing.T) {
+	alice := newTestTown(t, "alice", filepath.Join(t.TempDir(), "unused.git"))
+
+	for _, to := range []string{"alice:gastown/witness", "local:gastown/witness"} {
+		if err := alice.fed.Send(NewMessage("mayor/", to, "hi", "")); err != nil {
+			t.Fatalf("Send(%s): %v", to, err)
+		}
+	}
+	if len(alice.delivered) != 2 || alice.delivered[0].To != "gastown/witness" || alice.delivered[1].To != "gastown/witness" {
+		t.Errorf("delivered = %+v", alice.delivered)
+	}
+}
+
+func TestGitTransport_InterleavedTowns(t *testing.T) {
+	remote := newMailRepo(t)
+	a := NewGitTransport(remote, "main", filepath.Join(t.TempDir(), "a"), "alice")
+	b := NewGitTransport(remote, "main", filepath.Join(t.TempDir(), "b"), "bob")
+
+	// Each transport pushes on top of the other's commits without merging.
+	for i, tr := range []*GitTransport{a, b, a, b} {
+		env := &Envelope{ID: "e" + string(rune('0'+i)), Kind: EnvelopeMessage, From: tr.Author, To: "carol"}
+		if err := tr.Send([]*Envelope{env}); err != nil {
+			t.Fatalf("Send #%d: %v", i, err)
+		}
+	}
+	envs, err := a.Receive("carol")
+	if err != nil || len(envs) != 4 {
+		t.Fatalf("Receive = %d, %v", len(envs), err)
+	}
+	if err := b.Ack("carol", []string{"e0", "e3", "missing"}); err != nil {
+		t.Fatalf("Ack: %v", err)
+	}
+	envs, _ = a.Receive("carol")
+	if len(envs) != 2 || envs[0].ID != "e1" || envs[1].ID != "e2" {
+		t.Errorf("after ack = %+v", envs)
+	}
+
+	if err := a.Send([]*Envelope{{ID: "../escape", To: "carol"}}); err == nil {
+		t.Error("expected an error for an envelope ID with a path separator")
+	}
+}
+
+func TestGitTransport_StalledRemoteTimesOut(t *testing.T) {
+	// An ssh that never answers, like a host dropping packets.
+	ssh := filepath.Join(t.TempDir(), "ssh")
+	if err := os.WriteFile(ssh, []byte("#!/bin/sh\nexec sleep 30\n"), 0755); err != nil { //nolint:gosec // G306: test script must be executable
+		t.Fatal(err)
+	}
+	t.Setenv("GIT_SSH_COMMAND", ssh)
+	old := gitCommandTimeout
+	gitCommandTimeout = 200 * time.Millisecond
+	defer func() { gitCommandTimeout = old }()
+
+	tr := NewGitTransport("ssh://mail.invalid/mail.git", "main", filepath.Join(t.TempDir(), "repo"), "alice")
+	start := time.Now()
+	_, err := tr.Receive("alice")
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Receive err = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Receive took %s", elapsed)
+	}
+}
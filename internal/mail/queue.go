@@ -0,0 +1,316 @@
+package mail
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// Queue claims are leases. Claiming a queue message adds claimed-by,
+// claimed-at and lease-until labels; the claimant renews the lease while
+// it works, and the daemon renews it for claimants whose session is still
+// running. A claim whose lease lapses (usually because the worker's
+// session died) is expired, and requeueing strips the claim labels so the
+// message can be claimed again.
+
+// Claim label prefixes on queue messages.
+const (
+	ClaimedByLabel  = "claimed-by:"
+	ClaimedAtLabel  = "claimed-at:"
+	LeaseUntilLabel = "lease-until:"
+)
+
+// ErrQueueFull indicates a queue already has its maximum number of claims.
+var ErrQueueFull = errors.New("queue at max claims")
+
+// IsClaimLabel reports whether label records a queue claim.
+func IsClaimLabel(label string) bool {
+	return strings.HasPrefix(label, ClaimedByLabel) ||
+		strings.HasPrefix(label, ClaimedAtLabel) ||
+		strings.HasPrefix(label, LeaseUntilLabel)
+}
+
+// ClaimLabels returns the labels recording a claim by claimant at now,
+// leased for ttl.
+func ClaimLabels(claimant string, now time.Time, ttl time.Duration) []string {
+	now = now.UTC()
+	return []string{
+		ClaimedByLabel + claimant,
+		ClaimedAtLabel + now.Format(time.RFC3339),
+		LeaseUntilLabel + now.Add(ttl).Format(time.RFC3339),
+	}
+}
+
+// ClaimExpired reports whether the message is claimed and its lease has
+// lapsed. Claims made before leases existed expire ttl after claimed-at.
+// Labels must already be parsed (ListQueueMessages does this).
+func (bm *BeadsMessage) ClaimExpired(now time.Time, ttl time.Duration) bool {
+	if bm.claimedBy == "" {
+		return false
+	}
+	if bm.leaseUntil != nil {
+		return now.After(*bm.leaseUntil)
+	}
+	return bm.claimedAt != nil && now.After(bm.claimedAt.Add(ttl))
+}
+
+// QueueStats counts a queue's open messages by claim state.
+type QueueStats struct {
+	Queue     string `json:"queue"`
+	Unclaimed int    `json:"unclaimed"`
+	Claimed   int    `json:"claimed"` // claims with a live lease
+	Expired   int    `json:"expired"` // claims awaiting requeue
+	MaxClaims int    `json:"max_claims"`
+}
+
+// Full reports whether the queue's live claims have reached MaxClaims.
+func (s QueueStats) Full() bool {
+	return s.MaxClaims > 0 && s.Claimed >= s.MaxClaims
+}
+
+// CountQueue tallies messages (as returned by ListQueueMessages) by claim
+// state.
+func CountQueue(queueName string, messages []*BeadsMessage, maxClaims int, now time.Time, ttl time.Duration) QueueStats {
+	stats := QueueStats{Queue: queueName, MaxClaims: maxClaims}
+	for _, bm := range messages {
+		switch {
+		case bm.GetClaimedBy() == "":
+			stats.Unclaimed++
+		case bm.ClaimExpired(now, ttl):
+			stats.Expired++
+		default:
+			stats.Claimed++
+		}
+	}
+	return stats
+}
+
+// ListQueueMessages returns the open messages in a queue, claimed or not,
+// oldest first.
+func ListQueueMessages(beadsDir, queueName string) ([]*BeadsMessage, error) {
+	args := []string{"list",
+		"--label", "queue:" + queueName,
+		"--status", "open",
+		"--type", "message",
+		"--json",
+	}
+	stdout, err := runBdCommand(args, filepath.Dir(beadsDir), beadsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	trimmed := strings.TrimSpace(string(stdout))
+	if trimmed == "" || trimmed == "[]" {
+		return nil, nil
+	}
+	var messages []*BeadsMessage
+	if err := json.Unmarshal(stdout, &messages); err != nil {
+		return nil, fmt.Errorf("parsing bd output: %w", err)
+	}
+	for _, bm := range messages {
+		bm.ParseLabels()
+	}
+
+	// FIFO: oldest first
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].CreatedAt.Before(messages[j].CreatedAt)
+	})
+	return messages, nil
+}
+
+// RemoveClaimLabels removes the claim labels among labels from a message,
+// returning it to its queue.
+func RemoveClaimLabels(beadsDir, messageID, actor string, labels []string) error {
+	for _, label := range labels {
+		if !IsClaimLabel(label) {
+			continue
+		}
+		args := []string{"label", "remove", messageID, label}
+		if _, err := runBdCommand(args, filepath.Dir(beadsDir), beadsDir, "BD_ACTOR="+actor); err != nil {
+			var bdErr *bdError
+			if errors.As(err, &bdErr) && bdErr.ContainsError("does not have label") {
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// RenewLease extends a claim's lease to now+ttl. The new lease label is
+// added before the old one is removed so the claim is never unleased.
+func RenewLease(beadsDir, messageID, actor string, labels []string, now time.Time, ttl time.Duration) (time.Time, error) {
+	until := now.UTC().Add(ttl).Truncate(time.Second)
+	renewed := LeaseUntilLabel + until.Format(time.RFC3339)
+
+	args := []string{"label", "add", messageID, renewed}
+	if _, err := runBdCommand(args, filepath.Dir(beadsDir), beadsDir, "BD_ACTOR="+actor); err != nil {
+		return time.Time{}, err
+	}
+
+	var stale []string
+	for _, label := range labels {
+		if strings.HasPrefix(label, LeaseUntilLabel) && label != renewed {
+			stale = append(stale, label)
+		}
+	}
+	if err := RemoveClaimLabels(beadsDir, messageID, actor, stale); err != nil {
+		return time.Time{}, err
+	}
+	return until, nil
+}
+
+// RequeueExpired releases the expired claims among messages, clearing
+// their claim state in place. It returns the requeued messages.
+func RequeueExpired(beadsDir string, messages []*BeadsMessage, now time.Time, ttl time.Duration) ([]*BeadsMessage, error) {
+	var requeued []*BeadsMessage
+	for _, bm := range messages {
+		if !bm.ClaimExpired(now, ttl) {
+			continue
+		}
+		if err := RemoveClaimLabels(beadsDir, bm.ID, "daemon", bm.Labels); err != nil {
+			return requeued, fmt.Errorf("requeueing %s: %w", bm.ID, err)
+		}
+		var kept []string
+		for _, label := range bm.Labels {
+			if !IsClaimLabel(label) {
+				kept = append(kept, label)
+			}
+		}
+		bm.Labels = kept
+		bm.claimedBy, bm.claimedAt, bm.leaseUntil = "", nil, nil
+		requeued = append(requeued, bm)
+	}
+	return requeued, nil
+}
+
+// LockQueue takes a queue's claim lock, serializing claims, renewals and
+// requeues so a claim limit holds and a renewal isn't undone by a requeue
+// working from stale labels. Unlock the returned lock when done.
+func LockQueue(beadsDir, queueName string) (*flock.Flock, error) {
+	lock := flock.New(filepath.Join(beadsDir, "queue-"+strings.ReplaceAll(queueName, "/", "_")+".lock"))
+	if err := lock.Lock(); err != nil {
+		return nil, fmt.Errorf("locking queue %s: %w", queueName, err)
+	}
+	return lock, nil
+}
+
+// RenewDue reports whether a live claim has used up half its lease and
+// should be renewed. Labels must already be parsed.
+func (bm *BeadsMessage) RenewDue(now time.Time, ttl time.Duration) bool {
+	if bm.claimedBy == "" || bm.ClaimExpired(now, ttl) {
+		return false
+	}
+	if bm.leaseUntil != nil {
+		return bm.leaseUntil.Sub(now) < ttl/2
+	}
+	return bm.claimedAt != nil && now.Sub(*bm.claimedAt) > ttl/2
+}
+
+// ClaimSweep counts what SweepClaims did.
+type ClaimSweep struct {
+	Renewed  int
+	Requeued int
+}
+
+// SweepClaims maintains the claims in every leased queue: those in the
+// town's messaging config and those that only have a queue bead (which
+// lease for DefaultClaimTTL). A claim whose claimant alive reports as
+// running is renewed once half its lease has passed, so a working
+// claimant keeps it without renewing by hand; an expired claim is
+// requeued. alive may be nil, leaving renewal to the claimants.
+func SweepClaims(townRoot string, alive func(claimant string) bool) (ClaimSweep, error) {
+	var sweep ClaimSweep
+	ttls, err := leasedQueues(townRoot)
+	if err != nil {
+		return sweep, err
+	}
+
+	beadsDir := beads.ResolveBeadsDir(townRoot)
+	names := make([]string, 0, len(ttls))
+	for name := range ttls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		renewed, requeued, err := sweepQueue(beadsDir, name, ttls[name], alive)
+		sweep.Renewed += renewed
+		sweep.Requeued += requeued
+		if err != nil {
+			errs = append(errs, fmt.Errorf("queue %s: %w", name, err))
+		}
+	}
+	return sweep, errors.Join(errs...)
+}
+
+// leasedQueues returns the claim TTL of every queue in the town.
+func leasedQueues(townRoot string) (map[string]time.Duration, error) {
+	ttls := make(map[string]time.Duration)
+
+	beadsDir := beads.ResolveBeadsDir(townRoot)
+	queueBeads, err := beads.NewWithBeadsDir(townRoot, beadsDir).ListQueueBeads()
+	if err != nil {
+		return nil, fmt.Errorf("listing queue beads: %w", err)
+	}
+	for id, issue := range queueBeads {
+		name := beads.ParseQueueFields(issue.Description).Name
+		if name == "" {
+			name = id
+		}
+		ttls[name] = config.DefaultClaimTTL
+	}
+
+	cfg, err := config.LoadMessagingConfig(config.MessagingConfigPath(townRoot))
+	if err != nil && !errors.Is(err, config.ErrNotFound) {
+		return nil, err
+	}
+	if cfg != nil {
+		for name, qc := range cfg.Queues {
+			ttls[name] = qc.GetClaimTTL()
+		}
+	}
+	return ttls, nil
+}
+
+// sweepQueue renews and requeues one queue's claims under its lock.
+func sweepQueue(beadsDir, queueName string, ttl time.Duration, alive func(string) bool) (renewed, requeued int, err error) {
+	lock, err := LockQueue(beadsDir, queueName)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	messages, err := ListQueueMessages(beadsDir, queueName)
+	if err != nil {
+		return 0, 0, err
+	}
+	now := time.Now()
+	var errs []error
+	if alive != nil {
+		for _, bm := range messages {
+			if !bm.RenewDue(now, ttl) || !alive(bm.claimedBy) {
+				continue
+			}
+			if _, err := RenewLease(beadsDir, bm.ID, "daemon", bm.Labels, now, ttl); err != nil {
+				errs = append(errs, fmt.Errorf("renewing %s: %w", bm.ID, err))
+				continue
+			}
+			renewed++
+		}
+	}
+	out, err := RequeueExpired(beadsDir, messages, now, ttl)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return renewed, len(out), errors.Join(errs...)
+}
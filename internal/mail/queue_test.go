@@ -0,0 +1,108 @@
+package mail
+
+import (
+	"testing"
+	"time"
+)
+
+func queueMsg(id string, labels ...string) *BeadsMessage {
+	bm := &BeadsMessage{ID: id, Labels: append([]string{"queue:work"}, labels...)}
+	bm.ParseLabels()
+	return bm
+}
+
+func TestClaimLabels(t *testing.T) {
+	now := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	bm := queueMsg("hq-1", ClaimLabels("gastown/nux", now, 10*time.Minute)...)
+
+	if bm.GetClaimedBy() != "gastown/nux" {
+		t.Errorf("claimed-by = %q", bm.GetClaimedBy())
+	}
+	if bm.GetClaimedAt() == nil || !bm.GetClaimedAt().Equal(now) {
+		t.Errorf("claimed-at = %v, want %v", bm.GetClaimedAt(), now)
+	}
+	if bm.GetLeaseUntil() == nil || !bm.GetLeaseUntil().Equal(now.Add(10*time.Minute)) {
+		t.Errorf("lease-until = %v", bm.GetLeaseUntil())
+	}
+	for _, label := range bm.Labels[1:] {
+		if !IsClaimLabel(label) {
+			t.Errorf("IsClaimLabel(%q) = false", label)
+		}
+	}
+	if IsClaimLabel("queue:work") {
+		t.Error("queue label treated as a claim label")
+	}
+}
+
+func TestClaimExpired(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	ttl := 30 * time.Minute
+
+	tests := []struct {
+		name string
+		msg  *BeadsMessage
+		want bool
+	}{
+		{"unclaimed", queueMsg("a"), false},
+		{"live lease", queueMsg("b", ClaimLabels("nux", now.Add(-time.Hour), 2*time.Hour)...), false},
+		{"lapsed lease", queueMsg("c", ClaimLabels("nux", now.Add(-time.Hour), 10*time.Minute)...), true},
+		{"legacy claim within ttl", queueMsg("d", ClaimedByLabel+"nux", ClaimedAtLabel+now.Add(-10*time.Minute).Format(time.RFC3339)), false},
+		{"legacy claim past ttl", queueMsg("e", ClaimedByLabel+"nux", ClaimedAtLabel+now.Add(-time.Hour).Format(time.RFC3339)), true},
+		{"claim without timestamps", queueMsg("f", ClaimedByLabel+"nux"), false},
+	}
+	for _, tt := range tests {
+		if got := tt.msg.ClaimExpired(now, ttl); got != tt.want {
+			t.Errorf("%s: ClaimExpired = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCountQueue(t *testing.T) {
+	now := time.Now()
+	messages := []*BeadsMessage{
+		queueMsg("a"),
+		queueMsg("b"),
+		queueMsg("c", ClaimLabels("nux", now, time.Hour)...),
+		queueMsg("d", ClaimLabels("furiosa", now.Add(-2*time.Hour), time.Hour)...),
+	}
+
+	stats := CountQueue("work", messages, 1, now, time.Hour)
+	want := QueueStats{Queue: "work", Unclaimed: 2, Claimed: 1, Expired: 1, MaxClaims: 1}
+	if stats != want {
+		t.Errorf("CountQueue = %+v, want %+v", stats, want)
+	}
+	if !stats.Full() {
+		t.Error("queue with 1/1 live claims should be full")
+	}
+
+	// Expired claims don't count against the limit, and 0 is unlimited.
+	stats.Claimed = 0
+	if stats.Full() {
+		t.Error("queue with only expired claims should not be full")
+	}
+	if (QueueStats{Claimed: 50}).Full() {
+		t.Error("max_claims 0 should be unlimited")
+	}
+}
+
+func TestRenewDue(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	ttl := 30 * time.Minute
+
+	tests := []struct {
+		name string
+		msg  *BeadsMessage
+		want bool
+	}{
+		{"unclaimed", queueMsg("a"), false},
+		{"fresh lease", queueMsg("b", ClaimLabels("nux", now.Add(-5*time.Minute), ttl)...), false},
+		{"half used", queueMsg("c", ClaimLabels("nux", now.Add(-20*time.Minute), ttl)...), true},
+		{"expired", queueMsg("d", ClaimLabels("nux", now.Add(-time.Hour), ttl)...), false},
+		{"legacy claim past half ttl", queueMsg("e", ClaimedByLabel+"nux", ClaimedAtLabel+now.Add(-20*time.Minute).Format(time.RFC3339)), true},
+	}
+	for _, tt := range tests {
+		if got := tt.msg.RenewDue(now, ttl); got != tt.want {
+			t.Errorf("%s: RenewDue = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
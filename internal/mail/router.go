@@ -501,7 +501,14 @@ func (r *Router) shouldBeWisp(msg *Message) bool {
 // Supports single-copy delivery for:
 // - Queues (queue:name) - stores single message for worker claiming
 // - Announces (announce:name) - bulletin board, no claiming, retention-limited
+// Addresses on another machine (machine:address) are forwarded to that
+// machine's town through the federation transport.
 func (r *Router) Send(msg *Message) error {
+	// Check for machine:address - forward to another town
+	if isRemoteAddress(msg.To) {
+		return r.sendToRemote(msg)
+	}
+
 	// Check for mailing list address
 	if isListAddress(msg.To) {
 		return r.sendToList(msg)
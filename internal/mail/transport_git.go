@@ -0,0 +1,254 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// gitPushAttempts bounds retries when another town pushes first.
+const gitPushAttempts = 5
+
+// gitCommandTimeout bounds each git command, so an unreachable or stalled
+// remote can't hold up the daemon heartbeat that syncs federated mail.
+var gitCommandTimeout = time.Minute
+
+// GitTransport carries envelopes through a shared git repo that every
+// town can push to. Each machine has a directory of pending envelopes,
+// one JSON file per envelope:
+//
+//	<machine>/<envelope-id>.json
+//
+// Towns add envelopes to the destination machine's directory and remove
+// them from their own once handled. Every change is applied to a fresh
+// checkout of the remote branch and pushed, and simply reapplied if
+// another town pushed first, so towns never merge. A bare repo on a
+// shared host (or a local path, in tests) works as the remote.
+//
+// A GitTransport's clone is not safe for concurrent use; Federation
+// serializes access.
+type GitTransport struct {
+	Remote string // git URL or path of the shared mail repo
+	Branch string // branch envelopes are exchanged on
+	Dir    string // local clone
+	Author string // commit author, usually the machine name
+}
+
+// NewGitTransport creates a transport using a clone of remote at dir.
+func NewGitTransport(remote, branch, dir, author string) *GitTransport {
+	return &GitTransport{Remote: remote, Branch: branch, Dir: dir, Author: author}
+}
+
+// Send commits envelopes into their destination machines' directories.
+func (g *GitTransport) Send(envs []*Envelope) error {
+	if len(envs) == 0 {
+		return nil
+	}
+	files := make(map[string][]byte, len(envs))
+	for _, env := range envs {
+		if err := validEnvelopePath(env.To, env.ID); err != nil {
+			return err
+		}
+		data, err := json.MarshalIndent(env, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encoding envelope %s: %w", env.ID, err)
+		}
+		files[filepath.Join(env.To, env.ID+".json")] = append(data, '\n')
+	}
+
+	return g.update(fmt.Sprintf("%s: send %d envelope(s)", g.Author, len(envs)), func() (bool, error) {
+		for path, data := range files {
+			full := filepath.Join(g.Dir, path)
+			if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+				return false, err
+			}
+			if err := os.WriteFile(full, data, 0644); err != nil { //nolint:gosec // G306: mail is shared with other towns by design
+				return false, err
+			}
+		}
+		return true, nil
+	})
+}
+
+// Receive returns the envelopes in machine's directory, oldest first.
+func (g *GitTransport) Receive(machine string) ([]*Envelope, error) {
+	if err := validEnvelopePath(machine, ""); err != nil {
+		return nil, err
+	}
+	if err := g.checkout(); err != nil {
+		return nil, err
+	}
+
+	paths, err := filepath.Glob(filepath.Join(g.Dir, machine, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var envs []*Envelope
+	for _, path := range paths {
+		data, err := os.ReadFile(path) //nolint:gosec // G304: path is within the mail clone
+		if err != nil {
+			return nil, err
+		}
+		var env Envelope
+		if err := json.Unmarshal(data, &env); err != nil {
+			// Keep the file name as the ID so a corrupt envelope can be acked away.
+			env = Envelope{ID: strings.TrimSuffix(filepath.Base(path), ".json"), Kind: "corrupt"}
+		}
+		envs = append(envs, &env)
+	}
+	sort.SliceStable(envs, func(i, j int) bool {
+		return envs[i].SentAt.Before(envs[j].SentAt)
+	})
+	return envs, nil
+}
+
+// Ack removes envelopes from machine's directory.
+func (g *GitTransport) Ack(machine string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	for _, id := range ids {
+		if err := validEnvelopePath(machine, id); err != nil {
+			return err
+		}
+	}
+	return g.update(fmt.Sprintf("%s: ack %d envelope(s)", g.Author, len(ids)), func() (bool, error) {
+		changed := false
+		for _, id := range ids {
+			err := os.Remove(filepath.Join(g.Dir, machine, id+".json"))
+			if err == nil {
+				changed = true
+			} else if !os.IsNotExist(err) {
+				return false, err
+			}
+		}
+		return changed, nil
+	})
+}
+
+// update applies change to a fresh checkout of the remote branch, commits
+// and pushes. If the push is rejected because another town pushed first,
+// it starts over from the new remote state.
+func (g *GitTransport) update(message string, change func() (bool, error)) error {
+	var lastErr error
+	for attempt := 0; attempt < gitPushAttempts; attempt++ {
+		if err := g.checkout(); err != nil {
+			return err
+		}
+		changed, err := change()
+		if err != nil {
+			return fmt.Errorf("updating mail clone: %w", err)
+		}
+		if !changed {
+			return nil
+		}
+		if _, err := g.git("add", "-A"); err != nil {
+			return err
+		}
+		if _, err := g.git("commit", "-q", "-m", message); err != nil {
+			return err
+		}
+		if _, lastErr = g.git("push", "-q", "origin", "HEAD:refs/heads/"+g.Branch); lastErr == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("pushing to mail repo after %d attempts: %w", gitPushAttempts, lastErr)
+}
+
+// checkout makes Dir a clean checkout of the remote branch, cloning on
+// first use. If the branch doesn't exist yet, Dir is an empty branch that
+// the first push creates.
+func (g *GitTransport) checkout() error {
+	if _, err := os.Stat(filepath.Join(g.Dir, ".git")); os.IsNotExist(err) {
+		if err := os.MkdirAll(g.Dir, 0755); err != nil {
+			return fmt.Errorf("creating mail clone: %w", err)
+		}
+		if _, err := g.git("init", "-q"); err != nil {
+			return err
+		}
+		if _, err := g.git("remote", "add", "origin", g.Remote); err != nil {
+			return err
+		}
+	}
+
+	if _, err := g.git("fetch", "-q", "--prune", "origin"); err != nil {
+		return err
+	}
+	remoteRef := "refs/remotes/origin/" + g.Branch
+	if _, err := g.git("rev-parse", "-q", "--verify", remoteRef); err == nil {
+		if _, err := g.git("checkout", "-q", "-f", "-B", g.Branch, remoteRef); err != nil {
+			return err
+		}
+	} else {
+		// Remote branch doesn't exist yet: start from an empty tree,
+		// dropping anything left over from a failed push.
+		if _, err := g.git("symbolic-ref", "HEAD", "refs/heads/"+g.Branch); err != nil {
+			return err
+		}
+		_, _ = g.git("update-ref", "-d", "refs/heads/"+g.Branch)
+		if _, err := g.git("read-tree", "--empty"); err != nil {
+			return err
+		}
+	}
+	_, err := g.git("clean", "-q", "-f", "-d", "-x")
+	return err
+}
+
+// git runs a git command in the clone, killing it after gitCommandTimeout.
+// Commits are attributed to the transport's author so they work on machines
+// without a git identity. Credential and host-key prompts fail instead of
+// waiting for input that never comes: ssh runs in batch mode unless
+// GIT_SSH_COMMAND is already set.
+func (g *GitTransport) git(args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), gitCommandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = g.Dir
+	cmd.WaitDelay = time.Second // don't wait on ssh children holding the pipes
+	email := g.Author + "@gastown.local"
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME="+g.Author, "GIT_AUTHOR_EMAIL="+email,
+		"GIT_COMMITTER_NAME="+g.Author, "GIT_COMMITTER_EMAIL="+email,
+		"GIT_TERMINAL_PROMPT=0",
+	)
+	if os.Getenv("GIT_SSH_COMMAND") == "" {
+		cmd.Env = append(cmd.Env, "GIT_SSH_COMMAND=ssh -o BatchMode=yes -o ConnectTimeout=30")
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", fmt.Errorf("git %s: timed out after %s", args[0], gitCommandTimeout)
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return stdout.String(), nil
+}
+
+// validEnvelopePath rejects machine names and IDs that would escape the
+// clone or collide with git's own files.
+func validEnvelopePath(machine, id string) error {
+	for _, part := range []string{machine, id} {
+		if strings.ContainsAny(part, `/\`) || strings.HasPrefix(part, ".") {
+			return fmt.Errorf("invalid envelope path component %q", part)
+		}
+	}
+	if machine == "" {
+		return fmt.Errorf("envelope has no destination machine")
+	}
+	return nil
+}
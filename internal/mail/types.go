@@ -276,20 +276,21 @@ type BeadsMessage struct {
 	Priority    int       `json:"priority"`    // 0=urgent, 1=high, 2=normal, 3=low
 	Status      string    `json:"status"`      // open=unread, closed=read
 	CreatedAt   time.Time `json:"created_at"`
-	Labels      []string  `json:"labels"` // Metadata labels (from:X, thread:X, reply-to:X, msg-type:X, cc:X, queue:X, channel:X, claimed-by:X, claimed-at:X)
+	Labels      []string  `json:"labels"` // Metadata labels (from:X, thread:X, reply-to:X, msg-type:X, cc:X, queue:X, channel:X, claimed-by:X, claimed-at:X, lease-until:X)
 	Pinned      bool      `json:"pinned,omitempty"`
 	Wisp        bool      `json:"wisp,omitempty"` // Ephemeral message (filtered from JSONL export)
 
 	// Cached parsed values (populated by ParseLabels)
-	sender    string
-	threadID  string
-	replyTo   string
-	msgType   string
-	cc        []string   // CC recipients
-	queue     string     // Queue name (for queue messages)
-	channel   string     // Channel name (for broadcast messages)
-	claimedBy string     // Who claimed the queue message
-	claimedAt *time.Time // When the queue message was claimed
+	sender     string
+	threadID   string
+	replyTo    string
+	msgType    string
+	cc         []string   // CC recipients
+	queue      string     // Queue name (for queue messages)
+	channel    string     // Channel name (for broadcast messages)
+	claimedBy  string     // Who claimed the queue message
+	claimedAt  *time.Time // When the queue message was claimed
+	leaseUntil *time.Time // When the claim lapses unless renewed
 }
 
 // ParseLabels extracts metadata from the labels array.
@@ -316,6 +317,11 @@ func (bm *BeadsMessage) ParseLabels() {
 			if t, err := time.Parse(time.RFC3339, ts); err == nil {
 				bm.claimedAt = &t
 			}
+		} else if strings.HasPrefix(label, "lease-until:") {
+			ts := strings.TrimPrefix(label, "lease-until:")
+			if t, err := time.Parse(time.RFC3339, ts); err == nil {
+				bm.leaseUntil = &t
+			}
 		}
 	}
 }
@@ -407,6 +413,11 @@ func (bm *BeadsMessage) GetClaimedAt() *time.Time {
 	return bm.claimedAt
 }
 
+// GetLeaseUntil returns when the queue message's claim lease lapses.
+func (bm *BeadsMessage) GetLeaseUntil() *time.Time {
+	return bm.leaseUntil
+}
+
 // IsQueueMessage returns true if this is a queue-routed message.
 func (bm *BeadsMessage) IsQueueMessage() bool {
 	bm.ParseLabels()
@@ -0,0 +1,252 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// httpClient is shared by the HTTP backends. Timeouts come from the context.
+var httpClient = &http.Client{}
+
+// SMTP sends email through an SMTP server. net/smtp upgrades to STARTTLS
+// when the server offers it, and refuses PLAIN auth over an unencrypted
+// connection to anything but localhost.
+type SMTP struct {
+	Host     string
+	Port     int // default 587
+	Username string
+	Password string
+	From     string
+
+	// send is smtp.SendMail; tests replace it.
+	send func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// Name implements Notifier.
+func (s *SMTP) Name() string { return "smtp" }
+
+// Notify implements Notifier.
+func (s *SMTP) Notify(ctx context.Context, msg Message) error {
+	if msg.To == "" {
+		return permanent(fmt.Errorf("no recipient"))
+	}
+	port := s.Port
+	if port == 0 {
+		port = 587
+	}
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+	send := s.send
+	if send == nil {
+		send = smtp.SendMail
+	}
+
+	// smtp.SendMail has no context support; run it so ctx can abandon it.
+	done := make(chan error, 1)
+	go func() {
+		done <- send(net.JoinHostPort(s.Host, strconv.Itoa(port)), auth, s.From, []string{msg.To}, s.format(msg))
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// format builds an RFC 5322 message.
+func (s *SMTP) format(msg Message) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", s.From)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", headerSafe(msg.Subject()))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	if msg.Severity == "critical" || msg.Severity == "high" {
+		b.WriteString("X-Priority: 1\r\n")
+	}
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return b.Bytes()
+}
+
+// headerSafe strips line breaks so a title can't inject headers.
+func headerSafe(s string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+}
+
+// Slack posts to a Slack incoming webhook.
+type Slack struct {
+	WebhookURL string
+}
+
+// Name implements Notifier.
+func (s *Slack) Name() string { return "slack" }
+
+// Notify implements Notifier.
+func (s *Slack) Notify(ctx context.Context, msg Message) error {
+	payload := map[string]interface{}{
+		"text": fmt.Sprintf("%s *%s*\n%s", slackEmoji(msg.Severity), msg.Subject(), msg.Body),
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return permanent(err)
+	}
+	return postJSON(ctx, http.MethodPost, s.WebhookURL, nil, data)
+}
+
+func slackEmoji(severity string) string {
+	switch severity {
+	case "critical":
+		return ":rotating_light:"
+	case "high":
+		return ":warning:"
+	case "medium":
+		return ":loudspeaker:"
+	default:
+		return ":information_source:"
+	}
+}
+
+// Webhook sends a message to an arbitrary HTTP endpoint. The body is the
+// JSON-encoded Message unless a Body template is given.
+type Webhook struct {
+	Label   string // Name in delivery records (default "webhook")
+	URL     string
+	Method  string // default POST
+	Headers map[string]string
+	Body    string // Optional text/template over Message
+}
+
+// Name implements Notifier.
+func (w *Webhook) Name() string {
+	if w.Label != "" {
+		return w.Label
+	}
+	return "webhook"
+}
+
+// Notify implements Notifier.
+func (w *Webhook) Notify(ctx context.Context, msg Message) error {
+	var body []byte
+	if w.Body != "" {
+		tmpl, err := template.New("body").Parse(w.Body)
+		if err != nil {
+			return permanent(fmt.Errorf("parsing body template: %w", err))
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, msg); err != nil {
+			return permanent(fmt.Errorf("rendering body template: %w", err))
+		}
+		body = buf.Bytes()
+	} else {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return permanent(err)
+		}
+		body = data
+	}
+
+	method := w.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	return postJSON(ctx, method, w.URL, w.Headers, body)
+}
+
+// postJSON sends body to url. Content-Type defaults to application/json
+// unless a header overrides it.
+func postJSON(ctx context.Context, method, url string, headers map[string]string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, os.ExpandEnv(v))
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	return checkResponse(resp)
+}
+
+// Command runs a local command, e.g. a CLI for an SMS provider.
+// The message body is written to stdin and the fields are exported as
+// GT_ESCALATION_ID, GT_SEVERITY, GT_TITLE, GT_SUBJECT, GT_TO and GT_SOURCE.
+type Command struct {
+	Label   string // Name in delivery records (default "command")
+	Command string // Run with sh -c
+}
+
+// Name implements Notifier.
+func (c *Command) Name() string {
+	if c.Label != "" {
+		return c.Label
+	}
+	return "command"
+}
+
+// Notify implements Notifier.
+func (c *Command) Notify(ctx context.Context, msg Message) error {
+	cmd := exec.CommandContext(ctx, "sh", "-c", c.Command) //nolint:gosec // G204: command comes from town settings
+	cmd.Stdin = strings.NewReader(msg.Body)
+	env := map[string]string{
+		"GT_ESCALATION_ID": msg.ID,
+		"GT_SEVERITY":      msg.Severity,
+		"GT_TITLE":         msg.Title,
+		"GT_SUBJECT":       msg.Subject(),
+		"GT_TO":            msg.To,
+		"GT_SOURCE":        msg.Source,
+	}
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	cmd.Env = os.Environ()
+	for _, k := range keys {
+		cmd.Env = append(cmd.Env, k+"="+env[k])
+	}
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// Verify the backends implement Notifier.
+var (
+	_ Notifier = (*SMTP)(nil)
+	_ Notifier = (*Slack)(nil)
+	_ Notifier = (*Webhook)(nil)
+	_ Notifier = (*Command)(nil)
+)
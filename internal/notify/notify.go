@@ -0,0 +1,105 @@
+// Package notify delivers escalation notifications to humans over external
+// channels: SMTP email, Slack incoming webhooks, and generic HTTP or command
+// hooks (used for SMS gateways).
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Message is a notification to deliver.
+type Message struct {
+	ID       string `json:"id"`       // Escalation bead ID
+	Severity string `json:"severity"` // critical, high, medium, low
+	Title    string `json:"title"`    // One-line summary
+	Body     string `json:"body"`     // Full text
+	To       string `json:"to"`       // Recipient (address, phone number); empty for channels like Slack
+	Source   string `json:"source,omitempty"`
+}
+
+// Subject returns the conventional "[SEVERITY] title" subject line.
+func (m Message) Subject() string {
+	return fmt.Sprintf("[%s] %s", strings.ToUpper(m.Severity), m.Title)
+}
+
+// Notifier is a delivery backend.
+type Notifier interface {
+	// Name identifies the backend in delivery records (e.g., "smtp").
+	Name() string
+
+	// Notify delivers a message once. Retries are handled by Deliver.
+	Notify(ctx context.Context, msg Message) error
+}
+
+// ErrPermanent marks delivery errors that retrying cannot fix
+// (bad configuration, a 4xx response).
+var ErrPermanent = errors.New("permanent delivery failure")
+
+// permanent wraps err so Deliver does not retry it.
+func permanent(err error) error {
+	return fmt.Errorf("%w: %w", ErrPermanent, err)
+}
+
+// RetryPolicy controls how Deliver retries failed attempts.
+type RetryPolicy struct {
+	Retries int           // Extra attempts after the first
+	Backoff time.Duration // Delay before the first retry; doubles each time
+	Timeout time.Duration // Per-attempt limit (0 = none)
+}
+
+// Result is the outcome of delivering one message through one notifier.
+type Result struct {
+	Notifier string
+	Attempts int
+	Err      error
+}
+
+// Deliver sends msg through n, retrying transient failures with exponential
+// backoff. It gives up early on ErrPermanent or when ctx is done.
+func Deliver(ctx context.Context, n Notifier, msg Message, policy RetryPolicy) Result {
+	res := Result{Notifier: n.Name()}
+	backoff := policy.Backoff
+	for {
+		res.Attempts++
+		res.Err = attempt(ctx, n, msg, policy.Timeout)
+		if res.Err == nil || errors.Is(res.Err, ErrPermanent) || res.Attempts > policy.Retries {
+			return res
+		}
+
+		select {
+		case <-ctx.Done():
+			res.Err = fmt.Errorf("%w (after %d attempts)", res.Err, res.Attempts)
+			return res
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// attempt makes one delivery attempt under the per-attempt timeout.
+func attempt(ctx context.Context, n Notifier, msg Message, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return n.Notify(ctx, msg)
+}
+
+// checkResponse converts an HTTP response status into an error.
+// 4xx responses (except 429) are permanent; 5xx and 429 are retried.
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	err := fmt.Errorf("HTTP %s", resp.Status)
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+		return permanent(err)
+	}
+	return err
+}
@@ -0,0 +1,224 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+type fakeNotifier struct {
+	errs  []error
+	calls int
+}
+
+func (f *fakeNotifier) Name() string { return "fake" }
+
+func (f *fakeNotifier) Notify(context.Context, Message) error {
+	f.calls++
+	if f.calls <= len(f.errs) {
+		return f.errs[f.calls-1]
+	}
+	return nil
+}
+
+var testMsg = Message{ID: "hq-1", Severity: "critical", Title: "Build failing", Body: "CI is red", To: "oncall@example.com"}
+
+func TestDeliver_Retries(t *testing.T) {
+	n := &fakeNotifier{errs: []error{errors.New("timeout"), errors.New("timeout")}}
+	res := Deliver(context.Background(), n, testMsg, RetryPolicy{Retries: 2, Backoff: time.Millisecond})
+	if res.Err != nil || res.Attempts != 3 {
+		t.Errorf("Deliver = %+v, want success on attempt 3", res)
+	}
+
+	n = &fakeNotifier{errs: []error{errors.New("a"), errors.New("b"), errors.New("c")}}
+	res = Deliver(context.Background(), n, testMsg, RetryPolicy{Retries: 1, Backoff: time.Millisecond})
+	if res.Err == nil || res.Attempts != 2 {
+		t.Errorf("Deliver = %+v, want failure after 2 attempts", res)
+	}
+
+	n = &fakeNotifier{errs: []error{permanent(errors.New("bad config"))}}
+	res = Deliver(context.Background(), n, testMsg, RetryPolicy{Retries: 5, Backoff: time.Millisecond})
+	if !errors.Is(res.Err, ErrPermanent) || res.Attempts != 1 {
+		t.Errorf("Deliver = %+v, want permanent failure without retry", res)
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	var gotBody, gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		gotBody = string(data)
+		gotAuth = r.Header.Get("Authorization")
+	}))
+	defer srv.Close()
+
+	t.Setenv("GT_TEST_TOKEN", "s3cret")
+	w := &Webhook{URL: srv.URL, Headers: map[string]string{"Authorization": "Bearer $GT_TEST_TOKEN"}}
+	if err := w.Notify(context.Background(), testMsg); err != nil {
+		t.Fatal(err)
+	}
+	var got Message
+	if err := json.Unmarshal([]byte(gotBody), &got); err != nil || got != testMsg {
+		t.Errorf("body = %s (%v)", gotBody, err)
+	}
+	if gotAuth != "Bearer s3cret" {
+		t.Errorf("Authorization = %q", gotAuth)
+	}
+
+	w.Body = `to={{.To}}&text={{.Severity}}: {{.Title}}`
+	if err := w.Notify(context.Background(), testMsg); err != nil {
+		t.Fatal(err)
+	}
+	if gotBody != "to=oncall@example.com&text=critical: Build failing" {
+		t.Errorf("templated body = %q", gotBody)
+	}
+}
+
+func TestWebhook_StatusHandling(t *testing.T) {
+	var calls int32
+	status := http.StatusBadGateway
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	w := &Webhook{URL: srv.URL}
+	res := Deliver(context.Background(), w, testMsg, RetryPolicy{Retries: 2, Backoff: time.Millisecond})
+	if res.Err == nil || res.Attempts != 3 || atomic.LoadInt32(&calls) != 3 {
+		t.Errorf("5xx: %+v (calls=%d), want 3 attempts", res, calls)
+	}
+
+	status = http.StatusUnauthorized
+	atomic.StoreInt32(&calls, 0)
+	res = Deliver(context.Background(), w, testMsg, RetryPolicy{Retries: 2, Backoff: time.Millisecond})
+	if !errors.Is(res.Err, ErrPermanent) || res.Attempts != 1 {
+		t.Errorf("4xx: %+v, want one permanent failure", res)
+	}
+}
+
+func TestSlack(t *testing.T) {
+	var payload map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+	}))
+	defer srv.Close()
+
+	if err := (&Slack{WebhookURL: srv.URL}).Notify(context.Background(), testMsg); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(payload["text"], "[CRITICAL] Build failing") || !strings.Contains(payload["text"], "CI is red") {
+		t.Errorf("text = %q", payload["text"])
+	}
+}
+
+func TestCommand(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	c := &Command{Command: `{ echo "$GT_TO $GT_SEVERITY $GT_ESCALATION_ID"; cat; } > ` + out}
+	if err := c.Notify(context.Background(), testMsg); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(out)
+	if string(data) != "oncall@example.com critical hq-1\nCI is red" {
+		t.Errorf("command saw %q", data)
+	}
+
+	c = &Command{Command: "echo gateway down >&2; exit 1"}
+	if err := c.Notify(context.Background(), testMsg); err == nil || !strings.Contains(err.Error(), "gateway down") {
+		t.Errorf("err = %v, want output in error", err)
+	}
+}
+
+func TestSMTP(t *testing.T) {
+	var gotAddr, gotFrom string
+	var gotTo []string
+	var gotMsg []byte
+	s := &SMTP{Host: "smtp.example.com", From: "gt@example.com", Username: "gt", Password: "pw",
+		send: func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+			gotAddr, gotFrom, gotTo, gotMsg = addr, from, to, msg
+			return nil
+		}}
+
+	msg := testMsg
+	msg.Title = "Injected\r\nBcc: evil@example.com"
+	if err := s.Notify(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+	if gotAddr != "smtp.example.com:587" || gotFrom != "gt@example.com" || len(gotTo) != 1 || gotTo[0] != "oncall@example.com" {
+		t.Errorf("send(%q, %q, %v)", gotAddr, gotFrom, gotTo)
+	}
+	text := string(gotMsg)
+	if !strings.Contains(text, "Subject: [CRITICAL] Injected  Bcc: evil@example.com\r\n") || strings.Contains(text, "\r\nBcc:") {
+		t.Errorf("headers not sanitized:\n%s", text)
+	}
+	if !strings.HasSuffix(text, "\r\n\r\nCI is red\r\n") {
+		t.Errorf("body missing:\n%s", text)
+	}
+}
+
+func TestResolveAndDispatch(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+	}))
+	defer srv.Close()
+
+	retries := 0
+	cfg := config.NewEscalationConfig()
+	cfg.Contacts.HumanSMS = "+15555550100"
+	cfg.Contacts.SlackWebhook = srv.URL
+	cfg.Channels = config.EscalationChannels{
+		SMS:      &config.EscalationHook{URL: srv.URL},
+		Webhooks: map[string]*config.EscalationHook{"pager": {URL: srv.URL, Timeout: "3s"}},
+		Retries:  &retries,
+	}
+
+	// email:human has no contact, webhook:none has no hook.
+	actions := []string{"bead", "mail:mayor", "email:human", "sms:human", "slack", "webhook:pager", "webhook:none"}
+	if r := Resolve("webhook:pager", cfg); r.Notifier == nil || r.Timeout != 3*time.Second {
+		t.Errorf("Resolve(webhook:pager) = %+v", r)
+	}
+	bad := *cfg
+	bad.Channels.Webhooks = map[string]*config.EscalationHook{"pager": {URL: srv.URL, Timeout: "0s"}}
+	if r := Resolve("webhook:pager", &bad); r.Notifier != nil || !strings.Contains(r.Skip, "invalid timeout") {
+		t.Errorf("Resolve(webhook:pager) with bad timeout = %+v", r)
+	}
+	if r := Resolve("email:ops@example.com", cfg); r.To != "ops@example.com" || r.Skip != "channels.smtp not configured" {
+		t.Errorf("Resolve(email:ops@...) = %+v", r)
+	}
+
+	deliveries := Dispatch(context.Background(), actions, cfg, testMsg)
+	want := map[string]string{
+		"email:human":   DeliverySkipped,
+		"sms:human":     DeliverySent,
+		"slack":         DeliverySent,
+		"webhook:pager": DeliverySent,
+		"webhook:none":  DeliverySkipped,
+	}
+	if len(deliveries) != len(want) {
+		t.Fatalf("got %d deliveries, want %d: %+v", len(deliveries), len(want), deliveries)
+	}
+	for _, d := range deliveries {
+		if d.Status != want[d.Action] {
+			t.Errorf("%s: status %s (%s), want %s", d.Action, d.Status, d.Detail, want[d.Action])
+		}
+	}
+	if deliveries[1].To != "+15555550100" {
+		t.Errorf("sms recipient = %q", deliveries[1].To)
+	}
+	if atomic.LoadInt32(&hits) != 3 {
+		t.Errorf("server hits = %d, want 3", hits)
+	}
+}
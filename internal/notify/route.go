@@ -0,0 +1,191 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// defaultHookTimeout bounds each attempt when a hook sets no timeout.
+const defaultHookTimeout = 10 * time.Second
+
+// Route is an external escalation action resolved against the escalation
+// config: which backend delivers it and to whom.
+type Route struct {
+	Action   string        // The route action, e.g. "email:human"
+	Notifier Notifier      // Nil when Skip is set
+	To       string        // Recipient address or number, if any
+	Timeout  time.Duration // Per-attempt limit
+
+	// Skip explains why the action can't be delivered (missing contact or
+	// channel config). Skipped actions are recorded but not attempted.
+	Skip string
+}
+
+// IsExternal reports whether an action is delivered outside Gas Town.
+// "bead", "mail:*" and "log" are handled by gt itself.
+func IsExternal(action string) bool {
+	return strings.HasPrefix(action, "email:") ||
+		strings.HasPrefix(action, "sms:") ||
+		strings.HasPrefix(action, "webhook:") ||
+		action == "slack"
+}
+
+// Resolve maps an external action to its backend. The "human" recipient in
+// email:human and sms:human comes from contacts; any other suffix is used as
+// the address or number itself (e.g., "email:oncall@example.com").
+func Resolve(action string, cfg *config.EscalationConfig) Route {
+	r := Route{Action: action, Timeout: defaultHookTimeout}
+	ch := cfg.Channels
+
+	switch {
+	case strings.HasPrefix(action, "email:"):
+		r.To = recipient(strings.TrimPrefix(action, "email:"), cfg.Contacts.HumanEmail)
+		switch {
+		case r.To == "":
+			r.Skip = "contacts.human_email not configured"
+		case ch.SMTP == nil:
+			r.Skip = "channels.smtp not configured"
+		default:
+			password := ""
+			if ch.SMTP.PasswordEnv != "" {
+				password = os.Getenv(ch.SMTP.PasswordEnv)
+			}
+			r.Notifier = &SMTP{
+				Host:     ch.SMTP.Host,
+				Port:     ch.SMTP.Port,
+				Username: ch.SMTP.Username,
+				Password: password,
+				From:     ch.SMTP.From,
+			}
+			r.Timeout = 30 * time.Second
+		}
+
+	case strings.HasPrefix(action, "sms:"):
+		r.To = recipient(strings.TrimPrefix(action, "sms:"), cfg.Contacts.HumanSMS)
+		switch {
+		case r.To == "":
+			r.Skip = "contacts.human_sms not configured"
+		case ch.SMS == nil:
+			r.Skip = "channels.sms not configured"
+		default:
+			r.resolveHook("sms", "channels.sms", ch.SMS)
+		}
+
+	case strings.HasPrefix(action, "webhook:"):
+		name := strings.TrimPrefix(action, "webhook:")
+		hook := ch.Webhooks[name]
+		if hook == nil {
+			r.Skip = fmt.Sprintf("channels.webhooks.%s not configured", name)
+		} else {
+			r.resolveHook("webhook:"+name, "channels.webhooks."+name, hook)
+		}
+
+	case action == "slack":
+		if cfg.Contacts.SlackWebhook == "" {
+			r.Skip = "contacts.slack_webhook not configured"
+		} else {
+			r.Notifier = &Slack{WebhookURL: cfg.Contacts.SlackWebhook}
+		}
+
+	default:
+		r.Skip = "not an external action"
+	}
+	return r
+}
+
+// recipient resolves the "human" placeholder to the configured contact.
+func recipient(target, human string) string {
+	if target == "human" || target == "" {
+		return human
+	}
+	return target
+}
+
+// resolveHook sets the route's notifier from a hook, or skips the route when
+// the hook is misconfigured.
+func (r *Route) resolveHook(label, key string, hook *config.EscalationHook) {
+	n, timeout, err := hookNotifier(label, hook)
+	if err != nil {
+		r.Skip = fmt.Sprintf("%s: %v", key, err)
+		return
+	}
+	r.Notifier, r.Timeout = n, timeout
+}
+
+// hookNotifier builds the notifier for a webhook or command hook. An invalid
+// or non-positive timeout is an error rather than a silent default.
+func hookNotifier(label string, hook *config.EscalationHook) (Notifier, time.Duration, error) {
+	timeout := defaultHookTimeout
+	if hook.Timeout != "" {
+		d, err := time.ParseDuration(hook.Timeout)
+		if err != nil || d <= 0 {
+			return nil, 0, fmt.Errorf("invalid timeout %q", hook.Timeout)
+		}
+		timeout = d
+	}
+	if hook.Command != "" {
+		return &Command{Label: label, Command: hook.Command}, timeout, nil
+	}
+	return &Webhook{Label: label, URL: hook.URL, Method: hook.Method, Headers: hook.Headers, Body: hook.Body}, timeout, nil
+}
+
+// Delivery is the outcome of one external action for one escalation.
+type Delivery struct {
+	Action   string
+	Notifier string
+	To       string
+	Status   string // DeliverySent, DeliveryFailed or DeliverySkipped
+	Attempts int
+	Detail   string // Error or skip reason
+	At       time.Time
+}
+
+// Delivery statuses.
+const (
+	DeliverySent    = "sent"
+	DeliveryFailed  = "failed"
+	DeliverySkipped = "skipped"
+)
+
+// Dispatch delivers msg for every external action in actions, in order,
+// with the retry policy from cfg.Channels. Non-external actions are ignored.
+func Dispatch(ctx context.Context, actions []string, cfg *config.EscalationConfig, msg Message) []Delivery {
+	var deliveries []Delivery
+	for _, action := range actions {
+		if !IsExternal(action) {
+			continue
+		}
+		route := Resolve(action, cfg)
+		d := Delivery{Action: action, To: route.To, At: time.Now()}
+		if route.Notifier == nil {
+			d.Status = DeliverySkipped
+			d.Detail = route.Skip
+			deliveries = append(deliveries, d)
+			continue
+		}
+
+		m := msg
+		m.To = route.To
+		res := Deliver(ctx, route.Notifier, m, RetryPolicy{
+			Retries: cfg.Channels.GetRetries(),
+			Backoff: cfg.Channels.GetRetryBackoff(),
+			Timeout: route.Timeout,
+		})
+		d.Notifier = res.Notifier
+		d.Attempts = res.Attempts
+		d.At = time.Now()
+		if res.Err != nil {
+			d.Status = DeliveryFailed
+			d.Detail = res.Err.Error()
+		} else {
+			d.Status = DeliverySent
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries
+}
@@ -0,0 +1,322 @@
+package plugin
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule is a parsed five-field cron expression:
+//
+//	minute hour day-of-month month day-of-week
+//
+// Fields accept *, lists (1,15), ranges (1-5), steps (*/15, 0-30/10) and
+// month/weekday names (jan, mon). Day-of-week is 0-7 with both 0 and 7
+// meaning Sunday. As in Vixie cron, when both day-of-month and day-of-week
+// are restricted a day matches if either does.
+//
+// The macros @yearly (@annually), @monthly, @weekly, @daily (@midnight) and
+// @hourly are supported, as is a leading "CRON_TZ=<zone>" (or "TZ=<zone>")
+// to evaluate the schedule in a specific timezone.
+type CronSchedule struct {
+	expr     string
+	minute   uint64 // bit i set = minute i matches
+	hour     uint64
+	dom      uint64
+	month    uint64
+	dow      uint64
+	domStar  bool
+	dowStar  bool
+	location *time.Location
+}
+
+// cronSearchYears bounds how far Next and Prev search. Expressions like
+// "0 0 30 2 *" never match; this keeps them from looping forever.
+const cronSearchYears = 5
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var dowNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// ParseCron parses a cron expression. loc is the timezone the schedule is
+// evaluated in (nil means local time); a CRON_TZ= prefix overrides it.
+func ParseCron(expr string, loc *time.Location) (*CronSchedule, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+	spec := strings.TrimSpace(expr)
+
+	if strings.HasPrefix(spec, "CRON_TZ=") || strings.HasPrefix(spec, "TZ=") {
+		i := strings.IndexAny(spec, " \t")
+		if i < 0 {
+			return nil, fmt.Errorf("cron %q: missing schedule after timezone", expr)
+		}
+		zone := spec[strings.Index(spec, "=")+1 : i]
+		tz, err := time.LoadLocation(zone)
+		if err != nil {
+			return nil, fmt.Errorf("cron %q: unknown timezone %q: %w", expr, zone, err)
+		}
+		loc = tz
+		spec = strings.TrimSpace(spec[i:])
+	}
+
+	if strings.HasPrefix(spec, "@") {
+		macro, ok := cronMacros[strings.ToLower(spec)]
+		if !ok {
+			return nil, fmt.Errorf("cron %q: unknown macro %s", expr, spec)
+		}
+		spec = macro
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron %q: expected 5 fields (minute hour day month weekday), got %d", expr, len(fields))
+	}
+
+	s := &CronSchedule{expr: expr, location: loc}
+	var err error
+	if s.minute, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("cron %q: minute: %w", expr, err)
+	}
+	if s.hour, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("cron %q: hour: %w", expr, err)
+	}
+	if s.dom, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("cron %q: day of month: %w", expr, err)
+	}
+	if s.month, err = parseCronField(fields[3], 1, 12, monthNames); err != nil {
+		return nil, fmt.Errorf("cron %q: month: %w", expr, err)
+	}
+	if s.dow, err = parseCronField(fields[4], 0, 7, dowNames); err != nil {
+		return nil, fmt.Errorf("cron %q: day of week: %w", expr, err)
+	}
+	// 7 is an alias for Sunday.
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domStar = strings.HasPrefix(fields[2], "*")
+	s.dowStar = strings.HasPrefix(fields[4], "*")
+
+	return s, nil
+}
+
+// parseCronField parses one comma-separated field into a bitmask.
+func parseCronField(field string, min, max int, names map[string]int) (uint64, error) {
+	var mask uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+			rangePart, step = part[:i], n
+		}
+
+		lo, hi := min, max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if lo, err = parseCronValue(bounds[0], names); err != nil {
+				return 0, err
+			}
+			if hi, err = parseCronValue(bounds[1], names); err != nil {
+				return 0, err
+			}
+		default:
+			v, err := parseCronValue(rangePart, names)
+			if err != nil {
+				return 0, err
+			}
+			lo = v
+			// "5/15" means starting at 5, every 15.
+			if step > 1 {
+				hi = max
+			} else {
+				hi = v
+			}
+		}
+
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q out of range %d-%d", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			mask |= 1 << uint(v)
+		}
+	}
+	return mask, nil
+}
+
+// parseCronValue parses a number or a name.
+func parseCronValue(s string, names map[string]int) (int, error) {
+	if v, ok := names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	return v, nil
+}
+
+// String returns the original expression.
+func (s *CronSchedule) String() string {
+	return s.expr
+}
+
+// Location returns the timezone the schedule is evaluated in.
+func (s *CronSchedule) Location() *time.Location {
+	return s.location
+}
+
+// dayMatches applies the Vixie cron day-of-month/day-of-week rule.
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// Next returns the first scheduled time strictly after t, or the zero time
+// if the schedule never fires.
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	t = t.In(s.location).Truncate(time.Minute).Add(time.Minute)
+	limit := t.Year() + cronSearchYears
+
+	for t.Year() <= limit {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = forward(t, time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.location))
+		case !s.dayMatches(t):
+			t = forward(t, time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.location))
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = forward(t, time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.location))
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// Prev returns the latest scheduled time at or before t, or the zero time
+// if the schedule never fired within the search window.
+func (s *CronSchedule) Prev(t time.Time) time.Time {
+	t = t.In(s.location).Truncate(time.Minute)
+	limit := t.Year() - cronSearchYears
+
+	for t.Year() >= limit {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = backward(t, time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, s.location).Add(-time.Minute))
+		case !s.dayMatches(t):
+			t = backward(t, time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, s.location).Add(-time.Minute))
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = backward(t, time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, s.location).Add(-time.Minute))
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(-time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// forward and backward guard the calendar jumps in Next and Prev: around a
+// DST transition time.Date may resolve an ambiguous local time to the
+// "wrong" side, so fall back to a one-minute step if the jump didn't move.
+func forward(from, to time.Time) time.Time {
+	if !to.After(from) {
+		return from.Add(time.Minute)
+	}
+	return to
+}
+
+func backward(from, to time.Time) time.Time {
+	if !to.Before(from) {
+		return from.Add(-time.Minute)
+	}
+	return to
+}
+
+// CatchUp controls what happens to cron windows missed while the Deacon
+// was paused or down.
+type CatchUp string
+
+const (
+	// CatchUpOnce runs the plugin once to cover any number of missed windows.
+	CatchUpOnce CatchUp = "once"
+
+	// CatchUpSkip drops missed windows: the plugin is only due if the most
+	// recent window is within the grace period.
+	CatchUpSkip CatchUp = "skip"
+)
+
+// maxMissedCount caps how many missed windows Evaluate counts.
+const maxMissedCount = 1000
+
+// CronStatus is the result of evaluating a cron schedule against a plugin's
+// last run.
+type CronStatus struct {
+	// Due is true if the plugin should run now.
+	Due bool
+
+	// LastWindow is the most recent scheduled time at or before now.
+	LastWindow time.Time
+
+	// NextWindow is the first scheduled time after now.
+	NextWindow time.Time
+
+	// Missed counts windows since the last run (capped at 1000). More than
+	// one means windows were missed while the Deacon was not patrolling.
+	Missed int
+}
+
+// Evaluate reports whether the schedule is due at now given the last run
+// (zero if the plugin has never run). A plugin is due when a window has
+// passed since its last run; with CatchUpSkip, that window must also be
+// no older than grace.
+func (s *CronSchedule) Evaluate(lastRun, now time.Time, policy CatchUp, grace time.Duration) CronStatus {
+	status := CronStatus{
+		LastWindow: s.Prev(now),
+		NextWindow: s.Next(now),
+	}
+	if status.LastWindow.IsZero() || !status.LastWindow.After(lastRun) {
+		return status
+	}
+
+	if lastRun.IsZero() {
+		status.Missed = 1
+	} else {
+		for w := s.Next(lastRun); !w.IsZero() && !w.After(now) && status.Missed < maxMissedCount; w = s.Next(w) {
+			status.Missed++
+		}
+	}
+
+	status.Due = true
+	if policy == CatchUpSkip && now.Sub(status.LastWindow) > grace {
+		status.Due = false
+	}
+	return status
+}
@@ -0,0 +1,199 @@
+package plugin
+
+import (
+	"testing"
+	"time"
+)
+
+func mustParseCron(t *testing.T, expr string) *CronSchedule {
+	t.Helper()
+	s, err := ParseCron(expr, time.UTC)
+	if err != nil {
+		t.Fatalf("ParseCron(%q): %v", expr, err)
+	}
+	return s
+}
+
+func utc(s string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"@sometimes",
+		"CRON_TZ=Nowhere/City 0 9 * * *",
+	} {
+		if _, err := ParseCron(expr, time.UTC); err == nil {
+			t.Errorf("ParseCron(%q) succeeded, want error", expr)
+		}
+	}
+}
+
+func TestCronSchedule_Next(t *testing.T) {
+	tests := []struct {
+		expr string
+		from string
+		want string
+	}{
+		{"0 9 * * *", "2025-03-10 08:59", "2025-03-10 09:00"},
+		{"0 9 * * *", "2025-03-10 09:00", "2025-03-11 09:00"},
+		{"*/15 * * * *", "2025-03-10 10:16", "2025-03-10 10:30"},
+		{"0 9 * * 1-5", "2025-03-14 10:00", "2025-03-17 09:00"}, // Fri -> Mon
+		{"0 0 1 jan *", "2025-03-10 00:00", "2026-01-01 00:00"},
+		{"30 6 * * sun", "2025-03-10 00:00", "2025-03-16 06:30"},
+		{"0 0 * * 7", "2025-03-10 00:00", "2025-03-16 00:00"},  // 7 = Sunday
+		{"0 0 13 * 5", "2025-03-10 00:00", "2025-03-13 00:00"}, // 13th OR Friday
+		{"0 0 29 2 *", "2025-03-10 00:00", "2028-02-29 00:00"},
+		{"@hourly", "2025-03-10 10:16", "2025-03-10 11:00"},
+		{"0,30 8-9 * * *", "2025-03-10 08:31", "2025-03-10 09:00"},
+	}
+	for _, tt := range tests {
+		got := mustParseCron(t, tt.expr).Next(utc(tt.from))
+		if !got.Equal(utc(tt.want)) {
+			t.Errorf("%q Next(%s) = %s, want %s", tt.expr, tt.from, got.Format("2006-01-02 15:04 Mon"), tt.want)
+		}
+	}
+}
+
+func TestCronSchedule_Prev(t *testing.T) {
+	tests := []struct {
+		expr string
+		from string
+		want string
+	}{
+		{"0 9 * * *", "2025-03-10 09:00", "2025-03-10 09:00"},
+		{"0 9 * * *", "2025-03-10 08:59", "2025-03-09 09:00"},
+		{"0 9 * * 1-5", "2025-03-16 12:00", "2025-03-14 09:00"}, // Sun -> Fri
+		{"*/15 * * * *", "2025-03-10 10:44", "2025-03-10 10:30"},
+		{"0 0 1 * *", "2025-03-10 00:00", "2025-03-01 00:00"},
+	}
+	for _, tt := range tests {
+		got := mustParseCron(t, tt.expr).Prev(utc(tt.from))
+		if !got.Equal(utc(tt.want)) {
+			t.Errorf("%q Prev(%s) = %s, want %s", tt.expr, tt.from, got.Format("2006-01-02 15:04 Mon"), tt.want)
+		}
+	}
+
+	if got := mustParseCron(t, "0 0 30 2 *").Prev(utc("2025-03-10 00:00")); !got.IsZero() {
+		t.Errorf("impossible schedule Prev = %s, want zero", got)
+	}
+}
+
+func TestCronSchedule_Timezone(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+
+	// 09:00 in New York is 13:00 UTC during daylight time.
+	s, err := ParseCron("CRON_TZ=America/New_York 0 9 * * *", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Location().String() != ny.String() {
+		t.Errorf("Location = %s, want %s", s.Location(), ny)
+	}
+	got := s.Next(utc("2025-07-01 12:00"))
+	if !got.Equal(utc("2025-07-01 13:00")) {
+		t.Errorf("Next = %s, want 13:00 UTC", got.UTC())
+	}
+
+	// Across the spring-forward gap, 02:30 doesn't exist; the schedule must
+	// still make progress rather than loop.
+	s, _ = ParseCron("30 2 * * *", ny)
+	next := s.Next(time.Date(2025, 3, 9, 0, 0, 0, 0, ny))
+	if next.IsZero() || !next.After(time.Date(2025, 3, 9, 0, 0, 0, 0, ny)) {
+		t.Errorf("Next across DST gap = %s", next)
+	}
+
+	// Half-hour offset zones must not lose the top of the hour.
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	s, _ = ParseCron("0 11 * * *", kolkata)
+	want := time.Date(2025, 3, 10, 11, 0, 0, 0, kolkata)
+	if got := s.Next(time.Date(2025, 3, 10, 10, 45, 0, 0, kolkata)); !got.Equal(want) {
+		t.Errorf("Next in Asia/Kolkata = %s, want %s", got, want)
+	}
+}
+
+func TestCronSchedule_Evaluate(t *testing.T) {
+	s := mustParseCron(t, "0 9 * * *")
+	now := utc("2025-03-10 09:05")
+
+	// Ran after today's window: not due.
+	st := s.Evaluate(utc("2025-03-10 09:01"), now, CatchUpOnce, time.Hour)
+	if st.Due || st.Missed != 0 || !st.NextWindow.Equal(utc("2025-03-11 09:00")) {
+		t.Errorf("after run: %+v", st)
+	}
+
+	// Ran yesterday after the window: today's window is due.
+	st = s.Evaluate(utc("2025-03-09 09:01"), now, CatchUpOnce, time.Hour)
+	if !st.Due || st.Missed != 1 || !st.LastWindow.Equal(utc("2025-03-10 09:00")) {
+		t.Errorf("one window: %+v", st)
+	}
+
+	// Deacon paused for three days: due once, three windows missed.
+	st = s.Evaluate(utc("2025-03-07 09:01"), now, CatchUpOnce, time.Hour)
+	if !st.Due || st.Missed != 3 {
+		t.Errorf("catch up once: %+v", st)
+	}
+
+	// Never run: due.
+	if st = s.Evaluate(time.Time{}, now, CatchUpOnce, time.Hour); !st.Due {
+		t.Errorf("never run: %+v", st)
+	}
+
+	// Skip: a window within grace still runs, a stale one does not.
+	if st = s.Evaluate(utc("2025-03-07 09:01"), now, CatchUpSkip, time.Hour); !st.Due {
+		t.Errorf("skip within grace: %+v", st)
+	}
+	late := utc("2025-03-10 12:00")
+	if st = s.Evaluate(utc("2025-03-07 09:01"), late, CatchUpSkip, time.Hour); st.Due || st.Missed != 3 {
+		t.Errorf("skip past grace: %+v", st)
+	}
+}
+
+func TestPluginCheckDue(t *testing.T) {
+	now := utc("2025-03-10 09:05")
+
+	cooldown := &Plugin{Name: "c", Gate: &Gate{Type: GateCooldown, Duration: "2h"}}
+	if st, err := cooldown.CheckDue(time.Time{}, now); err != nil || !st.Due {
+		t.Errorf("cooldown never run: %+v, %v", st, err)
+	}
+	st, err := cooldown.CheckDue(now.Add(-time.Hour), now)
+	if err != nil || st.Due || !st.NextRun.Equal(now.Add(time.Hour)) {
+		t.Errorf("cooldown active: %+v, %v", st, err)
+	}
+	if st, err := cooldown.CheckDue(now.Add(-3*time.Hour), now); err != nil || !st.Due {
+		t.Errorf("cooldown elapsed: %+v, %v", st, err)
+	}
+
+	cron := &Plugin{Name: "k", Gate: &Gate{Type: GateCron, Schedule: "0 9 * * *", Timezone: "UTC"}}
+	if st, err := cron.CheckDue(utc("2025-03-07 10:00"), now); err != nil || !st.Due || st.Missed != 3 {
+		t.Errorf("cron catch-up: %+v, %v", st, err)
+	}
+	cron.Gate.CatchUp = "sometimes"
+	if _, err := cron.CheckDue(time.Time{}, now); err == nil {
+		t.Error("invalid catch_up accepted")
+	}
+
+	manual := &Plugin{Name: "m"}
+	if st, err := manual.CheckDue(time.Time{}, now); err != nil || st.Due {
+		t.Errorf("manual: %+v, %v", st, err)
+	}
+}
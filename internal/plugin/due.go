@@ -0,0 +1,125 @@
+package plugin
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultCooldown is the cooldown used when a cooldown gate has no duration.
+const DefaultCooldown = time.Hour
+
+// DefaultCronGrace is the grace period for cron gates with catch_up = "skip".
+const DefaultCronGrace = time.Hour
+
+// DueStatus reports whether a time-gated plugin should run.
+type DueStatus struct {
+	// Due is true if the plugin's gate is open.
+	Due bool `json:"due"`
+
+	// Reason explains the verdict in a few words.
+	Reason string `json:"reason"`
+
+	// LastRun is the plugin's most recent recorded run (zero if never).
+	LastRun time.Time `json:"last_run,omitempty"`
+
+	// NextRun is when the gate next opens (zero if it is open now or the
+	// gate is not time-based).
+	NextRun time.Time `json:"next_run,omitempty"`
+
+	// Missed counts cron windows since the last run; more than one means
+	// windows were missed while the Deacon was not patrolling.
+	Missed int `json:"missed,omitempty"`
+}
+
+// CronSchedule parses a cron gate's schedule in its configured timezone.
+func (g *Gate) CronSchedule() (*CronSchedule, error) {
+	if g.Schedule == "" {
+		return nil, fmt.Errorf("cron gate has no schedule")
+	}
+	loc := time.Local
+	if g.Timezone != "" {
+		tz, err := time.LoadLocation(g.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("unknown timezone %q: %w", g.Timezone, err)
+		}
+		loc = tz
+	}
+	return ParseCron(g.Schedule, loc)
+}
+
+// CheckDue evaluates a plugin's gate at now, given its last run (zero if it
+// has never run). Only cooldown and cron gates are time-based; condition,
+// event and manual gates are never reported as due.
+func (p *Plugin) CheckDue(lastRun, now time.Time) (DueStatus, error) {
+	status := DueStatus{LastRun: lastRun}
+
+	gateType := GateManual
+	if p.Gate != nil && p.Gate.Type != "" {
+		gateType = p.Gate.Type
+	}
+
+	switch gateType {
+	case GateCooldown:
+		cooldown := DefaultCooldown
+		if p.Gate.Duration != "" {
+			d, err := time.ParseDuration(p.Gate.Duration)
+			if err != nil {
+				return status, fmt.Errorf("plugin %s: invalid cooldown %q: %w", p.Name, p.Gate.Duration, err)
+			}
+			cooldown = d
+		}
+		if lastRun.IsZero() {
+			status.Due, status.Reason = true, "never run"
+			return status, nil
+		}
+		ready := lastRun.Add(cooldown)
+		if !now.Before(ready) {
+			status.Due, status.Reason = true, fmt.Sprintf("cooldown %s elapsed", cooldown)
+			return status, nil
+		}
+		status.NextRun = ready
+		status.Reason = fmt.Sprintf("cooling down (%s)", cooldown)
+		return status, nil
+
+	case GateCron:
+		sched, err := p.Gate.CronSchedule()
+		if err != nil {
+			return status, fmt.Errorf("plugin %s: %w", p.Name, err)
+		}
+		policy := p.Gate.CatchUp
+		if policy == "" {
+			policy = CatchUpOnce
+		}
+		if policy != CatchUpOnce && policy != CatchUpSkip {
+			return status, fmt.Errorf("plugin %s: invalid catch_up %q (want once or skip)", p.Name, policy)
+		}
+		grace := DefaultCronGrace
+		if p.Gate.Grace != "" {
+			if grace, err = time.ParseDuration(p.Gate.Grace); err != nil {
+				return status, fmt.Errorf("plugin %s: invalid grace %q: %w", p.Name, p.Gate.Grace, err)
+			}
+		}
+
+		cron := sched.Evaluate(lastRun, now, policy, grace)
+		status.Due = cron.Due
+		status.Missed = cron.Missed
+		if !cron.Due {
+			status.NextRun = cron.NextWindow
+		}
+		switch {
+		case cron.Due && cron.Missed > 1:
+			status.Reason = fmt.Sprintf("catching up %d missed windows", cron.Missed)
+		case cron.Due:
+			status.Reason = fmt.Sprintf("window %s", cron.LastWindow.Format("2006-01-02 15:04 MST"))
+		case cron.Missed > 0:
+			status.Reason = fmt.Sprintf("skipped %d missed window(s)", cron.Missed)
+		default:
+			status.Reason = "waiting for next window"
+		}
+		return status, nil
+
+	default:
+		status.Reason = fmt.Sprintf("%s gate is not time-based", gateType)
+		return status, nil
+	}
+}
@@ -105,7 +105,14 @@ func (r *Recorder) RecordRun(record PluginRunRecord) (string, error) {
 // GetLastRun returns the most recent run for a plugin.
 // Returns nil if no runs found.
 func (r *Recorder) GetLastRun(pluginName string) (*PluginRunBead, error) {
-	runs, err := r.queryRuns(pluginName, 1, "")
+	return r.GetLastRunForRig(pluginName, "")
+}
+
+// GetLastRunForRig returns the most recent run of a plugin in a rig.
+// An empty rigName matches runs in any scope.
+// Returns nil if no runs found.
+func (r *Recorder) GetLastRunForRig(pluginName, rigName string) (*PluginRunBead, error) {
+	runs, err := r.queryRuns(pluginName, 1, "", rigName)
 	if err != nil {
 		return nil, err
 	}
@@ -115,14 +122,49 @@ func (r *Recorder) GetLastRun(pluginName string) (*PluginRunBead, error) {
 	return runs[0], nil
 }
 
+// GetLastTownRun returns the most recent run of a town-level plugin.
+// Runs recorded by a rig plugin of the same name carry a rig label and are
+// skipped. Returns nil if no runs found.
+func (r *Recorder) GetLastTownRun(pluginName string) (*PluginRunBead, error) {
+	runs, err := r.queryRuns(pluginName, 0, "", "")
+	if err != nil {
+		return nil, err
+	}
+	return lastTownRun(runs), nil
+}
+
+// lastTownRun returns the newest run without a rig label.
+func lastTownRun(runs []*PluginRunBead) *PluginRunBead {
+	var last *PluginRunBead
+	for _, run := range runs {
+		if run.RigName() != "" {
+			continue
+		}
+		if last == nil || run.CreatedAt.After(last.CreatedAt) {
+			last = run
+		}
+	}
+	return last
+}
+
+// RigName returns the rig a run was recorded for, or "" for a town run.
+func (b *PluginRunBead) RigName() string {
+	for _, label := range b.Labels {
+		if rig, ok := strings.CutPrefix(label, "rig:"); ok {
+			return rig
+		}
+	}
+	return ""
+}
+
 // GetRunsSince returns all runs for a plugin since the given duration.
 // Duration format: "1h", "24h", "7d", etc.
 func (r *Recorder) GetRunsSince(pluginName string, since string) ([]*PluginRunBead, error) {
-	return r.queryRuns(pluginName, 0, since)
+	return r.queryRuns(pluginName, 0, since, "")
 }
 
 // queryRuns queries plugin run beads from the ledger.
-func (r *Recorder) queryRuns(pluginName string, limit int, since string) ([]*PluginRunBead, error) {
+func (r *Recorder) queryRuns(pluginName string, limit int, since string, rigName string) ([]*PluginRunBead, error) {
 	args := []string{
 		"list",
 		"--json",
@@ -130,6 +172,9 @@ func (r *Recorder) queryRuns(pluginName string, limit int, since string) ([]*Plu
 		"-l", "type:plugin-run",
 		"-l", fmt.Sprintf("plugin:%s", pluginName),
 	}
+	if rigName != "" {
+		args = append(args, "-l", fmt.Sprintf("rig:%s", rigName))
+	}
 	if limit > 0 {
 		args = append(args, fmt.Sprintf("--limit=%d", limit))
 	}
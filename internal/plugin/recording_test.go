@@ -2,6 +2,7 @@ package plugin
 
 import (
 	"testing"
+	"time"
 )
 
 func TestPluginRunRecord(t *testing.T) {
@@ -45,6 +46,24 @@ func TestNewRecorder(t *testing.T) {
 	}
 }
 
+func TestLastTownRun(t *testing.T) {
+	now := time.Now()
+	runs := []*PluginRunBead{
+		{ID: "rig-new", CreatedAt: now, Labels: []string{"type:plugin-run", "plugin:sync", "rig:gastown"}},
+		{ID: "town-old", CreatedAt: now.Add(-2 * time.Hour), Labels: []string{"type:plugin-run", "plugin:sync"}},
+		{ID: "town-new", CreatedAt: now.Add(-time.Hour), Labels: []string{"type:plugin-run", "plugin:sync"}},
+	}
+	if got := lastTownRun(runs); got == nil || got.ID != "town-new" {
+		t.Errorf("lastTownRun = %+v, want town-new", got)
+	}
+	if got := lastTownRun(runs[:1]); got != nil {
+		t.Errorf("lastTownRun with only rig runs = %+v, want nil", got)
+	}
+	if rig := runs[0].RigName(); rig != "gastown" {
+		t.Errorf("RigName = %q, want gastown", rig)
+	}
+}
+
 // Integration tests for RecordRun, GetLastRun, GetRunsSince require
 // a working beads installation and are skipped in unit tests.
 // These functions shell out to `bd` commands.
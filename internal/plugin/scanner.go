@@ -60,6 +60,27 @@ func (s *Scanner) DiscoverAll() ([]*Plugin, error) {
 	return plugins, nil
 }
 
+// DiscoverScoped returns every plugin in every scope without deduplication:
+// the town-level plugins followed by each rig's plugins. A plugin that
+// exists at both levels appears once per scope, since each scope runs
+// (and records runs) independently.
+func (s *Scanner) DiscoverScoped() ([]*Plugin, error) {
+	plugins, err := s.scanTownPlugins()
+	if err != nil {
+		return nil, fmt.Errorf("scanning town plugins: %w", err)
+	}
+	for _, rigName := range s.rigNames {
+		rigPlugins, err := s.scanRigPlugins(rigName)
+		if err != nil {
+			// Log warning but continue with other rigs
+			fmt.Fprintf(os.Stderr, "Warning: scanning plugins for rig %q: %v\n", rigName, err)
+			continue
+		}
+		plugins = append(plugins, rigPlugins...)
+	}
+	return plugins, nil
+}
+
 // scanTownPlugins scans the town-level plugins directory.
 func (s *Scanner) scanTownPlugins() ([]*Plugin, error) {
 	pluginsDir := filepath.Join(s.townRoot, "plugins")
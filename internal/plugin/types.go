@@ -67,6 +67,18 @@ type Gate struct {
 	// Schedule is for cron gates (e.g., "0 9 * * *").
 	Schedule string `json:"schedule,omitempty" toml:"schedule,omitempty"`
 
+	// Timezone is the IANA zone a cron schedule is evaluated in
+	// (e.g., "America/Los_Angeles"). Defaults to the local timezone.
+	Timezone string `json:"timezone,omitempty" toml:"timezone,omitempty"`
+
+	// CatchUp is the cron policy for windows missed while the Deacon was
+	// paused: "once" (default) or "skip".
+	CatchUp CatchUp `json:"catch_up,omitempty" toml:"catch_up,omitempty"`
+
+	// Grace is how late a cron window may still run with catch_up = "skip"
+	// (e.g., "15m"). Defaults to 1h.
+	Grace string `json:"grace,omitempty" toml:"grace,omitempty"`
+
 	// Check is for condition gates (command that returns exit 0 to run).
 	Check string `json:"check,omitempty" toml:"check,omitempty"`
 
@@ -244,6 +244,14 @@ func (m *Manager) AddWithOptions(name string, opts AddOptions) (*Polecat, error)
 		return nil, ErrPolecatExists
 	}
 
+	// Run pre-spawn lifecycle hooks in the rig; a failing "fail" hook stops the
+	// spawn and hands the allocated name back to the pool.
+	hookEnv := rig.HookEnv{Rig: m.rig.Name, Polecat: name, Bead: opts.HookBead}
+	if err := rig.RunLifecycleHooks(m.rig.Path, config.HookPreSpawn, hookEnv, os.Stdout); err != nil {
+		m.ReleaseName(name)
+		return nil, err
+	}
+
 	// New structure: polecats/<name>/<rigname>/ for LLM ergonomics
 	// The polecat's home dir is polecats/<name>/, worktree is polecats/<name>/<rigname>/
 	polecatDir := m.polecatDir(name)
@@ -329,7 +337,7 @@ func (m *Manager) AddWithOptions(name string, opts AddOptions) (*Polecat, error)
 
 	// Copy overlay files from .runtime/overlay/ to polecat root.
 	// This allows services to have .env and other config files at their root.
-	if err := rig.CopyOverlay(m.rig.Path, clonePath); err != nil {
+	if err := rig.CopyWorkerOverlay(m.rig.Path, "polecat", name, clonePath); err != nil {
 		// Non-fatal - log warning but continue
 		fmt.Printf("Warning: could not copy overlay files: %v\n", err)
 	}
@@ -341,6 +349,23 @@ func (m *Manager) AddWithOptions(name string, opts AddOptions) (*Polecat, error)
 		fmt.Printf("Warning: could not run setup hooks: %v\n", err)
 	}
 
+	// Run post-spawn lifecycle hooks in the new worktree (e.g., warm caches).
+	// A failing "fail" hook discards the worktree, its branch and the name so
+	// the spawn can be retried.
+	hookEnv.Branch = branchName
+	hookEnv.Worktree = clonePath
+	if err := rig.RunLifecycleHooks(m.rig.Path, config.HookPostSpawn, hookEnv, os.Stdout); err != nil {
+		if removeErr := repoGit.WorktreeRemove(clonePath, true); removeErr != nil {
+			_ = os.RemoveAll(clonePath)
+		}
+		_ = os.RemoveAll(polecatDir)
+		_ = repoGit.WorktreePrune()
+		_ = repoGit.DeleteBranch(branchName, true)
+		m.ReleaseName(name)
+		_ = rig.ReleaseOverlaySlot(m.rig.Path, "polecat", name)
+		return nil, err
+	}
+
 	// NOTE: Slash commands (.claude/commands/) are provisioned at town level by gt install.
 	// All agents inherit them via Claude's directory traversal - no per-workspace copies needed.
 
@@ -429,6 +454,22 @@ func (m *Manager) RemoveWithOptions(name string, force, nuclear bool) error {
 		}
 	}
 
+	// Run pre-nuke lifecycle hooks in the worktree. A failing "fail" hook
+	// blocks removal, unless nuclear bypasses safety checks.
+	if hooks, _ := rig.LifecycleHooks(m.rig.Path, config.HookPreNuke); len(hooks) > 0 {
+		hookEnv := rig.HookEnv{Rig: m.rig.Name, Polecat: name, Worktree: clonePath}
+		if p, err := m.Get(name); err == nil {
+			hookEnv.Bead = p.Issue
+			hookEnv.Branch = p.Branch
+		}
+		if err := rig.RunLifecycleHooks(m.rig.Path, config.HookPreNuke, hookEnv, os.Stdout); err != nil {
+			if !nuclear {
+				return err
+			}
+			fmt.Printf("Warning: %v (continuing: nuclear)\n", err)
+		}
+	}
+
 	// Get repo base to remove the worktree properly
 	repoGit, err := m.repoBase()
 	if err != nil {
@@ -477,6 +518,9 @@ func (m *Manager) RemoveWithOptions(name string, force, nuclear bool) error {
 	m.namePool.Release(name)
 	_ = m.namePool.Save()
 
+	// Free the polecat's overlay port block (non-fatal: state file update)
+	_ = rig.ReleaseOverlaySlot(m.rig.Path, "polecat", name)
+
 	// Close agent bead (non-fatal: may not exist or beads may not be available)
 	// NOTE: We use CloseAndClearAgentBead instead of DeleteAgentBead because bd delete --hard
 	// creates tombstones that cannot be reopened.
@@ -510,6 +554,35 @@ func (m *Manager) AllocateName() (string, error) {
 	return name, nil
 }
 
+// ClaimName allocates a specific name instead of the next one from the pool.
+// Used by skill routing to bring back an identity whose worktree was nuked.
+func (m *Manager) ClaimName(name string) error {
+	m.ReconcilePool()
+
+	if m.exists(name) {
+		return fmt.Errorf("%w: %s", ErrPolecatExists, name)
+	}
+	if err := m.namePool.Claim(name); err != nil {
+		return err
+	}
+
+	if err := m.namePool.Save(); err != nil {
+		return fmt.Errorf("saving pool state: %w", err)
+	}
+	return nil
+}
+
+// TouchHistory returns the files touched by each of the newest limit
+// commits in the rig's repo, grouped by git author. Polecats commit as
+// their own name, so this is each identity's record of past touches.
+func (m *Manager) TouchHistory(limit int) (map[string][][]string, error) {
+	repoGit, err := m.repoBase()
+	if err != nil {
+		return nil, err
+	}
+	return repoGit.CommitFilesByAuthor(limit)
+}
+
 // ReleaseName releases a name back to the pool.
 // This is called when a polecat is removed.
 func (m *Manager) ReleaseName(name string) {
@@ -635,7 +708,7 @@ func (m *Manager) RepairWorktreeWithOptions(name string, force bool, opts AddOpt
 	}
 
 	// Copy overlay files from .runtime/overlay/ to polecat root.
-	if err := rig.CopyOverlay(m.rig.Path, newClonePath); err != nil {
+	if err := rig.CopyWorkerOverlay(m.rig.Path, "polecat", name, newClonePath); err != nil {
 		fmt.Printf("Warning: could not copy overlay files: %v\n", err)
 	}
 
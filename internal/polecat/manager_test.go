@@ -7,6 +7,7 @@ import (
 	"sort"
 	"testing"
 
+	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/rig"
 )
@@ -451,6 +452,66 @@ func TestAddWithOptions_AgentsMDFallback(t *testing.T) {
 //   true   | false      | in-use (normal finished polecat)
 //   false  | true       | orphan → kill session, available
 //   true   | true       | in-use (normal working polecat)
+func TestAddWithOptions_FailedSpawnHookCleansUp(t *testing.T) {
+	// A failing spawn hook must not leave the new branch behind or keep the
+	// pooled name allocated.
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	root := t.TempDir()
+	mayorRig := filepath.Join(root, "mayor", "rig")
+	if err := os.MkdirAll(mayorRig, 0755); err != nil {
+		t.Fatalf("mkdir mayor/rig: %v", err)
+	}
+	for _, args := range [][]string{
+		{"init"},
+		{"commit", "--allow-empty", "-m", "init"},
+		{"remote", "add", "origin", mayorRig},
+		{"update-ref", "refs/remotes/origin/main", "HEAD"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = mayorRig
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	for _, phase := range []string{config.HookPreSpawn, config.HookPostSpawn} {
+		settings := config.NewRigSettings()
+		settings.LifecycleHooks = map[string][]config.LifecycleHook{
+			phase: {{Name: "broken", Command: "exit 1", OnFailure: config.HookOnFailureFail}},
+		}
+		if err := config.SaveRigSettings(config.RigSettingsPath(root), settings); err != nil {
+			t.Fatalf("SaveRigSettings: %v", err)
+		}
+
+		m := NewManager(&rig.Rig{Name: "rig", Path: root}, git.NewGit(root), nil)
+		name, err := m.AllocateName()
+		if err != nil {
+			t.Fatalf("AllocateName: %v", err)
+		}
+		if _, err := m.AddWithOptions(name, AddOptions{}); err == nil {
+			t.Fatalf("%s: AddWithOptions succeeded despite failing hook", phase)
+		}
+
+		if m.namePool.InUse[name] {
+			t.Errorf("%s: name %s still allocated", phase, name)
+		}
+		branches, err := git.NewGit(mayorRig).ListBranches("polecat/*")
+		if err != nil {
+			t.Fatalf("ListBranches: %v", err)
+		}
+		if len(branches) != 0 {
+			t.Errorf("%s: branches left behind: %v", phase, branches)
+		}
+		if _, err := os.Stat(m.polecatDir(name)); !os.IsNotExist(err) {
+			t.Errorf("%s: polecat dir left behind", phase)
+		}
+	}
+}
+
 func TestReconcilePoolWith(t *testing.T) {
 	t.Parallel()
 
@@ -238,6 +238,22 @@ func (p *NamePool) Allocate() (string, error) {
 	return name, nil
 }
 
+// Claim allocates a specific name, such as a returning identity picked by
+// skill routing. Fails if the name is a pool name already in use.
+func (p *NamePool) Claim(name string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.isThemedName(name) {
+		return nil
+	}
+	if p.InUse[name] {
+		return fmt.Errorf("name %s is already in use", name)
+	}
+	p.InUse[name] = true
+	return nil
+}
+
 // Release returns a name slot to the available pool.
 // Called when a polecat is nuked - the name becomes available for new polecats.
 // NOTE: This releases the NAME, not the polecat. The polecat is gone (nuked).
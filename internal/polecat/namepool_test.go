@@ -305,6 +305,30 @@ func TestNamePool_MarkInUse(t *testing.T) {
 	}
 }
 
+func TestNamePool_Claim(t *testing.T) {
+	pool := NewNamePoolWithConfig(t.TempDir(), "testrig", "mad-max", nil, DefaultPoolSize)
+
+	// Claiming a returning identity takes it out of the pool.
+	if err := pool.Claim("nux"); err != nil {
+		t.Fatalf("Claim: %v", err)
+	}
+	if err := pool.Claim("nux"); err == nil {
+		t.Error("expected error claiming a name in use")
+	}
+	name, _ := pool.Allocate()
+	if name != "furiosa" {
+		t.Errorf("expected furiosa, got %s", name)
+	}
+	if pool.ActiveCount() != 2 {
+		t.Errorf("expected 2 active, got %d", pool.ActiveCount())
+	}
+
+	// Overflow names aren't tracked.
+	if err := pool.Claim("testrig-7"); err != nil {
+		t.Errorf("Claim overflow name: %v", err)
+	}
+}
+
 func TestNamePool_StateFilePath(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "namepool-test-*")
 	if err != nil {
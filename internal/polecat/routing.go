@@ -0,0 +1,258 @@
+package polecat
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+// Skill routing weights. Past touches of the bead's files count most: a
+// polecat that has fixed a subsystem three times should get the fourth bug.
+const (
+	routeWeightLabels  = 2.0
+	routeWeightFiles   = 1.0
+	routeWeightTouches = 3.0
+	routeWeightSuccess = 1.0
+
+	// routeSaturation is the number of matching issues or commits that
+	// counts as full familiarity.
+	routeSaturation = 3
+)
+
+// IdentityRecord is a polecat identity's work history, the input to skill
+// routing. It is the data behind the identity's CV.
+type IdentityRecord struct {
+	Name      string
+	Completed int
+	Failed    int            // escalated or deferred
+	Labels    map[string]int // labels on completed issues
+	Mentioned map[string]int // paths mentioned in completed issues
+	Commits   [][]string     // files touched by each of its commits
+}
+
+// NewIdentityRecord builds an identity's record from the issues assigned to
+// it and the files its commits touched.
+func NewIdentityRecord(name string, issues []*beads.Issue, commits [][]string) *IdentityRecord {
+	rec := &IdentityRecord{
+		Name:      name,
+		Labels:    make(map[string]int),
+		Mentioned: make(map[string]int),
+		Commits:   commits,
+	}
+	for _, issue := range issues {
+		switch issue.Status {
+		case "closed":
+			rec.Completed++
+			for _, label := range routeLabels(issue.Labels) {
+				rec.Labels[label]++
+			}
+			for _, p := range MentionedPaths(issue.Title + "\n" + issue.Description) {
+				rec.Mentioned[p]++
+			}
+		case "escalated", "deferred":
+			rec.Failed++
+		}
+	}
+	return rec
+}
+
+// RouteScore is how well an identity fits a bead. Each factor is in [0, 1];
+// Score is their weighted sum, or zero when the identity has no relevant
+// history (success rate alone doesn't route work).
+type RouteScore struct {
+	Name    string   `json:"name"`
+	Labels  float64  `json:"labels"`
+	Files   float64  `json:"files"`
+	Touches float64  `json:"touches"`
+	Success float64  `json:"success"`
+	Score   float64  `json:"score"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// Relevant reports whether the identity has history related to the bead.
+func (s RouteScore) Relevant() bool {
+	return s.Labels+s.Files+s.Touches > 0
+}
+
+// ScoreIdentities scores each identity against the bead, best fit first.
+//
+//	labels   bead labels seen on its completed issues
+//	files    bead paths mentioned in its completed issues
+//	touches  commits touching the bead's paths, or at half weight the
+//	         same directory; the best-known path counts
+//	success  completed share of its finished issues, smoothed so an
+//	         identity with little history sits near 0.5
+func ScoreIdentities(bead *beads.Issue, records []*IdentityRecord) []RouteScore {
+	labels := routeLabels(bead.Labels)
+	paths := MentionedPaths(bead.Title + "\n" + bead.Description)
+
+	scores := make([]RouteScore, 0, len(records))
+	for _, rec := range records {
+		s := RouteScore{Name: rec.Name}
+
+		for _, label := range labels {
+			if n := rec.Labels[label]; n > 0 {
+				s.Labels += saturate(n) / float64(len(labels))
+				s.Reasons = append(s.Reasons, fmt.Sprintf("label %s on %d completed issue(s)", label, n))
+			}
+		}
+
+		for _, p := range paths {
+			if n := mentionCount(rec.Mentioned, p); n > 0 {
+				s.Files += 1 / float64(len(paths))
+				s.Reasons = append(s.Reasons, fmt.Sprintf("%s mentioned in %d completed issue(s)", p, n))
+			}
+
+			file, dir := touchCounts(rec.Commits, p)
+			touch := saturate(file)
+			reason := fmt.Sprintf("%d commit(s) touched %s", file, p)
+			if d := saturate(dir) / 2; d > touch {
+				touch = d
+				reason = fmt.Sprintf("%d commit(s) in %s/", dir, path.Dir(p))
+			}
+			if touch > s.Touches {
+				s.Touches = touch
+			}
+			if touch > 0 {
+				s.Reasons = append(s.Reasons, reason)
+			}
+		}
+
+		s.Success = float64(rec.Completed+1) / float64(rec.Completed+rec.Failed+2)
+		if s.Relevant() {
+			s.Score = routeWeightLabels*s.Labels + routeWeightFiles*s.Files +
+				routeWeightTouches*s.Touches + routeWeightSuccess*s.Success
+		}
+		scores = append(scores, s)
+	}
+
+	sort.SliceStable(scores, func(i, j int) bool {
+		if scores[i].Score != scores[j].Score {
+			return scores[i].Score > scores[j].Score
+		}
+		return scores[i].Name < scores[j].Name
+	})
+	return scores
+}
+
+// BestIdentity returns the best-fit identity, or false when no identity has
+// relevant history and the name pool should pick instead.
+func BestIdentity(scores []RouteScore) (RouteScore, bool) {
+	if len(scores) == 0 || !scores[0].Relevant() {
+		return RouteScore{}, false
+	}
+	return scores[0], true
+}
+
+// routeLabels drops Gas Town's structural gt: labels, which say what kind
+// of bead something is rather than what it is about.
+func routeLabels(labels []string) []string {
+	var out []string
+	for _, label := range labels {
+		if !strings.HasPrefix(label, "gt:") {
+			out = append(out, label)
+		}
+	}
+	return out
+}
+
+func saturate(n int) float64 {
+	if n >= routeSaturation {
+		return 1
+	}
+	return float64(n) / routeSaturation
+}
+
+// pathTokenRe matches runs of characters that can make up a file path.
+var pathTokenRe = regexp.MustCompile(`[\w.\-/]+`)
+
+// fileExtRe matches a lowercase file extension like ".go" or ".tsx".
+var fileExtRe = regexp.MustCompile(`^\.[a-z][a-z0-9]{0,5}$`)
+
+// MentionedPaths extracts the file and directory paths mentioned in text,
+// in order of first mention. A token is a path if it contains a slash or
+// ends in a file extension; URLs and line-number suffixes are dropped.
+func MentionedPaths(text string) []string {
+	var paths []string
+	seen := make(map[string]bool)
+	for _, field := range strings.Fields(text) {
+		if strings.Contains(field, "://") {
+			continue
+		}
+		for _, tok := range pathTokenRe.FindAllString(field, -1) {
+			tok = strings.TrimPrefix(strings.Trim(tok, ".-"), "/")
+			tok = strings.TrimSuffix(tok, "/")
+			if tok == "" || seen[tok] || !looksLikePath(tok) {
+				continue
+			}
+			seen[tok] = true
+			paths = append(paths, tok)
+		}
+	}
+	return paths
+}
+
+func looksLikePath(tok string) bool {
+	if strings.Contains(tok, "/") {
+		return !strings.Contains(tok, "//")
+	}
+	ext := path.Ext(tok)
+	stem := strings.TrimSuffix(tok, ext)
+	return len(stem) >= 2 && fileExtRe.MatchString(ext)
+}
+
+// samePath reports whether touched (repo-relative) is the mentioned path,
+// which may be a shorter suffix of it or a directory containing it.
+func samePath(touched, mentioned string) bool {
+	return touched == mentioned ||
+		strings.HasSuffix(touched, "/"+mentioned) ||
+		strings.HasPrefix(touched, mentioned+"/") ||
+		strings.Contains(touched, "/"+mentioned+"/")
+}
+
+// sameDir reports whether touched lives in the mentioned path's directory.
+func sameDir(touched, mentioned string) bool {
+	dir := path.Dir(mentioned)
+	if dir == "." {
+		return false
+	}
+	touchedDir := path.Dir(touched)
+	return touchedDir == dir || strings.HasSuffix(touchedDir, "/"+dir)
+}
+
+func mentionCount(mentioned map[string]int, p string) int {
+	n := 0
+	for m, count := range mentioned {
+		if samePath(m, p) || samePath(p, m) {
+			n += count
+		}
+	}
+	return n
+}
+
+// touchCounts returns how many commits touched the path, and how many
+// touched its directory.
+func touchCounts(commits [][]string, p string) (file, dir int) {
+	for _, files := range commits {
+		hitFile, hitDir := false, false
+		for _, f := range files {
+			if samePath(f, p) {
+				hitFile = true
+			}
+			if sameDir(f, p) {
+				hitDir = true
+			}
+		}
+		if hitFile {
+			file++
+		}
+		if hitDir {
+			dir++
+		}
+	}
+	return file, dir
+}
@@ -0,0 +1,72 @@
+package polecat
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+func TestMentionedPaths(t *testing.T) {
+	text := "Login fails in `internal/auth/login.go:42` (see session.go).\n" +
+		"Docs at https://example.com/auth/help.html, e.g. v1.2 and internal/auth/."
+	want := []string{"internal/auth/login.go", "session.go", "internal/auth"}
+	if got := MentionedPaths(text); !reflect.DeepEqual(got, want) {
+		t.Errorf("MentionedPaths = %v, want %v", got, want)
+	}
+}
+
+func TestScoreIdentities(t *testing.T) {
+	authFix := func(status string) *beads.Issue {
+		return &beads.Issue{Status: status, Title: "Fix token refresh in auth/login.go", Labels: []string{"auth", "gt:task"}}
+	}
+	records := []*IdentityRecord{
+		// Fixed the auth subsystem three times.
+		NewIdentityRecord("nux", []*beads.Issue{authFix("closed"), authFix("closed"), authFix("closed")},
+			[][]string{{"internal/auth/login.go"}, {"internal/auth/login.go", "README.md"}, {"internal/auth/token.go"}}),
+		// Worked next door in the same directory once.
+		NewIdentityRecord("slit", []*beads.Issue{{Status: "closed", Title: "Add logout"}},
+			[][]string{{"internal/auth/logout.go"}}),
+		// Perfect record, nothing to do with auth.
+		NewIdentityRecord("rictus", []*beads.Issue{{Status: "closed"}, {Status: "closed"}, {Status: "closed"}},
+			[][]string{{"web/index.html"}}),
+	}
+
+	bead := &beads.Issue{ID: "gt-4th", Title: "Login loops after token refresh",
+		Description: "Regression in internal/auth/login.go.", Labels: []string{"auth", "gt:task"}}
+	scores := ScoreIdentities(bead, records)
+
+	var order []string
+	for _, s := range scores {
+		order = append(order, s.Name)
+	}
+	if strings.Join(order, ",") != "nux,slit,rictus" {
+		t.Fatalf("order = %v", order)
+	}
+
+	nux := scores[0]
+	if nux.Labels != 1 || nux.Files != 1 || nux.Touches < 0.66 || nux.Success != 0.8 {
+		t.Errorf("nux = %+v", nux)
+	}
+	if !strings.Contains(strings.Join(nux.Reasons, "; "), "2 commit(s) touched internal/auth/login.go") {
+		t.Errorf("nux reasons = %v", nux.Reasons)
+	}
+	if slit := scores[1]; slit.Touches == 0 || slit.Labels != 0 || slit.Score >= nux.Score {
+		t.Errorf("slit = %+v", slit)
+	}
+	if rictus := scores[2]; rictus.Relevant() || rictus.Score != 0 || rictus.Success != 0.8 {
+		t.Errorf("rictus = %+v", rictus)
+	}
+
+	best, ok := BestIdentity(scores)
+	if !ok || best.Name != "nux" {
+		t.Errorf("BestIdentity = %+v, %v", best, ok)
+	}
+
+	// Nobody has relevant history: fall back to the pool.
+	unrelated := &beads.Issue{Title: "Update the changelog"}
+	if _, ok := BestIdentity(ScoreIdentities(unrelated, records)); ok {
+		t.Error("expected no identity for an unrelated bead")
+	}
+}
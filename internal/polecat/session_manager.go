@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/steveyegge/gastown/internal/cgroup"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
 	"github.com/steveyegge/gastown/internal/rig"
@@ -35,18 +36,30 @@ var (
 
 // SessionManager handles polecat session lifecycle.
 type SessionManager struct {
-	tmux *tmux.Tmux
-	rig  *rig.Rig
+	backend session.SessionBackend
+	rig     *rig.Rig
 }
 
 // NewSessionManager creates a new polecat session manager for a rig.
-func NewSessionManager(t *tmux.Tmux, r *rig.Rig) *SessionManager {
+// The backend is usually a *tmux.Tmux; see session.NewBackend.
+func NewSessionManager(b session.SessionBackend, r *rig.Rig) *SessionManager {
 	return &SessionManager{
-		tmux: t,
-		rig:  r,
+		backend: b,
+		rig:     r,
 	}
 }
 
+// attacher is implemented by backends that can attach the user's terminal.
+type attacher interface {
+	AttachSession(session string) error
+}
+
+// debouncedSender is implemented by backends with a configurable
+// paste-to-Enter delay.
+type debouncedSender interface {
+	SendKeysDebounced(session, keys string, debounceMs int) error
+}
+
 // SessionStartOptions configures polecat session startup.
 type SessionStartOptions struct {
 	// WorkDir overrides the default working directory (polecat clone dir).
@@ -149,7 +162,7 @@ func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
 	// Check if session already exists
 	// Note: Orphan sessions are cleaned up by ReconcilePool during AllocateName,
 	// so by this point, any existing session should be legitimately in use.
-	running, err := m.tmux.HasSession(sessionID)
+	running, err := m.backend.HasSession(sessionID)
 	if err != nil {
 		return fmt.Errorf("checking session: %w", err)
 	}
@@ -189,10 +202,15 @@ func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
 	if runtimeConfig.Session != nil && runtimeConfig.Session.ConfigDirEnv != "" && opts.RuntimeConfigDir != "" {
 		command = config.PrependEnv(command, map[string]string{runtimeConfig.Session.ConfigDirEnv: opts.RuntimeConfigDir})
 	}
+	// Run in a cgroup scope when the rig caps polecat resources
+	limits := config.ResolveResourceLimits(filepath.Dir(m.rig.Path), m.rig.Path, "polecat")
+	if command, err = cgroup.WrapSession(sessionID, limits, command); err != nil {
+		fmt.Printf("Warning: starting %s without resource limits: %v\n", sessionID, err)
+	}
 
 	// Create session with command directly to avoid send-keys race condition.
 	// See: https://github.com/anthropics/gastown/issues/280
-	if err := m.tmux.NewSessionWithCommand(sessionID, workDir, command); err != nil {
+	if err := m.backend.NewSessionWithCommand(sessionID, workDir, command); err != nil {
 		return fmt.Errorf("creating session: %w", err)
 	}
 
@@ -208,7 +226,7 @@ func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
 		BeadsNoDaemon:    true,
 	})
 	for k, v := range envVars {
-		debugSession("SetEnvironment "+k, m.tmux.SetEnvironment(sessionID, k, v))
+		debugSession("SetEnvironment "+k, m.backend.SetEnvironment(sessionID, k, v))
 	}
 
 	// Hook the issue to the polecat if provided via --issue flag
@@ -219,27 +237,32 @@ func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
 		}
 	}
 
-	// Apply theme (non-fatal)
-	theme := tmux.AssignTheme(m.rig.Name)
-	debugSession("ConfigureGasTownSession", m.tmux.ConfigureGasTownSession(sessionID, theme, m.rig.Name, polecat, "polecat"))
+	if t, ok := m.backend.(*tmux.Tmux); ok {
+		// Apply theme (non-fatal)
+		theme := tmux.AssignTheme(m.rig.Name)
+		debugSession("ConfigureGasTownSession", t.ConfigureGasTownSession(sessionID, theme, m.rig.Name, polecat, "polecat"))
 
-	// Set pane-died hook for crash detection (non-fatal)
-	agentID := fmt.Sprintf("%s/%s", m.rig.Name, polecat)
-	debugSession("SetPaneDiedHook", m.tmux.SetPaneDiedHook(sessionID, agentID))
+		// Set pane-died hook for crash detection (non-fatal)
+		agentID := fmt.Sprintf("%s/%s", m.rig.Name, polecat)
+		debugSession("SetPaneDiedHook", t.SetPaneDiedHook(sessionID, agentID))
 
-	// Wait for Claude to start (non-fatal)
-	debugSession("WaitForCommand", m.tmux.WaitForCommand(sessionID, constants.SupportedShells, constants.ClaudeStartTimeout))
+		// Wait for Claude to start (non-fatal)
+		debugSession("WaitForCommand", t.WaitForCommand(sessionID, constants.SupportedShells, constants.ClaudeStartTimeout))
 
-	// Accept bypass permissions warning dialog if it appears
-	debugSession("AcceptBypassPermissionsWarning", m.tmux.AcceptBypassPermissionsWarning(sessionID))
+		// Accept bypass permissions warning dialog if it appears
+		debugSession("AcceptBypassPermissionsWarning", t.AcceptBypassPermissionsWarning(sessionID))
+	} else {
+		// Wait for Claude to start (non-fatal)
+		debugSession("WaitForAgent", session.WaitForAgent(m.backend, sessionID, constants.ClaudeStartTimeout))
+	}
 
 	// Wait for runtime to be fully ready at the prompt (not just started)
 	runtime.SleepForReadyDelay(runtimeConfig)
-	_ = runtime.RunStartupFallback(m.tmux, sessionID, "polecat", runtimeConfig)
+	_ = runtime.RunStartupFallback(m.backend, sessionID, "polecat", runtimeConfig)
 
 	// Inject startup nudge for predecessor discovery via /resume
 	address := fmt.Sprintf("%s/polecats/%s", m.rig.Name, polecat)
-	debugSession("StartupNudge", session.StartupNudge(m.tmux, sessionID, session.StartupNudgeConfig{
+	debugSession("StartupNudge", session.StartupNudge(m.backend, sessionID, session.StartupNudgeConfig{
 		Recipient: address,
 		Sender:    "witness",
 		Topic:     "assigned",
@@ -248,11 +271,11 @@ func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
 
 	// GUPP: Send propulsion nudge to trigger autonomous work execution
 	time.Sleep(2 * time.Second)
-	debugSession("NudgeSession PropulsionNudge", m.tmux.NudgeSession(sessionID, session.PropulsionNudge()))
+	debugSession("NudgeSession PropulsionNudge", m.backend.NudgeSession(sessionID, session.PropulsionNudge()))
 
 	// Verify session survived startup - if the command crashed, the session may have died.
 	// Without this check, Start() would return success even if the pane died during initialization.
-	running, err = m.tmux.HasSession(sessionID)
+	running, err = m.backend.HasSession(sessionID)
 	if err != nil {
 		return fmt.Errorf("verifying session: %w", err)
 	}
@@ -267,7 +290,7 @@ func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
 func (m *SessionManager) Stop(polecat string, force bool) error {
 	sessionID := m.SessionName(polecat)
 
-	running, err := m.tmux.HasSession(sessionID)
+	running, err := m.backend.HasSession(sessionID)
 	if err != nil {
 		return fmt.Errorf("checking session: %w", err)
 	}
@@ -284,12 +307,12 @@ func (m *SessionManager) Stop(polecat string, force bool) error {
 	}
 
 	// Try graceful shutdown first
-	if !force {
-		_ = m.tmux.SendKeysRaw(sessionID, "C-c")
+	if t, ok := m.backend.(*tmux.Tmux); ok && !force {
+		_ = t.SendKeysRaw(sessionID, "C-c")
 		time.Sleep(100 * time.Millisecond)
 	}
 
-	if err := m.tmux.KillSession(sessionID); err != nil {
+	if err := m.backend.KillSession(sessionID); err != nil {
 		return fmt.Errorf("killing session: %w", err)
 	}
 
@@ -306,14 +329,14 @@ func (m *SessionManager) syncBeads(workDir string) error {
 // IsRunning checks if a polecat session is active.
 func (m *SessionManager) IsRunning(polecat string) (bool, error) {
 	sessionID := m.SessionName(polecat)
-	return m.tmux.HasSession(sessionID)
+	return m.backend.HasSession(sessionID)
 }
 
 // Status returns detailed status for a polecat session.
 func (m *SessionManager) Status(polecat string) (*SessionInfo, error) {
 	sessionID := m.SessionName(polecat)
 
-	running, err := m.tmux.HasSession(sessionID)
+	running, err := m.backend.HasSession(sessionID)
 	if err != nil {
 		return nil, fmt.Errorf("checking session: %w", err)
 	}
@@ -329,7 +352,11 @@ func (m *SessionManager) Status(polecat string) (*SessionInfo, error) {
 		return info, nil
 	}
 
-	tmuxInfo, err := m.tmux.GetSessionInfo(sessionID)
+	t, ok := m.backend.(*tmux.Tmux)
+	if !ok {
+		return info, nil
+	}
+	tmuxInfo, err := t.GetSessionInfo(sessionID)
 	if err != nil {
 		return info, nil
 	}
@@ -364,7 +391,7 @@ func (m *SessionManager) Status(polecat string) (*SessionInfo, error) {
 
 // List returns information about all polecat sessions for this rig.
 func (m *SessionManager) List() ([]SessionInfo, error) {
-	sessions, err := m.tmux.ListSessions()
+	sessions, err := m.backend.ListSessions()
 	if err != nil {
 		return nil, err
 	}
@@ -393,7 +420,7 @@ func (m *SessionManager) List() ([]SessionInfo, error) {
 func (m *SessionManager) Attach(polecat string) error {
 	sessionID := m.SessionName(polecat)
 
-	running, err := m.tmux.HasSession(sessionID)
+	running, err := m.backend.HasSession(sessionID)
 	if err != nil {
 		return fmt.Errorf("checking session: %w", err)
 	}
@@ -401,14 +428,18 @@ func (m *SessionManager) Attach(polecat string) error {
 		return ErrSessionNotFound
 	}
 
-	return m.tmux.AttachSession(sessionID)
+	a, ok := m.backend.(attacher)
+	if !ok {
+		return fmt.Errorf("session backend does not support attach")
+	}
+	return a.AttachSession(sessionID)
 }
 
 // Capture returns the recent output from a polecat session.
 func (m *SessionManager) Capture(polecat string, lines int) (string, error) {
 	sessionID := m.SessionName(polecat)
 
-	running, err := m.tmux.HasSession(sessionID)
+	running, err := m.backend.HasSession(sessionID)
 	if err != nil {
 		return "", fmt.Errorf("checking session: %w", err)
 	}
@@ -416,12 +447,12 @@ func (m *SessionManager) Capture(polecat string, lines int) (string, error) {
 		return "", ErrSessionNotFound
 	}
 
-	return m.tmux.CapturePane(sessionID, lines)
+	return m.backend.CapturePane(sessionID, lines)
 }
 
 // CaptureSession returns the recent output from a session by raw session ID.
 func (m *SessionManager) CaptureSession(sessionID string, lines int) (string, error) {
-	running, err := m.tmux.HasSession(sessionID)
+	running, err := m.backend.HasSession(sessionID)
 	if err != nil {
 		return "", fmt.Errorf("checking session: %w", err)
 	}
@@ -429,14 +460,14 @@ func (m *SessionManager) CaptureSession(sessionID string, lines int) (string, er
 		return "", ErrSessionNotFound
 	}
 
-	return m.tmux.CapturePane(sessionID, lines)
+	return m.backend.CapturePane(sessionID, lines)
 }
 
 // Inject sends a message to a polecat session.
 func (m *SessionManager) Inject(polecat, message string) error {
 	sessionID := m.SessionName(polecat)
 
-	running, err := m.tmux.HasSession(sessionID)
+	running, err := m.backend.HasSession(sessionID)
 	if err != nil {
 		return fmt.Errorf("checking session: %w", err)
 	}
@@ -449,7 +480,10 @@ func (m *SessionManager) Inject(polecat, message string) error {
 		debounceMs = 1500
 	}
 
-	return m.tmux.SendKeysDebounced(sessionID, message, debounceMs)
+	if d, ok := m.backend.(debouncedSender); ok {
+		return d.SendKeysDebounced(sessionID, message, debounceMs)
+	}
+	return m.backend.SendKeys(sessionID, message)
 }
 
 // StopAll terminates all polecat sessions for this rig.
@@ -190,3 +190,114 @@ func TestPolecatCommandFormat(t *testing.T) {
 		t.Error("GT_ROLE must be 'polecat', not 'mayor' or 'crew'")
 	}
 }
+
+// fakeBackend is an in-memory session.SessionBackend.
+type fakeBackend struct {
+	sessions map[string]bool
+	env      map[string]string
+	sent     []string
+	killed   []string
+	output   string
+}
+
+func newFakeBackend(sessions ...string) *fakeBackend {
+	f := &fakeBackend{sessions: map[string]bool{}, env: map[string]string{}}
+	for _, s := range sessions {
+		f.sessions[s] = true
+	}
+	return f
+}
+
+func (f *fakeBackend) NewSessionWithCommand(name, workDir, command string) error {
+	if f.sessions[name] {
+		return tmux.ErrSessionExists
+	}
+	f.sessions[name] = true
+	return nil
+}
+
+func (f *fakeBackend) HasSession(name string) (bool, error) { return f.sessions[name], nil }
+
+func (f *fakeBackend) ListSessions() ([]string, error) {
+	var names []string
+	for name := range f.sessions {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (f *fakeBackend) SetEnvironment(session, key, value string) error {
+	f.env[key] = value
+	return nil
+}
+
+func (f *fakeBackend) GetEnvironment(session, key string) (string, error) { return f.env[key], nil }
+
+func (f *fakeBackend) SendKeys(session, keys string) error {
+	f.sent = append(f.sent, session+": "+keys)
+	return nil
+}
+
+func (f *fakeBackend) NudgeSession(session, message string) error {
+	return f.SendKeys(session, message)
+}
+
+func (f *fakeBackend) CapturePane(session string, lines int) (string, error) { return f.output, nil }
+
+func (f *fakeBackend) IsAgentRunning(session string, expectedPaneCommands ...string) bool {
+	return f.sessions[session]
+}
+
+func (f *fakeBackend) KillSession(name string) error {
+	delete(f.sessions, name)
+	f.killed = append(f.killed, name)
+	return nil
+}
+
+func (f *fakeBackend) KillSessionWithProcesses(name string) error {
+	return f.KillSession(name)
+}
+
+func TestSessionManager_FakeBackend(t *testing.T) {
+	r := &rig.Rig{Name: "gastown", Polecats: []string{"Toast", "Nux"}}
+	b := newFakeBackend("gt-gastown-Toast", "gt-gastown-Nux", "gt-other-Toast", "hq-mayor")
+	b.output = "working on gt-abc"
+	m := NewSessionManager(b, r)
+
+	infos, err := m.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(infos) != 2 {
+		t.Errorf("List = %+v, want the two gastown polecats", infos)
+	}
+
+	if out, err := m.Capture("Toast", 10); err != nil || out != "working on gt-abc" {
+		t.Errorf("Capture = %q, %v", out, err)
+	}
+
+	if err := m.Inject("Toast", "check your hook"); err != nil {
+		t.Fatalf("Inject: %v", err)
+	}
+	if len(b.sent) != 1 || b.sent[0] != "gt-gastown-Toast: check your hook" {
+		t.Errorf("sent = %v", b.sent)
+	}
+
+	if err := m.Stop("Toast", true); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if len(b.killed) != 1 || b.killed[0] != "gt-gastown-Toast" {
+		t.Errorf("killed = %v", b.killed)
+	}
+	if running, _ := m.IsRunning("Toast"); running {
+		t.Error("Toast still running after Stop")
+	}
+
+	status, err := m.Status("Nux")
+	if err != nil || !status.Running || status.Windows != 0 {
+		t.Errorf("Status = %+v, %v", status, err)
+	}
+	if err := m.Attach("Nux"); err == nil || !strings.Contains(err.Error(), "does not support attach") {
+		t.Errorf("Attach = %v, want unsupported error", err)
+	}
+}
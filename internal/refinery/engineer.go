@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/mail"
 	"github.com/steveyegge/gastown/internal/protocol"
@@ -50,6 +51,7 @@ type MergeQueueConfig struct {
 	PollInterval time.Duration `json:"poll_interval"`
 
 	// MaxConcurrent is the maximum number of MRs to process concurrently.
+	// Values above 1 enable merge trains (see ProcessTrain).
 	MaxConcurrent int `json:"max_concurrent"`
 }
 
@@ -230,6 +232,10 @@ type ProcessResult struct {
 	Error       string
 	Conflict    bool
 	TestsFailed bool
+
+	// Strategy is the conflict strategy that handled this MR, if it hit a
+	// conflict: "auto_rebase" or "assign_back". Empty when no conflict.
+	Strategy string
 }
 
 // ProcessMR processes a single merge request from a beads issue.
@@ -249,12 +255,20 @@ func (e *Engineer) ProcessMR(ctx context.Context, mr *beads.Issue) ProcessResult
 	_, _ = fmt.Fprintf(e.output, "  Target: %s\n", mrFields.Target)
 	_, _ = fmt.Fprintf(e.output, "  Worker: %s\n", mrFields.Worker)
 
-	return e.doMerge(ctx, mrFields.Branch, mrFields.Target, mrFields.SourceIssue)
+	return e.doMerge(ctx, &MRInfo{
+		ID:          mr.ID,
+		Branch:      mrFields.Branch,
+		Target:      mrFields.Target,
+		SourceIssue: mrFields.SourceIssue,
+		Worker:      mrFields.Worker,
+	})
 }
 
 // doMerge performs the actual git merge operation.
 // This is the core merge logic shared by ProcessMR and ProcessMRFromQueue.
-func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue string) ProcessResult {
+func (e *Engineer) doMerge(ctx context.Context, mr *MRInfo) ProcessResult {
+	branch, target, sourceIssue := mr.Branch, mr.Target, mr.SourceIssue
+
 	// Step 1: Verify source branch exists locally (shared .repo.git with polecats)
 	_, _ = fmt.Fprintf(e.output, "[Engineer] Checking local branch %s...\n", branch)
 	exists, err := e.git.BranchExists(branch)
@@ -296,16 +310,51 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 			Error:    fmt.Sprintf("conflict check failed: %v", err),
 		}
 	}
+	strategy := ""
+	testsDone := false
 	if len(conflicts) > 0 {
-		return ProcessResult{
-			Success:  false,
-			Conflict: true,
-			Error:    fmt.Sprintf("merge conflicts in: %v", conflicts),
+		if e.config.OnConflict != config.OnConflictAutoRebase {
+			return ProcessResult{
+				Success:  false,
+				Conflict: true,
+				Error:    fmt.Sprintf("merge conflicts in: %v", conflicts),
+				Strategy: config.OnConflictAssignBack,
+			}
+		}
+
+		// Step 3.5: auto_rebase - rebase the branch onto target ourselves and
+		// only fall back to assign_back if the rebase genuinely conflicts.
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Conflicts in %v - attempting auto-rebase of %s onto %s...\n", conflicts, branch, target)
+		rebase := e.autoRebase(ctx, branch, target)
+		switch {
+		case len(rebase.Conflicts) > 0:
+			return ProcessResult{
+				Success:  false,
+				Conflict: true,
+				Error:    fmt.Sprintf("auto-rebase conflicts in: %v", rebase.Conflicts),
+				Strategy: config.OnConflictAssignBack,
+			}
+		case rebase.Err != nil:
+			return ProcessResult{
+				Success:  false,
+				Conflict: true,
+				Error:    fmt.Sprintf("auto-rebase failed: %v", rebase.Err),
+				Strategy: config.OnConflictAssignBack,
+			}
+		case rebase.TestsRan && !rebase.Test.Success:
+			return ProcessResult{
+				Success:     false,
+				TestsFailed: true,
+				Error:       rebase.Test.Error,
+				Strategy:    config.OnConflictAutoRebase,
+			}
 		}
+		strategy = config.OnConflictAutoRebase
+		testsDone = rebase.TestsRan
 	}
 
-	// Step 4: Run tests if configured
-	if e.config.RunTests && e.config.TestCommand != "" {
+	// Step 4: Run tests if configured (already done if we auto-rebased)
+	if e.config.RunTests && e.config.TestCommand != "" && !testsDone {
 		_, _ = fmt.Fprintf(e.output, "[Engineer] Running tests: %s\n", e.config.TestCommand)
 		result := e.runTests(ctx)
 		if !result.Success {
@@ -319,6 +368,13 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 	}
 
 	// Step 5: Perform the actual merge
+	preMerge, err := e.git.Rev("HEAD")
+	if err != nil {
+		return ProcessResult{
+			Success: false,
+			Error:   fmt.Sprintf("failed to get target SHA: %v", err),
+		}
+	}
 	mergeMsg := fmt.Sprintf("Merge %s into %s", branch, target)
 	if sourceIssue != "" {
 		mergeMsg = fmt.Sprintf("Merge %s into %s (%s)", branch, target, sourceIssue)
@@ -334,6 +390,7 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 				Success:  false,
 				Conflict: true,
 				Error:    "merge conflict during actual merge",
+				Strategy: config.OnConflictAssignBack,
 			}
 		}
 		return ProcessResult{
@@ -342,6 +399,18 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 		}
 	}
 
+	// Step 5.5: Run pre-merge lifecycle hooks on the merged tree, before
+	// anything is pushed. A failing hook undoes the local merge.
+	if err := e.runMergeHooks(config.HookPreMerge, mr, e.workDir); err != nil {
+		if resetErr := e.git.ResetHard(preMerge); resetErr != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: resetting %s after failed pre-merge hook: %v\n", target, resetErr)
+		}
+		return ProcessResult{
+			Success: false,
+			Error:   err.Error(),
+		}
+	}
+
 	// Step 6: Get the merge commit SHA
 	mergeCommit, err := e.git.Rev("HEAD")
 	if err != nil {
@@ -361,14 +430,29 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 	}
 
 	_, _ = fmt.Fprintf(e.output, "[Engineer] Successfully merged: %s\n", mergeCommit[:8])
+
+	// Step 8: Run post-merge lifecycle hooks. The merge has landed, so a
+	// failing hook is reported but can't fail the MR.
+	if err := e.runMergeHooks(config.HookPostMerge, mr, e.workDir); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: %v (merge already landed)\n", err)
+	}
+
 	return ProcessResult{
 		Success:     true,
 		MergeCommit: mergeCommit,
+		Strategy:    strategy,
 	}
 }
 
 // runTests runs the configured test command and returns the result.
 func (e *Engineer) runTests(ctx context.Context) ProcessResult {
+	return e.runTestsIn(ctx, e.workDir, e.output)
+}
+
+// runTestsIn runs the configured test command in the given directory,
+// reporting progress to out. Merge trains use this to test each speculative
+// worktree concurrently, with a shared out that serializes writes.
+func (e *Engineer) runTestsIn(ctx context.Context, dir string, out io.Writer) ProcessResult {
 	if e.config.TestCommand == "" {
 		return ProcessResult{Success: true}
 	}
@@ -382,13 +466,13 @@ func (e *Engineer) runTests(ctx context.Context) ProcessResult {
 	var lastErr error
 	for attempt := 1; attempt <= maxRetries; attempt++ {
 		if attempt > 1 {
-			_, _ = fmt.Fprintf(e.output, "[Engineer] Retrying tests (attempt %d/%d)...\n", attempt, maxRetries)
+			_, _ = fmt.Fprintf(out, "[Engineer] Retrying tests (attempt %d/%d)...\n", attempt, maxRetries)
 		}
 
 		// Note: TestCommand comes from rig's config.json (trusted infrastructure config),
 		// not from PR branches. Shell execution is intentional for flexibility (pipes, etc).
 		cmd := exec.CommandContext(ctx, "sh", "-c", e.config.TestCommand) //nolint:gosec // G204: TestCommand is from trusted rig config
-		cmd.Dir = e.workDir
+		cmd.Dir = dir
 		var stdout, stderr bytes.Buffer
 		cmd.Stdout = &stdout
 		cmd.Stderr = &stderr
@@ -432,6 +516,9 @@ func (e *Engineer) handleSuccess(mr *beads.Issue, result ProcessResult) {
 	// 1. Update MR with merge_commit SHA
 	mrFields.MergeCommit = result.MergeCommit
 	mrFields.CloseReason = "merged"
+	if result.Strategy != "" {
+		mrFields.ConflictStrategy = result.Strategy
+	}
 	newDesc := beads.SetMRFields(mr, mrFields)
 	if err := e.beads.Update(mr.ID, beads.UpdateOptions{Description: &newDesc}); err != nil {
 		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to update MR %s with merge commit: %v\n", mr.ID, err)
@@ -503,7 +590,21 @@ func (e *Engineer) ProcessMRInfo(ctx context.Context, mr *MRInfo) ProcessResult
 	_, _ = fmt.Fprintf(e.output, "  Source: %s\n", mr.SourceIssue)
 
 	// Use the shared merge logic
-	return e.doMerge(ctx, mr.Branch, mr.Target, mr.SourceIssue)
+	return e.doMerge(ctx, mr)
+}
+
+// runMergeHooks runs the rig's pre-merge or post-merge lifecycle hooks for
+// an MR in dir, a refinery worktree.
+func (e *Engineer) runMergeHooks(phase string, mr *MRInfo, dir string) error {
+	return rig.RunLifecycleHooks(e.rig.Path, phase, rig.HookEnv{
+		Rig:      e.rig.Name,
+		Polecat:  mr.Worker,
+		Bead:     mr.SourceIssue,
+		Branch:   mr.Branch,
+		MR:       mr.ID,
+		Target:   mr.Target,
+		Worktree: dir,
+	}, e.output)
 }
 
 // HandleMRInfoSuccess handles a successful merge from MRInfo.
@@ -536,6 +637,9 @@ func (e *Engineer) HandleMRInfoSuccess(mr *MRInfo, result ProcessResult) {
 			}
 			mrFields.MergeCommit = result.MergeCommit
 			mrFields.CloseReason = "merged"
+			if result.Strategy != "" {
+				mrFields.ConflictStrategy = result.Strategy
+			}
 			newDesc := beads.SetMRFields(mrBead, mrFields)
 			if err := e.beads.Update(mr.ID, beads.UpdateOptions{Description: &newDesc}); err != nil {
 				_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to update MR %s with merge commit: %v\n", mr.ID, err)
@@ -576,7 +680,17 @@ func (e *Engineer) HandleMRInfoSuccess(mr *MRInfo, result ProcessResult) {
 		}
 	}
 
-	// 3. Log success
+	// 3. Notify Witness so the polecat can be nuked
+	if mr.Worker != "" {
+		msg := protocol.NewMergedMessage(e.rig.Name, mr.Worker, mr.Branch, mr.SourceIssue, mr.Target, result.MergeCommit)
+		if err := e.router.Send(msg); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to send MERGED to witness: %v\n", err)
+		} else {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Notified witness of merge for %s\n", mr.Worker)
+		}
+	}
+
+	// 4. Log success
 	_, _ = fmt.Fprintf(e.output, "[Engineer] ✓ Merged: %s (commit: %s)\n", mr.ID, result.MergeCommit)
 }
 
@@ -601,8 +715,10 @@ func (e *Engineer) HandleMRInfoFailure(mr *MRInfo, result ProcessResult) {
 
 	// If this was a conflict, create a conflict-resolution task for dispatch
 	// and block the MR until the task is resolved (non-blocking delegation)
+	taskID := ""
 	if result.Conflict {
-		taskID, err := e.createConflictResolutionTaskForMR(mr, result)
+		var err error
+		taskID, err = e.createConflictResolutionTaskForMR(mr, result)
 		if err != nil {
 			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to create conflict resolution task: %v\n", err)
 		} else if taskID != "" {
@@ -616,6 +732,11 @@ func (e *Engineer) HandleMRInfoFailure(mr *MRInfo, result ProcessResult) {
 		}
 	}
 
+	// Record which conflict strategy handled this MR
+	if result.Strategy != "" {
+		e.recordConflictStrategy(mr.ID, result.Strategy, taskID)
+	}
+
 	// Log the failure - MR stays in queue but may be blocked
 	_, _ = fmt.Fprintf(e.output, "[Engineer] ✗ Failed: %s - %s\n", mr.ID, result.Error)
 	if mr.BlockedBy != "" {
@@ -625,6 +746,28 @@ func (e *Engineer) HandleMRInfoFailure(mr *MRInfo, result ProcessResult) {
 	}
 }
 
+// recordConflictStrategy stores the conflict strategy used for an MR (and the
+// conflict-resolution task, if one was created) in the MR bead's fields.
+func (e *Engineer) recordConflictStrategy(mrID, strategy, taskID string) {
+	mrBead, err := e.beads.Show(mrID)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to fetch MR bead %s: %v\n", mrID, err)
+		return
+	}
+	mrFields := beads.ParseMRFields(mrBead)
+	if mrFields == nil {
+		mrFields = &beads.MRFields{}
+	}
+	mrFields.ConflictStrategy = strategy
+	if taskID != "" {
+		mrFields.ConflictTaskID = taskID
+	}
+	newDesc := beads.SetMRFields(mrBead, mrFields)
+	if err := e.beads.Update(mrID, beads.UpdateOptions{Description: &newDesc}); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to record conflict strategy on MR %s: %v\n", mrID, err)
+	}
+}
+
 // createConflictResolutionTaskForMR creates a dispatchable task for resolving merge conflicts.
 // This task will be picked up by bd ready and can be slung to a fresh polecat (spawned on demand).
 // Returns the created task's ID for blocking the MR until resolution.
@@ -1,12 +1,16 @@
 package refinery
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/rig"
 )
 
@@ -215,3 +219,46 @@ func TestEngineer_DeleteMergedBranchesConfig(t *testing.T) {
 		t.Error("expected DeleteMergedBranches to be true by default")
 	}
 }
+
+func TestProcessMRInfo_LifecycleHooks(t *testing.T) {
+	r := setupTrainRig(t, map[string]string{
+		"polecat/good": "good.txt",
+		"polecat/bad":  "bad.txt",
+	})
+	settings := config.NewRigSettings()
+	settings.LifecycleHooks = map[string][]config.LifecycleHook{
+		config.HookPreMerge: {
+			// Runs on the merged tree, so it sees the branch's files.
+			{Name: "policy", Command: `test ! -e bad.txt`, OnFailure: config.HookOnFailureFail},
+		},
+		config.HookPostMerge: {
+			{Name: "record", Command: `echo "$GT_HOOK_MR $GT_HOOK_TARGET $(git rev-parse --abbrev-ref HEAD)" >> "$GT_RIG_PATH/merged.log"`},
+		},
+	}
+	if err := config.SaveRigSettings(config.RigSettingsPath(r.Path), settings); err != nil {
+		t.Fatal(err)
+	}
+
+	e := NewEngineer(r)
+	e.SetOutput(&bytes.Buffer{})
+	e.config.RunTests = false
+
+	bad := e.ProcessMRInfo(context.Background(), &MRInfo{ID: "mr-bad", Branch: "polecat/bad", Target: "main"})
+	if bad.Success || !strings.Contains(bad.Error, `pre-merge hook "policy" failed`) {
+		t.Errorf("mr-bad = %+v", bad)
+	}
+	good := e.ProcessMRInfo(context.Background(), &MRInfo{ID: "mr-good", Branch: "polecat/good", Target: "main"})
+	if !good.Success {
+		t.Fatalf("mr-good: %s", good.Error)
+	}
+
+	log, _ := os.ReadFile(filepath.Join(r.Path, "merged.log"))
+	if got := strings.TrimSpace(string(log)); got != "mr-good main main" {
+		t.Errorf("post-merge log = %q", got)
+	}
+	// The rejected merge was undone locally, so the next push didn't carry it.
+	files := runGit(t, filepath.Join(r.Path, "origin.git"), "ls-tree", "--name-only", "main")
+	if strings.Contains(files, "bad.txt") || !strings.Contains(files, "good.txt") {
+		t.Errorf("origin/main = %q", files)
+	}
+}
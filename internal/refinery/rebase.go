@@ -0,0 +1,126 @@
+// Package refinery provides the merge queue processing agent.
+// This file contains the auto_rebase conflict strategy.
+
+package refinery
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// rebaseResult is the outcome of an auto-rebase attempt.
+type rebaseResult struct {
+	// Rebased is true when the branch was rebased and updated in place.
+	Rebased bool
+
+	// Conflicts lists files that conflicted during the rebase (if any).
+	Conflicts []string
+
+	// TestsRan is true when the configured tests ran on the rebased branch.
+	TestsRan bool
+
+	// Test holds the test result when TestsRan is true.
+	Test ProcessResult
+
+	// Err is set for failures other than conflicts or tests.
+	Err error
+}
+
+// autoRebase rebases branch onto target in a scratch worktree, so the
+// refinery's own checkout is never left mid-rebase. If the rebase applies
+// cleanly, the configured tests run in the scratch worktree; when they pass,
+// the branch is moved to the rebased commit (locally and on origin).
+//
+// Only the real target branch may be passed as target: the rebased branch
+// is published. Merge trains use rebaseDetached instead.
+func (e *Engineer) autoRebase(ctx context.Context, branch, target string) rebaseResult {
+	dir, err := os.MkdirTemp("", fmt.Sprintf("gt-rebase-%s-*", e.rig.Name))
+	if err != nil {
+		return rebaseResult{Err: fmt.Errorf("creating scratch worktree: %w", err)}
+	}
+	// git worktree add wants to create the directory itself.
+	_ = os.Remove(dir)
+	if err := e.git.WorktreeAddDetached(dir, branch); err != nil {
+		return rebaseResult{Err: fmt.Errorf("creating scratch worktree: %w", err)}
+	}
+	defer func() {
+		if err := e.git.WorktreeRemove(dir, true); err != nil {
+			_ = os.RemoveAll(dir)
+			_ = e.git.WorktreePrune()
+		}
+	}()
+
+	wt := git.NewGit(dir)
+	if err := wt.Rebase(target); err != nil {
+		conflicts, conflictErr := wt.GetConflictingFiles()
+		_ = wt.AbortRebase()
+		if conflictErr == nil && len(conflicts) > 0 {
+			return rebaseResult{Conflicts: conflicts}
+		}
+		return rebaseResult{Err: fmt.Errorf("rebase onto %s: %w", target, err)}
+	}
+
+	var res rebaseResult
+	if e.config.RunTests && e.config.TestCommand != "" {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Running tests on rebased branch: %s\n", e.config.TestCommand)
+		res.TestsRan = true
+		res.Test = e.runTestsIn(ctx, dir, e.output)
+		if !res.Test.Success {
+			return res
+		}
+	}
+
+	rebased, err := wt.Rev("HEAD")
+	if err != nil {
+		return rebaseResult{Err: fmt.Errorf("reading rebased HEAD: %w", err)}
+	}
+	if err := e.git.ResetBranch(branch, rebased); err != nil {
+		return rebaseResult{Err: fmt.Errorf("updating branch %s: %w", branch, err)}
+	}
+	// Keep origin in step so the polecat branch on the remote matches what merged.
+	if err := e.git.Push("origin", branch, true); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: force-push rebased %s: %v (continuing)\n", branch, err)
+	}
+
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Rebased %s onto %s: %s\n", branch, target, shortSHA(rebased))
+	res.Rebased = true
+	return res
+}
+
+// rebaseDetached rebases a detached copy of branch onto the commit onto in
+// wt and returns the rebased commit, leaving wt detached at onto again. The
+// branch itself is neither moved nor pushed, so commits from a speculative
+// merge train never leak into the MR branch. Returns the conflicting files
+// if the rebase conflicts.
+func rebaseDetached(wt *git.Git, branch, onto string) (string, []string, error) {
+	head, err := wt.Rev(branch)
+	if err != nil {
+		return "", nil, fmt.Errorf("resolving %s: %w", branch, err)
+	}
+	if err := wt.Checkout(head); err != nil {
+		return "", nil, fmt.Errorf("checking out %s: %w", branch, err)
+	}
+
+	if err := wt.Rebase(onto); err != nil {
+		conflicts, conflictErr := wt.GetConflictingFiles()
+		_ = wt.AbortRebase()
+		_ = wt.Checkout(onto)
+		if conflictErr == nil && len(conflicts) > 0 {
+			return "", conflicts, nil
+		}
+		return "", nil, fmt.Errorf("rebase onto %s: %w", shortSHA(onto), err)
+	}
+
+	rebased, err := wt.Rev("HEAD")
+	if err != nil {
+		_ = wt.Checkout(onto)
+		return "", nil, fmt.Errorf("reading rebased HEAD: %w", err)
+	}
+	if err := wt.Checkout(onto); err != nil {
+		return "", nil, fmt.Errorf("returning to %s: %w", shortSHA(onto), err)
+	}
+	return rebased, nil, nil
+}
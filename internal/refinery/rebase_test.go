@@ -0,0 +1,92 @@
+package refinery
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func writeAndCommit(t *testing.T, dir, file, content, msg string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-m", msg)
+}
+
+func TestDoMerge_AutoRebaseResolvesCherryPickedConflict(t *testing.T) {
+	r := setupTrainRig(t, nil)
+	work := filepath.Join(r.Path, "refinery", "rig")
+
+	// Branch: A -> X, then X -> Y. Main gets the A -> X fix cherry-picked,
+	// so a merge conflicts but a rebase drops the duplicate commit cleanly.
+	writeAndCommit(t, work, "file.txt", "A\n", "base")
+	runGit(t, work, "push", "origin", "main")
+	runGit(t, work, "checkout", "-b", "polecat/nux")
+	writeAndCommit(t, work, "file.txt", "X\n", "fix")
+	fix := runGit(t, work, "rev-parse", "HEAD")
+	writeAndCommit(t, work, "file.txt", "Y\n", "feature")
+	runGit(t, work, "checkout", "main")
+	writeAndCommit(t, work, "other.txt", "other\n", "other")
+	runGit(t, work, "cherry-pick", fix)
+	runGit(t, work, "push", "origin", "main")
+
+	e := NewEngineer(r)
+	e.SetOutput(&bytes.Buffer{})
+	e.config.OnConflict = config.OnConflictAutoRebase
+	e.config.RunTests = true
+	e.config.TestCommand = "test -f other.txt"
+
+	result := e.ProcessMRInfo(context.Background(), &MRInfo{ID: "mr-1", Branch: "polecat/nux", Target: "main"})
+	if !result.Success {
+		t.Fatalf("expected auto-rebase merge to succeed: %s", result.Error)
+	}
+	if result.Strategy != config.OnConflictAutoRebase {
+		t.Errorf("Strategy = %q, want %q", result.Strategy, config.OnConflictAutoRebase)
+	}
+
+	got := runGit(t, filepath.Join(r.Path, "origin.git"), "show", "main:file.txt")
+	if got != "Y" {
+		t.Errorf("origin main:file.txt = %q, want %q", got, "Y")
+	}
+}
+
+func TestDoMerge_AutoRebaseFallsBackOnRealConflict(t *testing.T) {
+	r := setupTrainRig(t, nil)
+	work := filepath.Join(r.Path, "refinery", "rig")
+
+	writeAndCommit(t, work, "file.txt", "A\n", "base")
+	runGit(t, work, "push", "origin", "main")
+	runGit(t, work, "checkout", "-b", "polecat/nux")
+	writeAndCommit(t, work, "file.txt", "Y\n", "feature")
+	runGit(t, work, "checkout", "main")
+	writeAndCommit(t, work, "file.txt", "Z\n", "other")
+	runGit(t, work, "push", "origin", "main")
+
+	e := NewEngineer(r)
+	e.SetOutput(&bytes.Buffer{})
+	e.config.OnConflict = config.OnConflictAutoRebase
+	e.config.RunTests = false
+
+	result := e.ProcessMRInfo(context.Background(), &MRInfo{ID: "mr-1", Branch: "polecat/nux", Target: "main"})
+	if result.Success || !result.Conflict {
+		t.Fatalf("expected conflict, got %+v", result)
+	}
+	if result.Strategy != config.OnConflictAssignBack {
+		t.Errorf("Strategy = %q, want %q", result.Strategy, config.OnConflictAssignBack)
+	}
+	if !strings.Contains(result.Error, "auto-rebase conflicts") {
+		t.Errorf("unexpected error: %s", result.Error)
+	}
+
+	// The branch must be left untouched for the conflict-resolution task.
+	if got := runGit(t, work, "show", "polecat/nux:file.txt"); got != "Y" {
+		t.Errorf("polecat/nux:file.txt = %q, want %q", got, "Y")
+	}
+}
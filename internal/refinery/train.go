@@ -0,0 +1,377 @@
+// Package refinery provides the merge queue processing agent.
+// This file contains the merge train: concurrent, speculative processing
+// of several MRs at once when MaxConcurrent > 1.
+
+package refinery
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// TrainResult is the outcome of one MR in a merge train.
+type TrainResult struct {
+	MR     *MRInfo
+	Result ProcessResult
+
+	// Deferred is true when the train stopped before reaching a verdict for
+	// this MR (e.g., a push failed). Deferred MRs should simply be released
+	// back to the queue; they are neither merged nor failed.
+	Deferred bool
+}
+
+// trainCar is one speculative merge in a train. Car i lives in its own
+// worktree whose HEAD is the target branch plus the MRs of cars 0..i merged
+// in score order, so testing car i tests exactly what main would look like
+// if everything up to and including it landed.
+type trainCar struct {
+	mr      *MRInfo
+	dir     string // Worktree path
+	tip     string // Merge commit SHA at the head of this car
+	result  ProcessResult
+	skipped bool // Car could not be built (conflict, missing branch); not part of the stack
+
+	// deferred is set on skipped cars that failed for reasons unrelated to
+	// the MR (e.g., its worktree couldn't be created); they go back to the
+	// queue instead of being failed.
+	deferred bool
+}
+
+// SelectTrain picks the next batch of MRs to process as a train.
+// MRs are ordered by score (highest first) and only MRs sharing the
+// top-scored MR's target branch are selected, up to MaxConcurrent.
+func (e *Engineer) SelectTrain(mrs []*MRInfo) []*MRInfo {
+	if len(mrs) == 0 {
+		return nil
+	}
+
+	sorted := make([]*MRInfo, len(mrs))
+	copy(sorted, mrs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Score() > sorted[j].Score()
+	})
+
+	limit := e.config.MaxConcurrent
+	if limit < 1 {
+		limit = 1
+	}
+
+	target := sorted[0].Target
+	var batch []*MRInfo
+	for _, mr := range sorted {
+		if mr.Target != target {
+			continue
+		}
+		batch = append(batch, mr)
+		if len(batch) == limit {
+			break
+		}
+	}
+	return batch
+}
+
+// ProcessTrain processes a batch of MRs (see SelectTrain) as a merge train.
+//
+// Each MR is speculatively merged onto a stack of temporary worktrees, the
+// configured tests run in every worktree in parallel, and the longest passing
+// prefix of the stack is pushed to the target in one go. Because the stack is
+// cumulative, the first failing car bisects the batch: everything before it
+// is known-good and lands, the car itself is the culprit and is failed, and
+// the cars after it are rebuilt on the new target and tested again.
+//
+// With MaxConcurrent <= 1 (or a single MR) this is equivalent to calling
+// ProcessMRInfo for each MR in order.
+func (e *Engineer) ProcessTrain(ctx context.Context, mrs []*MRInfo) []TrainResult {
+	if len(mrs) == 0 {
+		return nil
+	}
+
+	if e.config.MaxConcurrent <= 1 || len(mrs) == 1 {
+		results := make([]TrainResult, 0, len(mrs))
+		for _, mr := range mrs {
+			results = append(results, TrainResult{MR: mr, Result: e.ProcessMRInfo(ctx, mr)})
+		}
+		return results
+	}
+
+	target := mrs[0].Target
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Starting merge train: %d MR(s) → %s\n", len(mrs), target)
+
+	// Refresh the target so the train is built on the latest origin state.
+	if err := e.git.FetchBranch("origin", target); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: fetch origin/%s: %v (continuing)\n", target, err)
+	}
+	base, err := e.git.Rev("origin/" + target)
+	if err != nil {
+		if base, err = e.git.Rev(target); err != nil {
+			return deferAll(mrs, fmt.Sprintf("failed to resolve target %s: %v", target, err))
+		}
+	}
+
+	var results []TrainResult
+	pending := mrs
+	for len(pending) > 0 {
+		if ctx.Err() != nil {
+			return append(results, deferAll(pending, "merge train canceled")...)
+		}
+
+		cars := e.buildTrain(base, pending)
+		e.testTrain(ctx, cars)
+
+		// Find the longest passing prefix of the stack. Skipped cars are not
+		// part of the stack and never block the cars behind them.
+		landed := -1
+		failed := -1
+		for i, car := range cars {
+			if car.skipped {
+				continue
+			}
+			if !car.result.Success {
+				failed = i
+				break
+			}
+			landed = i
+		}
+
+		if landed >= 0 {
+			tip := cars[landed].tip
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Landing train prefix at %s on origin/%s...\n", shortSHA(tip), target)
+			if err := e.git.Push("origin", tip+":refs/heads/"+target, false); err != nil {
+				e.removeTrain(cars)
+				return append(results, deferAll(pending, fmt.Sprintf("failed to push to origin: %v", err))...)
+			}
+			base = tip
+
+			// Post-merge hooks run in each landed car's worktree; the merges
+			// have landed, so failures are only reported.
+			for _, car := range cars[:landed+1] {
+				if car.skipped {
+					continue
+				}
+				if err := e.runMergeHooks(config.HookPostMerge, car.mr, car.dir); err != nil {
+					_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: %v (merge already landed)\n", err)
+				}
+			}
+		}
+
+		var next []*MRInfo
+		for i, car := range cars {
+			switch {
+			case failed >= 0 && i > failed:
+				// Behind the culprit: built and tested on top of it, so
+				// whether it passed, failed or couldn't be built says
+				// nothing about the real target. Rebuild without it.
+				next = append(next, car.mr)
+			case car.deferred:
+				results = append(results, TrainResult{MR: car.mr, Result: car.result, Deferred: true})
+			default:
+				results = append(results, TrainResult{MR: car.mr, Result: car.result})
+			}
+		}
+		e.removeTrain(cars)
+
+		if failed >= 0 {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Train car %s failed; ejecting and rebuilding %d MR(s) behind it\n", cars[failed].mr.ID, len(next))
+		}
+		pending = next
+	}
+
+	// Keep the refinery's own checkout of the target current.
+	if err := e.git.Checkout(target); err == nil {
+		if err := e.git.Pull("origin", target); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: pull from origin/%s: %v (continuing)\n", target, err)
+		}
+	}
+
+	return results
+}
+
+// buildTrain creates one detached worktree per MR, each stacked on the
+// previous car's merge commit. MRs that cannot be merged onto the stack are
+// marked skipped with a failure result and the stack continues without them.
+func (e *Engineer) buildTrain(base string, mrs []*MRInfo) []*trainCar {
+	cars := make([]*trainCar, 0, len(mrs))
+	tip := base
+	for i, mr := range mrs {
+		car := &trainCar{mr: mr}
+		cars = append(cars, car)
+
+		exists, err := e.git.BranchExists(mr.Branch)
+		if err != nil || !exists {
+			car.skipped = true
+			car.result = ProcessResult{Error: fmt.Sprintf("branch %s not found locally", mr.Branch)}
+			continue
+		}
+
+		dir, err := os.MkdirTemp("", fmt.Sprintf("gt-train-%s-%d-*", e.rig.Name, i))
+		if err != nil {
+			car.skipped, car.deferred = true, true
+			car.result = ProcessResult{Error: fmt.Sprintf("creating train worktree: %v", err)}
+			continue
+		}
+		// git worktree add wants to create the directory itself.
+		_ = os.Remove(dir)
+		if err := e.git.WorktreeAddDetached(dir, tip); err != nil {
+			car.skipped, car.deferred = true, true
+			car.result = ProcessResult{Error: fmt.Sprintf("creating train worktree: %v", err)}
+			continue
+		}
+		car.dir = dir
+
+		wt := git.NewGit(dir)
+		mergeMsg := fmt.Sprintf("Merge %s into %s", mr.Branch, mr.Target)
+		if mr.SourceIssue != "" {
+			mergeMsg = fmt.Sprintf("Merge %s into %s (%s)", mr.Branch, mr.Target, mr.SourceIssue)
+		}
+		result, ok := e.mergeCar(wt, mr, tip, mergeMsg)
+		car.result = result
+		if !ok {
+			car.skipped = true
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Train car %s dropped: %s\n", mr.ID, car.result.Error)
+			continue
+		}
+
+		// Pre-merge hooks see the merged tree. A failing hook drops the
+		// car; the next one stacks on the previous tip, not this merge.
+		if err := e.runMergeHooks(config.HookPreMerge, mr, dir); err != nil {
+			car.skipped = true
+			car.result = ProcessResult{Error: err.Error()}
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Train car %s dropped: %s\n", mr.ID, car.result.Error)
+			continue
+		}
+
+		head, err := wt.Rev("HEAD")
+		if err != nil {
+			car.skipped = true
+			car.result = ProcessResult{Error: fmt.Sprintf("failed to get merge commit SHA: %v", err)}
+			continue
+		}
+		car.tip = head
+		tip = head
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Train car %d: %s at %s\n", i+1, mr.ID, shortSHA(head))
+	}
+	return cars
+}
+
+// mergeCar merges an MR into a car's worktree. On conflict with the
+// auto_rebase strategy, a detached copy of the branch is rebased onto the
+// stack tip inside the car and that is merged instead. The MR branch is
+// never rewritten or pushed: the tip holds cars that may not land.
+// Returns false with a failure result if the car can't be built.
+func (e *Engineer) mergeCar(wt *git.Git, mr *MRInfo, tip, mergeMsg string) (ProcessResult, bool) {
+	err := wt.MergeNoFF(mr.Branch, mergeMsg)
+	if err == nil {
+		return ProcessResult{}, true
+	}
+
+	conflicts, conflictErr := wt.GetConflictingFiles()
+	if conflictErr != nil || len(conflicts) == 0 {
+		return ProcessResult{Error: fmt.Sprintf("merge failed: %v", err)}, false
+	}
+	_ = wt.AbortMerge()
+
+	if e.config.OnConflict != config.OnConflictAutoRebase {
+		return ProcessResult{
+			Conflict: true,
+			Error:    fmt.Sprintf("merge conflicts in: %v", conflicts),
+			Strategy: config.OnConflictAssignBack,
+		}, false
+	}
+
+	rebased, conflicts, err := rebaseDetached(wt, mr.Branch, tip)
+	if err != nil || len(conflicts) > 0 {
+		msg := fmt.Sprintf("auto-rebase conflicts in: %v", conflicts)
+		if err != nil {
+			msg = fmt.Sprintf("auto-rebase failed: %v", err)
+		}
+		return ProcessResult{Conflict: true, Error: msg, Strategy: config.OnConflictAssignBack}, false
+	}
+	if err := wt.MergeNoFF(rebased, mergeMsg); err != nil {
+		_ = wt.AbortMerge()
+		return ProcessResult{Error: fmt.Sprintf("merge after auto-rebase failed: %v", err)}, false
+	}
+	return ProcessResult{Strategy: config.OnConflictAutoRebase}, true
+}
+
+// testTrain runs the configured tests in every built car concurrently and
+// records each car's result. Without a test command every built car passes.
+func (e *Engineer) testTrain(ctx context.Context, cars []*trainCar) {
+	runTests := e.config.RunTests && e.config.TestCommand != ""
+	if runTests {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Running tests in parallel: %s\n", e.config.TestCommand)
+	}
+
+	// Test output from concurrent cars shares one writer.
+	out := &lockedWriter{w: e.output}
+
+	var wg sync.WaitGroup
+	for _, car := range cars {
+		if car.skipped {
+			continue
+		}
+		if !runTests {
+			car.result = ProcessResult{Success: true, MergeCommit: car.tip, Strategy: car.result.Strategy}
+			continue
+		}
+		wg.Add(1)
+		go func(car *trainCar) {
+			defer wg.Done()
+			result := e.runTestsIn(ctx, car.dir, out)
+			if result.Success {
+				result.MergeCommit = car.tip
+			}
+			result.Strategy = car.result.Strategy
+			car.result = result
+		}(car)
+	}
+	wg.Wait()
+}
+
+// removeTrain removes the temporary worktrees of a train.
+func (e *Engineer) removeTrain(cars []*trainCar) {
+	for _, car := range cars {
+		if car.dir == "" {
+			continue
+		}
+		if err := e.git.WorktreeRemove(car.dir, true); err != nil {
+			_ = os.RemoveAll(car.dir)
+		}
+	}
+	_ = e.git.WorktreePrune()
+}
+
+// deferAll returns a Deferred result for each MR.
+func deferAll(mrs []*MRInfo, reason string) []TrainResult {
+	results := make([]TrainResult, 0, len(mrs))
+	for _, mr := range mrs {
+		results = append(results, TrainResult{MR: mr, Result: ProcessResult{Error: reason}, Deferred: true})
+	}
+	return results
+}
+
+// shortSHA abbreviates a commit SHA for log output.
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
+
+// lockedWriter serializes writes from concurrently running train cars.
+type lockedWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
+}
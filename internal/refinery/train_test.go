@@ -0,0 +1,246 @@
+package refinery
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return string(bytes.TrimSpace(out))
+}
+
+// setupTrainRig creates a rig with a bare origin and a refinery/rig clone
+// holding one polecat branch per entry in files (branch name -> file added).
+func setupTrainRig(t *testing.T, files map[string]string) *rig.Rig {
+	t.Helper()
+	rigPath := t.TempDir()
+
+	origin := filepath.Join(rigPath, "origin.git")
+	runGit(t, rigPath, "init", "--bare", "-b", "main", origin)
+
+	work := filepath.Join(rigPath, "refinery", "rig")
+	runGit(t, rigPath, "clone", origin, work)
+	runGit(t, work, "config", "user.email", "test@test.com")
+	runGit(t, work, "config", "user.name", "Test User")
+	runGit(t, work, "checkout", "-b", "main")
+	if err := os.WriteFile(filepath.Join(work, "README.md"), []byte("# Test\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, work, "add", ".")
+	runGit(t, work, "commit", "-m", "initial")
+	runGit(t, work, "push", "origin", "main")
+
+	for branch, file := range files {
+		runGit(t, work, "checkout", "-b", branch, "main")
+		if err := os.WriteFile(filepath.Join(work, file), []byte(branch+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		runGit(t, work, "add", ".")
+		runGit(t, work, "commit", "-m", "add "+file)
+	}
+	runGit(t, work, "checkout", "main")
+
+	return &rig.Rig{Name: "test-rig", Path: rigPath}
+}
+
+func TestSelectTrain(t *testing.T) {
+	e := NewEngineer(&rig.Rig{Name: "test-rig", Path: t.TempDir()})
+	e.config.MaxConcurrent = 2
+
+	now := time.Now()
+	mrs := []*MRInfo{
+		{ID: "low", Target: "main", Priority: 3, CreatedAt: now},
+		{ID: "high", Target: "main", Priority: 0, CreatedAt: now},
+		{ID: "other", Target: "develop", Priority: 1, CreatedAt: now},
+		{ID: "mid", Target: "main", Priority: 2, CreatedAt: now},
+	}
+
+	batch := e.SelectTrain(mrs)
+	if len(batch) != 2 {
+		t.Fatalf("expected 2 MRs, got %d", len(batch))
+	}
+	if batch[0].ID != "high" || batch[1].ID != "mid" {
+		t.Errorf("expected [high mid], got [%s %s]", batch[0].ID, batch[1].ID)
+	}
+}
+
+func TestProcessTrain_EjectsFailingCar(t *testing.T) {
+	r := setupTrainRig(t, map[string]string{
+		"polecat/a": "a.txt",
+		"polecat/b": "bad.txt",
+		"polecat/c": "c.txt",
+	})
+
+	e := NewEngineer(r)
+	e.SetOutput(&bytes.Buffer{})
+	e.config.MaxConcurrent = 3
+	e.config.RunTests = true
+	e.config.TestCommand = "test ! -f bad.txt"
+
+	mrs := []*MRInfo{
+		{ID: "mr-a", Branch: "polecat/a", Target: "main"},
+		{ID: "mr-b", Branch: "polecat/b", Target: "main"},
+		{ID: "mr-c", Branch: "polecat/c", Target: "main"},
+	}
+
+	results := e.ProcessTrain(context.Background(), mrs)
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+
+	got := make(map[string]TrainResult)
+	for _, tr := range results {
+		got[tr.MR.ID] = tr
+	}
+	if !got["mr-a"].Result.Success {
+		t.Errorf("mr-a should merge: %s", got["mr-a"].Result.Error)
+	}
+	if got["mr-b"].Result.Success || !got["mr-b"].Result.TestsFailed {
+		t.Errorf("mr-b should fail tests, got %+v", got["mr-b"].Result)
+	}
+	if !got["mr-c"].Result.Success {
+		t.Errorf("mr-c should merge after rebuild: %s", got["mr-c"].Result.Error)
+	}
+
+	origin := filepath.Join(r.Path, "origin.git")
+	files := runGit(t, origin, "ls-tree", "--name-only", "main")
+	for _, want := range []string{"a.txt", "c.txt"} {
+		if !bytes.Contains([]byte(files), []byte(want)) {
+			t.Errorf("origin/main missing %s: %q", want, files)
+		}
+	}
+	if bytes.Contains([]byte(files), []byte("bad.txt")) {
+		t.Errorf("origin/main should not contain bad.txt: %q", files)
+	}
+}
+
+func TestProcessTrain_ConflictDropsCar(t *testing.T) {
+	r := setupTrainRig(t, map[string]string{
+		"polecat/a": "same.txt",
+		"polecat/b": "same.txt",
+	})
+
+	e := NewEngineer(r)
+	e.SetOutput(&bytes.Buffer{})
+	e.config.MaxConcurrent = 2
+	e.config.RunTests = false
+
+	mrs := []*MRInfo{
+		{ID: "mr-a", Branch: "polecat/a", Target: "main"},
+		{ID: "mr-b", Branch: "polecat/b", Target: "main"},
+	}
+
+	results := e.ProcessTrain(context.Background(), mrs)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	for _, tr := range results {
+		switch tr.MR.ID {
+		case "mr-a":
+			if !tr.Result.Success {
+				t.Errorf("mr-a should merge: %s", tr.Result.Error)
+			}
+		case "mr-b":
+			if !tr.Result.Conflict {
+				t.Errorf("mr-b should conflict, got %+v", tr.Result)
+			}
+		}
+	}
+}
+
+func TestProcessTrain_RebuildsCarsDroppedBehindCulprit(t *testing.T) {
+	// mr-c only conflicts with mr-b, which fails its tests. Once mr-b is
+	// ejected, mr-c must be rebuilt without it rather than failed.
+	r := setupTrainRig(t, map[string]string{
+		"polecat/a": "a.txt",
+		"polecat/b": "same.txt",
+		"polecat/c": "same.txt",
+	})
+
+	e := NewEngineer(r)
+	e.SetOutput(&bytes.Buffer{})
+	e.config.MaxConcurrent = 3
+	e.config.RunTests = true
+	e.config.TestCommand = "! grep -qs polecat/b same.txt"
+
+	results := e.ProcessTrain(context.Background(), []*MRInfo{
+		{ID: "mr-a", Branch: "polecat/a", Target: "main"},
+		{ID: "mr-b", Branch: "polecat/b", Target: "main"},
+		{ID: "mr-c", Branch: "polecat/c", Target: "main"},
+	})
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	for _, tr := range results {
+		want := tr.MR.ID != "mr-b"
+		if tr.Result.Success != want || tr.Deferred {
+			t.Errorf("%s: success=%v deferred=%v (%s), want success=%v", tr.MR.ID, tr.Result.Success, tr.Deferred, tr.Result.Error, want)
+		}
+	}
+	if got := runGit(t, filepath.Join(r.Path, "origin.git"), "show", "main:same.txt"); got != "polecat/c" {
+		t.Errorf("origin main:same.txt = %q, want %q", got, "polecat/c")
+	}
+}
+
+func TestProcessTrain_AutoRebaseLeavesBranchAlone(t *testing.T) {
+	r := setupTrainRig(t, nil)
+	work := filepath.Join(r.Path, "refinery", "rig")
+	origin := filepath.Join(r.Path, "origin.git")
+
+	// polecat/b is A -> X -> Y; polecat/a carries the A -> X fix alone, so
+	// merging b onto a's car conflicts but rebasing b onto it is clean.
+	writeAndCommit(t, work, "file.txt", "A\n", "base")
+	runGit(t, work, "push", "origin", "main")
+	runGit(t, work, "checkout", "-b", "polecat/b")
+	writeAndCommit(t, work, "file.txt", "X\n", "fix")
+	fix := runGit(t, work, "rev-parse", "HEAD")
+	writeAndCommit(t, work, "file.txt", "Y\n", "feature")
+	runGit(t, work, "push", "origin", "polecat/b")
+	runGit(t, work, "checkout", "-b", "polecat/a", "main")
+	writeAndCommit(t, work, "a.txt", "a\n", "add a")
+	runGit(t, work, "cherry-pick", fix)
+	runGit(t, work, "checkout", "main")
+	before := runGit(t, work, "rev-parse", "polecat/b")
+
+	e := NewEngineer(r)
+	e.SetOutput(&bytes.Buffer{})
+	e.config.MaxConcurrent = 2
+	e.config.OnConflict = config.OnConflictAutoRebase
+	e.config.RunTests = false
+
+	results := e.ProcessTrain(context.Background(), []*MRInfo{
+		{ID: "mr-a", Branch: "polecat/a", Target: "main"},
+		{ID: "mr-b", Branch: "polecat/b", Target: "main"},
+	})
+	for _, tr := range results {
+		if !tr.Result.Success {
+			t.Errorf("%s should merge: %s", tr.MR.ID, tr.Result.Error)
+		}
+	}
+	if got := runGit(t, origin, "show", "main:file.txt"); got != "Y" {
+		t.Errorf("origin main:file.txt = %q, want %q", got, "Y")
+	}
+
+	// The train must not rewrite or push the MR branch.
+	if got := runGit(t, work, "rev-parse", "polecat/b"); got != before {
+		t.Errorf("local polecat/b moved: %s -> %s", before, got)
+	}
+	if got := runGit(t, origin, "rev-parse", "polecat/b"); got != before {
+		t.Errorf("origin polecat/b moved: %s -> %s", before, got)
+	}
+}
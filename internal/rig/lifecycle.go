@@ -0,0 +1,138 @@
+package rig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// HookEnv describes the worktree a lifecycle hook runs for. Fields that
+// don't apply to a phase are left empty and omitted from the environment.
+type HookEnv struct {
+	Rig      string // rig name
+	Polecat  string // polecat name
+	Bead     string // issue being worked
+	Branch   string // polecat branch
+	MR       string // merge-request bead (pre-merge, post-merge)
+	Target   string // merge target branch (pre-merge, post-merge)
+	Worktree string // working directory the hook runs in
+}
+
+// HookError reports a lifecycle hook with on_failure "fail" that failed or
+// timed out. The operation that ran the phase should stop.
+type HookError struct {
+	Phase string
+	Hook  string
+	Err   error
+}
+
+func (e *HookError) Error() string {
+	return fmt.Sprintf("%s hook %q failed: %v", e.Phase, e.Hook, e.Err)
+}
+
+func (e *HookError) Unwrap() error { return e.Err }
+
+// LifecycleHooks returns the hooks configured for phase in the rig's
+// settings/config.json, or nil if there are none.
+func LifecycleHooks(rigPath, phase string) ([]config.LifecycleHook, error) {
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(rigPath))
+	if err != nil {
+		if errors.Is(err, config.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return settings.LifecycleHooks[phase], nil
+}
+
+// RunLifecycleHooks runs the rig's hooks for a lifecycle phase in order,
+// each with sh -c in env.Worktree (the rig directory if empty).
+//
+// Each hook sees the usual environment plus:
+//
+//	GT_HOOK_PHASE     the phase (e.g. "pre-done")
+//	GT_HOOK_RIG       rig name
+//	GT_HOOK_POLECAT   polecat name
+//	GT_HOOK_BEAD      issue being worked
+//	GT_HOOK_BRANCH    polecat branch
+//	GT_HOOK_MR        merge-request bead (pre-merge, post-merge)
+//	GT_HOOK_TARGET    merge target branch (pre-merge, post-merge)
+//	GT_WORKTREE_PATH  the hook's working directory
+//	GT_RIG_PATH       rig directory
+//
+// A hook that fails or outlives its timeout is reported according to its
+// on_failure policy: "warn" prints a warning and moves on to the next hook,
+// "fail" stops and returns a *HookError. Hook output goes to out.
+func RunLifecycleHooks(rigPath, phase string, env HookEnv, out io.Writer) error {
+	hooks, err := LifecycleHooks(rigPath, phase)
+	if err != nil {
+		return fmt.Errorf("loading %s hooks: %w", phase, err)
+	}
+
+	for _, h := range hooks {
+		name := h.Name
+		if name == "" {
+			name = h.Command
+		}
+		start := time.Now()
+		if err := runLifecycleHook(rigPath, phase, h, env, out); err != nil {
+			if h.OnFailure == config.HookOnFailureFail {
+				return &HookError{Phase: phase, Hook: name, Err: err}
+			}
+			_, _ = fmt.Fprintf(out, "Warning: %s hook %q failed: %v\n", phase, name, err)
+			continue
+		}
+		_, _ = fmt.Fprintf(out, "Ran %s hook: %s (%s)\n", phase, name, time.Since(start).Round(time.Millisecond))
+	}
+	return nil
+}
+
+func runLifecycleHook(rigPath, phase string, h config.LifecycleHook, env HookEnv, out io.Writer) error {
+	timeout := config.DefaultLifecycleHookTimeout
+	if h.Timeout != "" {
+		d, err := time.ParseDuration(h.Timeout)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid timeout %q", h.Timeout)
+		}
+		timeout = d
+	}
+	dir := env.Worktree
+	if dir == "" {
+		dir = rigPath
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// Hook commands come from the rig's settings (trusted infrastructure config).
+	cmd := exec.CommandContext(ctx, "sh", "-c", h.Command) //nolint:gosec // G204: command is from trusted rig settings
+	cmd.Dir = dir
+	cmd.Stdout = out
+	cmd.Stderr = out
+	cmd.WaitDelay = time.Second // don't wait on orphaned children holding the pipes
+	cmd.Env = append(os.Environ(), "GT_HOOK_PHASE="+phase, "GT_WORKTREE_PATH="+dir, "GT_RIG_PATH="+rigPath)
+	for key, value := range map[string]string{
+		"GT_HOOK_RIG":     env.Rig,
+		"GT_HOOK_POLECAT": env.Polecat,
+		"GT_HOOK_BEAD":    env.Bead,
+		"GT_HOOK_BRANCH":  env.Branch,
+		"GT_HOOK_MR":      env.MR,
+		"GT_HOOK_TARGET":  env.Target,
+	} {
+		if value != "" {
+			cmd.Env = append(cmd.Env, key+"="+value)
+		}
+	}
+
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+	return err
+}
@@ -0,0 +1,86 @@
+package rig
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func writeHooks(t *testing.T, rigPath string, hooks map[string][]config.LifecycleHook) {
+	t.Helper()
+	settings := config.NewRigSettings()
+	settings.LifecycleHooks = hooks
+	if err := config.SaveRigSettings(config.RigSettingsPath(rigPath), settings); err != nil {
+		t.Fatalf("SaveRigSettings: %v", err)
+	}
+}
+
+func TestRunLifecycleHooks(t *testing.T) {
+	rigPath := t.TempDir()
+	worktree := t.TempDir()
+	writeHooks(t, rigPath, map[string][]config.LifecycleHook{
+		config.HookPreDone: {
+			{Name: "env", Command: `echo "$GT_HOOK_PHASE $GT_HOOK_POLECAT $GT_HOOK_BEAD $GT_HOOK_BRANCH [$GT_HOOK_MR]" > env.txt`},
+			{Name: "flaky", Command: "exit 3"},
+			{Name: "lint", Command: "echo lint failed; exit 1", OnFailure: config.HookOnFailureFail},
+			{Name: "never", Command: "touch never.txt"},
+		},
+		config.HookPostMerge: {
+			{Name: "slow", Command: "exec sleep 5", Timeout: "100ms", OnFailure: config.HookOnFailureFail},
+		},
+	})
+
+	var out bytes.Buffer
+	env := HookEnv{Rig: "gastown", Polecat: "nux", Bead: "gt-abc", Branch: "polecat/nux/gt-abc", Worktree: worktree}
+	err := RunLifecycleHooks(rigPath, config.HookPreDone, env, &out)
+
+	var hookErr *HookError
+	if !errors.As(err, &hookErr) || hookErr.Hook != "lint" || hookErr.Phase != config.HookPreDone {
+		t.Fatalf("err = %v, want lint HookError", err)
+	}
+	data, _ := os.ReadFile(filepath.Join(worktree, "env.txt"))
+	if got := strings.TrimSpace(string(data)); got != "pre-done nux gt-abc polecat/nux/gt-abc []" {
+		t.Errorf("hook env = %q", got)
+	}
+	if !strings.Contains(out.String(), `Warning: pre-done hook "flaky" failed`) || !strings.Contains(out.String(), "lint failed") {
+		t.Errorf("output = %q", out.String())
+	}
+	if _, err := os.Stat(filepath.Join(worktree, "never.txt")); err == nil {
+		t.Error("hook after a failing fail-policy hook ran")
+	}
+
+	// Timeouts count as failures.
+	err = RunLifecycleHooks(rigPath, config.HookPostMerge, HookEnv{Worktree: worktree}, &out)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("timeout err = %v", err)
+	}
+
+	// No hooks for the phase, or no settings at all.
+	if err := RunLifecycleHooks(rigPath, config.HookPreNuke, env, &out); err != nil {
+		t.Errorf("unconfigured phase: %v", err)
+	}
+	if err := RunLifecycleHooks(t.TempDir(), config.HookPreNuke, env, &out); err != nil {
+		t.Errorf("no settings: %v", err)
+	}
+}
+
+func TestLifecycleHooksValidation(t *testing.T) {
+	for _, hooks := range []map[string][]config.LifecycleHook{
+		{"pre-commit": {{Command: "true"}}},
+		{config.HookPreDone: {{Command: ""}}},
+		{config.HookPreDone: {{Command: "true", Timeout: "soon"}}},
+		{config.HookPreDone: {{Command: "true", Timeout: "0s"}}},
+		{config.HookPreDone: {{Command: "true", OnFailure: "ignore"}}},
+	} {
+		settings := config.NewRigSettings()
+		settings.LifecycleHooks = hooks
+		if err := config.SaveRigSettings(config.RigSettingsPath(t.TempDir()), settings); err == nil {
+			t.Errorf("expected validation error for %+v", hooks)
+		}
+	}
+}
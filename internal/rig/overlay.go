@@ -1,33 +1,69 @@
 package rig
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/gofrs/flock"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/util"
 )
 
-// CopyOverlay copies files from <rigPath>/.runtime/overlay/ to the destination path.
-// This allows storing gitignored files (like .env) that services need at their root.
-// The overlay is copied non-recursively - only files, not subdirectories.
-// File permissions from the source are preserved.
+// OverlayVars are the variables available to .tmpl overlay files, so that
+// parallel workers get their own ports, database names and so on.
+type OverlayVars struct {
+	Rig       string // rig name
+	Role      string // "polecat" or "crew"
+	Name      string // polecat or crew member name
+	Worktree  string // worktree the overlay is copied into
+	Slot      int    // worker's slot, unique among the rig's workers
+	Port      int    // first port of the worker's block
+	PortCount int    // number of ports in the block
+}
+
+// CopyOverlay copies <rigPath>/.runtime/overlay/ into the destination path,
+// recursively. This allows storing gitignored files (like .env or
+// .vscode/settings.json) that services need in the worktree.
+// File and directory permissions from the source are preserved.
 //
 // Structure:
 //
 //	rig/
 //	  .runtime/
 //	    overlay/
-//	      .env          <- Copied to destPath
-//	      config.json   <- Copied to destPath
+//	      .env              <- Copied to destPath/.env
+//	      config/
+//	        local.yaml.tmpl <- Rendered to destPath/config/local.yaml
+//
+// Files ending in .tmpl are rendered as Go templates with vars as data and
+// written without the suffix. Besides the OverlayVars fields, templates can
+// use {{port N}} for the Nth port of the worker's block and {{ident S}} to
+// turn S into a lowercase identifier usable as a database name:
+//
+//	PORT={{port 0}}
+//	DATABASE_URL=postgres://localhost/app_{{ident .Name}}
+//
+// secret://NAME placeholders in any overlay file are replaced with the
+// secret's value at copy time (see config.OverlayConfig), so the values
+// themselves are never stored under .runtime/.
 //
 // Returns nil if the overlay directory doesn't exist (nothing to copy).
 // Individual file copy failures are logged as warnings but don't stop the process.
-func CopyOverlay(rigPath, destPath string) error {
+func CopyOverlay(rigPath, destPath string, vars OverlayVars) error {
 	overlayDir := filepath.Join(rigPath, ".runtime", "overlay")
 
 	// Check if overlay directory exists
-	entries, err := os.ReadDir(overlayDir)
-	if err != nil {
+	if _, err := os.Stat(overlayDir); err != nil {
 		if os.IsNotExist(err) {
 			// No overlay directory - not an error, just nothing to copy
 			return nil
@@ -35,23 +71,81 @@ func CopyOverlay(rigPath, destPath string) error {
 		return fmt.Errorf("reading overlay dir: %w", err)
 	}
 
-	// Copy each file (not directories) from overlay to destination
-	for _, entry := range entries {
-		if entry.IsDir() {
-			// Skip subdirectories - only copy files at overlay root
-			continue
+	secrets := newSecretResolver(rigPath, overlayConfig(rigPath))
+	return filepath.WalkDir(overlayDir, func(srcPath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("reading overlay dir: %w", err)
+		}
+		rel, err := filepath.Rel(overlayDir, srcPath)
+		if err != nil || rel == "." {
+			return err
 		}
 
-		srcPath := filepath.Join(overlayDir, entry.Name())
-		dstPath := filepath.Join(destPath, entry.Name())
+		if entry.IsDir() {
+			info, err := entry.Info()
+			if err != nil {
+				return fmt.Errorf("reading overlay dir: %w", err)
+			}
+			if err := os.MkdirAll(filepath.Join(destPath, rel), info.Mode().Perm()); err != nil {
+				// Log warning but continue - don't fail spawn for overlay issues
+				fmt.Printf("Warning: could not create overlay directory %s: %v\n", rel, err)
+				return filepath.SkipDir
+			}
+			return nil
+		}
 
-		if err := copyFilePreserveMode(srcPath, dstPath); err != nil {
+		if err := copyOverlayFile(srcPath, filepath.Join(destPath, rel), vars, secrets); err != nil {
 			// Log warning but continue - don't fail spawn for overlay issues
-			fmt.Printf("Warning: could not copy overlay file %s: %v\n", entry.Name(), err)
-			continue
+			fmt.Printf("Warning: could not copy overlay file %s: %v\n", rel, err)
+		}
+		return nil
+	})
+}
+
+// CopyWorkerOverlay copies the overlay into a polecat's or crew member's
+// worktree, with variables from WorkerOverlayVars. Rigs without an overlay
+// are left alone, so no slot is handed out.
+func CopyWorkerOverlay(rigPath, role, name, destPath string) error {
+	if _, err := os.Stat(filepath.Join(rigPath, ".runtime", "overlay")); os.IsNotExist(err) {
+		return nil
+	}
+	vars, err := WorkerOverlayVars(rigPath, role, name, destPath)
+	if err != nil {
+		return err
+	}
+	return CopyOverlay(rigPath, destPath, vars)
+}
+
+// copyOverlayFile copies one overlay file, rendering .tmpl files and
+// resolving secret placeholders, preserving the source file's permissions.
+func copyOverlayFile(src, dst string, vars OverlayVars, secrets *secretResolver) error {
+	srcInfo, err := os.Stat(src)
+	if err != nil {
+		return fmt.Errorf("stat source: %w", err)
+	}
+	content, err := os.ReadFile(src) //nolint:gosec // G304: path is inside the rig's overlay dir
+	if err != nil {
+		return fmt.Errorf("read source: %w", err)
+	}
+
+	isTemplate := strings.HasSuffix(dst, ".tmpl")
+	if !isTemplate && !bytes.Contains(content, []byte(secretScheme)) {
+		return copyFilePreserveMode(src, dst)
+	}
+
+	if isTemplate {
+		dst = strings.TrimSuffix(dst, ".tmpl")
+		if content, err = renderOverlayTemplate(filepath.Base(src), content, vars); err != nil {
+			return err
 		}
 	}
+	if content, err = secrets.Replace(content); err != nil {
+		return err
+	}
 
+	if err := os.WriteFile(dst, content, srcInfo.Mode().Perm()); err != nil {
+		return fmt.Errorf("write destination: %w", err)
+	}
 	return nil
 }
 
@@ -84,3 +178,237 @@ func copyFilePreserveMode(src, dst string) error {
 
 	return nil
 }
+
+// renderOverlayTemplate executes a .tmpl overlay file.
+func renderOverlayTemplate(name string, content []byte, vars OverlayVars) ([]byte, error) {
+	funcs := template.FuncMap{
+		"port": func(n int) (int, error) {
+			if n < 0 || n >= vars.PortCount {
+				return 0, fmt.Errorf("port %d is outside the worker's block of %d", n, vars.PortCount)
+			}
+			return vars.Port + n, nil
+		},
+		"ident": overlayIdent,
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Funcs(funcs).Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("parse template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return nil, fmt.Errorf("render template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+var nonIdentRe = regexp.MustCompile(`[^a-z0-9]+`)
+
+// overlayIdent lowercases s and replaces runs of other characters with "_".
+func overlayIdent(s string) string {
+	return strings.Trim(nonIdentRe.ReplaceAllString(strings.ToLower(s), "_"), "_")
+}
+
+// overlayConfig returns the rig's overlay settings, or defaults.
+func overlayConfig(rigPath string) config.OverlayConfig {
+	var c config.OverlayConfig
+	if settings, err := config.LoadRigSettings(config.RigSettingsPath(rigPath)); err == nil && settings.Overlay != nil {
+		c = *settings.Overlay
+	}
+	if c.PortBase == 0 {
+		c.PortBase = config.DefaultOverlayPortBase
+	}
+	if c.PortStride == 0 {
+		c.PortStride = config.DefaultOverlayPortStride
+	}
+	return c
+}
+
+// secretScheme prefixes secret placeholders in overlay files.
+const secretScheme = "secret://"
+
+// secretRe matches secret://NAME placeholders.
+var secretRe = regexp.MustCompile(secretScheme + `([A-Za-z0-9_][A-Za-z0-9_./-]*)`)
+
+// secretResolver looks up secrets for one overlay copy, from the rig's
+// secrets file and then its secrets command. Values are cached so the
+// command runs once per secret.
+type secretResolver struct {
+	rigPath string
+	cfg     config.OverlayConfig
+	file    map[string]string // nil until loaded
+	cache   map[string]string
+}
+
+func newSecretResolver(rigPath string, cfg config.OverlayConfig) *secretResolver {
+	return &secretResolver{rigPath: rigPath, cfg: cfg, cache: make(map[string]string)}
+}
+
+// Replace replaces every secret://NAME placeholder in content. It fails
+// if any secret can't be resolved, so a file is never written half-done.
+func (r *secretResolver) Replace(content []byte) ([]byte, error) {
+	if !bytes.Contains(content, []byte(secretScheme)) {
+		return content, nil
+	}
+	var firstErr error
+	out := secretRe.ReplaceAllFunc(content, func(m []byte) []byte {
+		name := string(secretRe.FindSubmatch(m)[1])
+		value, err := r.Lookup(name)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			return m
+		}
+		return []byte(value)
+	})
+	return out, firstErr
+}
+
+// Lookup resolves one secret.
+func (r *secretResolver) Lookup(name string) (string, error) {
+	if value, ok := r.cache[name]; ok {
+		return value, nil
+	}
+
+	if r.file == nil && r.cfg.SecretsFile != "" {
+		path := r.cfg.SecretsFile
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(r.rigPath, path)
+		}
+		file, err := readEnvFile(path)
+		if err != nil {
+			return "", fmt.Errorf("reading secrets file: %w", err)
+		}
+		r.file = file
+	}
+	if value, ok := r.file[name]; ok {
+		r.cache[name] = value
+		return value, nil
+	}
+
+	if r.cfg.SecretsCommand == "" {
+		return "", fmt.Errorf("secret %q not found (set overlay.secrets_file or overlay.secrets_command in settings/config.json)", name)
+	}
+	// The command comes from the rig's settings (trusted infrastructure config).
+	cmd := exec.Command("sh", "-c", r.cfg.SecretsCommand) //nolint:gosec // G204: command is from trusted rig settings
+	cmd.Dir = r.rigPath
+	cmd.Env = append(os.Environ(), "GT_SECRET_NAME="+name)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("resolving secret %q: %v: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+	value := strings.TrimRight(string(out), "\r\n")
+	r.cache[name] = value
+	return value, nil
+}
+
+// readEnvFile parses NAME=value lines. Blank lines, # comments and an
+// "export " prefix are allowed; matching quotes around a value are removed.
+func readEnvFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is from trusted rig settings
+	if err != nil {
+		return nil, err
+	}
+	env := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		env[strings.TrimSpace(name)] = value
+	}
+	return env, nil
+}
+
+// overlaySlots is the persisted slot assignment, keyed by "role/name".
+type overlaySlots struct {
+	Slots map[string]int `json:"slots"`
+}
+
+func overlaySlotsPath(rigPath string) string {
+	return filepath.Join(rigPath, ".runtime", "overlay-slots.json")
+}
+
+// updateOverlaySlots runs fn on the rig's slot assignment under a file lock
+// and saves the result.
+func updateOverlaySlots(rigPath string, fn func(*overlaySlots)) error {
+	path := overlaySlotsPath(rigPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating runtime dir: %w", err)
+	}
+	lock := flock.New(path + ".lock")
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("locking overlay slots: %w", err)
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	state := &overlaySlots{}
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is in the rig's runtime dir
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("reading overlay slots: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, state); err != nil {
+			return fmt.Errorf("parsing overlay slots: %w", err)
+		}
+	}
+	if state.Slots == nil {
+		state.Slots = make(map[string]int)
+	}
+	fn(state)
+	return util.AtomicWriteJSON(path, state)
+}
+
+// WorkerOverlayVars returns the overlay variables for a worker, assigning
+// it a slot (and so a port block) if it doesn't have one. A worker keeps
+// its slot until ReleaseOverlaySlot, so a respawned polecat gets the same
+// ports back.
+func WorkerOverlayVars(rigPath, role, name, worktree string) (OverlayVars, error) {
+	vars := OverlayVars{Rig: filepath.Base(rigPath), Role: role, Name: name, Worktree: worktree}
+	key := role + "/" + name
+	err := updateOverlaySlots(rigPath, func(state *overlaySlots) {
+		if slot, ok := state.Slots[key]; ok {
+			vars.Slot = slot
+			return
+		}
+		used := make(map[int]bool, len(state.Slots))
+		for _, slot := range state.Slots {
+			used[slot] = true
+		}
+		for used[vars.Slot] {
+			vars.Slot++
+		}
+		state.Slots[key] = vars.Slot
+	})
+	if err != nil {
+		return vars, err
+	}
+
+	cfg := overlayConfig(rigPath)
+	vars.Port = cfg.PortBase + vars.Slot*cfg.PortStride
+	vars.PortCount = cfg.PortStride
+	if vars.Port+vars.PortCount-1 > 65535 {
+		return vars, fmt.Errorf("slot %d's port block starts at %d, past the end of the port range", vars.Slot, vars.Port)
+	}
+	return vars, nil
+}
+
+// ReleaseOverlaySlot frees a worker's slot when its worktree is removed.
+func ReleaseOverlaySlot(rigPath, role, name string) error {
+	if _, err := os.Stat(overlaySlotsPath(rigPath)); err != nil {
+		return nil // no slots handed out
+	}
+	return updateOverlaySlots(rigPath, func(state *overlaySlots) {
+		delete(state.Slots, role+"/"+name)
+	})
+}
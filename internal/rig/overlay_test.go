@@ -4,6 +4,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
 )
 
 func TestCopyOverlay_NoOverlayDirectory(t *testing.T) {
@@ -11,7 +13,7 @@ func TestCopyOverlay_NoOverlayDirectory(t *testing.T) {
 	destDir := t.TempDir()
 
 	// No overlay directory exists
-	err := CopyOverlay(tmpDir, destDir)
+	err := CopyOverlay(tmpDir, destDir, OverlayVars{})
 	if err != nil {
 		t.Errorf("CopyOverlay() with no overlay directory should return nil, got %v", err)
 	}
@@ -39,7 +41,7 @@ func TestCopyOverlay_CopiesFiles(t *testing.T) {
 	}
 
 	// Copy overlay
-	err := CopyOverlay(rigDir, destDir)
+	err := CopyOverlay(rigDir, destDir, OverlayVars{})
 	if err != nil {
 		t.Fatalf("CopyOverlay() error = %v", err)
 	}
@@ -81,7 +83,7 @@ func TestCopyOverlay_PreservesPermissions(t *testing.T) {
 	}
 
 	// Copy overlay
-	err := CopyOverlay(rigDir, destDir)
+	err := CopyOverlay(rigDir, destDir, OverlayVars{})
 	if err != nil {
 		t.Fatalf("CopyOverlay() error = %v", err)
 	}
@@ -98,51 +100,125 @@ func TestCopyOverlay_PreservesPermissions(t *testing.T) {
 	}
 }
 
-func TestCopyOverlay_SkipsSubdirectories(t *testing.T) {
+func TestCopyOverlay_CopiesSubdirectories(t *testing.T) {
 	rigDir := t.TempDir()
 	destDir := t.TempDir()
 
-	// Create overlay directory with a subdirectory
+	// Create overlay directory with nested subdirectories
 	overlayDir := filepath.Join(rigDir, ".runtime", "overlay")
-	if err := os.MkdirAll(overlayDir, 0755); err != nil {
-		t.Fatalf("Failed to create overlay dir: %v", err)
-	}
-
-	// Create a subdirectory
-	subDir := filepath.Join(overlayDir, "subdir")
+	subDir := filepath.Join(overlayDir, "config", "local")
 	if err := os.MkdirAll(subDir, 0755); err != nil {
 		t.Fatalf("Failed to create subdirectory: %v", err)
 	}
-
-	// Create a file in the overlay root
-	testFile := filepath.Join(overlayDir, "test.txt")
-	if err := os.WriteFile(testFile, []byte("content"), 0644); err != nil {
+	if err := os.WriteFile(filepath.Join(overlayDir, "test.txt"), []byte("content"), 0644); err != nil {
 		t.Fatalf("Failed to create test file: %v", err)
 	}
-
-	// Create a file in the subdirectory
-	subFile := filepath.Join(subDir, "sub.txt")
-	if err := os.WriteFile(subFile, []byte("subcontent"), 0644); err != nil {
+	if err := os.WriteFile(filepath.Join(subDir, "sub.txt"), []byte("subcontent"), 0644); err != nil {
 		t.Fatalf("Failed to create sub file: %v", err)
 	}
 
 	// Copy overlay
-	err := CopyOverlay(rigDir, destDir)
+	err := CopyOverlay(rigDir, destDir, OverlayVars{})
 	if err != nil {
 		t.Fatalf("CopyOverlay() error = %v", err)
 	}
 
-	// Verify root file was copied
+	// Verify root and nested files were copied
 	if _, err := os.Stat(filepath.Join(destDir, "test.txt")); err != nil {
 		t.Error("Root file should be copied")
 	}
+	content, err := os.ReadFile(filepath.Join(destDir, "config", "local", "sub.txt"))
+	if err != nil || string(content) != "subcontent" {
+		t.Errorf("Nested file = %q, %v", content, err)
+	}
+}
+
+func TestCopyOverlay_RendersTemplatesAndSecrets(t *testing.T) {
+	rigDir := t.TempDir()
+	destDir := t.TempDir()
+
+	overlayDir := filepath.Join(rigDir, ".runtime", "overlay")
+	if err := os.MkdirAll(filepath.Join(overlayDir, ".vscode"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		".env.tmpl":             "PORT={{port 0}}\nDB=app_{{ident .Rig}}_{{ident .Name}}\nKEY=secret://API_KEY\n",
+		".vscode/settings.json": `{"token": "secret://deploy/token"}`,
+		"bad.tmpl":              "{{port 10}}",
+		"missing.txt":           "secret://NOPE",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(overlayDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(rigDir, "secrets.env"), []byte("# local secrets\nexport API_KEY=\"k-123\"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	settings := config.NewRigSettings()
+	settings.Overlay = &config.OverlayConfig{
+		SecretsFile:    "secrets.env",
+		SecretsCommand: `[ "$GT_SECRET_NAME" = deploy/token ] && echo t-456`,
+	}
+	if err := config.SaveRigSettings(config.RigSettingsPath(rigDir), settings); err != nil {
+		t.Fatal(err)
+	}
+
+	vars := OverlayVars{Rig: "my-app", Name: "Nux", Port: 20010, PortCount: 10}
+	if err := CopyOverlay(rigDir, destDir, vars); err != nil {
+		t.Fatalf("CopyOverlay() error = %v", err)
+	}
+
+	env, err := os.ReadFile(filepath.Join(destDir, ".env"))
+	if err != nil {
+		t.Fatalf("rendered .env: %v", err)
+	}
+	if want := "PORT=20010\nDB=app_my_app_nux\nKEY=k-123\n"; string(env) != want {
+		t.Errorf(".env = %q, want %q", env, want)
+	}
+	if _, err := os.Stat(filepath.Join(destDir, ".env.tmpl")); err == nil {
+		t.Error(".tmpl file should be written without its suffix")
+	}
+	vscode, _ := os.ReadFile(filepath.Join(destDir, ".vscode", "settings.json"))
+	if string(vscode) != `{"token": "t-456"}` {
+		t.Errorf("settings.json = %q", vscode)
+	}
 
-	// Verify subdirectory was NOT copied
-	if _, err := os.Stat(filepath.Join(destDir, "subdir")); err == nil {
-		t.Error("Subdirectory should not be copied")
+	// Port outside the block and unresolvable secrets skip the file.
+	for _, name := range []string{"bad", "missing.txt"} {
+		if _, err := os.Stat(filepath.Join(destDir, name)); err == nil {
+			t.Errorf("%s should not be written", name)
+		}
+	}
+}
+
+func TestWorkerOverlayVars(t *testing.T) {
+	rigDir := filepath.Join(t.TempDir(), "gastown")
+	settings := config.NewRigSettings()
+	settings.Overlay = &config.OverlayConfig{PortBase: 30000, PortStride: 5}
+	if err := config.SaveRigSettings(config.RigSettingsPath(rigDir), settings); err != nil {
+		t.Fatal(err)
+	}
+
+	nux, err := WorkerOverlayVars(rigDir, "polecat", "nux", "/wt/nux")
+	if err != nil {
+		t.Fatalf("WorkerOverlayVars() error = %v", err)
+	}
+	slit, _ := WorkerOverlayVars(rigDir, "polecat", "slit", "/wt/slit")
+	joe, _ := WorkerOverlayVars(rigDir, "crew", "joe", "/wt/joe")
+	if nux.Port != 30000 || slit.Port != 30005 || joe.Port != 30010 || nux.PortCount != 5 || nux.Rig != "gastown" {
+		t.Errorf("ports = %d, %d, %d (%+v)", nux.Port, slit.Port, joe.Port, nux)
+	}
+
+	// A respawn keeps its slot; a released slot is reused.
+	if again, _ := WorkerOverlayVars(rigDir, "polecat", "nux", "/wt/nux"); again.Slot != nux.Slot {
+		t.Errorf("respawned nux got slot %d, want %d", again.Slot, nux.Slot)
+	}
+	if err := ReleaseOverlaySlot(rigDir, "polecat", "slit"); err != nil {
+		t.Fatal(err)
 	}
-	if _, err := os.Stat(filepath.Join(destDir, "subdir", "sub.txt")); err == nil {
-		t.Error("File in subdirectory should not be copied")
+	if rictus, _ := WorkerOverlayVars(rigDir, "polecat", "rictus", "/wt/rictus"); rictus.Slot != slit.Slot {
+		t.Errorf("rictus got slot %d, want released slot %d", rictus.Slot, slit.Slot)
 	}
 }
 
@@ -157,7 +233,7 @@ func TestCopyOverlay_EmptyOverlay(t *testing.T) {
 	}
 
 	// Copy overlay
-	err := CopyOverlay(rigDir, destDir)
+	err := CopyOverlay(rigDir, destDir, OverlayVars{})
 	if err != nil {
 		t.Fatalf("CopyOverlay() error = %v", err)
 	}
@@ -187,7 +263,7 @@ func TestCopyOverlay_OverwritesExisting(t *testing.T) {
 	}
 
 	// Copy overlay
-	err := CopyOverlay(rigDir, destDir)
+	err := CopyOverlay(rigDir, destDir, OverlayVars{})
 	if err != nil {
 		t.Fatalf("CopyOverlay() error = %v", err)
 	}
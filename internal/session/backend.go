@@ -0,0 +1,83 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/headless"
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+// SessionBackend is the set of session operations the session managers
+// need. *tmux.Tmux is the default implementation; *headless.Backend runs
+// sessions on a PTY without tmux (containers, CI). Tests can supply a fake.
+//
+// Tmux-only extras (theming, status line, pane-died hooks) are applied by
+// type-asserting to *tmux.Tmux.
+type SessionBackend interface {
+	// NewSessionWithCommand starts a detached session running command.
+	NewSessionWithCommand(name, workDir, command string) error
+
+	// HasSession reports whether the session exists.
+	HasSession(name string) (bool, error)
+
+	// ListSessions returns the names of all sessions.
+	ListSessions() ([]string, error)
+
+	// SetEnvironment sets a session-level environment variable.
+	SetEnvironment(session, key, value string) error
+
+	// GetEnvironment reads a session-level environment variable.
+	GetEnvironment(session, key string) (string, error)
+
+	// SendKeys types keys into the session and presses Enter.
+	SendKeys(session, keys string) error
+
+	// NudgeSession reliably delivers a message to the agent's prompt.
+	NudgeSession(session, message string) error
+
+	// CapturePane returns the last lines of session output as plain text.
+	CapturePane(session string, lines int) (string, error)
+
+	// IsAgentRunning reports whether the session's foreground command is
+	// one of expectedPaneCommands (or any non-shell if none are given).
+	IsAgentRunning(session string, expectedPaneCommands ...string) bool
+
+	// KillSession terminates the session.
+	KillSession(name string) error
+
+	// KillSessionWithProcesses terminates the session and every process in it.
+	KillSessionWithProcesses(name string) error
+}
+
+// BackendEnv selects the session backend: "tmux" (default) or "headless".
+const BackendEnv = "GT_SESSION_BACKEND"
+
+// NewBackend returns the session backend selected by GT_SESSION_BACKEND.
+func NewBackend() SessionBackend {
+	if os.Getenv(BackendEnv) == "headless" {
+		return headless.NewBackend("")
+	}
+	return tmux.NewTmux()
+}
+
+// Verify the backends implement SessionBackend.
+var (
+	_ SessionBackend = (*tmux.Tmux)(nil)
+	_ SessionBackend = (*headless.Backend)(nil)
+)
+
+// WaitForAgent polls until the session's foreground command is no longer a
+// shell. *tmux.Tmux callers can use WaitForCommand instead.
+func WaitForAgent(b SessionBackend, name string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if b.IsAgentRunning(name) {
+			return nil
+		}
+		time.Sleep(constants.PollInterval)
+	}
+	return fmt.Errorf("timeout waiting for agent in session %s", name)
+}
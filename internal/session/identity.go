@@ -142,6 +142,38 @@ func (a *AgentIdentity) Address() string {
 	}
 }
 
+// ParseAddress parses a mail-style address into an AgentIdentity. It
+// accepts the forms Address returns, a trailing slash on mayor/deacon, and
+// the short polecat form "<rig>/<name>".
+func ParseAddress(address string) (*AgentIdentity, error) {
+	switch strings.TrimSuffix(address, "/") {
+	case "mayor":
+		return &AgentIdentity{Role: RoleMayor}, nil
+	case "deacon":
+		return &AgentIdentity{Role: RoleDeacon}, nil
+	}
+
+	parts := strings.Split(address, "/")
+	for _, p := range parts {
+		if p == "" {
+			return nil, fmt.Errorf("invalid address %q", address)
+		}
+	}
+	switch {
+	case len(parts) == 2 && parts[1] == "witness":
+		return &AgentIdentity{Role: RoleWitness, Rig: parts[0]}, nil
+	case len(parts) == 2 && parts[1] == "refinery":
+		return &AgentIdentity{Role: RoleRefinery, Rig: parts[0]}, nil
+	case len(parts) == 2:
+		return &AgentIdentity{Role: RolePolecat, Rig: parts[0], Name: parts[1]}, nil
+	case len(parts) == 3 && parts[1] == "crew":
+		return &AgentIdentity{Role: RoleCrew, Rig: parts[0], Name: parts[2]}, nil
+	case len(parts) == 3 && parts[1] == "polecats":
+		return &AgentIdentity{Role: RolePolecat, Rig: parts[0], Name: parts[2]}, nil
+	}
+	return nil, fmt.Errorf("invalid address %q", address)
+}
+
 // GTRole returns the GT_ROLE environment variable format.
 // This is the same as Address() for most roles.
 func (a *AgentIdentity) GTRole() string {
@@ -227,6 +227,38 @@ func TestAgentIdentity_Address(t *testing.T) {
 	}
 }
 
+func TestParseAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		want    string // session name
+	}{
+		{"mayor", "hq-mayor"},
+		{"mayor/", "hq-mayor"},
+		{"deacon/", "hq-deacon"},
+		{"gastown/witness", "gt-gastown-witness"},
+		{"my-project/refinery", "gt-my-project-refinery"},
+		{"gastown/crew/max", "gt-gastown-crew-max"},
+		{"gastown/polecats/Toast", "gt-gastown-Toast"},
+		{"gastown/Toast", "gt-gastown-Toast"},
+	}
+	for _, tt := range tests {
+		identity, err := ParseAddress(tt.address)
+		if err != nil {
+			t.Errorf("ParseAddress(%q) error = %v", tt.address, err)
+			continue
+		}
+		if got := identity.SessionName(); got != tt.want {
+			t.Errorf("ParseAddress(%q).SessionName() = %q, want %q", tt.address, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "overseer", "gastown/", "a/b/c", "a/b/c/d"} {
+		if _, err := ParseAddress(bad); err == nil {
+			t.Errorf("ParseAddress(%q) should fail", bad)
+		}
+	}
+}
+
 func TestParseSessionName_RoundTrip(t *testing.T) {
 	// Test that parsing then reconstructing gives the same result
 	sessions := []string{
@@ -0,0 +1,301 @@
+package templates
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Template kinds, which are also their directory names.
+const (
+	KindRole    = "roles"
+	KindMessage = "messages"
+)
+
+// Template sources, in lookup order.
+const (
+	SourceRig      = "rig"
+	SourceTown     = "town"
+	SourceEmbedded = "embedded"
+)
+
+const templateExt = ".md.tmpl"
+
+// Info describes where a template comes from.
+type Info struct {
+	Name   string `json:"name"`
+	Kind   string `json:"kind"`
+	Source string `json:"source"`
+	Path   string `json:"path,omitempty"` // override file; empty when embedded
+
+	// Ejected is the embedded template's hash recorded when the override was
+	// ejected, empty for overrides written by hand.
+	Ejected string `json:"ejected,omitempty"`
+
+	// Current is the embedded template's hash now, set for ejected
+	// overrides.
+	Current string `json:"current,omitempty"`
+
+	// Stale is set when the embedded template has changed since the
+	// override was ejected from it.
+	Stale bool `json:"stale,omitempty"`
+}
+
+// File returns the template's path relative to a templates directory,
+// e.g. "roles/polecat.md.tmpl".
+func (i Info) File() string {
+	return i.Kind + "/" + i.Name + templateExt
+}
+
+// TownOverrideDir returns the directory holding a town's template overrides.
+func TownOverrideDir(townRoot string) string {
+	return filepath.Join(townRoot, "settings", "templates")
+}
+
+// RigOverrideDir returns the directory holding a rig's template overrides.
+func RigOverrideDir(rigPath string) string {
+	return filepath.Join(rigPath, ".runtime", "templates")
+}
+
+// ejectHeaderRe matches the first line of an ejected template.
+var ejectHeaderRe = regexp.MustCompile(`^\{\{/\* gt template eject: (\S+) sha256:([0-9a-f]+)\b.*\*/ -\}\}\n`)
+
+// ejectHeader is written as the first line of an ejected template. It
+// renders to nothing and records which embedded version the copy came from.
+func ejectHeader(file, hash string) string {
+	return fmt.Sprintf("{{/* gt template eject: %s sha256:%s - keep this line so gt doctor can spot upstream changes */ -}}\n", file, hash)
+}
+
+// embeddedHash returns a short hash identifying an embedded template version.
+func embeddedHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// Names returns the embedded template names of a kind, sorted.
+func Names(kind string) ([]string, error) {
+	entries, err := templateFS.ReadDir(kind)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s directory: %w", kind, err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if name, ok := strings.CutSuffix(entry.Name(), templateExt); ok && !entry.IsDir() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// kindOf finds the kind of an embedded template. The name may be given as
+// "polecat", "roles/polecat" or "roles/polecat.md.tmpl".
+func kindOf(name string) (kind, base string, err error) {
+	name = strings.TrimSuffix(name, templateExt)
+	if k, n, ok := strings.Cut(name, "/"); ok {
+		if _, err := templateFS.ReadFile(k + "/" + n + templateExt); err != nil {
+			return "", "", fmt.Errorf("unknown template %q", name)
+		}
+		return k, n, nil
+	}
+	for _, k := range []string{KindRole, KindMessage} {
+		if _, err := templateFS.ReadFile(k + "/" + name + templateExt); err == nil {
+			return k, name, nil
+		}
+	}
+	return "", "", fmt.Errorf("unknown template %q", name)
+}
+
+// Embedded returns the embedded copy of a template.
+func Embedded(name string) (Info, []byte, error) {
+	kind, base, err := kindOf(name)
+	if err != nil {
+		return Info{}, nil, err
+	}
+	info := Info{Name: base, Kind: kind, Source: SourceEmbedded}
+	content, err := templateFS.ReadFile(info.File())
+	if err != nil {
+		return Info{}, nil, err
+	}
+	return info, content, nil
+}
+
+// Lookup resolves a template through the override chain: the rig's
+// .runtime/templates/, then the town's settings/templates/, then the
+// embedded copy. Either root may be empty to skip that level.
+func Lookup(townRoot, rigPath, name string) (Info, error) {
+	info, embedded, err := Embedded(name)
+	if err != nil {
+		return Info{}, err
+	}
+
+	type level struct{ source, dir string }
+	var levels []level
+	if rigPath != "" {
+		levels = append(levels, level{SourceRig, RigOverrideDir(rigPath)})
+	}
+	if townRoot != "" {
+		levels = append(levels, level{SourceTown, TownOverrideDir(townRoot)})
+	}
+
+	for _, l := range levels {
+		path := filepath.Join(l.dir, info.Kind, info.Name+templateExt)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		info.Source = l.source
+		info.Path = path
+		if err := readEjectHeader(&info, embedded); err != nil {
+			return Info{}, err
+		}
+		return info, nil
+	}
+	return info, nil
+}
+
+// List resolves every embedded template through the override chain, roles
+// first.
+func List(townRoot, rigPath string) ([]Info, error) {
+	var infos []Info
+	for _, kind := range []string{KindRole, KindMessage} {
+		names, err := Names(kind)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			info, err := Lookup(townRoot, rigPath, kind+"/"+name)
+			if err != nil {
+				return nil, err
+			}
+			infos = append(infos, info)
+		}
+	}
+	return infos, nil
+}
+
+// Overrides returns the overrides in one templates directory (see
+// TownOverrideDir and RigOverrideDir) for templates gt knows about.
+func Overrides(dir, source string) ([]Info, error) {
+	var infos []Info
+	for _, kind := range []string{KindRole, KindMessage} {
+		names, err := Names(kind)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			info, embedded, err := Embedded(kind + "/" + name)
+			if err != nil {
+				return nil, err
+			}
+			info.Source = source
+			info.Path = filepath.Join(dir, kind, name+templateExt)
+			if _, err := os.Stat(info.Path); err != nil {
+				continue
+			}
+			if err := readEjectHeader(&info, embedded); err != nil {
+				return nil, err
+			}
+			infos = append(infos, info)
+		}
+	}
+	return infos, nil
+}
+
+// readEjectHeader fills in Ejected and Stale from the override's first line.
+func readEjectHeader(info *Info, embedded []byte) error {
+	content, err := os.ReadFile(info.Path)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", info.Path, err)
+	}
+	if m := ejectHeaderRe.FindSubmatch(content); m != nil {
+		info.Ejected = string(m[2])
+		info.Current = embeddedHash(embedded)
+		info.Stale = info.Ejected != info.Current
+	}
+	return nil
+}
+
+// Content returns a template's text. With stripHeader, an ejected
+// override's provenance line is left out so it compares cleanly with the
+// embedded copy.
+func Content(info Info, stripHeader bool) ([]byte, error) {
+	if info.Path == "" {
+		return templateFS.ReadFile(info.File())
+	}
+	content, err := os.ReadFile(info.Path)
+	if err != nil {
+		return nil, err
+	}
+	if stripHeader {
+		if loc := ejectHeaderRe.FindIndex(content); loc != nil {
+			content = content[loc[1]:]
+		}
+	}
+	return content, nil
+}
+
+// ErrOverrideExists is returned by Eject when the override file is already
+// there.
+var ErrOverrideExists = errors.New("override already exists")
+
+// Eject copies an embedded template into dir (see TownOverrideDir and
+// RigOverrideDir) for editing. The copy starts with a header line recording
+// the embedded version, which gt doctor uses to warn when gt ships a newer
+// one. An existing override is only replaced with force.
+func Eject(dir, name string, force bool) (string, error) {
+	info, embedded, err := Embedded(name)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, info.Kind, info.Name+templateExt)
+	if _, err := os.Stat(path); err == nil && !force {
+		return path, fmt.Errorf("%s: %w", path, ErrOverrideExists)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("creating %s: %w", filepath.Dir(path), err)
+	}
+	content := ejectHeader(info.File(), embeddedHash(embedded)) + string(embedded)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil { //nolint:gosec // G306: template files are non-sensitive
+		return "", fmt.Errorf("writing %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// NewWithOverrides creates a Templates instance whose templates are
+// resolved through the override chain (see Lookup). rigPath is empty
+// outside a rig.
+func NewWithOverrides(townRoot, rigPath string) (*Templates, error) {
+	t, err := New()
+	if err != nil {
+		return nil, err
+	}
+
+	infos, err := List(townRoot, rigPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, info := range infos {
+		if info.Source == SourceEmbedded {
+			continue
+		}
+		content, err := os.ReadFile(info.Path)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", info.Path, err)
+		}
+		set := t.roleTemplates
+		if info.Kind == KindMessage {
+			set = t.messageTemplates
+		}
+		// Parsing a template under an existing name replaces it in the set.
+		if _, err := set.New(info.Name + templateExt).Parse(string(content)); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", info.Path, err)
+		}
+	}
+	return t, nil
+}
@@ -137,9 +137,10 @@ func (t *Templates) MessageNames() []string {
 }
 
 // CreateMayorCLAUDEmd creates the Mayor's CLAUDE.md file at the specified directory.
-// This is used by both gt install and gt doctor --fix.
+// This is used by both gt install and gt doctor --fix. A town override of
+// the mayor template is honored.
 func CreateMayorCLAUDEmd(mayorDir, townRoot, townName, mayorSession, deaconSession string) error {
-	tmpl, err := New()
+	tmpl, err := NewWithOverrides(townRoot, "")
 	if err != nil {
 		return err
 	}
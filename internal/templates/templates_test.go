@@ -1,6 +1,9 @@
 package templates
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -295,3 +298,107 @@ func TestGetAllRoleTemplates_ContentValidity(t *testing.T) {
 		}
 	}
 }
+
+func TestNewWithOverrides(t *testing.T) {
+	townRoot := t.TempDir()
+	rigPath := t.TempDir()
+
+	// Town override for polecat and nudge, rig override for polecat only.
+	writeOverride := func(dir, file, content string) {
+		t.Helper()
+		path := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeOverride(TownOverrideDir(townRoot), "roles/polecat.md.tmpl", "town polecat {{.Polecat}}")
+	writeOverride(TownOverrideDir(townRoot), "messages/nudge.md.tmpl", "town nudge {{.Polecat}}")
+	writeOverride(RigOverrideDir(rigPath), "roles/polecat.md.tmpl", "rig polecat {{.Polecat}}")
+
+	tmpl, err := NewWithOverrides(townRoot, rigPath)
+	if err != nil {
+		t.Fatalf("NewWithOverrides() error = %v", err)
+	}
+	if out, _ := tmpl.RenderRole("polecat", RoleData{Polecat: "nux"}); out != "rig polecat nux" {
+		t.Errorf("polecat = %q, want rig override", out)
+	}
+	if out, _ := tmpl.RenderMessage("nudge", NudgeData{Polecat: "nux"}); out != "town nudge nux" {
+		t.Errorf("nudge = %q, want town override", out)
+	}
+	if out, _ := tmpl.RenderRole("mayor", RoleData{TownRoot: "/test/town"}); !strings.Contains(out, "Mayor Context") {
+		t.Error("mayor should fall through to the embedded template")
+	}
+
+	// Outside the rig, the town override wins.
+	tmpl, err = NewWithOverrides(townRoot, "")
+	if err != nil {
+		t.Fatalf("NewWithOverrides() error = %v", err)
+	}
+	if out, _ := tmpl.RenderRole("polecat", RoleData{Polecat: "nux"}); out != "town polecat nux" {
+		t.Errorf("polecat = %q, want town override", out)
+	}
+
+	// A broken override is reported with its path.
+	writeOverride(RigOverrideDir(rigPath), "roles/polecat.md.tmpl", "{{.Polecat")
+	if _, err := NewWithOverrides(townRoot, rigPath); err == nil || !strings.Contains(err.Error(), rigPath) {
+		t.Errorf("expected parse error naming the override, got %v", err)
+	}
+}
+
+func TestEject(t *testing.T) {
+	townRoot := t.TempDir()
+	dir := TownOverrideDir(townRoot)
+
+	path, err := Eject(dir, "polecat", false)
+	if err != nil {
+		t.Fatalf("Eject() error = %v", err)
+	}
+	if _, err := Eject(dir, "roles/polecat", false); !errors.Is(err, ErrOverrideExists) {
+		t.Errorf("second Eject() error = %v, want ErrOverrideExists", err)
+	}
+	if _, err := Eject(dir, "nosuch", false); err == nil {
+		t.Error("expected error ejecting an unknown template")
+	}
+
+	// The header renders to nothing: the ejected copy renders like the original.
+	data := RoleData{Role: "polecat", RigName: "myrig", Polecat: "nux", TownRoot: "/test/town"}
+	embedded, _ := New()
+	want, _ := embedded.RenderRole("polecat", data)
+	tmpl, err := NewWithOverrides(townRoot, "")
+	if err != nil {
+		t.Fatalf("NewWithOverrides() error = %v", err)
+	}
+	if got, _ := tmpl.RenderRole("polecat", data); got != want {
+		t.Error("ejected template renders differently from the embedded one")
+	}
+
+	info, err := Lookup(townRoot, "", "polecat")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if info.Source != SourceTown || info.Path != path || info.Ejected == "" || info.Stale {
+		t.Errorf("Lookup() = %+v", info)
+	}
+	content, _ := Content(info, true)
+	_, original, _ := Embedded("polecat")
+	if string(content) != string(original) {
+		t.Error("Content() with stripHeader should match the embedded copy")
+	}
+
+	// Simulate an upgrade that changed the embedded template.
+	data2, _ := os.ReadFile(path)
+	stale := strings.Replace(string(data2), "sha256:"+info.Ejected, "sha256:000000000000", 1)
+	if err := os.WriteFile(path, []byte(stale), 0644); err != nil {
+		t.Fatal(err)
+	}
+	overrides, err := Overrides(dir, SourceTown)
+	if err != nil {
+		t.Fatalf("Overrides() error = %v", err)
+	}
+	if len(overrides) != 1 || overrides[0].Name != "polecat" || !overrides[0].Stale {
+		t.Errorf("Overrides() = %+v, want one stale polecat override", overrides)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -214,9 +215,11 @@ func parseBeadContext(beadID string) (actor, rig, role string) {
 
 // GtEventsSource reads events from ~/gt/.events.jsonl (gt activity log)
 type GtEventsSource struct {
+	path   string
 	file   *os.File
 	events chan Event
 	cancel context.CancelFunc
+	done   chan struct{}
 }
 
 // GtEvent is the structure of events in .events.jsonl
@@ -240,9 +243,11 @@ func NewGtEventsSource(townRoot string) (*GtEventsSource, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	source := &GtEventsSource{
+		path:   eventsPath,
 		file:   file,
 		events: make(chan Event, 100),
 		cancel: cancel,
+		done:   make(chan struct{}),
 	}
 
 	go source.tail(ctx)
@@ -250,35 +255,70 @@ func NewGtEventsSource(townRoot string) (*GtEventsSource, error) {
 	return source, nil
 }
 
-// tail follows the file and sends events
+// tail follows the file and sends events. Rotation truncates the log, and
+// a log can be replaced outright; either way the file is reopened and read
+// from the top.
 func (s *GtEventsSource) tail(ctx context.Context) {
+	defer close(s.done)
 	defer close(s.events)
 
 	// Seek to end for live tailing
-	_, _ = s.file.Seek(0, 2)
+	pos, _ := s.file.Seek(0, io.SeekEnd)
 
-	scanner := bufio.NewScanner(s.file)
+	reader := bufio.NewReader(s.file)
 	ticker := time.NewTicker(100 * time.Millisecond)
 	defer ticker.Stop()
 
+	// partial holds a line whose newline hasn't been written yet.
+	var partial string
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			for scanner.Scan() {
-				line := scanner.Text()
-				if event := parseGtEventLine(line); event != nil {
+			if s.rotated(pos) {
+				if f, err := os.Open(s.path); err == nil {
+					_ = s.file.Close()
+					s.file = f
+					reader.Reset(f)
+					pos = 0
+					partial = ""
+				}
+			}
+
+			for {
+				line, err := reader.ReadString('\n')
+				pos += int64(len(line))
+				if err != nil {
+					partial += line
+					break
+				}
+				if event := parseGtEventLine(partial + line); event != nil {
 					select {
 					case s.events <- *event:
 					default:
 					}
 				}
+				partial = ""
 			}
 		}
 	}
 }
 
+// rotated reports whether the log at s.path is shorter than what has been
+// read from it or is no longer the open file.
+func (s *GtEventsSource) rotated(pos int64) bool {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return false
+	}
+	if info.Size() < pos {
+		return true
+	}
+	open, err := s.file.Stat()
+	return err == nil && !os.SameFile(info, open)
+}
+
 // Events returns the event channel
 func (s *GtEventsSource) Events() <-chan Event {
 	return s.events
@@ -287,6 +327,7 @@ func (s *GtEventsSource) Events() <-chan Event {
 // Close stops the source
 func (s *GtEventsSource) Close() error {
 	s.cancel()
+	<-s.done
 	return s.file.Close()
 }
 
@@ -0,0 +1,186 @@
+package web
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// Actions performs dashboard control actions. The gt dashboard command
+// implements it with the same code paths as the equivalent CLI commands.
+type Actions interface {
+	// Nudge sends message to a rig/polecat or rig/crew/name address (gt nudge).
+	Nudge(target, message string) error
+	// RetryMR requeues a failed merge request (gt mq retry).
+	RetryMR(rig, id string) error
+	// RejectMR rejects a merge request and notifies its worker (gt mq reject).
+	RejectMR(rig, id, reason string) error
+	// CloseConvoy closes a convoy (gt convoy close).
+	CloseConvoy(id, reason string) error
+	// Peek returns recent output from an agent session (gt peek).
+	Peek(target string, lines int) (string, error)
+}
+
+// actionActor identifies the dashboard in events and nudge prefixes.
+const actionActor = "dashboard"
+
+// defaultPeekLines and maxPeekLines bound the output /api/actions/peek returns.
+const (
+	defaultPeekLines = 100
+	maxPeekLines     = 2000
+)
+
+// maxActionBody bounds action request bodies.
+const maxActionBody = 64 * 1024
+
+// actionRequest is the JSON body accepted by every action endpoint; each
+// action uses the fields it needs.
+type actionRequest struct {
+	Target  string `json:"target,omitempty"`
+	Message string `json:"message,omitempty"`
+	Rig     string `json:"rig,omitempty"`
+	ID      string `json:"id,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+	Lines   int    `json:"lines,omitempty"`
+}
+
+// EnableActions registers the authenticated control endpoints. Each POST
+// must carry "Authorization: Bearer <token>"; requiring a header (rather
+// than a cookie) also keeps other sites from triggering actions.
+//
+//	POST /api/actions/nudge          {"target": "rig/polecat", "message": "..."}
+//	POST /api/actions/mq/retry       {"rig": "...", "id": "..."}
+//	POST /api/actions/mq/reject      {"rig": "...", "id": "...", "reason": "..."}
+//	POST /api/actions/convoy/close   {"id": "...", "reason": "..."}
+//	POST /api/actions/peek           {"target": "rig/polecat", "lines": 100}
+//
+// Every action, including failures, is recorded with events.LogAudit.
+func (s *Server) EnableActions(actions Actions, token string) {
+	s.actions = actions
+	s.token = token
+	s.mux.HandleFunc("POST /api/actions/nudge", s.action("nudge", s.doNudge))
+	s.mux.HandleFunc("POST /api/actions/mq/retry", s.action("mq_retry", s.doRetry))
+	s.mux.HandleFunc("POST /api/actions/mq/reject", s.action("mq_reject", s.doReject))
+	s.mux.HandleFunc("POST /api/actions/convoy/close", s.action("convoy_close", s.doCloseConvoy))
+	s.mux.HandleFunc("POST /api/actions/peek", s.action("peek", s.doPeek))
+}
+
+// actionFunc validates and runs one action. A non-nil result is returned
+// to the client alongside "ok"; a badRequest error marks invalid input.
+type actionFunc func(req actionRequest) (map[string]interface{}, error)
+
+// badRequest is an action input error, reported as 400.
+type badRequest string
+
+func (e badRequest) Error() string { return string(e) }
+
+// action wraps an actionFunc with authentication, body decoding, audit
+// logging and the JSON response.
+func (s *Server) action(name string, fn actionFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.authorized(r) {
+			s.audit(name, actionRequest{}, "unauthorized")
+			writeJSONError(w, http.StatusUnauthorized, "missing or invalid dashboard token")
+			return
+		}
+
+		var req actionRequest
+		dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxActionBody))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&req); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+			return
+		}
+
+		result, err := fn(req)
+		if err != nil {
+			s.audit(name, req, err.Error())
+			status := http.StatusInternalServerError
+			if _, ok := err.(badRequest); ok {
+				status = http.StatusBadRequest
+			}
+			writeJSONError(w, status, err.Error())
+			return
+		}
+		s.audit(name, req, "")
+
+		if result == nil {
+			result = map[string]interface{}{}
+		}
+		result["ok"] = true
+		writeJSON(w, http.StatusOK, result)
+	}
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	if s.token == "" {
+		return false
+	}
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(strings.TrimSpace(got)), []byte(s.token)) == 1
+}
+
+// audit records an action attempt. errMsg is empty on success.
+func (s *Server) audit(name string, req actionRequest, errMsg string) {
+	payload := map[string]interface{}{"action": name}
+	for k, v := range map[string]string{
+		"target": req.Target, "message": req.Message, "rig": req.Rig, "id": req.ID, "reason": req.Reason,
+	} {
+		if v != "" {
+			payload[k] = v
+		}
+	}
+	if errMsg != "" {
+		payload["error"] = errMsg
+	}
+	_ = s.logAudit(events.TypeDashboardAction, actionActor, payload)
+}
+
+func (s *Server) doNudge(req actionRequest) (map[string]interface{}, error) {
+	if req.Target == "" || strings.TrimSpace(req.Message) == "" {
+		return nil, badRequest("target and message are required")
+	}
+	return nil, s.actions.Nudge(req.Target, req.Message)
+}
+
+func (s *Server) doRetry(req actionRequest) (map[string]interface{}, error) {
+	if req.Rig == "" || req.ID == "" {
+		return nil, badRequest("rig and id are required")
+	}
+	return nil, s.actions.RetryMR(req.Rig, req.ID)
+}
+
+func (s *Server) doReject(req actionRequest) (map[string]interface{}, error) {
+	if req.Rig == "" || req.ID == "" || strings.TrimSpace(req.Reason) == "" {
+		return nil, badRequest("rig, id and reason are required")
+	}
+	return nil, s.actions.RejectMR(req.Rig, req.ID, req.Reason)
+}
+
+func (s *Server) doCloseConvoy(req actionRequest) (map[string]interface{}, error) {
+	if req.ID == "" {
+		return nil, badRequest("id is required")
+	}
+	return nil, s.actions.CloseConvoy(req.ID, req.Reason)
+}
+
+func (s *Server) doPeek(req actionRequest) (map[string]interface{}, error) {
+	if req.Target == "" {
+		return nil, badRequest("target is required")
+	}
+	lines := req.Lines
+	if lines <= 0 {
+		lines = defaultPeekLines
+	}
+	output, err := s.actions.Peek(req.Target, min(lines, maxPeekLines))
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"output": output}, nil
+}
@@ -0,0 +1,206 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+// fakeActions records calls made by the action endpoints.
+type fakeActions struct {
+	calls []string
+	err   error
+}
+
+func (f *fakeActions) Nudge(target, message string) error {
+	f.calls = append(f.calls, "nudge "+target+" "+message)
+	return f.err
+}
+
+func (f *fakeActions) RetryMR(rig, id string) error {
+	f.calls = append(f.calls, "retry "+rig+" "+id)
+	return f.err
+}
+
+func (f *fakeActions) RejectMR(rig, id, reason string) error {
+	f.calls = append(f.calls, "reject "+rig+" "+id+" "+reason)
+	return f.err
+}
+
+func (f *fakeActions) CloseConvoy(id, reason string) error {
+	f.calls = append(f.calls, "close "+id+" "+reason)
+	return f.err
+}
+
+func (f *fakeActions) Peek(target string, lines int) (string, error) {
+	f.calls = append(f.calls, "peek "+target)
+	return "pane output", f.err
+}
+
+type auditRecord struct {
+	eventType, actor string
+	payload          map[string]interface{}
+}
+
+func newActionServer(t *testing.T, actions Actions) (*Server, *[]auditRecord) {
+	t.Helper()
+	s, err := NewServer(&MockConvoyFetcher{}, "")
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	var audits []auditRecord
+	s.logAudit = func(eventType, actor string, payload map[string]interface{}) error {
+		audits = append(audits, auditRecord{eventType, actor, payload})
+		return nil
+	}
+	s.EnableActions(actions, "s3cret")
+	return s, &audits
+}
+
+func postAction(s *Server, path, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	return w
+}
+
+func TestActions_RequireToken(t *testing.T) {
+	actions := &fakeActions{}
+	s, audits := newActionServer(t, actions)
+
+	for _, token := range []string{"", "wrong"} {
+		w := postAction(s, "/api/actions/nudge", token, `{"target":"gastown/nux","message":"hi"}`)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("token %q: status = %d, want 401", token, w.Code)
+		}
+	}
+	if len(actions.calls) != 0 {
+		t.Errorf("unauthorized requests ran actions: %v", actions.calls)
+	}
+	if len(*audits) != 2 || (*audits)[0].payload["error"] != "unauthorized" {
+		t.Errorf("unauthorized attempts not audited: %+v", *audits)
+	}
+
+	// GET is not an action.
+	req := httptest.NewRequest("GET", "/api/actions/nudge", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET status = %d, want 405", w.Code)
+	}
+}
+
+func TestActions_Dispatch(t *testing.T) {
+	tests := []struct {
+		path, body, call string
+	}{
+		{"/api/actions/nudge", `{"target":"gastown/nux","message":"status?"}`, "nudge gastown/nux status?"},
+		{"/api/actions/mq/retry", `{"rig":"gastown","id":"gt-mr-1"}`, "retry gastown gt-mr-1"},
+		{"/api/actions/mq/reject", `{"rig":"gastown","id":"gt-mr-1","reason":"breaks build"}`, "reject gastown gt-mr-1 breaks build"},
+		{"/api/actions/convoy/close", `{"id":"hq-cv-abc"}`, "close hq-cv-abc "},
+		{"/api/actions/peek", `{"target":"gastown/nux"}`, "peek gastown/nux"},
+	}
+	for _, tt := range tests {
+		actions := &fakeActions{}
+		s, audits := newActionServer(t, actions)
+
+		w := postAction(s, tt.path, "s3cret", tt.body)
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: status = %d, body %s", tt.path, w.Code, w.Body)
+			continue
+		}
+		if len(actions.calls) != 1 || actions.calls[0] != tt.call {
+			t.Errorf("%s: calls = %q, want %q", tt.path, actions.calls, tt.call)
+		}
+		if len(*audits) != 1 || (*audits)[0].eventType != events.TypeDashboardAction || (*audits)[0].actor != "dashboard" {
+			t.Errorf("%s: audits = %+v", tt.path, *audits)
+		}
+	}
+}
+
+func TestActions_PeekOutput(t *testing.T) {
+	s, _ := newActionServer(t, &fakeActions{})
+	w := postAction(s, "/api/actions/peek", "s3cret", `{"target":"gastown/nux","lines":20}`)
+
+	var resp map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp["ok"] != true || resp["output"] != "pane output" {
+		t.Errorf("peek response = %v", resp)
+	}
+}
+
+func TestActions_Errors(t *testing.T) {
+	actions := &fakeActions{}
+	s, audits := newActionServer(t, actions)
+
+	// Missing fields are rejected before reaching the action.
+	w := postAction(s, "/api/actions/mq/reject", "s3cret", `{"rig":"gastown","id":"gt-mr-1"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("missing reason: status = %d, want 400", w.Code)
+	}
+	w = postAction(s, "/api/actions/nudge", "s3cret", `{"target":"gastown/nux","bogus":1}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("unknown field: status = %d, want 400", w.Code)
+	}
+	if len(actions.calls) != 0 {
+		t.Errorf("invalid requests ran actions: %v", actions.calls)
+	}
+
+	// Action failures are reported and audited.
+	actions.err = errors.New("rig 'nope' not found")
+	*audits = nil
+	w = postAction(s, "/api/actions/mq/retry", "s3cret", `{"rig":"nope","id":"x"}`)
+	if w.Code != http.StatusInternalServerError || !strings.Contains(w.Body.String(), "not found") {
+		t.Errorf("failed action: status = %d, body %s", w.Code, w.Body)
+	}
+	if len(*audits) != 1 || (*audits)[0].payload["error"] != "rig 'nope' not found" || (*audits)[0].payload["rig"] != "nope" {
+		t.Errorf("failure audit = %+v", *audits)
+	}
+}
+
+func TestActions_DisabledByDefault(t *testing.T) {
+	s, err := NewServer(&MockConvoyFetcher{}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := postAction(s, "/api/actions/nudge", "anything", `{}`)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404 without EnableActions", w.Code)
+	}
+}
+
+func TestLoadOrCreateToken(t *testing.T) {
+	townRoot := t.TempDir()
+
+	token, err := LoadOrCreateToken(townRoot)
+	if err != nil {
+		t.Fatalf("LoadOrCreateToken: %v", err)
+	}
+	if len(token) != 64 {
+		t.Errorf("token length = %d, want 64 hex chars", len(token))
+	}
+	info, err := os.Stat(TokenPath(townRoot))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("token file mode = %o, want 600", perm)
+	}
+
+	again, err := LoadOrCreateToken(townRoot)
+	if err != nil || again != token {
+		t.Errorf("second load = %q, %v; want the same token", again, err)
+	}
+}
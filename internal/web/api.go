@@ -0,0 +1,165 @@
+package web
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+//go:embed static
+var staticFS embed.FS
+
+// defaultEventLimit is how many events /api/events returns without ?limit.
+const defaultEventLimit = 100
+
+// maxEventLimit caps ?limit so a request can't load the whole log.
+const maxEventLimit = 1000
+
+// Server serves the dashboard page, its JSON API and the live event stream.
+//
+// Routes:
+//
+//	GET /              dashboard page
+//	GET /static/...    embedded front-end assets
+//	GET /api/convoys   convoys as JSON
+//	GET /api/mq        merge queue as JSON
+//	GET /api/polecats  polecat workers as JSON
+//	GET /api/events    recent events from the events log (?limit=N&type=T)
+//	GET /api/stream    Server-Sent Events tailing the events log
+//
+// Control actions under /api/actions/ are added by EnableActions.
+type Server struct {
+	fetcher    ConvoyFetcher
+	page       *ConvoyHandler
+	eventsPath string
+	mux        *http.ServeMux
+
+	// pollInterval is how often the event stream checks for new lines.
+	pollInterval time.Duration
+	// heartbeat is how often an idle stream sends a keep-alive comment.
+	heartbeat time.Duration
+
+	// Control actions; nil until EnableActions is called.
+	actions  Actions
+	token    string
+	logAudit func(eventType, actor string, payload map[string]interface{}) error
+}
+
+// NewServer creates a dashboard server. eventsPath is the town's
+// .events.jsonl; it need not exist yet. History is read with events.Query,
+// so rotated segments next to it are included.
+func NewServer(fetcher ConvoyFetcher, eventsPath string) (*Server, error) {
+	page, err := NewConvoyHandler(fetcher)
+	if err != nil {
+		return nil, err
+	}
+	static, err := fs.Sub(staticFS, "static")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		fetcher:      fetcher,
+		page:         page,
+		eventsPath:   eventsPath,
+		mux:          http.NewServeMux(),
+		pollInterval: 250 * time.Millisecond,
+		heartbeat:    15 * time.Second,
+		logAudit:     events.LogAudit,
+	}
+	s.mux.Handle("GET /{$}", page)
+	s.mux.Handle("GET /static/", http.StripPrefix("/static/", http.FileServer(http.FS(static))))
+	s.mux.HandleFunc("GET /api/convoys", s.handleConvoys)
+	s.mux.HandleFunc("GET /api/mq", s.handleMergeQueue)
+	s.mux.HandleFunc("GET /api/polecats", s.handlePolecats)
+	s.mux.HandleFunc("GET /api/events", s.handleEvents)
+	s.mux.HandleFunc("GET /api/stream", s.handleStream)
+	return s, nil
+}
+
+// ServeHTTP dispatches to the dashboard routes.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleConvoys(w http.ResponseWriter, _ *http.Request) {
+	convoys, err := s.fetcher.FetchConvoys()
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to fetch convoys: "+err.Error())
+		return
+	}
+	if convoys == nil {
+		convoys = []ConvoyRow{}
+	}
+	writeJSON(w, http.StatusOK, convoys)
+}
+
+func (s *Server) handleMergeQueue(w http.ResponseWriter, _ *http.Request) {
+	mq, err := s.fetcher.FetchMergeQueue()
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to fetch merge queue: "+err.Error())
+		return
+	}
+	if mq == nil {
+		mq = []MergeQueueRow{}
+	}
+	writeJSON(w, http.StatusOK, mq)
+}
+
+func (s *Server) handlePolecats(w http.ResponseWriter, _ *http.Request) {
+	polecats, err := s.fetcher.FetchPolecats()
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to fetch polecats: "+err.Error())
+		return
+	}
+	if polecats == nil {
+		polecats = []PolecatRow{}
+	}
+	writeJSON(w, http.StatusOK, polecats)
+}
+
+// handleEvents returns the most recent events, oldest first.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	limit := defaultEventLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeJSONError(w, http.StatusBadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = min(n, maxEventLimit)
+	}
+
+	filter := events.Filter{Limit: limit}
+	if t := r.URL.Query().Get("type"); t != "" {
+		filter.Types = []string{t}
+	}
+	evts, err := events.Query(filepath.Dir(s.eventsPath), filter)
+	if err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "failed to read events: "+err.Error())
+		return
+	}
+	if evts == nil {
+		evts = []events.Event{}
+	}
+	writeJSON(w, http.StatusOK, evts)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+func writeJSONError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
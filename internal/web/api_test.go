@@ -0,0 +1,259 @@
+package web
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/events"
+)
+
+func newTestServer(t *testing.T, fetcher ConvoyFetcher) (*httptest.Server, string) {
+	t.Helper()
+	eventsPath := filepath.Join(t.TempDir(), events.EventsFile)
+	s, err := NewServer(fetcher, eventsPath)
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	s.pollInterval = 10 * time.Millisecond
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+	return ts, eventsPath
+}
+
+func getJSON(t *testing.T, url string, v interface{}) int {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("GET %s: Content-Type = %q, want application/json", url, ct)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("GET %s: decoding: %v", url, err)
+	}
+	return resp.StatusCode
+}
+
+func appendEvents(t *testing.T, path string, evts ...events.Event) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	for _, e := range evts {
+		data, _ := json.Marshal(e)
+		if _, err := f.Write(append(data, '\n')); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestServer_JSONEndpoints(t *testing.T) {
+	mock := &MockConvoyFetcher{
+		Convoys: []ConvoyRow{
+			{ID: "hq-cv-api", Title: "API Convoy", Status: "open", WorkStatus: "active", Progress: "1/2", Completed: 1, Total: 2},
+		},
+		MergeQueue: []MergeQueueRow{
+			{Number: 7, Repo: "roxas", Title: "Fix it", CIStatus: "pass", Mergeable: "ready"},
+		},
+	}
+	ts, _ := newTestServer(t, mock)
+
+	var convoys []map[string]interface{}
+	if code := getJSON(t, ts.URL+"/api/convoys", &convoys); code != http.StatusOK {
+		t.Fatalf("/api/convoys status = %d", code)
+	}
+	if len(convoys) != 1 || convoys[0]["id"] != "hq-cv-api" || convoys[0]["work_status"] != "active" {
+		t.Errorf("/api/convoys = %v", convoys)
+	}
+
+	var mq []map[string]interface{}
+	getJSON(t, ts.URL+"/api/mq", &mq)
+	if len(mq) != 1 || mq[0]["number"] != float64(7) || mq[0]["ci_status"] != "pass" {
+		t.Errorf("/api/mq = %v", mq)
+	}
+
+	// Nil results encode as empty arrays, not null.
+	resp, err := http.Get(ts.URL + "/api/polecats")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if strings.TrimSpace(string(body)) != "[]" {
+		t.Errorf("/api/polecats body = %q, want []", body)
+	}
+}
+
+func TestServer_FetchError(t *testing.T) {
+	ts, _ := newTestServer(t, &MockConvoyFetcher{Error: errFetchFailed})
+
+	var out map[string]string
+	if code := getJSON(t, ts.URL+"/api/convoys", &out); code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", code)
+	}
+	if !strings.Contains(out["error"], "fetch failed") {
+		t.Errorf("error = %q", out["error"])
+	}
+}
+
+func TestServer_Events(t *testing.T) {
+	ts, eventsPath := newTestServer(t, &MockConvoyFetcher{})
+
+	// Missing log is an empty list.
+	var evts []events.Event
+	getJSON(t, ts.URL+"/api/events", &evts)
+	if evts == nil || len(evts) != 0 {
+		t.Errorf("events with no log = %v, want []", evts)
+	}
+
+	appendEvents(t, eventsPath,
+		events.Event{Type: events.TypeSling, Actor: "mayor"},
+		events.Event{Type: events.TypeDone, Actor: "gastown/polecats/nux"},
+		events.Event{Type: events.TypeSling, Actor: "deacon"},
+		events.Event{Type: events.TypeMerged, Actor: "gastown/refinery"},
+	)
+
+	getJSON(t, ts.URL+"/api/events?limit=2", &evts)
+	if len(evts) != 2 || evts[0].Actor != "deacon" || evts[1].Type != events.TypeMerged {
+		t.Errorf("limit=2 = %+v", evts)
+	}
+
+	getJSON(t, ts.URL+"/api/events?type=sling", &evts)
+	if len(evts) != 2 || evts[0].Actor != "mayor" || evts[1].Actor != "deacon" {
+		t.Errorf("type=sling = %+v", evts)
+	}
+
+	var errOut map[string]string
+	if code := getJSON(t, ts.URL+"/api/events?limit=abc", &errOut); code != http.StatusBadRequest {
+		t.Errorf("bad limit status = %d, want 400", code)
+	}
+}
+
+func TestServer_Stream(t *testing.T) {
+	ts, eventsPath := newTestServer(t, &MockConvoyFetcher{})
+	appendEvents(t, eventsPath, events.Event{Type: events.TypeBoot, Actor: "old"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", ts.URL+"/api/stream", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /api/stream: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	r := bufio.NewReader(resp.Body)
+	// Wait for the retry preamble so the stream is positioned at the end.
+	if line, _ := r.ReadString('\n'); !strings.HasPrefix(line, "retry:") {
+		t.Fatalf("first line = %q", line)
+	}
+
+	appendEvents(t, eventsPath, events.Event{Type: events.TypeSpawn, Actor: "new"})
+
+	var id, data string
+	for data == "" {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading stream: %v", err)
+		}
+		switch {
+		case strings.HasPrefix(line, "id: "):
+			id = strings.TrimSpace(strings.TrimPrefix(line, "id: "))
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+
+	var e events.Event
+	if err := json.Unmarshal([]byte(data), &e); err != nil {
+		t.Fatalf("bad event data %q: %v", data, err)
+	}
+	if e.Actor != "new" {
+		t.Errorf("streamed actor = %q, want new (history should be skipped)", e.Actor)
+	}
+	info, _ := os.Stat(eventsPath)
+	if want := info.Size(); id != strconv.FormatInt(want, 10) {
+		t.Errorf("event id = %s, want offset %d", id, want)
+	}
+}
+
+func TestReadNewLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	if err := os.WriteFile(path, []byte("{\"a\":1}\n{\"b\":2}\n{\"c\""), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	lines, off := readNewLines(path, 0)
+	if len(lines) != 2 || string(lines[1].data) != `{"b":2}` || off != 16 {
+		t.Fatalf("readNewLines = %q, %d", lines, off)
+	}
+
+	// Partial line completes.
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	_, _ = f.WriteString(":3}\n")
+	f.Close()
+	lines, off = readNewLines(path, off)
+	if len(lines) != 1 || string(lines[0].data) != `{"c":3}` {
+		t.Fatalf("after completing line = %q", lines)
+	}
+
+	// Truncation restarts from the beginning.
+	_ = os.WriteFile(path, []byte("{\"d\":4}\n"), 0644)
+	lines, _ = readNewLines(path, off)
+	if len(lines) != 1 || string(lines[0].data) != `{"d":4}` {
+		t.Errorf("after truncate = %q", lines)
+	}
+}
+
+func TestServer_StaticAndPage(t *testing.T) {
+	ts, _ := newTestServer(t, &MockConvoyFetcher{})
+
+	resp, err := http.Get(ts.URL + "/static/dashboard.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "EventSource") {
+		t.Errorf("/static/dashboard.js: status %d, body %.80q", resp.StatusCode, body)
+	}
+
+	resp, err = http.Get(ts.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "Gas Town Convoys") {
+		t.Error("/ should render the dashboard page")
+	}
+	if strings.Contains(string(body), "unpkg.com") {
+		t.Error("dashboard page should not load external scripts")
+	}
+
+	resp, err = http.Get(ts.URL + "/nope")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("/nope status = %d, want 404", resp.StatusCode)
+	}
+}
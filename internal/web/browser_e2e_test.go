@@ -210,8 +210,8 @@ func TestBrowser_LastActivityColors(t *testing.T) {
 	t.Log("PASSED: Activity colors display correctly")
 }
 
-// TestBrowser_HtmxAutoRefresh tests that htmx auto-refresh attributes are present
-func TestBrowser_HtmxAutoRefresh(t *testing.T) {
+// TestBrowser_AutoRefresh tests that auto-refresh attributes are present
+func TestBrowser_AutoRefresh(t *testing.T) {
 	fetcher := &mockFetcher{
 		convoys: []ConvoyRow{
 			{
@@ -239,25 +239,22 @@ func TestBrowser_HtmxAutoRefresh(t *testing.T) {
 
 	page.MustWaitLoad()
 
-	// Check for htmx attributes
+	// Check for auto-refresh attributes
 	html := page.MustHTML()
 
-	if !strings.Contains(html, "hx-get") {
-		t.Error("Expected hx-get attribute for auto-refresh")
+	if !strings.Contains(html, "data-refresh=") {
+		t.Error("Expected data-refresh attribute for auto-refresh")
 	}
-	if !strings.Contains(html, "hx-trigger") {
-		t.Error("Expected hx-trigger attribute for auto-refresh")
-	}
-	if !strings.Contains(html, "every 30s") {
-		t.Error("Expected 'every 30s' trigger for auto-refresh")
+	if !strings.Contains(html, `data-refresh-every="10s"`) {
+		t.Error("Expected 'every 10s' interval for auto-refresh")
 	}
 
-	// Verify htmx library is loaded
-	if !strings.Contains(html, "htmx.org") {
-		t.Error("Expected htmx library to be loaded")
+	// Verify the embedded script is referenced
+	if !strings.Contains(html, "/static/dashboard.js") {
+		t.Error("Expected embedded dashboard script to be loaded")
 	}
 
-	t.Log("PASSED: htmx auto-refresh attributes present")
+	t.Log("PASSED: auto-refresh attributes present")
 }
 
 // TestBrowser_EmptyState tests the empty state when no convoys exist
@@ -491,9 +491,9 @@ func TestConvoyHandler_ProgressBarRendering(t *testing.T) {
 	}
 }
 
-// Integration test for HTMX auto-refresh
+// Integration test for auto-refresh
 
-func TestConvoyHandler_HTMXAutoRefresh(t *testing.T) {
+func TestConvoyHandler_AutoRefresh(t *testing.T) {
 	mock := &MockConvoyFetcher{
 		Convoys: []ConvoyRow{},
 	}
@@ -510,15 +510,15 @@ func TestConvoyHandler_HTMXAutoRefresh(t *testing.T) {
 
 	body := w.Body.String()
 
-	// Check htmx attributes for auto-refresh
-	if !strings.Contains(body, "hx-get") {
-		t.Error("Response should contain hx-get attribute for HTMX")
+	// Check auto-refresh attributes
+	if !strings.Contains(body, `data-refresh="/"`) {
+		t.Error("Response should contain data-refresh attribute")
 	}
-	if !strings.Contains(body, "hx-trigger") {
-		t.Error("Response should contain hx-trigger attribute for HTMX")
+	if !strings.Contains(body, `data-refresh-every="10s"`) {
+		t.Error("Response should contain 'every 10s' refresh interval")
 	}
-	if !strings.Contains(body, "every 10s") {
-		t.Error("Response should contain 'every 10s' trigger interval")
+	if !strings.Contains(body, `data-stream="/api/stream"`) {
+		t.Error("Response should subscribe to the event stream")
 	}
 }
 
@@ -686,7 +686,7 @@ func TestE2E_Server_FullDashboard(t *testing.T) {
 		{"Polecat section", "Polecat Workers"},
 		{"Polecat name", "furiosa"},
 		{"Polecat status", "Running E2E tests"},
-		{"HTMX auto-refresh", `hx-trigger="every 10s"`},
+		{"Auto-refresh", `data-refresh-every="10s"`},
 	}
 
 	for _, check := range checks {
@@ -871,7 +871,7 @@ func TestE2E_Server_HTMLStructure(t *testing.T) {
 		"<html",
 		"<head>",
 		"<title>Gas Town Dashboard</title>",
-		"htmx.org",
+		"/static/dashboard.js",
 		"<body>",
 		"</body>",
 		"</html>",
@@ -0,0 +1,283 @@
+// Gas Town dashboard: periodic refresh, a live event feed and control
+// actions.
+//
+// Served from the gt binary so the dashboard works offline. The page marks
+// its refreshable region with data-refresh (URL to re-fetch) and
+// data-refresh-every (interval, e.g. "10s"), and the event feed with
+// data-events (history URL) and data-stream (Server-Sent Events URL).
+// Buttons with data-action POST to /api/actions/<action> using the token
+// gt dashboard passes in the URL fragment (#token=...).
+(function () {
+    "use strict";
+
+    var FEED_MAX = 50;
+    var TOKEN_KEY = "gt-dashboard-token";
+
+    function parseInterval(s) {
+        var m = /^(\d+)(ms|s|m)?$/.exec(s || "");
+        if (!m) {
+            return 0;
+        }
+        var n = parseInt(m[1], 10);
+        switch (m[2]) {
+        case "ms": return n;
+        case "m": return n * 60000;
+        default: return n * 1000;
+        }
+    }
+
+    // Live region: re-fetch the page and swap in the new content.
+    function setupRefresh(region) {
+        var url = region.getAttribute("data-refresh");
+        var every = parseInterval(region.getAttribute("data-refresh-every"));
+        var inFlight = false;
+        var pending = null;
+
+        function refresh() {
+            if (inFlight) {
+                return;
+            }
+            inFlight = true;
+            document.body.classList.add("refreshing");
+            fetch(url, { headers: { "Accept": "text/html" } })
+                .then(function (resp) {
+                    if (!resp.ok) {
+                        throw new Error("HTTP " + resp.status);
+                    }
+                    return resp.text();
+                })
+                .then(function (html) {
+                    var doc = new DOMParser().parseFromString(html, "text/html");
+                    var next = doc.getElementById(region.id);
+                    if (next) {
+                        region.innerHTML = next.innerHTML;
+                    }
+                })
+                .catch(function () { /* keep the current view */ })
+                .finally(function () {
+                    inFlight = false;
+                    document.body.classList.remove("refreshing");
+                });
+        }
+
+        if (every > 0) {
+            setInterval(refresh, every);
+        }
+
+        // Events arrive in bursts; coalesce them into one refresh.
+        return function soon() {
+            if (pending) {
+                clearTimeout(pending);
+            }
+            pending = setTimeout(function () {
+                pending = null;
+                refresh();
+            }, 1000);
+        };
+    }
+
+    function formatTime(ts) {
+        var d = new Date(ts);
+        if (isNaN(d.getTime())) {
+            return ts || "";
+        }
+        return d.toLocaleTimeString();
+    }
+
+    function eventItem(ev) {
+        var li = document.createElement("li");
+        li.className = "event event-" + (ev.type || "unknown");
+
+        var time = document.createElement("span");
+        time.className = "event-time";
+        time.textContent = formatTime(ev.ts);
+
+        var type = document.createElement("span");
+        type.className = "event-type";
+        type.textContent = ev.type || "?";
+
+        var actor = document.createElement("span");
+        actor.className = "event-actor";
+        actor.textContent = ev.actor || "";
+
+        li.appendChild(time);
+        li.appendChild(type);
+        li.appendChild(actor);
+        return li;
+    }
+
+    function setupFeed(feed, onEvent) {
+        var list = feed.querySelector("ul");
+        var status = feed.querySelector(".stream-status");
+
+        function add(ev) {
+            var empty = list.querySelector(".event-empty");
+            if (empty) {
+                empty.remove();
+            }
+            list.insertBefore(eventItem(ev), list.firstChild);
+            while (list.children.length > FEED_MAX) {
+                list.removeChild(list.lastChild);
+            }
+        }
+
+        function setStatus(text, cls) {
+            if (status) {
+                status.textContent = text;
+                status.className = "stream-status " + cls;
+            }
+        }
+
+        var history = feed.getAttribute("data-events");
+        if (history) {
+            fetch(history)
+                .then(function (resp) { return resp.json(); })
+                .then(function (evts) {
+                    if (Array.isArray(evts)) {
+                        evts.forEach(add);
+                    }
+                })
+                .catch(function () {});
+        }
+
+        var streamURL = feed.getAttribute("data-stream");
+        if (!streamURL || !window.EventSource) {
+            setStatus("offline", "stream-down");
+            return;
+        }
+        var source = new EventSource(streamURL);
+        source.onopen = function () { setStatus("live", "stream-up"); };
+        source.onerror = function () { setStatus("reconnecting", "stream-down"); };
+        source.onmessage = function (msg) {
+            var ev;
+            try {
+                ev = JSON.parse(msg.data);
+            } catch (e) {
+                return;
+            }
+            add(ev);
+            onEvent(ev);
+        };
+    }
+
+    // Token: taken from #token=... once, then kept in localStorage.
+    function loadToken() {
+        var m = /(?:^#|&)token=([0-9a-fA-F]+)/.exec(window.location.hash);
+        if (m) {
+            try {
+                localStorage.setItem(TOKEN_KEY, m[1]);
+            } catch (e) { /* storage disabled: keep it for this page only */ }
+            history.replaceState(null, "", window.location.pathname + window.location.search);
+            return m[1];
+        }
+        try {
+            return localStorage.getItem(TOKEN_KEY) || "";
+        } catch (e) {
+            return "";
+        }
+    }
+
+    function setupActions(token, refreshSoon) {
+        var status = document.getElementById("action-status");
+        var peek = document.getElementById("peek-output");
+
+        function report(text, ok) {
+            if (status) {
+                status.textContent = text;
+                status.className = ok ? "action-ok" : "action-error";
+            }
+        }
+
+        function post(action, body) {
+            report(action + "…", true);
+            return fetch("/api/actions/" + action, {
+                method: "POST",
+                headers: {
+                    "Authorization": "Bearer " + token,
+                    "Content-Type": "application/json"
+                },
+                body: JSON.stringify(body)
+            }).then(function (resp) {
+                return resp.json().then(function (data) {
+                    if (!resp.ok) {
+                        throw new Error(data.error || ("HTTP " + resp.status));
+                    }
+                    return data;
+                });
+            }).then(function (data) {
+                report(action + ": done", true);
+                refreshSoon();
+                return data;
+            }, function (err) {
+                report(action + ": " + err.message, false);
+                throw err;
+            });
+        }
+
+        // Build the request body for a button, or null if the user cancelled.
+        function requestFor(btn) {
+            var action = btn.getAttribute("data-action");
+            var target = btn.getAttribute("data-target");
+            var id = btn.getAttribute("data-id");
+            var reason;
+            switch (action) {
+            case "nudge":
+                var message = prompt("Nudge " + target + ":");
+                return message ? { target: target, message: message } : null;
+            case "peek":
+                return { target: target, lines: 100 };
+            case "convoy/close":
+                reason = prompt("Close convoy " + id + "? Reason (optional):", "");
+                return reason === null ? null : { id: id, reason: reason };
+            case "mq/retry":
+            case "mq/reject":
+                var rig = document.getElementById("mr-rig").value.trim();
+                id = document.getElementById("mr-id").value.trim();
+                if (!rig || !id) {
+                    report("enter a rig and merge request ID", false);
+                    return null;
+                }
+                if (action === "mq/retry") {
+                    return { rig: rig, id: id };
+                }
+                reason = prompt("Reject " + id + ": reason");
+                return reason ? { rig: rig, id: id, reason: reason } : null;
+            }
+            return null;
+        }
+
+        document.addEventListener("click", function (ev) {
+            var btn = ev.target.closest("[data-action]");
+            if (!btn) {
+                return;
+            }
+            ev.preventDefault();
+            var action = btn.getAttribute("data-action");
+            var body = requestFor(btn);
+            if (!body) {
+                return;
+            }
+            post(action, body).then(function (data) {
+                if (action === "peek" && peek) {
+                    peek.querySelector(".peek-target").textContent = body.target;
+                    peek.querySelector("pre").textContent = data.output || "";
+                    peek.hidden = false;
+                }
+            }, function () {});
+        });
+    }
+
+    document.addEventListener("DOMContentLoaded", function () {
+        var region = document.querySelector("[data-refresh]");
+        var refreshSoon = region ? setupRefresh(region) : function () {};
+        var feed = document.getElementById("event-feed");
+        if (feed) {
+            setupFeed(feed, refreshSoon);
+        }
+        var token = loadToken();
+        if (token) {
+            document.body.classList.add("actions-enabled");
+            setupActions(token, refreshSoon);
+        }
+    });
+})();
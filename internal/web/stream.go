@@ -0,0 +1,128 @@
+package web
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// handleStream tails the events log as Server-Sent Events. Each complete
+// line is sent as one message whose id is the byte offset just past it, so
+// a reconnecting EventSource resumes from Last-Event-ID without gaps. New
+// clients start at the end of the log; use /api/events for history.
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	// The dashboard server sets a WriteTimeout; streams must outlive it.
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	offset := int64(-1)
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		if n, err := strconv.ParseInt(id, 10, 64); err == nil && n >= 0 {
+			offset = n
+		}
+	}
+	if offset < 0 {
+		offset = fileSize(s.eventsPath)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	_, _ = fmt.Fprintf(w, "retry: 2000\n\n")
+	flusher.Flush()
+
+	poll := time.NewTicker(s.pollInterval)
+	defer poll.Stop()
+	ping := time.NewTicker(s.heartbeat)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ping.C:
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-poll.C:
+			lines, next := readNewLines(s.eventsPath, offset)
+			offset = next
+			if len(lines) == 0 {
+				continue
+			}
+			for _, l := range lines {
+				if _, err := fmt.Fprintf(w, "id: %d\ndata: %s\n\n", l.end, l.data); err != nil {
+					return
+				}
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// streamLine is one complete log line and the offset just past it.
+type streamLine struct {
+	data []byte
+	end  int64
+}
+
+// readNewLines returns the complete lines written after offset and the
+// offset to resume from. A trailing partial line is left for the next
+// read. If the log shrank (rotated or truncated) reading restarts at 0.
+func readNewLines(path string, offset int64) ([]streamLine, int64) {
+	f, err := os.Open(path) //nolint:gosec // G304: path is the town events log
+	if err != nil {
+		return nil, 0
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, offset
+	}
+	if info.Size() < offset {
+		offset = 0
+	}
+	if info.Size() == offset {
+		return nil, offset
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, offset
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, offset
+	}
+
+	var lines []streamLine
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		offset += int64(i) + 1
+		if line := bytes.TrimSpace(data[:i]); len(line) > 0 {
+			lines = append(lines, streamLine{data: line, end: offset})
+		}
+		data = data[i+1:]
+	}
+	return lines, offset
+}
+
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
@@ -21,43 +21,43 @@ type ConvoyData struct {
 
 // PolecatRow represents a polecat worker in the dashboard.
 type PolecatRow struct {
-	Name         string        // e.g., "dag", "nux"
-	Rig          string        // e.g., "roxas", "gastown"
-	SessionID    string        // e.g., "gt-roxas-dag"
-	LastActivity activity.Info // Colored activity display
-	StatusHint   string        // Last line from pane (optional)
+	Name         string        `json:"name"`                  // e.g., "dag", "nux"
+	Rig          string        `json:"rig"`                   // e.g., "roxas", "gastown"
+	SessionID    string        `json:"session_id"`            // e.g., "gt-roxas-dag"
+	LastActivity activity.Info `json:"last_activity"`         // Colored activity display
+	StatusHint   string        `json:"status_hint,omitempty"` // Last line from pane (optional)
 }
 
 // MergeQueueRow represents a PR in the merge queue.
 type MergeQueueRow struct {
-	Number     int
-	Repo       string // Short repo name (e.g., "roxas", "gastown")
-	Title      string
-	URL        string
-	CIStatus   string // "pass", "fail", "pending"
-	Mergeable  string // "ready", "conflict", "pending"
-	ColorClass string // "mq-green", "mq-yellow", "mq-red"
+	Number     int    `json:"number"`
+	Repo       string `json:"repo"` // Short repo name (e.g., "roxas", "gastown")
+	Title      string `json:"title"`
+	URL        string `json:"url"`
+	CIStatus   string `json:"ci_status"`   // "pass", "fail", "pending"
+	Mergeable  string `json:"mergeable"`   // "ready", "conflict", "pending"
+	ColorClass string `json:"color_class"` // "mq-green", "mq-yellow", "mq-red"
 }
 
 // ConvoyRow represents a single convoy in the dashboard.
 type ConvoyRow struct {
-	ID            string
-	Title         string
-	Status        string // "open" or "closed" (raw beads status)
-	WorkStatus    string // Computed: "complete", "active", "stale", "stuck", "waiting"
-	Progress      string // e.g., "2/5"
-	Completed     int
-	Total         int
-	LastActivity  activity.Info
-	TrackedIssues []TrackedIssue
+	ID            string         `json:"id"`
+	Title         string         `json:"title"`
+	Status        string         `json:"status"`      // "open" or "closed" (raw beads status)
+	WorkStatus    string         `json:"work_status"` // Computed: "complete", "active", "stale", "stuck", "waiting"
+	Progress      string         `json:"progress"`    // e.g., "2/5"
+	Completed     int            `json:"completed"`
+	Total         int            `json:"total"`
+	LastActivity  activity.Info  `json:"last_activity"`
+	TrackedIssues []TrackedIssue `json:"tracked_issues,omitempty"`
 }
 
 // TrackedIssue represents an issue tracked by a convoy.
 type TrackedIssue struct {
-	ID       string
-	Title    string
-	Status   string
-	Assignee string
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Status   string `json:"status"`
+	Assignee string `json:"assignee,omitempty"`
 }
 
 // LoadTemplates loads and parses all HTML templates.
@@ -106,7 +106,7 @@ func TestConvoyTemplate_LastActivityColors(t *testing.T) {
 	}
 }
 
-func TestConvoyTemplate_HtmxAutoRefresh(t *testing.T) {
+func TestConvoyTemplate_AutoRefresh(t *testing.T) {
 	tmpl, err := LoadTemplates()
 	if err != nil {
 		t.Fatalf("LoadTemplates() error = %v", err)
@@ -130,14 +130,11 @@ func TestConvoyTemplate_HtmxAutoRefresh(t *testing.T) {
 
 	output := buf.String()
 
-	// Check for htmx attributes
-	if !strings.Contains(output, "hx-get") {
-		t.Error("Template should contain hx-get for auto-refresh")
+	// Check for auto-refresh attributes
+	if !strings.Contains(output, "data-refresh=") {
+		t.Error("Template should contain data-refresh for auto-refresh")
 	}
-	if !strings.Contains(output, "hx-trigger") {
-		t.Error("Template should contain hx-trigger for auto-refresh")
-	}
-	if !strings.Contains(output, "every 10s") {
+	if !strings.Contains(output, `data-refresh-every="10s"`) {
 		t.Error("Template should refresh every 10 seconds")
 	}
 }
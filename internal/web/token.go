@@ -0,0 +1,49 @@
+package web
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/constants"
+)
+
+// TokenFile is the name of the dashboard action token under settings/.
+const TokenFile = "dashboard.token"
+
+// TokenPath returns the path of the town's dashboard action token.
+func TokenPath(townRoot string) string {
+	return filepath.Join(townRoot, constants.DirSettings, TokenFile)
+}
+
+// LoadOrCreateToken returns the town's dashboard action token, generating
+// a random one (readable only by the owner) on first use.
+func LoadOrCreateToken(townRoot string) (string, error) {
+	path := TokenPath(townRoot)
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is constructed internally
+	if err == nil {
+		if token := strings.TrimSpace(string(data)); token != "" {
+			return token, nil
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("reading dashboard token: %w", err)
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generating dashboard token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("creating settings directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("writing dashboard token: %w", err)
+	}
+	return token, nil
+}
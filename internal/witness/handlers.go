@@ -160,6 +160,28 @@ func HandleLifecycleShutdown(workDir, rigName string, msg *mail.Message) *Handle
 	return result
 }
 
+// HandleOOMKilled processes an OOM_KILLED message from the daemon, which
+// has already recorded the session death with reason oom-kill.
+// The polecat is not restarted: with the same memory limit it would most
+// likely be killed again, so raising the limit or escalating is left to the
+// witness patrol.
+func HandleOOMKilled(workDir, rigName string, msg *mail.Message) *HandlerResult {
+	result := &HandlerResult{
+		MessageID:    msg.ID,
+		ProtocolType: ProtoOOMKilled,
+	}
+
+	payload, err := ParseOOMKilled(msg.Subject, msg.Body)
+	if err != nil {
+		result.Error = fmt.Errorf("parsing OOM_KILLED: %w", err)
+		return result
+	}
+
+	result.Handled = true
+	result.Action = fmt.Sprintf("oom-kill of %s (memory limit %s, issue %s) - not restarted, needs limit raise or escalation", payload.PolecatName, payload.MemoryMax, payload.IssueID)
+	return result
+}
+
 // HandleHelp processes a HELP message from a polecat requesting intervention.
 // Assesses the request and either helps directly or escalates to Mayor.
 func HandleHelp(workDir, rigName string, msg *mail.Message, router *mail.Router) *HandlerResult {
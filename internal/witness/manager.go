@@ -30,6 +30,7 @@ type Manager struct {
 	rig          *rig.Rig
 	workDir      string
 	stateManager *agent.StateManager[Witness]
+	backend      session.SessionBackend
 }
 
 // NewManager creates a new witness manager for a rig.
@@ -37,6 +38,7 @@ func NewManager(r *rig.Rig) *Manager {
 	return &Manager{
 		rig:     r,
 		workDir: r.Path,
+		backend: session.NewBackend(),
 		stateManager: agent.NewStateManager[Witness](r.Path, "witness.json", func() *Witness {
 			return &Witness{
 				RigName: r.Name,
@@ -108,13 +110,13 @@ func (m *Manager) Start(foreground bool, agentOverride string, envOverrides []st
 		return err
 	}
 
-	t := tmux.NewTmux()
+	t := m.backend
 	sessionID := m.SessionName()
 
 	if foreground {
 		// Foreground mode is deprecated - patrol logic moved to mol-witness-patrol
 		// Just check tmux session (no PID inference per ZFC)
-		if running, _ := t.HasSession(sessionID); running && t.IsClaudeRunning(sessionID) {
+		if running, _ := t.HasSession(sessionID); running && m.isClaudeRunning(sessionID) {
 			return ErrAlreadyRunning
 		}
 
@@ -131,7 +133,7 @@ func (m *Manager) Start(foreground bool, agentOverride string, envOverrides []st
 	running, _ := t.HasSession(sessionID)
 	if running {
 		// Session exists - check if Claude is actually running (healthy vs zombie)
-		if t.IsClaudeRunning(sessionID) {
+		if m.isClaudeRunning(sessionID) {
 			// Healthy - Claude is running
 			return ErrAlreadyRunning
 		}
@@ -197,8 +199,10 @@ func (m *Manager) Start(foreground bool, agentOverride string, envOverrides []st
 	}
 
 	// Apply Gas Town theming (non-fatal: theming failure doesn't affect operation)
-	theme := tmux.AssignTheme(m.rig.Name)
-	_ = t.ConfigureGasTownSession(sessionID, theme, m.rig.Name, "witness", "witness")
+	if tm, ok := t.(*tmux.Tmux); ok {
+		theme := tmux.AssignTheme(m.rig.Name)
+		_ = tm.ConfigureGasTownSession(sessionID, theme, m.rig.Name, "witness", "witness")
+	}
 
 	// Update state to running
 	now := time.Now()
@@ -212,14 +216,16 @@ func (m *Manager) Start(foreground bool, agentOverride string, envOverrides []st
 	}
 
 	// Wait for Claude to start - fatal if Claude fails to launch
-	if err := t.WaitForCommand(sessionID, constants.SupportedShells, constants.ClaudeStartTimeout); err != nil {
+	if err := m.waitForClaude(sessionID); err != nil {
 		// Kill the zombie session before returning error
 		_ = t.KillSessionWithProcesses(sessionID)
 		return fmt.Errorf("waiting for witness to start: %w", err)
 	}
 
 	// Accept bypass permissions warning dialog if it appears.
-	_ = t.AcceptBypassPermissionsWarning(sessionID)
+	if tm, ok := t.(*tmux.Tmux); ok {
+		_ = tm.AcceptBypassPermissionsWarning(sessionID)
+	}
 
 	time.Sleep(constants.ShutdownNotifyDelay)
 
@@ -240,6 +246,24 @@ func (m *Manager) Start(foreground bool, agentOverride string, envOverrides []st
 	return nil
 }
 
+// isClaudeRunning checks for a live agent in the witness session. tmux can
+// also recognize Claude running under a wrapper shell; other backends check
+// for any non-shell foreground command.
+func (m *Manager) isClaudeRunning(sessionID string) bool {
+	if t, ok := m.backend.(*tmux.Tmux); ok {
+		return t.IsClaudeRunning(sessionID)
+	}
+	return m.backend.IsAgentRunning(sessionID)
+}
+
+// waitForClaude waits for the agent to replace the startup shell.
+func (m *Manager) waitForClaude(sessionID string) error {
+	if t, ok := m.backend.(*tmux.Tmux); ok {
+		return t.WaitForCommand(sessionID, constants.SupportedShells, constants.ClaudeStartTimeout)
+	}
+	return session.WaitForAgent(m.backend, sessionID, constants.ClaudeStartTimeout)
+}
+
 func (m *Manager) roleConfig() (*beads.RoleConfig, error) {
 	// Role beads use hq- prefix and live in town-level beads, not rig beads
 	townRoot := m.townRoot()
@@ -292,7 +316,7 @@ func (m *Manager) Stop() error {
 	}
 
 	// Check if tmux session exists
-	t := tmux.NewTmux()
+	t := m.backend
 	sessionID := m.SessionName()
 	sessionRunning, _ := t.HasSession(sessionID)
 
@@ -25,6 +25,9 @@ var (
 	// MERGE_FAILED <name> - refinery reporting merge failure
 	PatternMergeFailed = regexp.MustCompile(`^MERGE_FAILED\s+(\S+)`)
 
+	// OOM_KILLED <name> - daemon reporting a polecat killed at its memory limit
+	PatternOOMKilled = regexp.MustCompile(`^OOM_KILLED\s+(\S+)`)
+
 	// HANDOFF - session continuity message
 	PatternHandoff = regexp.MustCompile(`^🤝\s*HANDOFF`)
 
@@ -41,6 +44,7 @@ const (
 	ProtoHelp              ProtocolType = "help"
 	ProtoMerged            ProtocolType = "merged"
 	ProtoMergeFailed       ProtocolType = "merge_failed"
+	ProtoOOMKilled         ProtocolType = "oom_killed"
 	ProtoHandoff           ProtocolType = "handoff"
 	ProtoSwarmStart        ProtocolType = "swarm_start"
 	ProtoUnknown           ProtocolType = "unknown"
@@ -84,6 +88,15 @@ type MergeFailedPayload struct {
 	FailedAt    time.Time
 }
 
+// OOMKilledPayload contains parsed data from an OOM_KILLED message.
+type OOMKilledPayload struct {
+	PolecatName string
+	SessionID   string
+	IssueID     string
+	MemoryMax   string // Memory limit the session hit, e.g. "4.0G"
+	KilledAt    time.Time
+}
+
 // SwarmStartPayload contains parsed data from a SWARM_START message.
 type SwarmStartPayload struct {
 	SwarmID   string
@@ -105,6 +118,8 @@ func ClassifyMessage(subject string) ProtocolType {
 		return ProtoMerged
 	case PatternMergeFailed.MatchString(subject):
 		return ProtoMergeFailed
+	case PatternOOMKilled.MatchString(subject):
+		return ProtoOOMKilled
 	case PatternHandoff.MatchString(subject):
 		return ProtoHandoff
 	case PatternSwarmStart.MatchString(subject):
@@ -260,6 +275,40 @@ func ParseMergeFailed(subject, body string) (*MergeFailedPayload, error) {
 	return payload, nil
 }
 
+// ParseOOMKilled extracts payload from an OOM_KILLED message.
+// Subject format: OOM_KILLED <polecat-name>
+// Body format:
+//
+//	Session: <session>
+//	Issue: <issue-id>
+//	MemoryMax: <limit>
+func ParseOOMKilled(subject, body string) (*OOMKilledPayload, error) {
+	matches := PatternOOMKilled.FindStringSubmatch(subject)
+	if len(matches) < 2 {
+		return nil, fmt.Errorf("invalid OOM_KILLED subject: %s", subject)
+	}
+
+	payload := &OOMKilledPayload{
+		PolecatName: matches[1],
+		KilledAt:    time.Now(),
+	}
+
+	// Parse body for structured fields
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "Session:"):
+			payload.SessionID = strings.TrimSpace(strings.TrimPrefix(line, "Session:"))
+		case strings.HasPrefix(line, "Issue:"):
+			payload.IssueID = strings.TrimSpace(strings.TrimPrefix(line, "Issue:"))
+		case strings.HasPrefix(line, "MemoryMax:"):
+			payload.MemoryMax = strings.TrimSpace(strings.TrimPrefix(line, "MemoryMax:"))
+		}
+	}
+
+	return payload, nil
+}
+
 // ParseSwarmStart extracts payload from a SWARM_START message.
 // Body format is JSON: {"swarm_id": "batch-123", "beads": ["bd-a", "bd-b"]}
 func ParseSwarmStart(body string) (*SwarmStartPayload, error) {
@@ -351,6 +400,13 @@ func AssessHelpRequest(payload *HelpPayload) *HelpAssessment {
 		assessment.EscalationReason = "Requirements clarification needed from Mayor"
 	}
 
+	// Thrashing - the agent is looping; more of the same won't converge
+	if strings.Contains(topic, "thrash") || strings.Contains(problem, "looping") {
+		assessment.CanHelp = false
+		assessment.NeedsEscalation = true
+		assessment.EscalationReason = "Agent is looping without progress; needs a fresh session or new direction"
+	}
+
 	// Default: escalate if we don't recognize the pattern
 	if !assessment.CanHelp && !assessment.NeedsEscalation {
 		assessment.NeedsEscalation = true
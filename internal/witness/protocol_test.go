@@ -18,6 +18,7 @@ func TestClassifyMessage(t *testing.T) {
 		{"MERGED valkyrie", ProtoMerged},
 		{"MERGE_FAILED nux", ProtoMergeFailed},
 		{"MERGE_FAILED ace", ProtoMergeFailed},
+		{"OOM_KILLED nux", ProtoOOMKilled},
 		{"🤝 HANDOFF: Patrol context", ProtoHandoff},
 		{"🤝HANDOFF: No space", ProtoHandoff},
 		{"SWARM_START", ProtoSwarmStart},
@@ -309,3 +310,33 @@ func TestAssessHelpRequest_BuildIssues(t *testing.T) {
 		t.Error("Should be able to help with build issues")
 	}
 }
+
+func TestParseOOMKilled(t *testing.T) {
+	subject := "OOM_KILLED nux"
+	body := `Polecat nux was killed by the OOM killer at its memory limit.
+
+Session: gt-gastown-nux
+Issue: gt-abc123
+MemoryMax: 4.0G`
+
+	payload, err := ParseOOMKilled(subject, body)
+	if err != nil {
+		t.Fatalf("ParseOOMKilled() error = %v", err)
+	}
+	if payload.PolecatName != "nux" {
+		t.Errorf("PolecatName = %q, want %q", payload.PolecatName, "nux")
+	}
+	if payload.SessionID != "gt-gastown-nux" {
+		t.Errorf("SessionID = %q, want %q", payload.SessionID, "gt-gastown-nux")
+	}
+	if payload.IssueID != "gt-abc123" {
+		t.Errorf("IssueID = %q, want %q", payload.IssueID, "gt-abc123")
+	}
+	if payload.MemoryMax != "4.0G" {
+		t.Errorf("MemoryMax = %q, want %q", payload.MemoryMax, "4.0G")
+	}
+
+	if _, err := ParseOOMKilled("Not OOM", body); err == nil {
+		t.Error("ParseOOMKilled() expected error for invalid subject")
+	}
+}
@@ -0,0 +1,339 @@
+package witness
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// Default parameters for thrash detection. A polecat is thrashing when it
+// is active but not converging: re-running the same tool calls and failing
+// tests, or rewriting and reverting its own commits.
+const (
+	DefaultThrashCaptureLines  = 200            // pane lines sampled per patrol
+	DefaultThrashWindow        = 2 * time.Hour  // git history considered
+	DefaultRepetitionThreshold = 0.6            // share of repeated actions
+	DefaultChurnThreshold      = 0.5            // share of lines rewritten or reverted
+	DefaultThrashConsecutive   = 2              // samples over threshold before flagging
+	DefaultThrashCooldown      = 1 * time.Hour  // minimum time between escalations
+	thrashMinActions           = 8              // fewer actions can't show a loop
+	thrashMinChurnLines        = 20             // fewer changed lines can't show churn
+	thrashRevertWeight         = 0.3            // churn per self-revert
+	thrashStateTTL             = 24 * time.Hour // forget polecats not sampled this long
+	thrashStateFile            = "thrash.json"  // in the rig's .runtime directory
+)
+
+// ThrashConfig holds the thrash detector's thresholds.
+type ThrashConfig struct {
+	CaptureLines        int           // pane lines sampled per patrol
+	Window              time.Duration // git history considered
+	RepetitionThreshold float64       // share of repeated actions
+	ChurnThreshold      float64       // share of lines rewritten or reverted
+	Consecutive         int           // samples over threshold before flagging
+	Cooldown            time.Duration // minimum time between escalations
+}
+
+// DefaultThrashConfig returns the default thrash detection config.
+func DefaultThrashConfig() *ThrashConfig {
+	return &ThrashConfig{
+		CaptureLines:        DefaultThrashCaptureLines,
+		Window:              DefaultThrashWindow,
+		RepetitionThreshold: DefaultRepetitionThreshold,
+		ChurnThreshold:      DefaultChurnThreshold,
+		Consecutive:         DefaultThrashConsecutive,
+		Cooldown:            DefaultThrashCooldown,
+	}
+}
+
+// ThrashReport is one sample of a polecat's repetition and churn.
+type ThrashReport struct {
+	Polecat string `json:"polecat"`
+
+	// Repetition is the share of tool calls and test failures in the pane
+	// that repeat an earlier one (0 when there are too few to tell).
+	Repetition  float64 `json:"repetition"`
+	Actions     int     `json:"actions"`
+	Repeated    string  `json:"repeated,omitempty"` // the most repeated action
+	RepeatCount int     `json:"repeat_count,omitempty"`
+
+	// Churn is the share of the branch's recent line changes that rewrite
+	// the same files back and forth, raised by reverts of its own commits.
+	Churn        float64  `json:"churn"`
+	Commits      int      `json:"commits"`
+	Reverts      int      `json:"reverts,omitempty"`
+	ChurnedFiles []string `json:"churned_files,omitempty"`
+
+	// Streak counts consecutive samples over a threshold; the polecat is
+	// Thrashing once it reaches ThrashConfig.Consecutive.
+	Streak    int    `json:"streak"`
+	Thrashing bool   `json:"thrashing"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+var (
+	// "⏺ Bash(go test ./...)", "● Read(internal/foo.go)"
+	thrashToolCallRe = regexp.MustCompile(`^[⏺●]\s*([A-Z][A-Za-z]*\(.*)$`)
+
+	// "--- FAIL: TestFoo (0.01s)", "FAIL	github.com/x/y	0.52s"
+	thrashFailureRe = regexp.MustCompile(`^(--- FAIL: \S+|FAIL\s+\S+)`)
+
+	// Run-to-run noise: durations and commit hashes.
+	thrashDurationRe = regexp.MustCompile(`\b\d+(\.\d+)?(ms|s|m)\b`)
+	thrashSHARe      = regexp.MustCompile(`\b[0-9a-f]{7,40}\b`)
+)
+
+// ScoreThrash scores a polecat from its captured pane and the commits on
+// its branch within the window.
+func ScoreThrash(polecat, pane string, commits []git.CommitStat) *ThrashReport {
+	r := &ThrashReport{Polecat: polecat, Commits: len(commits)}
+	r.Repetition, r.Actions, r.Repeated, r.RepeatCount = repetitionScore(pane)
+	r.Churn, r.Reverts, r.ChurnedFiles = churnScore(commits)
+	return r
+}
+
+// paneActions extracts normalized tool calls and test failures from pane
+// output, oldest first.
+func paneActions(pane string) []string {
+	var actions []string
+	for _, line := range strings.Split(pane, "\n") {
+		line = strings.TrimLeft(strings.TrimSpace(line), "⎿│ ")
+		var action string
+		if m := thrashToolCallRe.FindStringSubmatch(line); m != nil {
+			action = m[1]
+		} else if m := thrashFailureRe.FindStringSubmatch(line); m != nil {
+			action = strings.Join(strings.Fields(m[1]), " ")
+		} else {
+			continue
+		}
+		action = thrashDurationRe.ReplaceAllString(action, "Ns")
+		action = thrashSHARe.ReplaceAllString(action, "SHA")
+		actions = append(actions, action)
+	}
+	return actions
+}
+
+// repetitionScore returns the share of actions that repeat an earlier one,
+// the number of actions, and the most repeated action with its count.
+func repetitionScore(pane string) (score float64, total int, top string, topCount int) {
+	actions := paneActions(pane)
+	total = len(actions)
+	counts := make(map[string]int)
+	for _, a := range actions {
+		counts[a]++
+		if counts[a] > topCount || (counts[a] == topCount && a < top) {
+			top, topCount = a, counts[a]
+		}
+	}
+	if total < thrashMinActions {
+		return 0, total, "", 0
+	}
+	if topCount < 2 {
+		top, topCount = "", 0
+	}
+	return float64(total-len(counts)) / float64(total), total, top, topCount
+}
+
+// churnScore measures how much of the commits' line changes went into
+// files rewritten back and forth (touched by three or more commits, with
+// both additions and deletions), and counts commits that revert others in
+// the same set.
+func churnScore(commits []git.CommitStat) (score float64, reverts int, files []string) {
+	type fileChurn struct{ touches, added, deleted int }
+	perFile := make(map[string]*fileChurn)
+	var total int
+	for _, c := range commits {
+		if isSelfRevert(c, commits) {
+			reverts++
+		}
+		for _, f := range c.Files {
+			fc := perFile[f.Path]
+			if fc == nil {
+				fc = &fileChurn{}
+				perFile[f.Path] = fc
+			}
+			fc.touches++
+			fc.added += f.Added
+			fc.deleted += f.Deleted
+			total += f.Added + f.Deleted
+		}
+	}
+
+	if total >= thrashMinChurnLines {
+		var churned int
+		for path, fc := range perFile {
+			if fc.touches < 3 {
+				continue
+			}
+			if n := 2 * min(fc.added, fc.deleted); n > 0 {
+				churned += n
+				files = append(files, path)
+			}
+		}
+		score = float64(churned) / float64(total)
+	}
+	sort.Strings(files)
+	return min(1, max(score, thrashRevertWeight*float64(reverts))), reverts, files
+}
+
+// isSelfRevert reports whether c reverts one of the other commits, or is
+// titled as a revert (the reverted commit may predate the window).
+func isSelfRevert(c git.CommitStat, commits []git.CommitStat) bool {
+	if strings.HasPrefix(c.Subject, `Revert "`) {
+		return true
+	}
+	if c.Reverts == "" {
+		return false
+	}
+	for _, other := range commits {
+		if strings.HasPrefix(other.SHA, c.Reverts) {
+			return true
+		}
+	}
+	return false
+}
+
+// ThrashState is the detector's memory between patrols, so a polecat is
+// only flagged when it stays over a threshold. It lives in the rig's
+// .runtime directory.
+type ThrashState struct {
+	Polecats map[string]*PolecatThrash `json:"polecats"`
+}
+
+// PolecatThrash is one polecat's detector state.
+type PolecatThrash struct {
+	PaneHash      string    `json:"pane_hash,omitempty"` // last sampled pane
+	Streak        int       `json:"streak"`
+	LastSample    time.Time `json:"last_sample"`
+	LastEscalated time.Time `json:"last_escalated,omitempty"`
+}
+
+// ThrashStatePath returns the thrash state file: <rig>/.runtime/thrash.json.
+func ThrashStatePath(rigPath string) string {
+	return filepath.Join(constants.RigRuntimePath(rigPath), thrashStateFile)
+}
+
+// LoadThrashState loads the detector state. A missing file returns an
+// empty state.
+func LoadThrashState(path string) (*ThrashState, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is constructed internally
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &ThrashState{Polecats: make(map[string]*PolecatThrash)}, nil
+		}
+		return nil, fmt.Errorf("reading thrash state: %w", err)
+	}
+	var s ThrashState
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parsing thrash state: %w", err)
+	}
+	if s.Polecats == nil {
+		s.Polecats = make(map[string]*PolecatThrash)
+	}
+	return &s, nil
+}
+
+// Save writes the state to path, forgetting polecats that haven't been
+// sampled for a day (they have been nuked).
+func (s *ThrashState) Save(path string, now time.Time) error {
+	for name, p := range s.Polecats {
+		if now.Sub(p.LastSample) > thrashStateTTL {
+			delete(s.Polecats, name)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating thrash state dir: %w", err)
+	}
+	return util.AtomicWriteJSON(path, s)
+}
+
+// Observe records a sample and decides whether the polecat is thrashing,
+// filling in r's Streak, Thrashing and Reason. A pane unchanged since the
+// last sample doesn't count as repetition: the polecat is idle or stalled,
+// which the stuck checks handle. It returns true if the polecat should be
+// escalated now, i.e. it is thrashing and wasn't escalated within the
+// cooldown; call MarkEscalated once the escalation is sent.
+func (s *ThrashState) Observe(r *ThrashReport, pane string, cfg *ThrashConfig, now time.Time) bool {
+	p := s.Polecats[r.Polecat]
+	if p == nil {
+		p = &PolecatThrash{}
+		s.Polecats[r.Polecat] = p
+	}
+	sum := sha256.Sum256([]byte(pane))
+	hash := hex.EncodeToString(sum[:8])
+
+	var reasons []string
+	if r.Repetition >= cfg.RepetitionThreshold && hash != p.PaneHash {
+		reasons = append(reasons, fmt.Sprintf("repeated %q %d times (%d of %d actions are repeats)",
+			r.Repeated, r.RepeatCount, int(r.Repetition*float64(r.Actions)+0.5), r.Actions))
+	}
+	if r.Churn >= cfg.ChurnThreshold {
+		if r.Reverts > 0 {
+			reasons = append(reasons, fmt.Sprintf("reverted its own commits %d time(s)", r.Reverts))
+		}
+		if len(r.ChurnedFiles) > 0 {
+			reasons = append(reasons, fmt.Sprintf("rewrote %s back and forth over %d commits",
+				strings.Join(r.ChurnedFiles, ", "), r.Commits))
+		}
+	}
+
+	p.PaneHash = hash
+	p.LastSample = now
+	if len(reasons) == 0 {
+		p.Streak = 0
+	} else {
+		p.Streak++
+	}
+	r.Streak = p.Streak
+	r.Reason = strings.Join(reasons, "; ")
+	r.Thrashing = p.Streak >= cfg.Consecutive
+	return r.Thrashing && now.Sub(p.LastEscalated) >= cfg.Cooldown
+}
+
+// MarkEscalated records that a polecat's thrashing was escalated.
+func (s *ThrashState) MarkEscalated(polecat string, now time.Time) {
+	if p := s.Polecats[polecat]; p != nil {
+		p.LastEscalated = now
+	}
+}
+
+// ThrashHelpPayload builds the HELP request the Witness files on behalf of
+// a thrashing polecat.
+func ThrashHelpPayload(rigName, issueID string, r *ThrashReport, now time.Time) *HelpPayload {
+	return &HelpPayload{
+		Topic:   "Thrashing",
+		Agent:   fmt.Sprintf("%s/polecats/%s", rigName, r.Polecat),
+		IssueID: issueID,
+		Problem: "Looping without progress: " + r.Reason,
+		Tried: fmt.Sprintf("Detected by Witness over %d patrols (repetition %.2f, churn %.2f)",
+			r.Streak, r.Repetition, r.Churn),
+		RequestedAt: now,
+	}
+}
+
+// EscalateThrashing assesses a thrashing polecat's HELP request with
+// AssessHelpRequest and escalates it to the Mayor if it needs escalation.
+// Returns the assessment and the ID of the mail sent, if any.
+func EscalateThrashing(router *mail.Router, rigName, issueID string, r *ThrashReport) (*HelpAssessment, string, error) {
+	payload := ThrashHelpPayload(rigName, issueID, r, time.Now())
+	assessment := AssessHelpRequest(payload)
+	if !assessment.NeedsEscalation {
+		return assessment, "", nil
+	}
+	mailID, err := escalateToMayor(router, rigName, payload, assessment.EscalationReason)
+	if err != nil {
+		return assessment, "", fmt.Errorf("escalating to mayor: %w", err)
+	}
+	return assessment, mailID, nil
+}
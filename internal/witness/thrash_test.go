@@ -0,0 +1,158 @@
+package witness
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// loopingPane is a polecat re-running the same failing test after each
+// edit to the same file.
+func loopingPane(round int) string {
+	var b strings.Builder
+	for i := 0; i < 4; i++ {
+		fmt.Fprintf(&b, "⏺ Update(internal/auth/login.go)\n")
+		fmt.Fprintf(&b, "  ⎿  Updated internal/auth/login.go with 1 addition\n")
+		fmt.Fprintf(&b, "⏺ Bash(go test ./internal/auth/...)\n")
+		fmt.Fprintf(&b, "  ⎿  --- FAIL: TestLogin (0.0%ds)\n", i+round)
+		fmt.Fprintf(&b, "     FAIL\tgithub.com/x/app/internal/auth\t0.%d2s\n", i)
+		fmt.Fprintf(&b, "⏺ The test still fails. Let me try a different approach.\n")
+	}
+	return b.String()
+}
+
+func progressingPane() string {
+	var b strings.Builder
+	for _, f := range []string{"a.go", "b.go", "c.go", "d.go", "e.go"} {
+		fmt.Fprintf(&b, "⏺ Read(internal/%s)\n", f)
+		fmt.Fprintf(&b, "⏺ Update(internal/%s)\n", f)
+	}
+	b.WriteString("⏺ Bash(go test ./...)\n  ⎿  ok  \tgithub.com/x/app\t0.52s\n")
+	return b.String()
+}
+
+func TestRepetitionScore(t *testing.T) {
+	score, total, top, count := repetitionScore(loopingPane(0))
+	if total != 16 || score < 0.7 {
+		t.Errorf("looping pane: score %.2f over %d actions", score, total)
+	}
+	if top != "--- FAIL: TestLogin" || count != 4 {
+		t.Errorf("top = %q x%d", top, count)
+	}
+
+	if score, total, _, _ := repetitionScore(progressingPane()); score != 0 || total != 11 {
+		t.Errorf("progressing pane: score %.2f over %d actions", score, total)
+	}
+
+	// Too few actions to call it a loop.
+	if score, _, _, _ := repetitionScore("⏺ Bash(make)\n⏺ Bash(make)\n⏺ Bash(make)\n"); score != 0 {
+		t.Errorf("short pane scored %.2f", score)
+	}
+}
+
+func TestChurnScore(t *testing.T) {
+	edit := func(sha, subject string, added, deleted int) git.CommitStat {
+		return git.CommitStat{SHA: sha, Subject: subject,
+			Files: []git.FileStat{{Path: "login.go", Added: added, Deleted: deleted}, {Path: "README.md", Added: 2}}}
+	}
+
+	// Steady progress: one file grows over several commits.
+	steady := []git.CommitStat{edit("a1", "step", 30, 0), edit("a2", "step", 25, 2), edit("a3", "step", 20, 1)}
+	if score, reverts, files := churnScore(steady); score > 0.2 || reverts != 0 {
+		t.Errorf("steady: score %.2f, reverts %d, files %v", score, reverts, files)
+	}
+
+	// Rewriting the same function back and forth.
+	rewrites := []git.CommitStat{edit("b1", "try", 20, 18), edit("b2", "try", 18, 20), edit("b3", "try", 20, 19)}
+	score, _, files := churnScore(rewrites)
+	if score < 0.8 || strings.Join(files, ",") != "login.go" {
+		t.Errorf("rewrites: score %.2f, files %v", score, files)
+	}
+
+	// Reverting its own commits.
+	reverted := []git.CommitStat{
+		{SHA: "c3", Subject: "Reapply fix", Files: []git.FileStat{{Path: "x.go", Added: 5}}},
+		{SHA: "c2", Subject: "Undo fix", Reverts: "c1"},
+		{SHA: "c1", Subject: "Fix", Files: []git.FileStat{{Path: "x.go", Added: 5}}},
+		{SHA: "c0", Subject: `Revert "Older fix"`},
+	}
+	if score, reverts, _ := churnScore(reverted); reverts != 2 || score < 0.59 {
+		t.Errorf("reverted: score %.2f, reverts %d", score, reverts)
+	}
+}
+
+func TestThrashStateObserve(t *testing.T) {
+	cfg := DefaultThrashConfig()
+	state, err := LoadThrashState(filepath.Join(t.TempDir(), "thrash.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+
+	observe := func(pane string) (*ThrashReport, bool) {
+		r := ScoreThrash("nux", pane, nil)
+		escalate := state.Observe(r, pane, cfg, now)
+		now = now.Add(5 * time.Minute)
+		return r, escalate
+	}
+
+	r, escalate := observe(loopingPane(0))
+	if r.Streak != 1 || r.Thrashing || escalate || !strings.Contains(r.Reason, `repeated "--- FAIL: TestLogin" 4 times`) {
+		t.Fatalf("first sample: %+v, escalate %v", r, escalate)
+	}
+
+	// An unchanged pane is stalled, not looping.
+	if r, _ = observe(loopingPane(0)); r.Streak != 0 {
+		t.Errorf("unchanged pane kept the streak: %+v", r)
+	}
+
+	observe(loopingPane(1))
+	r, escalate = observe(loopingPane(2))
+	if !r.Thrashing || !escalate {
+		t.Fatalf("second consecutive sample: %+v, escalate %v", r, escalate)
+	}
+	state.MarkEscalated("nux", now)
+
+	// Still thrashing, but within the cooldown.
+	if r, escalate = observe(loopingPane(3)); !r.Thrashing || escalate {
+		t.Errorf("within cooldown: %+v, escalate %v", r, escalate)
+	}
+
+	// Progress resets the streak.
+	if r, _ = observe(progressingPane()); r.Thrashing || r.Streak != 0 || r.Reason != "" {
+		t.Errorf("after progress: %+v", r)
+	}
+
+	// State round-trips, and long-gone polecats are forgotten.
+	path := filepath.Join(t.TempDir(), "thrash.json")
+	state.Polecats["gone"] = &PolecatThrash{LastSample: now.Add(-48 * time.Hour)}
+	if err := state.Save(path, now); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadThrashState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := loaded.Polecats["gone"]; ok || loaded.Polecats["nux"].LastEscalated.IsZero() {
+		t.Errorf("loaded = %+v", loaded.Polecats)
+	}
+}
+
+func TestThrashHelpPayloadEscalates(t *testing.T) {
+	r := &ThrashReport{Polecat: "nux", Repetition: 0.75, Streak: 2,
+		Reason: `repeated "--- FAIL: TestLogin" 4 times (12 of 16 actions are repeats)`}
+	payload := ThrashHelpPayload("gastown", "gt-abc", r, time.Now())
+	if payload.Agent != "gastown/polecats/nux" || payload.IssueID != "gt-abc" {
+		t.Errorf("payload = %+v", payload)
+	}
+
+	// The problem mentions a failing test, but thrashing is what matters.
+	assessment := AssessHelpRequest(payload)
+	if assessment.CanHelp || !assessment.NeedsEscalation || !strings.Contains(assessment.EscalationReason, "looping") {
+		t.Errorf("assessment = %+v", assessment)
+	}
+}
@@ -11,6 +11,17 @@ Examples:
   python3 gen_hanoi.py 15  # 32767 moves (~4.7MB)
   python3 gen_hanoi.py 20  # 1048575 moves (~163MB)
 
+The checked-in towers-of-hanoi-{7,9,10} formulas are generated by this
+script; regenerate them instead of editing by hand:
+
+  for n in 7 9 10; do
+    python3 scripts/gen_hanoi.py $n > .beads/formulas/towers-of-hanoi-$n.formula.toml
+  done
+  go generate ./internal/formula   # copies them into internal/formula/formulas/
+
+The output is a flat formula (no extends/for_each), since gt sling hands
+it to bd cook, which doesn't understand gt's composition syntax.
+
 The generated formula creates a sequential workflow where each move
 depends on the previous one. This tests Gas Town's ability to:
 - Create large molecule hierarchies